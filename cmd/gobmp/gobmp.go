@@ -1,10 +1,15 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"net/http"
 	_ "net/http/pprof"
@@ -13,6 +18,7 @@ import (
 	"github.com/sbezverk/gobmp/pkg/dumper"
 	"github.com/sbezverk/gobmp/pkg/gobmpsrv"
 	"github.com/sbezverk/gobmp/pkg/kafka"
+	"github.com/sbezverk/gobmp/pkg/metrics"
 	"github.com/sbezverk/gobmp/pkg/pub"
 )
 
@@ -23,21 +29,67 @@ var (
 	kafkaSrv    string
 	intercept   bool
 	dumpmessage bool
+	queues      = gobmpsrv.DefaultQueueConfig()
+	framing     = gobmpsrv.DefaultFramingConfig()
+	recovery    string
+	routers     string
+	reconnect   = gobmpsrv.DefaultReconnectConfig()
+	security    gobmpsrv.SecurityConfig
+	tlsCert     string
+	tlsKey      string
+	tlsClientCA string
+	allowlist   string
+	md5Keys     string
+	listeners   string
+	shutdown    time.Duration
+	interceptTo string
+	interceptor = gobmpsrv.DefaultInterceptConfig()
+	raw         gobmpsrv.RawConfig
+	record      = gobmpsrv.DefaultRecordConfig()
 )
 
 func init() {
-	flag.IntVar(&srcPort, "source-port", 5000, "port exposed to outside")
+	flag.IntVar(&srcPort, "source-port", 5000, "port exposed to outside, used when listeners is not set")
+	flag.StringVar(&listeners, "listeners", "", "comma separated list of addresses to listen on, an address can be followed by \"=label\" attached to all messages of the listener's sessions, for example \"192.0.2.1:5000=region1,[2001:db8::1]:5000=region2\"")
 	flag.IntVar(&dstPort, "destination-port", 5050, "port openBMP is listening")
 	flag.StringVar(&kafkaSrv, "kafka-server", "", "URL to access Kafka server")
-	flag.BoolVar(&intercept, "intercept", false, "Mode of operation, in intercept mode, when intercept set \"true\", all incomming BMP messges will be copied to TCP port specified by destination-port, otherwise received BMP messages will be published to Kafka.")
-	flag.IntVar(&perfPort, "performance-port", 56767, "port used for performance debugging")
+	flag.BoolVar(&intercept, "intercept", false, "Mode of operation, in intercept mode, when intercept set \"true\", all incomming BMP messges will be copied to TCP port specified by destination-port or to intercept-destinations, otherwise received BMP messages will be published to Kafka.")
+	flag.StringVar(&interceptTo, "intercept-destinations", "", "comma separated list of collectors' host:port BMP messages are copied to in intercept mode, when not set, messages are copied to destination-port on localhost")
+	flag.IntVar(&interceptor.QueueDepth, "intercept-queue-depth", interceptor.QueueDepth, "number of BMP messages per session waiting to be copied to each intercept destination, when full, messages are dropped for the destination")
+	flag.DurationVar(&interceptor.WriteTimeout, "intercept-write-timeout", interceptor.WriteTimeout, "time a write to an intercept destination can take before the destination is reconnected")
+	flag.DurationVar(&interceptor.Reconnect.InitialDelay, "intercept-reconnect-initial-delay", interceptor.Reconnect.InitialDelay, "delay before reconnecting to an intercept destination, doubles after each failed attempt")
+	flag.DurationVar(&interceptor.Reconnect.MaxDelay, "intercept-reconnect-max-delay", interceptor.Reconnect.MaxDelay, "maximum delay between attempts to reconnect to an intercept destination")
+	flag.IntVar(&perfPort, "performance-port", 56767, "port used for performance debugging, expvar statistics, Prometheus metrics and read-only admin API")
 	flag.BoolVar(&dumpmessage, "dump-message", false, "Dump resulting messages to standard output")
+	flag.IntVar(&queues.ParserQueueDepth, "parser-queue-depth", queues.ParserQueueDepth, "number of raw BMP messages per session waiting to be parsed, when full reading from the router is paused")
+	flag.IntVar(&queues.ProducerQueueDepth, "producer-queue-depth", queues.ProducerQueueDepth, "number of parsed BMP messages per session waiting to be produced")
+	flag.IntVar(&queues.Workers, "producer-workers", queues.Workers, "number of workers per session producing messages in parallel")
+	flag.IntVar(&queues.WorkerQueueDepth, "worker-queue-depth", queues.WorkerQueueDepth, "number of messages waiting in each producer worker's queue")
+	flag.StringVar(&recovery, "framing-recovery", framing.Recovery.String(), "recovery from BMP framing errors, \"resync\" skips bytes until a valid BMP message header is found, \"close\" closes the session")
+	flag.IntVar(&framing.MaxMessageLength, "max-message-length", framing.MaxMessageLength, "largest BMP message length accepted as valid")
+	flag.StringVar(&routers, "active-routers", "", "comma separated list of routers' host:port, the collector dials out to in active mode, in addition to listening on source-port")
+	flag.DurationVar(&reconnect.InitialDelay, "reconnect-initial-delay", reconnect.InitialDelay, "delay before reconnecting to a router in active mode, doubles after each failed attempt")
+	flag.DurationVar(&reconnect.MaxDelay, "reconnect-max-delay", reconnect.MaxDelay, "maximum delay between attempts to reconnect to a router in active mode")
+	flag.DurationVar(&reconnect.DialTimeout, "dial-timeout", reconnect.DialTimeout, "timeout of a single attempt to connect to a router in active mode")
+	flag.StringVar(&tlsCert, "tls-cert", "", "server certificate file, when set with tls-key, BMP sessions are accepted over TLS")
+	flag.StringVar(&tlsKey, "tls-key", "", "server private key file")
+	flag.StringVar(&tlsClientCA, "tls-client-ca", "", "CA certificates file, when set, routers must present a certificate signed by one of the CAs")
+	flag.StringVar(&allowlist, "allowlist", "", "comma separated list of source prefixes allowed to connect, a prefix can be followed by \"=name\" identifying routers connecting from the prefix, for example \"10.0.0.0/8,192.0.2.1=r1\"")
+	flag.StringVar(&md5Keys, "tcp-md5-keys", "", "file with TCP-MD5 signature keys, each line carries a source prefix and the key separated by a white space, Linux only")
+	flag.IntVar(&security.MaxConnectionsPerSource, "max-connections-per-source", 0, "maximum number of concurrent BMP sessions from a single source address, 0 means no limit")
+	flag.DurationVar(&shutdown, "shutdown-timeout", 20*time.Second, "time to wait on shutdown for messages received from routers to be published")
+	flag.BoolVar(&raw.Enabled, "publish-raw", false, "when set to true, every BMP message is also published as received from the router, wrapped in OpenBMP v2 binary header, to gobmp.bmp_raw topic")
+	flag.StringVar(&raw.AdminID, "collector-admin-id", "", "collector's identity in OpenBMP binary header of raw BMP messages, defaults to the host name")
+	flag.StringVar(&record.Dir, "record-dir", "", "directory BMP sessions are recorded to, recordings can be replayed with \"gobmp replay\", recording is disabled when not set")
+	flag.Int64Var(&record.MaxFileSize, "record-max-file-size", record.MaxFileSize, "size in bytes after which a session's recording continues in a new file, 0 means no limit")
+	flag.DurationVar(&record.MaxFileAge, "record-max-file-age", record.MaxFileAge, "time after which a session's recording continues in a new file, 0 means no limit")
+	flag.IntVar(&framing.MaxResyncBytes, "max-resync-bytes", framing.MaxResyncBytes, "number of bytes skipped while resynchronizing BMP stream, after which the session is closed")
 
 }
 
 var (
 	onlyOneSignalHandler = make(chan struct{})
-	shutdownSignals      = []os.Signal{os.Interrupt}
+	shutdownSignals      = []os.Signal{os.Interrupt, syscall.SIGTERM}
 )
 
 func setupSignalHandler() (stopCh <-chan struct{}) {
@@ -56,39 +108,148 @@ func setupSignalHandler() (stopCh <-chan struct{}) {
 	return stop
 }
 
+// newPublisher returns Kafka publisher or the dumper when messages are dumped to standard output
+func newPublisher() (pub.Publisher, error) {
+	if dumpmessage {
+		return dumper.NewDumper(), nil
+	}
+
+	return kafka.NewKafkaPublisher(kafkaSrv)
+}
+
+// setupRaw sets the collector's identity in OpenBMP binary header of raw BMP messages to the host name
+// if it is not configured.
+func setupRaw() error {
+	if raw.AdminID != "" {
+		return nil
+	}
+	var err error
+	if raw.AdminID, err = os.Hostname(); err != nil {
+		return fmt.Errorf("fail to get host name with error: %+v", err)
+	}
+
+	return nil
+}
+
 func main() {
+	var err error
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		os.Exit(replay(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "pcap" {
+		os.Exit(capture(os.Args[2:]))
+	}
 	flag.Parse()
 	_ = flag.Set("logtostderr", "true")
+	if framing.Recovery, err = gobmpsrv.ParseRecoveryPolicy(recovery); err != nil {
+		glog.Errorf("%+v", err)
+		os.Exit(1)
+	}
+	if tlsCert != "" || tlsKey != "" {
+		if security.TLS, err = gobmpsrv.LoadTLSConfig(tlsCert, tlsKey, tlsClientCA); err != nil {
+			glog.Errorf("fail to setup TLS with error: %+v", err)
+			os.Exit(1)
+		}
+	}
+	if routers != "" {
+		if err := reconnect.Validate(); err != nil {
+			glog.Errorf("%+v", err)
+			os.Exit(1)
+		}
+	}
+	if listeners == "" {
+		listeners = fmt.Sprintf(":%d", srcPort)
+	}
+	bmpListeners, err := gobmpsrv.ParseListeners(listeners)
+	if err != nil {
+		glog.Errorf("%+v", err)
+		os.Exit(1)
+	}
+	if security.Allowlist, err = gobmpsrv.ParseAllowlist(allowlist); err != nil {
+		glog.Errorf("%+v", err)
+		os.Exit(1)
+	}
+	if md5Keys != "" {
+		if security.MD5Keys, err = gobmpsrv.LoadMD5Keys(md5Keys); err != nil {
+			glog.Errorf("fail to load TCP-MD5 keys with error: %+v", err)
+			os.Exit(1)
+		}
+	}
+	if intercept {
+		if interceptTo == "" {
+			interceptTo = fmt.Sprintf(":%d", dstPort)
+		}
+		if interceptor.Destinations, err = gobmpsrv.ParseDestinations(interceptTo); err != nil {
+			glog.Errorf("%+v", err)
+			os.Exit(1)
+		}
+		if err := interceptor.Reconnect.Validate(); err != nil {
+			glog.Errorf("intercept: %+v", err)
+			os.Exit(1)
+		}
+	}
+	if err := setupRaw(); err != nil {
+		glog.Errorf("%+v", err)
+		os.Exit(1)
+	}
 	// Initializing Kafka publisher
 	// other publishers sutisfying pub.Publisher interface can be used.
+	// Read-only admin API and Prometheus metrics are served on the performance port along with pprof and expvar
+	http.Handle("/api/", gobmpsrv.NewAdminHandler())
+	http.Handle("/metrics", metrics.Handler())
 	go func() {
 		glog.Info(http.ListenAndServe(fmt.Sprintf(":%d", perfPort), nil))
 	}()
-	var publisher pub.Publisher
-	var err error
-	if !dumpmessage {
-		publisher, err = kafka.NewKafkaPublisher(kafkaSrv)
-		if err != nil {
-			glog.Warningf("Kafka publisher is disabled, no Kafka server URL is provided.")
-		} else {
-			glog.V(6).Infof("Kafka publisher has been successfully initialized.")
-		}
+	publisher, err := newPublisher()
+	if err != nil {
+		glog.Warningf("Kafka publisher is disabled, no Kafka server URL is provided.")
 	} else {
-		publisher = dumper.NewDumper()
+		glog.V(6).Infof("Kafka publisher has been successfully initialized.")
 	}
 
 	// Initializing bmp server
-	bmpSrv, err := gobmpsrv.NewBMPServer(srcPort, dstPort, intercept, publisher)
+	bmpSrv, err := gobmpsrv.NewBMPServer(bmpListeners, interceptor, publisher, raw, record, queues, framing, security)
 	if err != nil {
 		glog.Errorf("fail to setup new bmp server with error: %+v", err)
 		os.Exit(1)
 	}
 	// Starting Interceptor server
 	bmpSrv.Start()
+	var activeSrv gobmpsrv.BMPServer
+	if routers != "" {
+		activeSrv, err = gobmpsrv.NewBMPActiveServer(strings.Split(routers, ","), interceptor, publisher, raw, record, queues, framing, reconnect)
+		if err != nil {
+			glog.Errorf("fail to setup new active bmp server with error: %+v", err)
+			os.Exit(1)
+		}
+		activeSrv.Start()
+	}
 
 	stopCh := setupSignalHandler()
 	<-stopCh
 
-	bmpSrv.Stop()
+	// Closing routers' sessions and publishing all messages received before the shutdown
+	ctx, cancel := context.WithTimeout(context.Background(), shutdown)
+	servers := []gobmpsrv.BMPServer{bmpSrv}
+	if activeSrv != nil {
+		servers = append(servers, activeSrv)
+	}
+	var wg sync.WaitGroup
+	for _, srv := range servers {
+		wg.Add(1)
+		go func(srv gobmpsrv.BMPServer) {
+			defer wg.Done()
+			if err := srv.Shutdown(ctx); err != nil {
+				glog.Errorf("%+v", err)
+			}
+		}(srv)
+	}
+	wg.Wait()
+	cancel()
+	if publisher != nil {
+		if err := publisher.Close(); err != nil {
+			glog.Errorf("fail to close publisher with error: %+v", err)
+		}
+	}
 	os.Exit(0)
 }
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/sbezverk/gobmp/pkg/gobmpsrv"
+	"github.com/sbezverk/gobmp/pkg/pcap"
+)
+
+// parsePorts parses comma separated list of TCP ports
+func parsePorts(s string) ([]int, error) {
+	ports := make([]int, 0)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		p, err := strconv.Atoi(entry)
+		if err != nil || p <= 0 || p > 65535 {
+			return nil, fmt.Errorf("invalid port %q, port must be between 1 and 65535", entry)
+		}
+		ports = append(ports, p)
+	}
+	if len(ports) == 0 {
+		return nil, fmt.Errorf("no BMP ports specified")
+	}
+
+	return ports, nil
+}
+
+// readCaptures reads BMP sessions from pcap or pcapng files
+func readCaptures(files []string, ports []int) ([]gobmpsrv.StreamSource, error) {
+	streams := make([]gobmpsrv.StreamSource, 0)
+	for _, name := range files {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		s, err := pcap.ReadStreams(f, ports)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("fail to read %s with error: %+v", name, err)
+		}
+		for _, stream := range s {
+			glog.Infof("%s: BMP session %s", name, stream)
+			streams = append(streams, stream)
+		}
+	}
+
+	return streams, nil
+}
+
+// capture runs "gobmp pcap" command, the command reassembles TCP streams sent to BMP ports from pcap or pcapng
+// files and feeds them through the parser and the producer, it returns the exit code.
+func capture(args []string) int {
+	fs := flag.NewFlagSet("pcap", flag.ExitOnError)
+	// Publishing, queues and framing flags of the collector apply to the captured sessions
+	flag.CommandLine.VisitAll(func(f *flag.Flag) {
+		fs.Var(f.Value, f.Name, f.Usage)
+	})
+	bmpPorts := fs.String("bmp-ports", "5000", "comma separated list of TCP ports of BMP sessions in the captures, the collector's ports of passive sessions and the routers' ports of active sessions")
+	speed := fs.Float64("speed", 0, "replay speed relative to the capture, 2 replays twice as fast, 0 replays as fast as possible")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s pcap [flags] pcap or pcapng files...\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	// Flags are set through fs, marking the command line as parsed for glog
+	flag.CommandLine.Parse(nil)
+	_ = flag.Set("logtostderr", "true")
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+	if *speed < 0 {
+		glog.Errorf("invalid replay speed %v", *speed)
+		return 1
+	}
+	ports, err := parsePorts(*bmpPorts)
+	if err != nil {
+		glog.Errorf("%+v", err)
+		return 1
+	}
+	if framing.Recovery, err = gobmpsrv.ParseRecoveryPolicy(recovery); err != nil {
+		glog.Errorf("%+v", err)
+		return 1
+	}
+	if err := setupRaw(); err != nil {
+		glog.Errorf("%+v", err)
+		return 1
+	}
+	streams, err := readCaptures(fs.Args(), ports)
+	if err != nil {
+		glog.Errorf("fail to read captures with error: %+v", err)
+		return 1
+	}
+	publisher, err := newPublisher()
+	if err != nil {
+		glog.Errorf("fail to initialize publisher with error: %+v", err)
+		return 1
+	}
+	glog.Infof("replaying %d captured sessions at speed %v", len(streams), *speed)
+	gobmpsrv.Replay(streams, *speed, publisher, raw, queues, framing)
+	if err := publisher.Close(); err != nil {
+		glog.Errorf("fail to close publisher with error: %+v", err)
+		return 1
+	}
+
+	return 0
+}
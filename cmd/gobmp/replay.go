@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/golang/glog"
+	"github.com/sbezverk/gobmp/pkg/gobmpsrv"
+)
+
+// replay runs "gobmp replay" command, the command feeds BMP sessions recorded with record-dir through
+// the parser and the producer, it returns the exit code.
+func replay(args []string) int {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	// Publishing, queues and framing flags of the collector apply to the replayed sessions
+	flag.CommandLine.VisitAll(func(f *flag.Flag) {
+		fs.Var(f.Value, f.Name, f.Usage)
+	})
+	speed := fs.Float64("speed", 1, "replay speed relative to the original, 2 replays twice as fast, 0 replays as fast as possible")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s replay [flags] recording files or directories...\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	// Flags are set through fs, marking the command line as parsed for glog
+	flag.CommandLine.Parse(nil)
+	_ = flag.Set("logtostderr", "true")
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+	if *speed < 0 {
+		glog.Errorf("invalid replay speed %v", *speed)
+		return 1
+	}
+	var err error
+	if framing.Recovery, err = gobmpsrv.ParseRecoveryPolicy(recovery); err != nil {
+		glog.Errorf("%+v", err)
+		return 1
+	}
+	if err := setupRaw(); err != nil {
+		glog.Errorf("%+v", err)
+		return 1
+	}
+	streams, err := gobmpsrv.OpenRecordings(fs.Args())
+	if err != nil {
+		glog.Errorf("fail to open recordings with error: %+v", err)
+		return 1
+	}
+	publisher, err := newPublisher()
+	if err != nil {
+		glog.Errorf("fail to initialize publisher with error: %+v", err)
+		return 1
+	}
+	glog.Infof("replaying %d sessions at speed %v", len(streams), *speed)
+	gobmpsrv.Replay(streams, *speed, publisher, raw, queues, framing)
+	if err := publisher.Close(); err != nil {
+		glog.Errorf("fail to close publisher with error: %+v", err)
+		return 1
+	}
+
+	return 0
+}
@@ -11,6 +11,7 @@ replace (
 	github.com/sbezverk/gobmp/pkg/kafka => ../../pkg/kafka
 	github.com/sbezverk/gobmp/pkg/ls => ../../pkg/ls
 	github.com/sbezverk/gobmp/pkg/message => ../../pkg/message
+	github.com/sbezverk/gobmp/pkg/metrics => ../../pkg/metrics
 	github.com/sbezverk/gobmp/pkg/parser => ../../pkg/parser
 	github.com/sbezverk/gobmp/pkg/pub => ../../pkg/pub
 	github.com/sbezverk/gobmp/pkg/sr => ../../pkg/sr
@@ -34,6 +35,7 @@ require (
 	github.com/sbezverk/gobmp/pkg/bgp v0.0.0-00010101000000-000000000000 // indirect
 	github.com/sbezverk/gobmp/pkg/bmp v0.0.0-00010101000000-000000000000 // indirect
 	github.com/sbezverk/gobmp/pkg/message v0.0.0-00010101000000-000000000000 // indirect
+	github.com/sbezverk/gobmp/pkg/metrics v0.0.0-00010101000000-000000000000
 	github.com/sbezverk/gobmp/pkg/pub v0.0.0-00010101000000-000000000000 // indirect
 	github.com/sbezverk/gobmp/pkg/topology/arangodb v0.0.0-00010101000000-000000000000 // indirect
 	github.com/sbezverk/gobmp/pkg/topology/database v0.0.0-00010101000000-000000000000 // indirect
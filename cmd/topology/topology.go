@@ -2,10 +2,13 @@ package main
 
 import (
 	"flag"
+	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 
 	"github.com/golang/glog"
+	"github.com/sbezverk/gobmp/pkg/metrics"
 	"github.com/sbezverk/gobmp/pkg/topology/arangodb"
 	"github.com/sbezverk/gobmp/pkg/topology/dbclient"
 	"github.com/sbezverk/gobmp/pkg/topology/kafkamessenger"
@@ -23,6 +26,7 @@ var (
 	dbName     string
 	dbUser     string
 	dbPass     string
+	perfPort   int
 )
 
 func init() {
@@ -33,6 +37,7 @@ func init() {
 	flag.StringVar(&dbName, "database-name", "", "DB name")
 	flag.StringVar(&dbUser, "database-user", "", "DB User name")
 	flag.StringVar(&dbPass, "database-pass", "", "DB User's password")
+	flag.IntVar(&perfPort, "performance-port", 56768, "port used to serve Prometheus metrics, 0 disables metrics")
 }
 
 var (
@@ -60,6 +65,13 @@ func main() {
 	flag.Parse()
 	_ = flag.Set("logtostderr", "true")
 
+	if perfPort != 0 {
+		http.Handle("/metrics", metrics.Handler())
+		go func() {
+			glog.Info(http.ListenAndServe(fmt.Sprintf(":%d", perfPort), nil))
+		}()
+	}
+
 	var dbSrv dbclient.Srv
 	var err error
 	// Initializing databse client
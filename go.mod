@@ -12,7 +12,9 @@ require (
 	github.com/sbezverk/gobmp/pkg/kafka v0.0.0-00010101000000-000000000000
 	github.com/sbezverk/gobmp/pkg/l3vpn v0.0.0-00010101000000-000000000000 // indirect
 	github.com/sbezverk/gobmp/pkg/message v0.0.0-00010101000000-000000000000 // indirect
+	github.com/sbezverk/gobmp/pkg/metrics v0.0.0-00010101000000-000000000000
 	github.com/sbezverk/gobmp/pkg/parser v0.0.0-00010101000000-000000000000 // indirect
+	github.com/sbezverk/gobmp/pkg/pcap v0.0.0-00010101000000-000000000000
 	github.com/sbezverk/gobmp/pkg/prefixsid v0.0.0-00010101000000-000000000000 // indirect
 	github.com/sbezverk/gobmp/pkg/pub v0.0.0-00010101000000-000000000000 // indirect
 	github.com/sbezverk/gobmp/pkg/unicast v0.0.0-00010101000000-000000000000 // indirect
@@ -32,7 +34,9 @@ replace (
 	github.com/sbezverk/gobmp/pkg/l3vpn => ./pkg/l3vpn
 	github.com/sbezverk/gobmp/pkg/ls => ./pkg/ls
 	github.com/sbezverk/gobmp/pkg/message => ./pkg/message
+	github.com/sbezverk/gobmp/pkg/metrics => ./pkg/metrics
 	github.com/sbezverk/gobmp/pkg/parser => ./pkg/parser
+	github.com/sbezverk/gobmp/pkg/pcap => ./pkg/pcap
 	github.com/sbezverk/gobmp/pkg/prefixsid => ./pkg/prefixsid
 	github.com/sbezverk/gobmp/pkg/pub => ./pkg/pub
 	github.com/sbezverk/gobmp/pkg/sr => ./pkg/sr
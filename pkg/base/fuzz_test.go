@@ -0,0 +1,113 @@
+package base
+
+import "testing"
+
+var (
+	// Protocol ID and Identifier
+	nlriHeader = []byte{0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+	// Local Node Descriptor with AS Number sub tlv
+	localNode = []byte{0x01, 0x00, 0x00, 0x08, 0x02, 0x00, 0x00, 0x04, 0x00, 0x00, 0xfd, 0xe8}
+	// Remote Node Descriptor with AS Number sub tlv
+	remoteNode = []byte{0x01, 0x01, 0x00, 0x08, 0x02, 0x00, 0x00, 0x04, 0x00, 0x00, 0xfd, 0xe9}
+)
+
+func concat(b ...[]byte) []byte {
+	r := []byte{}
+	for _, s := range b {
+		r = append(r, s...)
+	}
+	return r
+}
+
+func FuzzUnmarshalRoutes(f *testing.F) {
+	f.Add([]byte{0x18, 0x0a, 0x01, 0x02, 0x20, 0x0a, 0x00, 0x00, 0x01, 0x00}, false)
+	f.Add([]byte{0x00, 0x00, 0x00, 0x01, 0x18, 0x0a, 0x01, 0x02, 0x00, 0x00, 0x00, 0x02, 0x00}, true)
+	f.Fuzz(func(t *testing.T, b []byte, pathID bool) {
+		routes, err := UnmarshalRoutes(b, pathID)
+		if err != nil {
+			return
+		}
+		for _, r := range routes {
+			_ = r.String()
+		}
+	})
+}
+
+func FuzzUnmarshalNodeNLRI(f *testing.F) {
+	f.Add(concat(nlriHeader, localNode))
+	f.Fuzz(func(t *testing.T, b []byte) {
+		n, err := UnmarshalNodeNLRI(b)
+		if err != nil {
+			return
+		}
+		_ = n.String()
+		n.GetAllAttribute()
+		n.GetNodeProtocolID()
+		n.GetNodeLSID()
+		n.GetNodeIGPRouterID()
+		n.GetNodeASN()
+		n.GetNodeOSPFAreaID()
+	})
+}
+
+func FuzzUnmarshalLinkNLRI(f *testing.F) {
+	// Link Local/Remote Identifiers and IPv4 Interface Address
+	link := []byte{0x01, 0x02, 0x00, 0x08, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x02, 0x01, 0x03, 0x00, 0x04, 0x0a, 0x00, 0x00, 0x01}
+	f.Add(concat(nlriHeader, localNode, remoteNode, link))
+	f.Fuzz(func(t *testing.T, b []byte) {
+		l, err := UnmarshalLinkNLRI(b)
+		if err != nil {
+			return
+		}
+		_ = l.String()
+		l.GetAllAttribute()
+		l.GetLinkProtocolID()
+		for _, local := range []bool{true, false} {
+			l.GetLinkLSID(local)
+			l.GetLinkASN(local)
+			l.GetLinkOSPFAreaID(local)
+			l.GetLinkID(local)
+		}
+		l.GetLinkIPv4InterfaceAddr()
+		l.GetLinkIPv4NeighborAddr()
+		l.GetLinkIPv6InterfaceAddr()
+		l.GetLinkIPv6NeighborAddr()
+		l.GetLocalASN()
+		l.GetRemoteASN()
+		l.GetLocalIGPRouterID()
+		l.GetRemoteIGPRouterID()
+	})
+}
+
+func FuzzUnmarshalPrefixNLRI(f *testing.F) {
+	// IP Reachability Information
+	prefix := []byte{0x01, 0x09, 0x00, 0x04, 0x18, 0x0a, 0x01, 0x02}
+	f.Add(concat(nlriHeader, localNode, prefix), true)
+	f.Fuzz(func(t *testing.T, b []byte, ipv4 bool) {
+		p, err := UnmarshalPrefixNLRI(b, ipv4)
+		if err != nil {
+			return
+		}
+		_ = p.String()
+		p.GetAllAttribute()
+		p.GetPrefixProtocolID()
+		p.GetPrefixASN()
+		p.GetPrefixOSPFAreaID()
+		p.GetPrefixLSID()
+		p.GetLocalIGPRouterID()
+		p.GetLocalASN()
+		pd := p.Prefix
+		if pd == nil {
+			return
+		}
+		_ = pd.String()
+		pd.GetPrefixMTID()
+		pd.GetPrefixIPReachability(ipv4)
+		pd.GetPrefixIGPFlags()
+		pd.GetPrefixOSPFRouteType()
+		pd.GetPrefixIGPRouteTag()
+		pd.GetPrefixIGPExtRouteTag()
+		pd.GetPrefixMetric()
+		pd.GetPrefixOSPFForwardAddr()
+	})
+}
@@ -25,6 +25,9 @@ func (ipr *IPReachabilityInformation) String() string {
 // UnmarshalIPReachabilityInformation builds IP Reachability Information TLV object
 func UnmarshalIPReachabilityInformation(b []byte) (*IPReachabilityInformation, error) {
 	glog.V(6).Infof("IPReachabilityInformationTLV Raw: %s", tools.MessageHex(b))
+	if err := decoder.CheckLength("ip reachability information", b, 1); err != nil {
+		return nil, err
+	}
 	ipr := IPReachabilityInformation{
 		LengthInBits: b[0],
 	}
@@ -32,6 +35,9 @@ func UnmarshalIPReachabilityInformation(b []byte) (*IPReachabilityInformation, e
 	if ipr.LengthInBits%8 != 0 {
 		l++
 	}
+	if err := decoder.CheckLength("ip reachability information", b[1:], int(l)); err != nil {
+		return nil, err
+	}
 	ipr.Prefix = make([]byte, l)
 	copy(ipr.Prefix, b[1:])
 
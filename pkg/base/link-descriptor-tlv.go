@@ -64,11 +64,17 @@ func UnmarshalLinkDescriptorTLV(b []byte) ([]LinkDescriptorTLV, error) {
 	glog.V(6).Infof("LinkDescriptorTLV Raw: %s", tools.MessageHex(b))
 	ltlvs := make([]LinkDescriptorTLV, 0)
 	for p := 0; p < len(b); {
+		if err := decoder.CheckLength("link descriptor tlv", b[p:], 4); err != nil {
+			return nil, err
+		}
 		ltlv := LinkDescriptorTLV{}
 		ltlv.Type = binary.BigEndian.Uint16(b[p : p+2])
 		p += 2
 		ltlv.Length = binary.BigEndian.Uint16(b[p : p+2])
 		p += 2
+		if err := decoder.CheckLength("link descriptor tlv", b[p:], int(ltlv.Length)); err != nil {
+			return nil, err
+		}
 		ltlv.Value = make([]byte, ltlv.Length)
 		copy(ltlv.Value, b[p:p+int(ltlv.Length)])
 		ltlvs = append(ltlvs, ltlv)
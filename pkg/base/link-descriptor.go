@@ -26,7 +26,7 @@ func UnmarshalLinkDescriptor(b []byte) (*LinkDescriptor, error) {
 	glog.V(6).Infof("LinkDescriptor Raw: %s", tools.MessageHex(b))
 	ld := LinkDescriptor{}
 	p := 0
-	ltlv, err := UnmarshalLinkDescriptorTLV(b[p : p+len(b)])
+	ltlv, err := UnmarshalLinkDescriptorTLV(b[p:])
 	if err != nil {
 		return nil, err
 	}
@@ -26,7 +26,7 @@ func UnmarshalLinkMSD(b []byte) (*LinkMSD, error) {
 	glog.V(6).Infof("Link MSD Raw: %s", tools.MessageHex(b))
 	msd := LinkMSD{}
 	p := 0
-	tvsv, err := UnmarshalMSDTV(b[p : p+len(b)])
+	tvsv, err := UnmarshalMSDTV(b[p:])
 	if err != nil {
 		return nil, err
 	}
@@ -169,6 +169,10 @@ func (l *LinkNLRI) GetRemoteIGPRouterID() string {
 // UnmarshalLinkNLRI builds Link NLRI object
 func UnmarshalLinkNLRI(b []byte) (*LinkNLRI, error) {
 	glog.V(6).Infof("LinkNLRI Raw: %s", tools.MessageHex(b))
+	// Protocol ID, Identifier and Local Node Descriptor's Type and Length
+	if err := decoder.CheckLength("link nlri", b, 13); err != nil {
+		return nil, err
+	}
 	l := LinkNLRI{}
 	p := 0
 	l.ProtocolID = b[p]
@@ -180,7 +184,11 @@ func UnmarshalLinkNLRI(b []byte) (*LinkNLRI, error) {
 	// Local Node Descriptor
 	// Get Node Descriptor's length, skip Node Descriptor Type
 	ndl := binary.BigEndian.Uint16(b[p+2 : p+4])
-	ln, err := UnmarshalNodeDescriptor(b[p : p+int(ndl)])
+	// Local Node Descriptor is followed by Remote Node Descriptor's Type and Length
+	if err := decoder.CheckLength("link nlri", b[p+4:], int(ndl)+4); err != nil {
+		return nil, err
+	}
+	ln, err := UnmarshalNodeDescriptor(b[p : p+4+int(ndl)])
 	if err != nil {
 		return nil, err
 	}
@@ -192,7 +200,10 @@ func UnmarshalLinkNLRI(b []byte) (*LinkNLRI, error) {
 	// Remote Node Descriptor
 	// Get Node Descriptor's length, skip Node Descriptor Type
 	ndl = binary.BigEndian.Uint16(b[p+2 : p+4])
-	rn, err := UnmarshalNodeDescriptor(b[p : p+int(ndl)])
+	if err := decoder.CheckLength("link nlri", b[p+4:], int(ndl)); err != nil {
+		return nil, err
+	}
+	rn, err := UnmarshalNodeDescriptor(b[p : p+4+int(ndl)])
 	if err != nil {
 		return nil, err
 	}
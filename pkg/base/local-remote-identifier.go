@@ -35,6 +35,9 @@ func (lri *LocalRemoteIdentifierTLV) GetLinkID(local bool) string {
 // UnmarshalLocalRemoteIdentifierTLV builds Link Descriptor Local/Remote Identifiers TLV object
 func UnmarshalLocalRemoteIdentifierTLV(b []byte) (*LocalRemoteIdentifierTLV, error) {
 	glog.V(6).Infof("LocalRemoteIdentifierTLV Raw: %s", tools.MessageHex(b))
+	if len(b) != 8 {
+		return nil, decoder.Malformed("link local/remote identifiers", "invalid length %d, expected 8", len(b))
+	}
 	l := make([]byte, 4)
 	copy(l, b[:4])
 	r := make([]byte, 4)
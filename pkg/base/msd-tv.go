@@ -24,6 +24,9 @@ func (tv *MSDTV) String() string {
 // UnmarshalMSDTV builds slice of MSD Type Value tuples
 func UnmarshalMSDTV(b []byte) ([]MSDTV, error) {
 	glog.V(6).Infof("UnmarshalMSDTV Raw: %s", tools.MessageHex(b))
+	if len(b)%2 != 0 {
+		return nil, decoder.Malformed("msd", "invalid length %d, expected a multiple of 2", len(b))
+	}
 	tvs := make([]MSDTV, 0)
 	for p := 0; p < len(b); {
 		tv := MSDTV{}
@@ -38,6 +38,9 @@ func (mti *MultiTopologyIdentifierTLV) GetMTID() []uint16 {
 // UnmarshalMultiTopologyIdentifierTLV builds Multi Topology Identifier TLV object
 func UnmarshalMultiTopologyIdentifierTLV(b []byte) (*MultiTopologyIdentifierTLV, error) {
 	glog.V(6).Infof("MultiTopologyIdentifierTLV Raw: %s", tools.MessageHex(b))
+	if len(b)%2 != 0 {
+		return nil, decoder.Malformed("multi-topology identifier", "invalid length %d, expected a multiple of 2", len(b))
+	}
 	mti := MultiTopologyIdentifierTLV{
 		MTI: make([]MultiTopologyIdentifier, 0),
 	}
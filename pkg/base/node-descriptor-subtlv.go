@@ -21,7 +21,9 @@ func (stlv *NodeDescriptorSubTLV) String() string {
 	switch stlv.Type {
 	case 512:
 		s += fmt.Sprintf("      Node Descriptor Sub TLV Type: %d (Autonomous System)\n", stlv.Type)
-		s += fmt.Sprintf("         Autonomous System: %d\n", binary.BigEndian.Uint32(stlv.Value))
+		if len(stlv.Value) == 4 {
+			s += fmt.Sprintf("         Autonomous System: %d\n", binary.BigEndian.Uint32(stlv.Value))
+		}
 	case 513:
 		s += fmt.Sprintf("      Node Descriptor Sub TLV Type: %d (BGP-LS Identifier)\n", stlv.Type)
 		s += fmt.Sprintf("         BGP-LS Identifier: %s\n", tools.MessageHex(stlv.Value))
@@ -47,6 +49,9 @@ func UnmarshalNodeDescriptorSubTLV(b []byte) ([]NodeDescriptorSubTLV, error) {
 	glog.V(6).Infof("NodeDescriptorSubTLV Raw: %s", tools.MessageHex(b))
 	stlvs := make([]NodeDescriptorSubTLV, 0)
 	for p := 0; p < len(b); {
+		if err := decoder.CheckLength("node descriptor sub tlv", b[p:], 4); err != nil {
+			return nil, err
+		}
 		stlv := NodeDescriptorSubTLV{}
 		t := binary.BigEndian.Uint16(b[p : p+2])
 		switch t {
@@ -55,12 +60,15 @@ func UnmarshalNodeDescriptorSubTLV(b []byte) ([]NodeDescriptorSubTLV, error) {
 		case 514:
 		case 515:
 		default:
-			return nil, fmt.Errorf("invalid Node Descriptor Sub TLV type %d", t)
+			return nil, decoder.Malformed("node descriptor sub tlv", "invalid type %d", t)
 		}
 		stlv.Type = t
 		p += 2
 		stlv.Length = binary.BigEndian.Uint16(b[p : p+2])
 		p += 2
+		if err := decoder.CheckLength("node descriptor sub tlv", b[p:], int(stlv.Length)); err != nil {
+			return nil, err
+		}
 		stlv.Value = make([]byte, stlv.Length)
 		copy(stlv.Value, b[p:p+int(stlv.Length)])
 		stlvs = append(stlvs, stlv)
@@ -40,7 +40,7 @@ func (nd *NodeDescriptor) String() string {
 // GetASN returns Autonomous System Number used to uniqely identify BGP-LS domain
 func (nd *NodeDescriptor) GetASN() uint32 {
 	for _, tlv := range nd.SubTLV {
-		if tlv.Type != 512 {
+		if tlv.Type != 512 || len(tlv.Value) != 4 {
 			continue
 		}
 		return binary.BigEndian.Uint32(tlv.Value)
@@ -51,7 +51,7 @@ func (nd *NodeDescriptor) GetASN() uint32 {
 // GetLSID returns BGP-LS Identifier found in Node Descriptor sub tlv
 func (nd *NodeDescriptor) GetLSID() uint32 {
 	for _, tlv := range nd.SubTLV {
-		if tlv.Type != 513 {
+		if tlv.Type != 513 || len(tlv.Value) != 4 {
 			continue
 		}
 		return binary.BigEndian.Uint32(tlv.Value)
@@ -99,13 +99,19 @@ func (nd *NodeDescriptor) GetIGPRouterID() string {
 // UnmarshalNodeDescriptor build Node Descriptor object
 func UnmarshalNodeDescriptor(b []byte) (*NodeDescriptor, error) {
 	glog.V(6).Infof("NodeDescriptor Raw: %s", tools.MessageHex(b))
+	if err := decoder.CheckLength("node descriptor", b, 4); err != nil {
+		return nil, err
+	}
 	nd := NodeDescriptor{}
 	p := 0
 	nd.Type = binary.BigEndian.Uint16(b[p : p+2])
 	p += 2
 	nd.Length = binary.BigEndian.Uint16(b[p : p+2])
 	p += 2
-	stlv, err := UnmarshalNodeDescriptorSubTLV(b[p : p+len(b)])
+	if err := decoder.CheckLength("node descriptor", b[p:], int(nd.Length)); err != nil {
+		return nil, err
+	}
+	stlv, err := UnmarshalNodeDescriptorSubTLV(b[p : p+int(nd.Length)])
 	if err != nil {
 		return nil, err
 	}
@@ -26,7 +26,7 @@ func UnmarshalNodeMSD(b []byte) (*NodeMSD, error) {
 	glog.V(6).Infof("Node MSD Raw: %s", tools.MessageHex(b))
 	msd := NodeMSD{}
 	p := 0
-	tvsv, err := UnmarshalMSDTV(b[p : p+len(b)])
+	tvsv, err := UnmarshalMSDTV(b[p:])
 	if err != nil {
 		return nil, err
 	}
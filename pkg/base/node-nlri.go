@@ -63,6 +63,10 @@ func (n *NodeNLRI) GetNodeOSPFAreaID() string {
 // UnmarshalNodeNLRI builds Node NLRI object
 func UnmarshalNodeNLRI(b []byte) (*NodeNLRI, error) {
 	glog.V(6).Infof("NodeNLRI Raw: %s", tools.MessageHex(b))
+	// Protocol ID, Identifier and Node Descriptor's Type and Length
+	if err := decoder.CheckLength("node nlri", b, 13); err != nil {
+		return nil, err
+	}
 	n := NodeNLRI{}
 	p := 0
 	n.ProtocolID = b[p]
@@ -74,7 +78,10 @@ func UnmarshalNodeNLRI(b []byte) (*NodeNLRI, error) {
 	// Local Node Descriptor
 	// Get Node Descriptor's length, skip Node Descriptor Type
 	ndl := binary.BigEndian.Uint16(b[p+2 : p+4])
-	ln, err := UnmarshalNodeDescriptor(b[p : p+int(ndl)])
+	if err := decoder.CheckLength("node nlri", b[p+4:], int(ndl)); err != nil {
+		return nil, err
+	}
+	ln, err := UnmarshalNodeDescriptor(b[p : p+4+int(ndl)])
 	if err != nil {
 		return nil, err
 	}
@@ -54,11 +54,17 @@ func UnmarshalPrefixDescriptorTLV(b []byte) ([]PrefixDescriptorTLV, error) {
 	glog.V(6).Infof("PrefixDescriptorTLV Raw: %s", tools.MessageHex(b))
 	ptlvs := make([]PrefixDescriptorTLV, 0)
 	for p := 0; p < len(b); {
+		if err := decoder.CheckLength("prefix descriptor tlv", b[p:], 4); err != nil {
+			return nil, err
+		}
 		ptlv := PrefixDescriptorTLV{}
 		ptlv.Type = binary.BigEndian.Uint16(b[p : p+2])
 		p += 2
 		ptlv.Length = binary.BigEndian.Uint16(b[p : p+2])
 		p += 2
+		if err := decoder.CheckLength("prefix descriptor tlv", b[p:], int(ptlv.Length)); err != nil {
+			return nil, err
+		}
 		ptlv.Value = make([]byte, ptlv.Length)
 		copy(ptlv.Value, b[p:p+int(ptlv.Length)])
 		p += int(ptlv.Length)
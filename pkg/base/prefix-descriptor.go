@@ -46,7 +46,7 @@ func (pd *PrefixDescriptor) GetPrefixIPReachability(ipv4 bool) *Route {
 		if tlv.Type != 265 {
 			continue
 		}
-		routes, err := UnmarshalRoutes(tlv.Value)
+		routes, err := UnmarshalRoutes(tlv.Value, false)
 		if err != nil {
 			return nil
 		}
@@ -74,7 +74,7 @@ func (pd *PrefixDescriptor) GetPrefixIPReachability(ipv4 bool) *Route {
 // GetPrefixIGPFlags returns  IGP Flags
 func (pd *PrefixDescriptor) GetPrefixIGPFlags() uint8 {
 	for _, tlv := range pd.PrefixTLV {
-		if tlv.Type != 1152 {
+		if tlv.Type != 1152 || len(tlv.Value) == 0 {
 			continue
 		}
 		return uint8(tlv.Value[0])
@@ -86,7 +86,7 @@ func (pd *PrefixDescriptor) GetPrefixIGPFlags() uint8 {
 // GetPrefixOSPFRouteType returns  OSPF Route type
 func (pd *PrefixDescriptor) GetPrefixOSPFRouteType() uint8 {
 	for _, tlv := range pd.PrefixTLV {
-		if tlv.Type != 264 {
+		if tlv.Type != 264 || len(tlv.Value) == 0 {
 			continue
 		}
 		return uint8(tlv.Value[0])
@@ -102,7 +102,7 @@ func (pd *PrefixDescriptor) GetPrefixIGPRouteTag() []uint32 {
 		if tlv.Type != 1153 {
 			continue
 		}
-		for p := 0; p < len(tlv.Value); {
+		for p := 0; p+4 <= len(tlv.Value); {
 			tag := binary.BigEndian.Uint32(tlv.Value[p : p+4])
 			tags = append(tags, tag)
 			p += 4
@@ -120,7 +120,7 @@ func (pd *PrefixDescriptor) GetPrefixIGPExtRouteTag() []uint64 {
 		if tlv.Type != 1154 {
 			continue
 		}
-		for p := 0; p < len(tlv.Value); {
+		for p := 0; p+8 <= len(tlv.Value); {
 			tag := binary.BigEndian.Uint64(tlv.Value[p : p+8])
 			tags = append(tags, tag)
 			p += 8
@@ -134,7 +134,7 @@ func (pd *PrefixDescriptor) GetPrefixIGPExtRouteTag() []uint64 {
 // GetPrefixMetric returns  Prefix Metric
 func (pd *PrefixDescriptor) GetPrefixMetric() uint32 {
 	for _, tlv := range pd.PrefixTLV {
-		if tlv.Type != 1155 {
+		if tlv.Type != 1155 || len(tlv.Value) < 4 {
 			continue
 		}
 		return binary.BigEndian.Uint32(tlv.Value[0:4])
@@ -163,7 +163,7 @@ func UnmarshalPrefixDescriptor(b []byte) (*PrefixDescriptor, error) {
 	glog.V(6).Infof("PrefixDescriptor Raw: %s", tools.MessageHex(b))
 	pd := PrefixDescriptor{}
 	p := 0
-	ptlv, err := UnmarshalPrefixDescriptorTLV(b[p : p+len(b)])
+	ptlv, err := UnmarshalPrefixDescriptorTLV(b[p:])
 	if err != nil {
 		return nil, err
 	}
@@ -79,6 +79,10 @@ func UnmarshalPrefixNLRI(b []byte, ipv4 bool) (*PrefixNLRI, error) {
 	pr := PrefixNLRI{
 		IsPv4: ipv4,
 	}
+	// Protocol ID, Identifier and Node Descriptor's Type and Length
+	if err := decoder.CheckLength("prefix nlri", b, 13); err != nil {
+		return nil, err
+	}
 	p := 0
 	pr.ProtocolID = b[p]
 	p++
@@ -86,7 +90,10 @@ func UnmarshalPrefixNLRI(b []byte, ipv4 bool) (*PrefixNLRI, error) {
 	p += 8
 	// Get Node Descriptor's length, skip Node Descriptor Type
 	ndl := binary.BigEndian.Uint16(b[p+2 : p+4])
-	ln, err := UnmarshalNodeDescriptor(b[p : p+int(ndl)])
+	if err := decoder.CheckLength("prefix nlri", b[p+4:], int(ndl)); err != nil {
+		return nil, err
+	}
+	ln, err := UnmarshalNodeDescriptor(b[p : p+4+int(ndl)])
 	if err != nil {
 		return nil, err
 	}
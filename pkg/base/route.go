@@ -1,20 +1,29 @@
 package base
 
 import (
+	"encoding/binary"
 	"fmt"
 
 	"github.com/golang/glog"
 	"github.com/sbezverk/gobmp/pkg/tools"
 )
 
+// decoder builds errors of decoding routes and BGP-LS NLRI descriptors
+const decoder tools.Decoder = "base"
+
 // Route defines a structure of BGP Withdrawn prefix
 type Route struct {
+	// PathID is the Path Identifier of the route received from ADD-PATH peer (RFC 7911)
+	PathID uint32
 	Length uint8
 	Prefix []byte
 }
 
 func (r *Route) String() string {
 	var s string
+	if r.PathID != 0 {
+		s += fmt.Sprintf("path id: %d\n", r.PathID)
+	}
 	s += fmt.Sprintf("prefix length: %d\n", r.Length)
 	s += tools.MessageHex(r.Prefix)
 	s += "\n"
@@ -22,8 +31,9 @@ func (r *Route) String() string {
 	return s
 }
 
-// UnmarshalRoutes builds BGP Withdrawn routes object
-func UnmarshalRoutes(b []byte) ([]Route, error) {
+// UnmarshalRoutes builds BGP Withdrawn routes object, when pathID is true, every route is prefixed
+// by 4 bytes Path Identifier.
+func UnmarshalRoutes(b []byte, pathID bool) ([]Route, error) {
 	routes := make([]Route, 0)
 	if len(b) == 0 {
 		return nil, nil
@@ -31,12 +41,22 @@ func UnmarshalRoutes(b []byte) ([]Route, error) {
 	glog.V(6).Infof("Routes Raw: %s", tools.MessageHex(b))
 	for p := 0; p < len(b); {
 		route := Route{}
+		if pathID {
+			if err := decoder.CheckLength("route path id", b[p:], 5); err != nil {
+				return nil, err
+			}
+			route.PathID = binary.BigEndian.Uint32(b[p : p+4])
+			p += 4
+		}
 		route.Length = b[p]
 		l := route.Length / 8
 		if route.Length%8 != 0 {
 			l++
 		}
 		p++
+		if err := decoder.CheckLength("route", b[p:], int(l)); err != nil {
+			return nil, err
+		}
 		route.Prefix = make([]byte, l)
 		copy(route.Prefix, b[p:p+int(l)])
 		p += int(l)
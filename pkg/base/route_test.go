@@ -2,6 +2,7 @@ package base
 
 import (
 	"net"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -61,3 +62,53 @@ func TestGetPrefixIPReachability(t *testing.T) {
 		})
 	}
 }
+
+func TestUnmarshalRoutes(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  []byte
+		pathID bool
+		expect []Route
+		fail   bool
+	}{
+		{
+			name:   "routes without path id",
+			input:  []byte{24, 10, 1, 2, 0},
+			expect: []Route{{Length: 24, Prefix: []byte{10, 1, 2}}, {Length: 0, Prefix: []byte{}}},
+		},
+		{
+			name:   "routes with path id",
+			input:  []byte{0, 0, 0, 1, 24, 10, 1, 2, 0, 0, 0, 2, 24, 10, 1, 2},
+			pathID: true,
+			expect: []Route{{PathID: 1, Length: 24, Prefix: []byte{10, 1, 2}}, {PathID: 2, Length: 24, Prefix: []byte{10, 1, 2}}},
+		},
+		{
+			name:   "truncated path id",
+			input:  []byte{0, 0, 0, 1, 24, 10, 1, 2, 0, 0, 0},
+			pathID: true,
+			fail:   true,
+		},
+		{
+			name:  "path id decoded as prefix",
+			input: []byte{0, 0, 0, 1, 32, 10, 1, 2, 3},
+			fail:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UnmarshalRoutes(tt.input, tt.pathID)
+			if err != nil {
+				if !tt.fail {
+					t.Fatalf("expected to succeed but failed with error: %+v", err)
+				}
+				return
+			}
+			if tt.fail {
+				t.Fatalf("expected to fail but succeeded with %+v", got)
+			}
+			if !reflect.DeepEqual(tt.expect, got) {
+				t.Fatalf("expected routes %+v but got %+v", tt.expect, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package bgp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// AS_PATH segment types, https://tools.ietf.org/html/rfc4271#section-4.3 and https://tools.ietf.org/html/rfc5065#section-3
+const (
+	ASSet            = 1
+	ASSequence       = 2
+	ASConfedSequence = 3
+	ASConfedSet      = 4
+)
+
+// ASTrans is the reserved 2 bytes AS number representing 4 bytes AS numbers, https://tools.ietf.org/html/rfc6793#section-9
+const ASTrans = 23456
+
+// ASPathSegment defines a single segment of AS_PATH or AS4_PATH attribute
+type ASPathSegment struct {
+	Type uint8    `json:"type"`
+	ASN  []uint32 `json:"asn"`
+}
+
+// IsSet returns true if the segment is an unordered set of AS numbers
+func (s ASPathSegment) IsSet() bool {
+	return s.Type == ASSet || s.Type == ASConfedSet
+}
+
+// IsConfed returns true if the segment carries AS numbers of the member ASes of a confederation
+func (s ASPathSegment) IsConfed() bool {
+	return s.Type == ASConfedSequence || s.Type == ASConfedSet
+}
+
+// length returns the number of ASes the segment counts in the path length
+func (s ASPathSegment) length() int {
+	switch {
+	case s.IsConfed():
+		return 0
+	case s.IsSet():
+		return 1
+	}
+
+	return len(s.ASN)
+}
+
+func (s ASPathSegment) String() string {
+	asns := make([]string, len(s.ASN))
+	for i, as := range s.ASN {
+		asns[i] = fmt.Sprintf("%d", as)
+	}
+	switch s.Type {
+	case ASSet:
+		return "{" + strings.Join(asns, ",") + "}"
+	case ASConfedSequence:
+		return "(" + strings.Join(asns, " ") + ")"
+	case ASConfedSet:
+		return "[" + strings.Join(asns, ",") + "]"
+	}
+
+	return strings.Join(asns, " ")
+}
+
+// ASPath defines AS path as a sequence of segments
+type ASPath []ASPathSegment
+
+// UnmarshalASPath builds AS path from AS_PATH or AS4_PATH attribute, asLen is the size of AS numbers,
+// 2 for AS_PATH sent by a speaker not capable of 4 bytes AS numbers, 4 otherwise.
+func UnmarshalASPath(b []byte, asLen int) (ASPath, error) {
+	if asLen != 2 && asLen != 4 {
+		return nil, fmt.Errorf("invalid as number length %d", asLen)
+	}
+	path := make(ASPath, 0)
+	for p := 0; p < len(b); {
+		// Segment type and segment length, followed by the segment's ASNs
+		if err := decoder.CheckLength("as path segment", b[p:], 2); err != nil {
+			return path, err
+		}
+		s := ASPathSegment{Type: b[p]}
+		if s.Type < ASSet || s.Type > ASConfedSet {
+			return path, decoder.Malformed("as path segment", "invalid segment type %d", s.Type)
+		}
+		l := int(b[p+1])
+		p += 2
+		if err := decoder.CheckLength("as path segment", b[p:], l*asLen); err != nil {
+			return path, err
+		}
+		s.ASN = make([]uint32, l)
+		for n := 0; n < l; n++ {
+			if asLen == 4 {
+				s.ASN[n] = binary.BigEndian.Uint32(b[p : p+4])
+			} else {
+				s.ASN[n] = uint32(binary.BigEndian.Uint16(b[p : p+2]))
+			}
+			p += asLen
+		}
+		path = append(path, s)
+	}
+
+	return path, nil
+}
+
+// Flatten returns AS numbers of all segments in the order they appear in the path
+func (path ASPath) Flatten() []uint32 {
+	asns := make([]uint32, 0)
+	for _, s := range path {
+		asns = append(asns, s.ASN...)
+	}
+
+	return asns
+}
+
+// Length returns the path length used by best path selection, AS_SET counts as 1 regardless of the number of
+// its ASes and confederation segments are not counted, https://tools.ietf.org/html/rfc4271#section-9.1.2.2
+// and https://tools.ietf.org/html/rfc5065#section-5.3
+func (path ASPath) Length() int {
+	l := 0
+	for _, s := range path {
+		l += s.length()
+	}
+
+	return l
+}
+
+// OriginAS returns the AS which originated the route, it is the last AS of the last AS_SEQUENCE outside of
+// the confederation. When the path ends with AS_SET of aggregated routes, the origin can be determined only
+// if the set has a single AS. The route originated within the local confederation is originated by the
+// last member AS of the confederation path. False is returned when the origin cannot be determined.
+func (path ASPath) OriginAS() (uint32, bool) {
+	for i := len(path) - 1; i >= 0; i-- {
+		s := path[i]
+		if s.IsConfed() || len(s.ASN) == 0 {
+			continue
+		}
+		if s.IsSet() && len(s.ASN) != 1 {
+			return 0, false
+		}
+		return s.ASN[len(s.ASN)-1], true
+	}
+	for i := len(path) - 1; i >= 0; i-- {
+		s := path[i]
+		if len(s.ASN) == 0 {
+			continue
+		}
+		if s.IsSet() && len(s.ASN) != 1 {
+			return 0, false
+		}
+		return s.ASN[len(s.ASN)-1], true
+	}
+
+	return 0, false
+}
+
+func (path ASPath) String() string {
+	segments := make([]string, 0, len(path))
+	for _, s := range path {
+		segments = append(segments, s.String())
+	}
+
+	return strings.Join(segments, " ")
+}
+
+// MergeAS4Path reconstructs AS path from AS_PATH of 2 bytes AS numbers and AS4_PATH as described in
+// https://tools.ietf.org/html/rfc6793#section-4.2.3, the leading ASes of AS_PATH added by 2 bytes AS
+// speakers are prepended to AS4_PATH. Confederation segments found in AS4_PATH are discarded. When AS_PATH
+// is shorter than AS4_PATH, AS4_PATH is ignored and AS_PATH is returned.
+func MergeAS4Path(asPath, as4Path ASPath) ASPath {
+	as4 := make(ASPath, 0, len(as4Path))
+	for _, s := range as4Path {
+		if !s.IsConfed() {
+			as4 = append(as4, s)
+		}
+	}
+	n := asPath.Length() - as4.Length()
+	if len(as4) == 0 || n < 0 {
+		return asPath
+	}
+	path := make(ASPath, 0, len(asPath)+len(as4))
+	for _, s := range asPath {
+		// Leading confederation segments count zero and stay in front of the path
+		if n == 0 && !s.IsConfed() {
+			break
+		}
+		switch {
+		case s.IsConfed():
+			path = append(path, s)
+		case s.IsSet():
+			path = append(path, s)
+			n--
+		default:
+			l := len(s.ASN)
+			if l > n {
+				l = n
+			}
+			path = append(path, ASPathSegment{Type: s.Type, ASN: append([]uint32{}, s.ASN[:l]...)})
+			n -= l
+		}
+	}
+	for _, s := range as4 {
+		last := len(path) - 1
+		if last >= 0 && path[last].Type == ASSequence && s.Type == ASSequence {
+			// Adjacent sequences are joined in one segment
+			path[last].ASN = append(path[last].ASN, s.ASN...)
+			continue
+		}
+		path = append(path, ASPathSegment{Type: s.Type, ASN: append([]uint32{}, s.ASN...)})
+	}
+
+	return path
+}
@@ -0,0 +1,190 @@
+package bgp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestASPath(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  []byte
+		asLen  int
+		expect ASPath
+		length int
+		origin uint32
+		found  bool
+		str    string
+		fail   bool
+	}{
+		{
+			name:   "sequence",
+			input:  []byte{2, 3, 0, 0, 0xfd, 0xe9, 0, 0, 0xfd, 0xea, 0, 1, 0x86, 0xa0},
+			asLen:  4,
+			expect: ASPath{{Type: ASSequence, ASN: []uint32{65001, 65002, 100000}}},
+			length: 3,
+			origin: 100000,
+			found:  true,
+			str:    "65001 65002 100000",
+		},
+		{
+			name:   "aggregated routes of several origins",
+			input:  []byte{2, 1, 0xfd, 0xe9, 1, 2, 0xfd, 0xea, 0xfd, 0xeb},
+			asLen:  2,
+			expect: ASPath{{Type: ASSequence, ASN: []uint32{65001}}, {Type: ASSet, ASN: []uint32{65002, 65003}}},
+			length: 2,
+			str:    "65001 {65002,65003}",
+		},
+		{
+			name:   "aggregated routes of single origin",
+			input:  []byte{2, 1, 0xfd, 0xe9, 1, 1, 0xfd, 0xea},
+			asLen:  2,
+			expect: ASPath{{Type: ASSequence, ASN: []uint32{65001}}, {Type: ASSet, ASN: []uint32{65002}}},
+			length: 2,
+			origin: 65002,
+			found:  true,
+			str:    "65001 {65002}",
+		},
+		{
+			name:  "confederation",
+			input: []byte{3, 2, 0xfd, 0xf3, 0xfd, 0xf4, 4, 1, 0xfd, 0xf5, 2, 2, 0xfd, 0xe9, 0xfd, 0xea},
+			asLen: 2,
+			expect: ASPath{
+				{Type: ASConfedSequence, ASN: []uint32{65011, 65012}},
+				{Type: ASConfedSet, ASN: []uint32{65013}},
+				{Type: ASSequence, ASN: []uint32{65001, 65002}},
+			},
+			length: 2,
+			origin: 65002,
+			found:  true,
+			str:    "(65011 65012) [65013] 65001 65002",
+		},
+		{
+			name:   "originated within confederation",
+			input:  []byte{3, 2, 0xfd, 0xf3, 0xfd, 0xf4},
+			asLen:  2,
+			expect: ASPath{{Type: ASConfedSequence, ASN: []uint32{65011, 65012}}},
+			length: 0,
+			origin: 65012,
+			found:  true,
+			str:    "(65011 65012)",
+		},
+		{
+			name:   "empty",
+			input:  []byte{},
+			asLen:  4,
+			expect: ASPath{},
+		},
+		{
+			name:   "truncated segment",
+			input:  []byte{2, 1, 0xfd, 0xe9, 2, 2, 0xfd},
+			asLen:  2,
+			expect: ASPath{{Type: ASSequence, ASN: []uint32{65001}}},
+			length: 1,
+			origin: 65001,
+			found:  true,
+			str:    "65001",
+			fail:   true,
+		},
+		{
+			name:   "invalid segment type",
+			input:  []byte{5, 1, 0xfd, 0xe9},
+			asLen:  2,
+			expect: ASPath{},
+			fail:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UnmarshalASPath(tt.input, tt.asLen)
+			if err != nil && !tt.fail {
+				t.Fatalf("supposed to succeed but failed with error: %+v", err)
+			}
+			if err == nil && tt.fail {
+				t.Fatalf("supposed to fail but succeeded")
+			}
+			if !reflect.DeepEqual(tt.expect, got) {
+				t.Fatalf("expected path %+v but got %+v", tt.expect, got)
+			}
+			if l := got.Length(); l != tt.length {
+				t.Fatalf("expected path length %d but got %d", tt.length, l)
+			}
+			if origin, found := got.OriginAS(); origin != tt.origin || found != tt.found {
+				t.Fatalf("expected origin %d found %t but got %d found %t", tt.origin, tt.found, origin, found)
+			}
+			if s := got.String(); s != tt.str {
+				t.Fatalf("expected path %q but got %q", tt.str, s)
+			}
+		})
+	}
+}
+
+func TestGetASPath(t *testing.T) {
+	tests := []struct {
+		name       string
+		attrs      []PathAttribute
+		as4Capable bool
+		expect     ASPath
+	}{
+		{
+			name: "as4 path reconstruction",
+			attrs: []PathAttribute{
+				// 65001 23456 23456
+				{AttributeType: 2, Attribute: []byte{2, 3, 0xfd, 0xe9, 0x5b, 0xa0, 0x5b, 0xa0}},
+				// 100000 200000
+				{AttributeType: 17, Attribute: []byte{2, 2, 0, 1, 0x86, 0xa0, 0, 3, 0x0d, 0x40}},
+			},
+			expect: ASPath{{Type: ASSequence, ASN: []uint32{65001, 100000, 200000}}},
+		},
+		{
+			name: "as4 path reconstruction with confederation and set",
+			attrs: []PathAttribute{
+				// (65011) 65001 23456 {23456}
+				{AttributeType: 2, Attribute: []byte{3, 1, 0xfd, 0xf3, 2, 2, 0xfd, 0xe9, 0x5b, 0xa0, 1, 1, 0x5b, 0xa0}},
+				// (4200000000) 100000 {200000}
+				{AttributeType: 17, Attribute: []byte{3, 1, 0xfa, 0x56, 0xea, 0, 2, 1, 0, 1, 0x86, 0xa0, 1, 1, 0, 3, 0x0d, 0x40}},
+			},
+			expect: ASPath{
+				{Type: ASConfedSequence, ASN: []uint32{65011}},
+				{Type: ASSequence, ASN: []uint32{65001, 100000}},
+				{Type: ASSet, ASN: []uint32{200000}},
+			},
+		},
+		{
+			name: "as4 path longer than as path is ignored",
+			attrs: []PathAttribute{
+				{AttributeType: 2, Attribute: []byte{2, 1, 0x5b, 0xa0}},
+				{AttributeType: 17, Attribute: []byte{2, 2, 0, 1, 0x86, 0xa0, 0, 3, 0x0d, 0x40}},
+			},
+			expect: ASPath{{Type: ASSequence, ASN: []uint32{23456}}},
+		},
+		{
+			name: "aggregated by 2 bytes as speaker, as4 path is ignored",
+			attrs: []PathAttribute{
+				{AttributeType: 2, Attribute: []byte{2, 1, 0x5b, 0xa0}},
+				{AttributeType: 7, AttributeLength: 6, Attribute: []byte{0xfd, 0xe9, 10, 0, 0, 1}},
+				{AttributeType: 17, Attribute: []byte{2, 1, 0, 1, 0x86, 0xa0}},
+				{AttributeType: 18, AttributeLength: 8, Attribute: []byte{0, 1, 0x86, 0xa0, 10, 0, 0, 2}},
+			},
+			expect: ASPath{{Type: ASSequence, ASN: []uint32{23456}}},
+		},
+		{
+			name: "as4 path of 4 bytes as capable speaker is ignored",
+			attrs: []PathAttribute{
+				{AttributeType: 2, Attribute: []byte{2, 1, 0, 0, 0x5b, 0xa0}},
+				{AttributeType: 17, Attribute: []byte{2, 1, 0, 1, 0x86, 0xa0}},
+			},
+			as4Capable: true,
+			expect:     ASPath{{Type: ASSequence, ASN: []uint32{23456}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			up := &Update{PathAttributes: tt.attrs}
+			got := up.GetASPath(tt.as4Capable)
+			if !reflect.DeepEqual(tt.expect, got) {
+				t.Fatalf("expected path %+v but got %+v", tt.expect, got)
+			}
+		})
+	}
+}
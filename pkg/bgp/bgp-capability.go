@@ -30,17 +30,26 @@ func UnmarshalBGPInformationalTLVCapability(b []byte) ([]Capability, error) {
 	caps := make([]Capability, 0)
 	glog.V(6).Infof("BGPInformationalTLVCapability Raw: %s", tools.MessageHex(b))
 	for p := 0; p < len(b); {
+		if err := decoder.CheckLength("bgp capability", b[p:], 2); err != nil {
+			return nil, err
+		}
 		cap := Capability{}
 		cap.Code = b[p]
 		p++
 		cap.Length = b[p]
 		p++
+		if err := decoder.CheckLength("bgp capability", b[p:], int(cap.Length)); err != nil {
+			return nil, err
+		}
 		cap.Value = make([]byte, cap.Length)
 		copy(cap.Value, b[p:p+int(cap.Length)])
 		switch cap.Code {
 		case 1:
 			cap.Description = "MPBGP (1)"
 			// According RFC https://tools.ietf.org/html/rfc2858#section-7 Length will always be 4 bytes.
+			if cap.Length != 4 {
+				return nil, decoder.Malformed("bgp capability", "invalid length %d of multiprotocol capability", cap.Length)
+			}
 			afi := binary.BigEndian.Uint16(cap.Value[:2])
 			safi := cap.Value[3]
 			cap.Description += getAFISAFIString(afi, safi)
@@ -17,10 +17,16 @@ func UnmarshalBGPTLV(b []byte) ([]InformationalTLV, error) {
 	glog.V(6).Infof("BGPTLV Raw: %s", tools.MessageHex(b))
 	tlvs := make([]InformationalTLV, 0)
 	for p := 0; p < len(b); {
+		if err := decoder.CheckLength("bgp informational tlv", b[p:], 2); err != nil {
+			return nil, err
+		}
 		t := b[p]
 		p++
 		l := b[p]
 		p++
+		if err := decoder.CheckLength("bgp informational tlv", b[p:], int(l)); err != nil {
+			return nil, err
+		}
 		v := make([]byte, l)
 		copy(v, b[p:p+int(l)])
 		tlvs = append(tlvs, InformationalTLV{
@@ -0,0 +1,226 @@
+package bgp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/golang/glog"
+	"github.com/sbezverk/gobmp/pkg/tools"
+)
+
+const (
+	// NotificationMessageHeaderError defines Message Header Error code
+	NotificationMessageHeaderError = 1
+	// NotificationOpenMessageError defines OPEN Message Error code
+	NotificationOpenMessageError = 2
+	// NotificationUpdateMessageError defines UPDATE Message Error code
+	NotificationUpdateMessageError = 3
+	// NotificationHoldTimerExpired defines Hold Timer Expired code
+	NotificationHoldTimerExpired = 4
+	// NotificationFSMError defines Finite State Machine Error code
+	NotificationFSMError = 5
+	// NotificationCease defines Cease code
+	NotificationCease = 6
+	// NotificationRouteRefreshMessageError defines ROUTE-REFRESH Message Error code
+	NotificationRouteRefreshMessageError = 7
+
+	// CeaseAdminShutdown defines Cease Administrative Shutdown subcode
+	CeaseAdminShutdown = 2
+	// CeaseAdminReset defines Cease Administrative Reset subcode
+	CeaseAdminReset = 4
+)
+
+var notificationCodes = map[uint8]string{
+	NotificationMessageHeaderError:       "Message Header Error",
+	NotificationOpenMessageError:         "OPEN Message Error",
+	NotificationUpdateMessageError:       "UPDATE Message Error",
+	NotificationHoldTimerExpired:         "Hold Timer Expired",
+	NotificationFSMError:                 "Finite State Machine Error",
+	NotificationCease:                    "Cease",
+	NotificationRouteRefreshMessageError: "ROUTE-REFRESH Message Error",
+}
+
+var notificationSubCodes = map[uint8]map[uint8]string{
+	// https://tools.ietf.org/html/rfc4271#section-6.1
+	NotificationMessageHeaderError: {
+		1: "Connection Not Synchronized",
+		2: "Bad Message Length",
+		3: "Bad Message Type",
+	},
+	// https://tools.ietf.org/html/rfc4271#section-6.2, rfc5492, rfc9234
+	NotificationOpenMessageError: {
+		1:  "Unsupported Version Number",
+		2:  "Bad Peer AS",
+		3:  "Bad BGP Identifier",
+		4:  "Unsupported Optional Parameter",
+		5:  "Authentication Failure",
+		6:  "Unacceptable Hold Time",
+		7:  "Unsupported Capability",
+		11: "Role Mismatch",
+	},
+	// https://tools.ietf.org/html/rfc4271#section-6.3
+	NotificationUpdateMessageError: {
+		1:  "Malformed Attribute List",
+		2:  "Unrecognized Well-known Attribute",
+		3:  "Missing Well-known Attribute",
+		4:  "Attribute Flags Error",
+		5:  "Attribute Length Error",
+		6:  "Invalid ORIGIN Attribute",
+		7:  "AS Routing Loop",
+		8:  "Invalid NEXT_HOP Attribute",
+		9:  "Optional Attribute Error",
+		10: "Invalid Network Field",
+		11: "Malformed AS_PATH",
+	},
+	// https://tools.ietf.org/html/rfc6608
+	NotificationFSMError: {
+		1: "Receive Unexpected Message in OpenSent State",
+		2: "Receive Unexpected Message in OpenConfirm State",
+		3: "Receive Unexpected Message in Established State",
+	},
+	// https://tools.ietf.org/html/rfc4486, rfc8538
+	NotificationCease: {
+		1:  "Maximum Number of Prefixes Reached",
+		2:  "Administrative Shutdown",
+		3:  "Peer De-configured",
+		4:  "Administrative Reset",
+		5:  "Connection Rejected",
+		6:  "Other Configuration Change",
+		7:  "Connection Collision Resolution",
+		8:  "Out of Resources",
+		9:  "Hard Reset",
+		10: "BFD Down",
+	},
+	// https://tools.ietf.org/html/rfc7313
+	NotificationRouteRefreshMessageError: {
+		1: "Invalid Message Length",
+	},
+}
+
+// NotificationMessage defines BGP Notification Message structure per rfc4271 section 4.5
+type NotificationMessage struct {
+	Length       int16
+	Type         byte
+	ErrorCode    uint8
+	ErrorSubCode uint8
+	Data         []byte
+}
+
+// GetErrorCodeString returns the description of Notification's Error code
+func (n *NotificationMessage) GetErrorCodeString() string {
+	if s, ok := notificationCodes[n.ErrorCode]; ok {
+		return s
+	}
+	return fmt.Sprintf("Unknown error code (%d)", n.ErrorCode)
+}
+
+// GetErrorSubCodeString returns the description of Notification's Error subcode
+func (n *NotificationMessage) GetErrorSubCodeString() string {
+	if n.ErrorSubCode == 0 {
+		return "Unspecific"
+	}
+	if s, ok := notificationSubCodes[n.ErrorCode][n.ErrorSubCode]; ok {
+		return s
+	}
+	return fmt.Sprintf("Unknown error subcode (%d)", n.ErrorSubCode)
+}
+
+// GetShutdownCommunication returns Shutdown Communication carried in Cease Administrative Shutdown or Reset
+// Notification per rfc8203, the second returned value is false if the message does not carry a valid
+// Shutdown Communication.
+func (n *NotificationMessage) GetShutdownCommunication() (string, bool) {
+	if n.ErrorCode != NotificationCease {
+		return "", false
+	}
+	if n.ErrorSubCode != CeaseAdminShutdown && n.ErrorSubCode != CeaseAdminReset {
+		return "", false
+	}
+	if len(n.Data) == 0 {
+		return "", false
+	}
+	l := int(n.Data[0])
+	if l == 0 || l > len(n.Data)-1 {
+		return "", false
+	}
+	s := n.Data[1 : 1+l]
+	if !utf8.Valid(s) {
+		return "", false
+	}
+
+	return string(s), true
+}
+
+func (n *NotificationMessage) String() string {
+	s := n.GetErrorCodeString() + ": " + n.GetErrorSubCodeString()
+	if c, ok := n.GetShutdownCommunication(); ok {
+		s += fmt.Sprintf(": %q", c)
+	}
+
+	return s
+}
+
+// UnmarshalBGPNotificationMessage validates information passed in byte slice and returns BGP Notification Message object,
+// the slice is expected to start right after the marker.
+func UnmarshalBGPNotificationMessage(b []byte) (*NotificationMessage, error) {
+	glog.V(6).Infof("BGPNotificationMessage Raw: %s", tools.MessageHex(b))
+	if err := decoder.CheckLength("bgp notification message", b, 5); err != nil {
+		return nil, err
+	}
+	p := 0
+	m := NotificationMessage{}
+	m.Length = int16(binary.BigEndian.Uint16(b[p : p+2]))
+	p += 2
+	if b[p] != 3 {
+		return nil, decoder.Malformed("bgp notification message", "invalid message type %d", b[p])
+	}
+	m.Type = b[p]
+	p++
+	m.ErrorCode = b[p]
+	p++
+	m.ErrorSubCode = b[p]
+	p++
+	m.Data = make([]byte, len(b[p:]))
+	copy(m.Data, b[p:])
+
+	return &m, nil
+}
+
+var fsmEvents = map[uint16]string{
+	1:  "ManualStart",
+	2:  "ManualStop",
+	3:  "AutomaticStart",
+	4:  "ManualStart_with_PassiveTcpEstablishment",
+	5:  "AutomaticStart_with_PassiveTcpEstablishment",
+	6:  "AutomaticStart_with_DampPeerOscillations",
+	7:  "AutomaticStart_with_DampPeerOscillations_and_PassiveTcpEstablishment",
+	8:  "AutomaticStop",
+	9:  "ConnectRetryTimer_Expires",
+	10: "HoldTimer_Expires",
+	11: "KeepaliveTimer_Expires",
+	12: "DelayOpenTimer_Expires",
+	13: "IdleHoldTimer_Expires",
+	14: "TcpConnection_Valid",
+	15: "Tcp_CR_Invalid",
+	16: "Tcp_CR_Acked",
+	17: "TcpConnectionConfirmed",
+	18: "TcpConnectionFails",
+	19: "BGPOpen",
+	20: "BGPOpen with DelayOpenTimer running",
+	21: "BGPHeaderErr",
+	22: "BGPOpenMsgErr",
+	23: "OpenCollisionDump",
+	24: "NotifMsgVerErr",
+	25: "NotifMsg",
+	26: "KeepAliveMsg",
+	27: "UpdateMsg",
+	28: "UpdateMsgErr",
+}
+
+// FSMEventString returns the name of BGP FSM event per rfc4271 section 8.1
+func FSMEventString(e uint16) string {
+	if s, ok := fsmEvents[e]; ok {
+		return s
+	}
+	return fmt.Sprintf("Unknown FSM event (%d)", e)
+}
@@ -0,0 +1,75 @@
+package bgp
+
+import (
+	"testing"
+)
+
+func TestUnmarshalBGPNotificationMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   []byte
+		code    uint8
+		subCode uint8
+		text    string
+		fail    bool
+	}{
+		{
+			name:    "hold timer expired",
+			input:   []byte{0, 21, 3, 4, 0},
+			code:    4,
+			subCode: 0,
+			text:    "Hold Timer Expired: Unspecific",
+		},
+		{
+			name:    "administrative shutdown with communication",
+			input:   []byte{0, 33, 3, 6, 2, 11, 'm', 'a', 'i', 'n', 't', 'e', 'n', 'a', 'n', 'c', 'e'},
+			code:    6,
+			subCode: 2,
+			text:    "Cease: Administrative Shutdown: \"maintenance\"",
+		},
+		{
+			name:    "administrative reset with invalid communication length",
+			input:   []byte{0, 24, 3, 6, 4, 10, 'a', 'b'},
+			code:    6,
+			subCode: 4,
+			text:    "Cease: Administrative Reset",
+		},
+		{
+			name:    "malformed as path",
+			input:   []byte{0, 21, 3, 3, 11},
+			code:    3,
+			subCode: 11,
+			text:    "UPDATE Message Error: Malformed AS_PATH",
+		},
+		{
+			name:  "not a notification",
+			input: []byte{0, 21, 4, 3, 11},
+			fail:  true,
+		},
+		{
+			name:  "truncated",
+			input: []byte{0, 21, 3},
+			fail:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := UnmarshalBGPNotificationMessage(tt.input)
+			if err != nil && !tt.fail {
+				t.Fatalf("supposed to succeed but failed with error: %+v", err)
+			}
+			if err == nil && tt.fail {
+				t.Fatalf("supposed to fail but succeeded")
+			}
+			if err != nil {
+				return
+			}
+			if n.ErrorCode != tt.code || n.ErrorSubCode != tt.subCode {
+				t.Errorf("expected code %d subcode %d got code %d subcode %d", tt.code, tt.subCode, n.ErrorCode, n.ErrorSubCode)
+			}
+			if n.String() != tt.text {
+				t.Errorf("expected text %q got %q", tt.text, n.String())
+			}
+		})
+	}
+}
@@ -2,7 +2,6 @@ package bgp
 
 import (
 	"encoding/binary"
-	"fmt"
 
 	"github.com/golang/glog"
 	"github.com/sbezverk/gobmp/pkg/tools"
@@ -50,7 +49,7 @@ func (o *OpenMessage) Is4BytesASCapable() (int32, bool) {
 		}
 		for _, cap := range caps {
 			// 65 is 4 Bytes AS Capability code
-			if cap.Code != 65 {
+			if cap.Code != 65 || len(cap.Value) != 4 {
 				continue
 			}
 			return int32(binary.BigEndian.Uint32(cap.Value)), true
@@ -60,6 +59,53 @@ func (o *OpenMessage) Is4BytesASCapable() (int32, bool) {
 	return 0, false
 }
 
+// ADD-PATH capability's Send/Receive values, https://tools.ietf.org/html/rfc7911#section-4
+const (
+	// AddPathReceive is set when the speaker is able to receive multiple paths of AFI/SAFI
+	AddPathReceive = 1
+	// AddPathSend is set when the speaker is able to send multiple paths of AFI/SAFI
+	AddPathSend = 2
+)
+
+// GetAddPathCapability returns Send/Receive values of ADD-PATH capability advertised in Open message,
+// the values are keyed by the type of AFI/SAFI as returned by MPNLRI's GetAFISAFIType.
+func (o *OpenMessage) GetAddPathCapability() map[int]uint8 {
+	addPath := make(map[int]uint8)
+	for _, cap := range o.GetCapabilities() {
+		// 69 is ADD-PATH Capability code, the capability carries a list of AFI, SAFI and Send/Receive tuples
+		if cap.Code != 69 {
+			continue
+		}
+		for p := 0; p+4 <= len(cap.Value); p += 4 {
+			t := getNLRIMessageType(binary.BigEndian.Uint16(cap.Value[p:p+2]), cap.Value[p+2])
+			if t == 0 {
+				continue
+			}
+			addPath[t] = cap.Value[p+3]
+		}
+	}
+
+	return addPath
+}
+
+// NegotiatedAddPath returns the types of AFI/SAFI for which NLRI sent by remote speaker to local speaker
+// carry Path Identifiers, it is the case when local speaker advertised ADD-PATH Receive and remote speaker
+// advertised ADD-PATH Send for AFI/SAFI.
+func NegotiatedAddPath(local, remote *OpenMessage) map[int]bool {
+	addPath := make(map[int]bool)
+	if local == nil || remote == nil {
+		return addPath
+	}
+	send := remote.GetAddPathCapability()
+	for t, v := range local.GetAddPathCapability() {
+		if v&AddPathReceive != 0 && send[t]&AddPathSend != 0 {
+			addPath[t] = true
+		}
+	}
+
+	return addPath
+}
+
 // IsMultiLabelCapable returns true or false if Open message originated by a bgp speaker
 // supporting Multiple Label Capability
 func (o *OpenMessage) IsMultiLabelCapable() bool {
@@ -76,6 +122,10 @@ func (o *OpenMessage) IsMultiLabelCapable() bool {
 func UnmarshalBGPOpenMessage(b []byte) (*OpenMessage, error) {
 	glog.V(6).Infof("BGPOpenMessage Raw: %s", tools.MessageHex(b))
 	var err error
+	// 2 bytes of length, 1 byte of type and 10 bytes of fixed part of Open message
+	if err := decoder.CheckLength("bgp open message", b, 13); err != nil {
+		return nil, err
+	}
 	p := 0
 	m := OpenMessage{
 		BGPID: make([]byte, 4),
@@ -83,12 +133,12 @@ func UnmarshalBGPOpenMessage(b []byte) (*OpenMessage, error) {
 	m.Length = int16(binary.BigEndian.Uint16(b[p : p+2]))
 	p += 2
 	if b[p] != 1 {
-		return nil, fmt.Errorf("invalid message type %d for BGP Open Message", b[p])
+		return nil, decoder.Malformed("bgp open message", "invalid message type %d", b[p])
 	}
 	m.Type = b[p]
 	p++
 	if b[p] != 4 {
-		return nil, fmt.Errorf("invalid message version %d for BGP Open Message", b[p])
+		return nil, decoder.Malformed("bgp open message", "invalid message version %d", b[p])
 	}
 	m.Version = b[p]
 	p++
@@ -100,6 +150,9 @@ func UnmarshalBGPOpenMessage(b []byte) (*OpenMessage, error) {
 	p += 4
 	m.OptParamLen = b[p]
 	p++
+	if err := decoder.CheckLength("bgp open message optional parameters", b[p:], int(m.OptParamLen)); err != nil {
+		return nil, err
+	}
 	if m.OptParamLen != 0 {
 		m.OptionalParameters, err = UnmarshalBGPTLV(b[p : p+int(m.OptParamLen)])
 		if err != nil {
@@ -0,0 +1,64 @@
+package bgp
+
+import (
+	"reflect"
+	"testing"
+)
+
+// addPathOpen returns Open message carrying ADD-PATH capability with AFI, SAFI and Send/Receive tuples
+func addPathOpen(tuples ...byte) *OpenMessage {
+	caps := append([]byte{69, byte(len(tuples))}, tuples...)
+	return &OpenMessage{
+		OptionalParameters: []InformationalTLV{
+			{Type: 2, Length: byte(len(caps)), Value: caps},
+		},
+	}
+}
+
+func TestNegotiatedAddPath(t *testing.T) {
+	tests := []struct {
+		name   string
+		local  *OpenMessage
+		remote *OpenMessage
+		expect map[int]bool
+	}{
+		{
+			name:   "no add-path capability",
+			local:  &OpenMessage{},
+			remote: &OpenMessage{},
+			expect: map[int]bool{},
+		},
+		{
+			name:   "ipv4 unicast receive and send",
+			local:  addPathOpen(0, 1, 1, AddPathReceive),
+			remote: addPathOpen(0, 1, 1, AddPathSend),
+			expect: map[int]bool{1: true},
+		},
+		{
+			name:   "both directions for ipv6 unicast, send only for ipv4 labeled unicast",
+			local:  addPathOpen(0, 2, 1, AddPathReceive|AddPathSend, 0, 1, 4, AddPathSend),
+			remote: addPathOpen(0, 2, 1, AddPathReceive|AddPathSend, 0, 1, 4, AddPathReceive|AddPathSend),
+			expect: map[int]bool{2: true},
+		},
+		{
+			name:   "remote speaker does not send multiple paths",
+			local:  addPathOpen(0, 1, 128, AddPathReceive),
+			remote: addPathOpen(0, 1, 128, AddPathReceive),
+			expect: map[int]bool{},
+		},
+		{
+			name:   "missing open message",
+			local:  nil,
+			remote: addPathOpen(0, 1, 1, AddPathSend),
+			expect: map[int]bool{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NegotiatedAddPath(tt.local, tt.remote)
+			if !reflect.DeepEqual(tt.expect, got) {
+				t.Fatalf("expected %+v but got %+v", tt.expect, got)
+			}
+		})
+	}
+}
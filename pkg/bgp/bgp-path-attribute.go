@@ -23,7 +23,7 @@ func (pa *PathAttribute) String() string {
 	case 0xe:
 		// Found MP_REACH_NLRI attribute
 		s += fmt.Sprintf("Attribute Type: %d (MP_REACH_NLRI)\n", pa.AttributeType)
-		mp, err := UnmarshalMPReachNLRI(pa.Attribute)
+		mp, err := UnmarshalMPReachNLRI(pa.Attribute, nil)
 		if err != nil {
 			s += err.Error()
 		} else {
@@ -51,7 +51,9 @@ func (pa *PathAttribute) String() string {
 		s += fmt.Sprintf("   AS PATH: %s\n", tools.MessageHex(pa.Attribute))
 	case 5:
 		s += fmt.Sprintf("Attribute Type: %d (LOCAL_PREF)\n", pa.AttributeType)
-		s += fmt.Sprintf("   Local Pref: %d\n", binary.BigEndian.Uint32(pa.Attribute))
+		if len(pa.Attribute) == 4 {
+			s += fmt.Sprintf("   Local Pref: %d\n", binary.BigEndian.Uint32(pa.Attribute))
+		}
 	default:
 		s += fmt.Sprintf("Attribute Type: %d\n", pa.AttributeType)
 		s += tools.MessageHex(pa.Attribute)
@@ -67,18 +69,27 @@ func UnmarshalBGPPathAttributes(b []byte) ([]PathAttribute, error) {
 	attrs := make([]PathAttribute, 0)
 
 	for p := 0; p < len(b); {
+		if err := decoder.CheckLength("bgp path attribute", b[p:], 3); err != nil {
+			return nil, err
+		}
 		f := b[p]
 		t := b[p+1]
 		p += 2
 		var l uint16
 		// Checking for Extened
 		if f&0x10 == 0x10 {
+			if err := decoder.CheckLength("bgp path attribute", b[p:], 2); err != nil {
+				return nil, err
+			}
 			l = binary.BigEndian.Uint16(b[p : p+2])
 			p += 2
 		} else {
 			l = uint16(b[p])
 			p++
 		}
+		if err := decoder.CheckLength(fmt.Sprintf("bgp path attribute %d", t), b[p:], int(l)); err != nil {
+			return nil, err
+		}
 		attrs = append(attrs, PathAttribute{
 			AttributeTypeFlags: f,
 			AttributeType:      t,
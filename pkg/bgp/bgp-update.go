@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/golang/glog"
 	"github.com/sbezverk/gobmp/pkg/base"
@@ -13,6 +14,12 @@ import (
 	"github.com/sbezverk/gobmp/pkg/tools"
 )
 
+// decoder builds errors of decoding BGP messages and path attributes
+const decoder tools.Decoder = "bgp"
+
+// HeaderLength defines the length of BGP message header, 16 bytes Marker, 2 bytes Length and 1 byte Type
+const HeaderLength = 19
+
 // Update defines a structure of BGP Update message
 type Update struct {
 	WithdrawnRoutesLength    uint16
@@ -20,6 +27,9 @@ type Update struct {
 	TotalPathAttributeLength uint16
 	PathAttributes           []PathAttribute
 	NLRI                     []base.Route
+	// AddPath keeps the types of AFI/SAFI for which NLRI of the update carry Path Identifiers,
+	// MP_REACH_NLRI and MP_UNREACH_NLRI attributes are decoded accordingly.
+	AddPath map[int]bool
 }
 
 func (up *Update) String() string {
@@ -70,6 +80,9 @@ func (up *Update) GetAttrOrigin() *string {
 	var o string
 	for _, attr := range up.PathAttributes {
 		if attr.AttributeType == 1 {
+			if len(attr.Attribute) != 1 {
+				return nil
+			}
 			switch attr.Attribute[0] {
 			case 0:
 				o = "igp"
@@ -85,37 +98,49 @@ func (up *Update) GetAttrOrigin() *string {
 	return nil
 }
 
-// GetAttrASPath returns a sequence of AS path segments
-func (up *Update) GetAttrASPath(as4Capable bool) []uint32 {
-	path := make([]uint32, 0)
+// GetASPath returns AS path of the route, as4Capable tells if AS_PATH carries 4 bytes AS numbers. When AS_PATH
+// carries 2 bytes AS numbers, the path is reconstructed from AS_PATH and AS4_PATH as described in
+// https://tools.ietf.org/html/rfc6793#section-4.2.3. A malformed attribute's segments decoded before the error
+// are returned.
+func (up *Update) GetASPath(as4Capable bool) ASPath {
+	path := make(ASPath, 0)
 	for _, attr := range up.PathAttributes {
 		if attr.AttributeType != 2 {
 			continue
 		}
-		for p := 0; p < len(attr.Attribute); {
-			// Skipping type
-			p++
-			// Length of path segment in 2 bytes
-			l := attr.Attribute[p]
-			p++
-			for n := 0; n < int(l); n++ {
-				if as4Capable {
-					as := binary.BigEndian.Uint32(attr.Attribute[p : p+4])
-					p += 4
-					path = append(path, as)
-				} else {
-					as := binary.BigEndian.Uint16(attr.Attribute[p : p+2])
-					p += 2
-					path = append(path, uint32(as))
-				}
-
-			}
+		asLen := 2
+		if as4Capable {
+			asLen = 4
 		}
+		path, _ = UnmarshalASPath(attr.Attribute, asLen)
+		break
+	}
+	if as4Capable {
+		// AS4_PATH is not expected from 4 bytes AS capable speaker and must be ignored
+		return path
+	}
+	// AS4_PATH is ignored when AGGREGATOR carries a 2 bytes AS number, it means the route was aggregated
+	// by a speaker not capable of 4 bytes AS numbers after AS4_PATH was attached.
+	if agg := up.GetAttrAggregator(); len(agg) == 6 && binary.BigEndian.Uint16(agg[0:2]) != ASTrans && up.GetAttrAS4Aggregator() != nil {
+		return path
+	}
+	for _, attr := range up.PathAttributes {
+		if attr.AttributeType != 17 {
+			continue
+		}
+		as4Path, _ := UnmarshalASPath(attr.Attribute, 4)
+		return MergeAS4Path(path, as4Path)
 	}
 
 	return path
 }
 
+// GetAttrASPath returns AS numbers of all AS path segments, the path is reconstructed from AS4_PATH when
+// AS_PATH carries 2 bytes AS numbers.
+func (up *Update) GetAttrASPath(as4Capable bool) []uint32 {
+	return up.GetASPath(as4Capable).Flatten()
+}
+
 // GetAttrNextHop returns the value of Next Hop attribute if it is defined, otherwise it returns nil
 func (up *Update) GetAttrNextHop() []byte {
 	var nh []byte
@@ -135,6 +160,9 @@ func (up *Update) GetAttrMED() *uint32 {
 	var med uint32
 	for _, attr := range up.PathAttributes {
 		if attr.AttributeType == 4 {
+			if len(attr.Attribute) != 4 {
+				return nil
+			}
 			med = binary.BigEndian.Uint32(attr.Attribute)
 			return &med
 		}
@@ -148,6 +176,9 @@ func (up *Update) GetAttrLocalPref() *uint32 {
 	var lp uint32
 	for _, attr := range up.PathAttributes {
 		if attr.AttributeType == 5 {
+			if len(attr.Attribute) != 4 {
+				return nil
+			}
 			lp = binary.BigEndian.Uint32(attr.Attribute)
 			return &lp
 		}
@@ -188,7 +219,7 @@ func (up *Update) GetAttrCommunity() []uint32 {
 		if attr.AttributeType != 8 {
 			continue
 		}
-		for p := 0; p < len(attr.Attribute); {
+		for p := 0; p+4 <= len(attr.Attribute); {
 			c := binary.BigEndian.Uint32(attr.Attribute[p : p+4])
 			p += 4
 			comm = append(comm, c)
@@ -251,6 +282,35 @@ func (up *Update) GetAttrExtCommunity() ([]ExtCommunity, error) {
 	return nil, fmt.Errorf("not found")
 }
 
+// GetAttrIPv6ExtCommunity returns a slice with all IPv6 Address Specific Extended Communities found in bgp update
+func (up *Update) GetAttrIPv6ExtCommunity() ([]IPv6ExtCommunity, error) {
+	for _, attr := range up.PathAttributes {
+		if attr.AttributeType == 25 {
+			return UnmarshalBGPIPv6ExtCommunity(attr.Attribute)
+		}
+	}
+
+	return nil, fmt.Errorf("not found")
+}
+
+// GetAttrExtCommunityString returns the string with comma separated Extended Communities and IPv6 Address
+// Specific Extended Communities found in bgp update
+func (up *Update) GetAttrExtCommunityString() string {
+	exts := make([]string, 0)
+	if ecs, err := up.GetAttrExtCommunity(); err == nil {
+		for _, ec := range ecs {
+			exts = append(exts, ec.String())
+		}
+	}
+	if ecs, err := up.GetAttrIPv6ExtCommunity(); err == nil {
+		for _, ec := range ecs {
+			exts = append(exts, ec.String())
+		}
+	}
+
+	return strings.Join(exts, ", ")
+}
+
 // GetExtCommunityRT returns  a slice of Route Target EXTENDED_COMMUNITY
 func (up *Update) GetExtCommunityRT() ([]ExtCommunity, error) {
 	rts := make([]ExtCommunity, 0)
@@ -274,26 +334,15 @@ func (up *Update) GetExtCommunityRT() ([]ExtCommunity, error) {
 
 // GetAttrAS4Path returns a sequence of AS4 path segments
 func (up *Update) GetAttrAS4Path() []uint32 {
-	path := make([]uint32, 0)
 	for _, attr := range up.PathAttributes {
 		if attr.AttributeType != 17 {
 			continue
 		}
-		for p := 0; p < len(attr.Attribute); {
-			// Skipping type
-			p++
-			// Length of path segment in 4 bytes
-			l := attr.Attribute[p]
-			p++
-			for n := 0; n < int(l); n++ {
-				as := binary.BigEndian.Uint32(attr.Attribute[p : p+4])
-				p += 4
-				path = append(path, as)
-			}
-		}
+		path, _ := UnmarshalASPath(attr.Attribute, 4)
+		return path.Flatten()
 	}
 
-	return path
+	return []uint32{}
 }
 
 // GetAttrAS4Aggregator returns the value of AS4 AGGREGATOR attribute if it is defined, otherwise it returns nil
@@ -310,6 +359,56 @@ func (up *Update) GetAttrAS4Aggregator() []byte {
 	return agg
 }
 
+// GetAttrLargeCommunity returns a slice of Large Communities found in bgp update
+func (up *Update) GetAttrLargeCommunity() ([]LargeCommunity, error) {
+	for _, attr := range up.PathAttributes {
+		if attr.AttributeType == 32 {
+			return UnmarshalBGPLargeCommunity(attr.Attribute)
+		}
+	}
+
+	return nil, fmt.Errorf("not found")
+}
+
+// GetAttrLargeCommunityString returns the string with comma separated Large Communities, an empty string
+// is returned when the attribute is not found or malformed.
+func (up *Update) GetAttrLargeCommunityString() string {
+	var communities string
+	lcs, err := up.GetAttrLargeCommunity()
+	if err != nil {
+		return communities
+	}
+	for i, lc := range lcs {
+		communities += lc.String()
+		if i < len(lcs)-1 {
+			communities += ", "
+		}
+	}
+
+	return communities
+}
+
+// IsEndOfRIB checks if the update is End-of-RIB marker, https://tools.ietf.org/html/rfc4724#section-2, and
+// returns AFI/SAFI of the marker. IPv4 unicast marker is the update without withdrawn routes, path attributes
+// and NLRI, markers of other address families carry only MP_UNREACH_NLRI attribute without withdrawn routes.
+func (up *Update) IsEndOfRIB() (uint16, uint8, bool) {
+	if len(up.WithdrawnRoutes) != 0 || len(up.NLRI) != 0 {
+		return 0, 0, false
+	}
+	switch len(up.PathAttributes) {
+	case 0:
+		return 1, 1, true
+	case 1:
+		attr := up.PathAttributes[0]
+		if attr.AttributeType != 15 || len(attr.Attribute) != 3 {
+			return 0, 0, false
+		}
+		return binary.BigEndian.Uint16(attr.Attribute[0:2]), attr.Attribute[2], true
+	}
+
+	return 0, 0, false
+}
+
 // GetNLRI29 check for presense of NLRI 29 in the update and if exists, instantiate NLRI29 object
 func (up *Update) GetNLRI29() (*bgpls.NLRI, error) {
 	for _, attr := range up.PathAttributes {
@@ -340,15 +439,27 @@ func (up *Update) GetAttrPrefixSID() (*prefixsid.PSid, error) {
 	return nil, fmt.Errorf("not found")
 }
 
-// UnmarshalBGPUpdate build BGP Update object from the byte slice provided
-func UnmarshalBGPUpdate(b []byte) (*Update, error) {
+// UnmarshalBGPUpdate build BGP Update object from the byte slice provided, addPath carries the types
+// of AFI/SAFI for which the sender of the update negotiated ADD-PATH.
+func UnmarshalBGPUpdate(b []byte, addPath map[int]bool) (*Update, error) {
 	glog.V(6).Infof("BGPUpdate Raw: %s", tools.MessageHex(b))
 
 	p := 0
-	u := Update{}
+	u := Update{
+		AddPath: addPath,
+	}
+	// Withdrawn Routes and NLRI fields carry IPv4 unicast prefixes
+	pathID := addPath[getNLRIMessageType(1, 1)]
+	if err := decoder.CheckLength("bgp update", b, 4); err != nil {
+		return nil, err
+	}
 	u.WithdrawnRoutesLength = binary.BigEndian.Uint16(b[p : p+2])
 	p += 2
-	wdr, err := base.UnmarshalRoutes(b[p : p+int(u.WithdrawnRoutesLength)])
+	// Withdrawn routes are followed by 2 bytes of Total Path Attribute Length
+	if err := decoder.CheckLength("bgp update withdrawn routes", b[p:], int(u.WithdrawnRoutesLength)+2); err != nil {
+		return nil, err
+	}
+	wdr, err := base.UnmarshalRoutes(b[p:p+int(u.WithdrawnRoutesLength)], pathID)
 	if err != nil {
 		return nil, err
 	}
@@ -356,13 +467,16 @@ func UnmarshalBGPUpdate(b []byte) (*Update, error) {
 	p += int(u.WithdrawnRoutesLength)
 	u.TotalPathAttributeLength = binary.BigEndian.Uint16(b[p : p+2])
 	p += 2
+	if err := decoder.CheckLength("bgp update path attributes", b[p:], int(u.TotalPathAttributeLength)); err != nil {
+		return nil, err
+	}
 	attrs, err := UnmarshalBGPPathAttributes(b[p : p+int(u.TotalPathAttributeLength)])
 	if err != nil {
 		return nil, err
 	}
 	u.PathAttributes = attrs
 	p += int(u.TotalPathAttributeLength)
-	routes, err := base.UnmarshalRoutes(b[p:])
+	routes, err := base.UnmarshalRoutes(b[p:], pathID)
 	if err != nil {
 		return nil, err
 	}
@@ -260,3 +260,114 @@ func TestGetAttrExtCommunity(t *testing.T) {
 		})
 	}
 }
+
+func TestGetAttrLargeCommunity(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     *Update
+		expect    []LargeCommunity
+		expectStr string
+		fail      bool
+	}{
+		{
+			name: "two large communities",
+			input: &Update{
+				PathAttributes: []PathAttribute{
+					{
+						AttributeType: 32,
+						Attribute: []byte{0x00, 0x00, 0xfd, 0xe9, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x02,
+							0xfa, 0x56, 0xea, 0x00, 0x00, 0x00, 0x00, 0x64, 0xff, 0xff, 0xff, 0xff},
+					},
+				},
+			},
+			expect: []LargeCommunity{
+				{GlobalAdmin: 65001, LocalData1: 1, LocalData2: 2},
+				{GlobalAdmin: 4200000000, LocalData1: 100, LocalData2: 4294967295},
+			},
+			expectStr: "65001:1:2, 4200000000:100:4294967295",
+		},
+		{
+			name:  "no large community attribute",
+			input: &Update{},
+			fail:  true,
+		},
+		{
+			name: "invalid attribute length",
+			input: &Update{
+				PathAttributes: []PathAttribute{
+					{
+						AttributeType: 32,
+						Attribute:     []byte{0x00, 0x00, 0xfd, 0xe9, 0x00, 0x00, 0x00, 0x01},
+					},
+				},
+			},
+			fail: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.input.GetAttrLargeCommunity()
+			if err != nil && !tt.fail {
+				t.Fatalf("expected to succeed but failed with error: %+v", err)
+			}
+			if err == nil && tt.fail {
+				t.Fatalf("expected to fail but succeeded")
+			}
+			if !reflect.DeepEqual(got, tt.expect) {
+				t.Errorf("Expected large communities %+v does not match to actual large communities %+v", tt.expect, got)
+			}
+			if s := tt.input.GetAttrLargeCommunityString(); s != tt.expectStr {
+				t.Errorf("Expected large communities string %q does not match to actual string %q", tt.expectStr, s)
+			}
+		})
+	}
+}
+
+func TestIsEndOfRIB(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  []byte
+		afi    uint16
+		safi   uint8
+		expect bool
+	}{
+		{
+			name:   "ipv4 unicast end of rib",
+			input:  []byte{0, 0, 0, 0},
+			afi:    1,
+			safi:   1,
+			expect: true,
+		},
+		{
+			name:   "evpn end of rib",
+			input:  []byte{0, 0, 0, 6, 0x80, 15, 3, 0, 25, 70},
+			afi:    25,
+			safi:   70,
+			expect: true,
+		},
+		{
+			name:  "mp_unreach_nlri with withdrawn routes",
+			input: []byte{0, 0, 0, 8, 0x80, 15, 5, 0, 1, 1, 8, 10},
+		},
+		{
+			name:  "withdrawn routes",
+			input: []byte{0, 2, 8, 10, 0, 0},
+		},
+		{
+			name:  "empty mp_unreach_nlri with other attributes",
+			input: []byte{0, 0, 0, 10, 0x80, 15, 3, 0, 2, 1, 0x40, 1, 1, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			up, err := UnmarshalBGPUpdate(tt.input, nil)
+			if err != nil {
+				t.Fatalf("failed to unmarshal bgp update with error: %+v", err)
+			}
+			afi, safi, ok := up.IsEndOfRIB()
+			if ok != tt.expect || afi != tt.afi || safi != tt.safi {
+				t.Fatalf("expected end of rib %t afi %d safi %d but got %t afi %d safi %d", tt.expect, tt.afi, tt.safi, ok, afi, safi)
+			}
+		})
+	}
+}
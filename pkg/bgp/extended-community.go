@@ -2,109 +2,340 @@ package bgp
 
 import (
 	"encoding/binary"
+	"encoding/json"
 	"fmt"
+	"math"
 	"net"
+	"strings"
 
 	"github.com/sbezverk/gobmp/pkg/tools"
 )
 
+// Extended Community types, https://tools.ietf.org/html/rfc7153#section-5.1, the non-transitive variant of
+// a type has 0x40 bit set.
+const (
+	ExtCommunityTypeAS2         = 0x00
+	ExtCommunityTypeIPv4        = 0x01
+	ExtCommunityTypeAS4         = 0x02
+	ExtCommunityTypeOpaque      = 0x03
+	ExtCommunityTypeEVPN        = 0x06
+	ExtCommunityTypeFlowspec    = 0x80
+	ExtCommunityTypeFlowspecIP  = 0x81
+	ExtCommunityTypeFlowspecAS4 = 0x82
+	// ExtCommunityNonTransitive is set in the type of extended communities which are not transitive across ASes
+	ExtCommunityNonTransitive = 0x40
+)
+
+// tunnelTypes defines the names of BGP Tunnel Encapsulation types, https://www.iana.org/assignments/bgp-parameters
+var tunnelTypes = map[uint16]string{
+	1:  "l2tpv3",
+	2:  "gre",
+	7:  "ip-in-ip",
+	8:  "vxlan",
+	9:  "nvgre",
+	10: "mpls",
+	11: "mpls-in-gre",
+	12: "vxlan-gpe",
+	13: "mpls-in-udp",
+	15: "sr-policy",
+	19: "geneve",
+}
+
 // ExtCommunity defines BGP Extended Commuity
 type ExtCommunity struct {
-	Type    uint8
+	Type uint8
+	// SubType is nil for the types of extended communities without sub type
 	SubType *uint8
 	Value   []byte
 }
 
-// IsRouteTarget return true is a specific extended community of Route Target type
-func (ext *ExtCommunity) IsRouteTarget() bool {
-	if ext.SubType != nil {
-		if *ext.SubType == 2 {
-			return true
+// ExtCommunityDetails defines the structured form of an extended community, only the fields relevant to
+// the community's type and sub type are set.
+type ExtCommunityDetails struct {
+	Type       uint8  `json:"type"`
+	SubType    *uint8 `json:"sub_type,omitempty"`
+	Name       string `json:"name"`
+	Transitive bool   `json:"transitive"`
+	// Value is the canonical string of the community's value
+	Value string `json:"value"`
+	// GlobalAdmin and LocalAdmin are the administrator and the assigned number of Route Target, Route Origin and
+	// other AS or IP address specific communities
+	GlobalAdmin string  `json:"global_admin,omitempty"`
+	LocalAdmin  *uint32 `json:"local_admin,omitempty"`
+	Color       *uint32 `json:"color,omitempty"`
+	// ColorOnly carries CO bits of Color community, rfc9256 section 8.8.1
+	ColorOnly  *uint8  `json:"color_only,omitempty"`
+	TunnelType *uint16 `json:"tunnel_type,omitempty"`
+	// Bandwidth is in bytes per second, it is set for Link Bandwidth and Flowspec traffic-rate communities
+	Bandwidth *float32 `json:"bandwidth,omitempty"`
+	// PacketRate is in packets per second, it is set for Flowspec traffic-rate-packets community
+	PacketRate *float32 `json:"packet_rate,omitempty"`
+	Terminal   *bool    `json:"terminal,omitempty"`
+	Sample     *bool    `json:"sample,omitempty"`
+	DSCP       *uint8   `json:"dscp,omitempty"`
+	MAC        string   `json:"mac,omitempty"`
+	Sequence   *uint32  `json:"sequence,omitempty"`
+	Sticky     *bool    `json:"sticky,omitempty"`
+	// Label is 24 bits Label field of ESI Label community, it carries MPLS label in high order 20 bits or VNI
+	Label        *uint32 `json:"label,omitempty"`
+	SingleActive *bool   `json:"single_active,omitempty"`
+	// Raw is the hex string of the value of communities which are not decoded
+	Raw string `json:"raw,omitempty"`
+}
+
+func u32(v uint32) *uint32 { return &v }
+func u16(v uint16) *uint16 { return &v }
+func u8(v uint8) *uint8    { return &v }
+func boolean(v bool) *bool { return &v }
+
+// ieeeFloat returns IEEE floating point number carried in the bytes, nil is returned for NaN and infinity
+// as they cannot be represented in JSON.
+func ieeeFloat(b []byte) *float32 {
+	v := math.Float32frombits(binary.BigEndian.Uint32(b))
+	if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+		return nil
+	}
+
+	return &v
+}
+
+// adminSpecific decodes Global and Local Administrator fields of AS or IP address specific community
+func (ext *ExtCommunity) adminSpecific(d *ExtCommunityDetails) {
+	switch ext.Type &^ ExtCommunityNonTransitive {
+	case ExtCommunityTypeAS2, ExtCommunityTypeFlowspec:
+		d.GlobalAdmin = fmt.Sprintf("%d", binary.BigEndian.Uint16(ext.Value[0:2]))
+		d.LocalAdmin = u32(binary.BigEndian.Uint32(ext.Value[2:]))
+	case ExtCommunityTypeIPv4, ExtCommunityTypeFlowspecIP:
+		d.GlobalAdmin = net.IP(ext.Value[0:4]).To4().String()
+		d.LocalAdmin = u32(uint32(binary.BigEndian.Uint16(ext.Value[4:])))
+	case ExtCommunityTypeAS4, ExtCommunityTypeFlowspecAS4:
+		d.GlobalAdmin = fmt.Sprintf("%d", binary.BigEndian.Uint32(ext.Value[0:4]))
+		d.LocalAdmin = u32(uint32(binary.BigEndian.Uint16(ext.Value[4:])))
+	}
+	d.Value = fmt.Sprintf("%s:%d", d.GlobalAdmin, *d.LocalAdmin)
+}
+
+// Details returns the structured form of the extended community
+func (ext *ExtCommunity) Details() *ExtCommunityDetails {
+	d := &ExtCommunityDetails{
+		Type:       ext.Type,
+		SubType:    ext.SubType,
+		Transitive: ext.Type&ExtCommunityNonTransitive == 0,
+		Name:       "unknown",
+		Raw:        fmt.Sprintf("%x", ext.Value),
+	}
+	if ext.SubType == nil || len(ext.Value) != 6 {
+		d.Value = fmt.Sprintf("Type: %d Value: %s", ext.Type, tools.MessageHex(ext.Value))
+		return d
+	}
+	st := *ext.SubType
+	decoded := true
+	switch t := ext.Type &^ ExtCommunityNonTransitive; {
+	case (t == ExtCommunityTypeAS2 || t == ExtCommunityTypeIPv4 || t == ExtCommunityTypeAS4) && st != 0x04:
+		switch st {
+		case 0x02:
+			d.Name = "rt"
+		case 0x03:
+			d.Name = "ro"
+		case 0x05:
+			d.Name = "odi"
+		case 0x08:
+			d.Name = "bdc"
+		case 0x09:
+			d.Name = "sas"
+		case 0x0a:
+			d.Name = "l2i"
+		case 0x0b:
+			d.Name = "vri"
+		default:
+			decoded = false
+		}
+		if decoded {
+			ext.adminSpecific(d)
 		}
+	case t == ExtCommunityTypeAS2 && st == 0x04:
+		// Link Bandwidth, 2 bytes AS and IEEE floating point bandwidth in bytes per second
+		d.Name = "lb"
+		d.GlobalAdmin = fmt.Sprintf("%d", binary.BigEndian.Uint16(ext.Value[0:2]))
+		d.Bandwidth = ieeeFloat(ext.Value[2:])
+		d.Value = fmt.Sprintf("%s:%g", d.GlobalAdmin, math.Float32frombits(binary.BigEndian.Uint32(ext.Value[2:])))
+	case t == ExtCommunityTypeOpaque && st == 0x0b:
+		// Color, 2 bytes of flags with CO bits followed by 4 bytes of color value
+		d.Name = "color"
+		d.ColorOnly = u8(ext.Value[0] >> 6)
+		d.Color = u32(binary.BigEndian.Uint32(ext.Value[2:]))
+		d.Value = fmt.Sprintf("%d:%d", *d.ColorOnly, *d.Color)
+	case t == ExtCommunityTypeOpaque && st == 0x0c:
+		// Encapsulation, 4 reserved bytes followed by 2 bytes of tunnel type
+		d.Name = "encap"
+		d.TunnelType = u16(binary.BigEndian.Uint16(ext.Value[4:]))
+		d.Value = fmt.Sprintf("%d", *d.TunnelType)
+		if name, ok := tunnelTypes[*d.TunnelType]; ok {
+			d.Value = name
+		}
+	case t == ExtCommunityTypeEVPN:
+		ext.evpn(d)
+		decoded = d.Name != "unknown"
+	case ext.Type == ExtCommunityTypeFlowspec || ext.Type == ExtCommunityTypeFlowspecIP || ext.Type == ExtCommunityTypeFlowspecAS4:
+		ext.flowspec(d)
+		decoded = d.Name != "unknown"
+	default:
+		decoded = false
+	}
+	if !decoded {
+		d.Value = fmt.Sprintf("Type: %d Subtype: %d Value: %s", ext.Type, st, tools.MessageHex(ext.Value))
+		return d
 	}
+	d.Raw = ""
 
-	return false
+	return d
 }
 
-func (ext *ExtCommunity) String() string {
-	var s string
-	var prefix string
+// evpn decodes EVPN extended communities, https://tools.ietf.org/html/rfc7432#section-7
+func (ext *ExtCommunity) evpn(d *ExtCommunityDetails) {
 	switch *ext.SubType {
-	case 0x0:
-		prefix = "mmb="
+	case 0x00:
+		// MAC Mobility Extended Community
+		d.Name = "mmb"
+		d.Sticky = boolean(ext.Value[0]&0x01 == 0x01)
+		d.Sequence = u32(binary.BigEndian.Uint32(ext.Value[2:]))
+		d.Value = fmt.Sprintf("%d:%d", ext.Value[0], *d.Sequence)
 	case 0x01:
-		prefix = "lb="
+		// ESI Label Extended Community, legacy string form shares "lb" prefix with Link Bandwidth
+		d.Name = "esi-label"
+		d.SingleActive = boolean(ext.Value[0]&0x01 == 0x01)
+		l := make([]byte, 4)
+		copy(l[1:], ext.Value[3:])
+		d.Label = u32(binary.BigEndian.Uint32(l))
+		d.Value = fmt.Sprintf("%d:%d", ext.Value[0], *d.Label)
 	case 0x02:
-		prefix = "rt="
+		// ES-Import Route Target
+		d.Name = "rt"
+		d.MAC = macString(ext.Value)
+		d.Value = d.MAC
 	case 0x03:
-		prefix = "ro="
-	case 0x05:
-		prefix = "odi="
+		// EVPN Router's MAC Extended Community, rfc9135 section 8.1
+		d.Name = "rmac"
+		d.MAC = macString(ext.Value)
+		d.Value = d.MAC
+	case 0x06:
+		// The DF Election Extended Community
+		d.Name = "df"
+		d.Value = fmt.Sprintf("%d:0x%04x", ext.Value[0], binary.BigEndian.Uint16(ext.Value[1:]))
+	}
+}
+
+// flowspec decodes Flowspec traffic filtering actions, https://tools.ietf.org/html/rfc8955#section-7
+func (ext *ExtCommunity) flowspec(d *ExtCommunityDetails) {
+	if ext.Type != ExtCommunityTypeFlowspec {
+		// IPv4 address and 4 bytes AS specific variants define only redirect action
+		if *ext.SubType == 0x08 {
+			d.Name = "redirect"
+			ext.adminSpecific(d)
+		}
+		return
+	}
+	switch *ext.SubType {
 	case 0x06:
-		prefix = "df="
+		d.Name = "traffic-rate"
+		d.GlobalAdmin = fmt.Sprintf("%d", binary.BigEndian.Uint16(ext.Value[0:2]))
+		d.Bandwidth = ieeeFloat(ext.Value[2:])
+		d.Value = fmt.Sprintf("%s:%g", d.GlobalAdmin, math.Float32frombits(binary.BigEndian.Uint32(ext.Value[2:])))
+	case 0x0c:
+		d.Name = "traffic-rate-packets"
+		d.GlobalAdmin = fmt.Sprintf("%d", binary.BigEndian.Uint16(ext.Value[0:2]))
+		d.PacketRate = ieeeFloat(ext.Value[2:])
+		d.Value = fmt.Sprintf("%s:%g", d.GlobalAdmin, math.Float32frombits(binary.BigEndian.Uint32(ext.Value[2:])))
+	case 0x07:
+		d.Name = "traffic-action"
+		d.Sample = boolean(ext.Value[5]&0x02 == 0x02)
+		d.Terminal = boolean(ext.Value[5]&0x01 == 0x01)
+		actions := make([]string, 0, 2)
+		if *d.Sample {
+			actions = append(actions, "sample")
+		}
+		if *d.Terminal {
+			actions = append(actions, "terminal")
+		}
+		d.Value = strings.Join(actions, ",")
 	case 0x08:
-		prefix = "bdc="
+		d.Name = "redirect"
+		ext.adminSpecific(d)
 	case 0x09:
-		prefix = "sas="
-	case 0x0a:
-		prefix = "l2i="
-	}
-	switch ext.Type {
-	case 0:
-		s += fmt.Sprintf("%d:%d", binary.BigEndian.Uint16(ext.Value[0:2]), binary.BigEndian.Uint32(ext.Value[2:]))
-	case 1:
-		s += fmt.Sprintf("%s:%d", net.IP(ext.Value[0:4]).To4().String(), binary.BigEndian.Uint16(ext.Value[4:]))
-	case 2:
-		s += fmt.Sprintf("%d:%d", binary.BigEndian.Uint32(ext.Value[0:4]), binary.BigEndian.Uint16(ext.Value[4:]))
-	case 6:
-		// EVPN related extended communities
-		switch *ext.SubType {
-		case 0x01:
-			// ESI Label Extended Community
-			l := make([]byte, 4)
-			copy(l, ext.Value[3:])
-			s += fmt.Sprintf("%d:%d", ext.Value[0], binary.BigEndian.Uint32(l))
-		case 0x02:
-			// ES-Import Route Target
-			for i, m := range ext.Value {
-				s += fmt.Sprintf("%02x", m)
-				if i < len(ext.Value)-1 {
-					s += ":"
-				}
-			}
-		case 0x00:
-			// MAC Mobility Extended Community
-			s += fmt.Sprintf("%d:%d", ext.Value[0], binary.BigEndian.Uint32(ext.Value[2:]))
-		case 0x06:
-			// The DF Election Extended Community
-			s += fmt.Sprintf("%d:0x%04x", ext.Value[0], binary.BigEndian.Uint16(ext.Value[1:]))
-		default:
-			prefix = "unknown="
-			s += fmt.Sprintf("Type: %d Subtype: %d Value: %s", ext.Type, *ext.SubType, tools.MessageHex(ext.Value))
+		d.Name = "traffic-marking"
+		d.DSCP = u8(ext.Value[5] & 0x3f)
+		d.Value = fmt.Sprintf("%d", *d.DSCP)
+	}
+}
+
+func macString(b []byte) string {
+	var s string
+	for i, m := range b {
+		s += fmt.Sprintf("%02x", m)
+		if i < len(b)-1 {
+			s += ":"
 		}
+	}
+
+	return s
+}
 
+// IsRouteTarget return true is a specific extended community of Route Target type
+func (ext *ExtCommunity) IsRouteTarget() bool {
+	if ext.SubType != nil {
+		if *ext.SubType == 2 {
+			return true
+		}
 	}
 
-	return prefix + s
+	return false
+}
+
+func (ext *ExtCommunity) String() string {
+	d := ext.Details()
+	if d.Name == "unknown" {
+		return "unknown=" + d.Value
+	}
+	if prefix, ok := legacyPrefixes[d.Name]; ok {
+		return prefix + "=" + d.Value
+	}
+
+	return d.Name + "=" + d.Value
+}
+
+// legacyPrefixes maps the names of the extended communities to the prefixes of their legacy string form
+// when the two differ
+var legacyPrefixes = map[string]string{
+	"esi-label": "lb",
+}
+
+// MarshalJSON returns the structured form of the extended community
+func (ext ExtCommunity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ext.Details())
+}
+
+// hasSubType returns true if the extended community type is followed by a sub type, https://tools.ietf.org/html/rfc7153
+func hasSubType(t uint8) bool {
+	switch t &^ ExtCommunityNonTransitive {
+	case ExtCommunityTypeAS2, ExtCommunityTypeIPv4, ExtCommunityTypeAS4, ExtCommunityTypeOpaque, ExtCommunityTypeEVPN:
+		return true
+	case ExtCommunityTypeFlowspec, ExtCommunityTypeFlowspecIP, ExtCommunityTypeFlowspecAS4:
+		return true
+	}
+
+	return false
 }
 
 func makeExtCommunity(b []byte) (*ExtCommunity, error) {
 	ext := ExtCommunity{}
 	if len(b) != 8 {
-		return nil, fmt.Errorf("invalid length expected 8 got %d", len(b))
+		return nil, decoder.Malformed("bgp extended community", "invalid length expected 8 got %d", len(b))
 	}
 	p := 0
 	ext.Type = b[p]
 	p++
 	l := 7
-	switch ext.Type & 0x3f {
-	case 0:
-		fallthrough
-	case 1:
-		fallthrough
-	case 2:
-		fallthrough
-	case 6:
+	if hasSubType(ext.Type) {
 		st := uint8(b[p])
 		ext.SubType = &st
 		l = 6
@@ -119,6 +350,9 @@ func makeExtCommunity(b []byte) (*ExtCommunity, error) {
 // UnmarshalBGPExtCommunity builds a slice of Extended Communities
 func UnmarshalBGPExtCommunity(b []byte) ([]ExtCommunity, error) {
 	exts := make([]ExtCommunity, 0)
+	if len(b)%8 != 0 {
+		return nil, decoder.Malformed("bgp extended community", "invalid attribute length %d, expected a multiple of 8", len(b))
+	}
 	for p := 0; p < len(b); {
 		ext, err := makeExtCommunity(b[p : p+8])
 		if err != nil {
@@ -130,3 +364,77 @@ func UnmarshalBGPExtCommunity(b []byte) ([]ExtCommunity, error) {
 
 	return exts, nil
 }
+
+// IPv6ExtCommunity defines IPv6 Address Specific Extended Community, https://tools.ietf.org/html/rfc5701
+type IPv6ExtCommunity struct {
+	Type       uint8
+	SubType    uint8
+	Address    net.IP
+	LocalAdmin uint16
+}
+
+// IsRouteTarget return true if IPv6 Address Specific Extended Community is of Route Target type
+func (ext *IPv6ExtCommunity) IsRouteTarget() bool {
+	return ext.SubType == 0x02
+}
+
+// Details returns the structured form of IPv6 Address Specific Extended Community
+func (ext *IPv6ExtCommunity) Details() *ExtCommunityDetails {
+	st := ext.SubType
+	d := &ExtCommunityDetails{
+		Type:        ext.Type,
+		SubType:     &st,
+		Transitive:  ext.Type&ExtCommunityNonTransitive == 0,
+		GlobalAdmin: ext.Address.To16().String(),
+		LocalAdmin:  u32(uint32(ext.LocalAdmin)),
+	}
+	switch ext.SubType {
+	case 0x02:
+		d.Name = "rt"
+	case 0x03:
+		d.Name = "ro"
+	case 0x0b:
+		d.Name = "vri"
+	case 0x0d:
+		// Flowspec Redirect to IPv6, rfc8956 section 6.1
+		d.Name = "redirect"
+	default:
+		d.Name = "unknown"
+	}
+	d.Value = fmt.Sprintf("[%s]:%d", d.GlobalAdmin, ext.LocalAdmin)
+	if d.Name == "unknown" {
+		d.Value = fmt.Sprintf("Type: %d Subtype: %d Value: %s", ext.Type, ext.SubType, d.Value)
+	}
+
+	return d
+}
+
+func (ext *IPv6ExtCommunity) String() string {
+	d := ext.Details()
+	return d.Name + "=" + d.Value
+}
+
+// MarshalJSON returns the structured form of IPv6 Address Specific Extended Community
+func (ext IPv6ExtCommunity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ext.Details())
+}
+
+// UnmarshalBGPIPv6ExtCommunity builds a slice of IPv6 Address Specific Extended Communities
+func UnmarshalBGPIPv6ExtCommunity(b []byte) ([]IPv6ExtCommunity, error) {
+	if len(b)%20 != 0 {
+		return nil, decoder.Malformed("bgp ipv6 extended community", "invalid attribute length %d, expected a multiple of 20", len(b))
+	}
+	exts := make([]IPv6ExtCommunity, 0, len(b)/20)
+	for p := 0; p < len(b); p += 20 {
+		ext := IPv6ExtCommunity{
+			Type:       b[p],
+			SubType:    b[p+1],
+			Address:    make(net.IP, 16),
+			LocalAdmin: binary.BigEndian.Uint16(b[p+18 : p+20]),
+		}
+		copy(ext.Address, b[p+2:p+18])
+		exts = append(exts, ext)
+	}
+
+	return exts, nil
+}
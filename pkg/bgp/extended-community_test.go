@@ -0,0 +1,175 @@
+package bgp
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestExtCommunityString(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      []byte
+		expectStr  string
+		expectJSON string
+	}{
+		{
+			name:       "2 bytes as route target",
+			input:      []byte{0x00, 0x02, 0xfd, 0xe8, 0x00, 0x00, 0x00, 0x64},
+			expectStr:  "rt=65000:100",
+			expectJSON: `{"type":0,"sub_type":2,"name":"rt","transitive":true,"value":"65000:100","global_admin":"65000","local_admin":100}`,
+		},
+		{
+			name:       "ipv4 route origin",
+			input:      []byte{0x01, 0x03, 0x0a, 0x00, 0x00, 0x01, 0x00, 0x07},
+			expectStr:  "ro=10.0.0.1:7",
+			expectJSON: `{"type":1,"sub_type":3,"name":"ro","transitive":true,"value":"10.0.0.1:7","global_admin":"10.0.0.1","local_admin":7}`,
+		},
+		{
+			name:       "4 bytes as route target",
+			input:      []byte{0x02, 0x02, 0xfa, 0x56, 0xea, 0x00, 0x00, 0x01},
+			expectStr:  "rt=4200000000:1",
+			expectJSON: `{"type":2,"sub_type":2,"name":"rt","transitive":true,"value":"4200000000:1","global_admin":"4200000000","local_admin":1}`,
+		},
+		{
+			name:       "non transitive link bandwidth",
+			input:      []byte{0x40, 0x04, 0xfd, 0xe8, 0x4b, 0x3e, 0xbc, 0x20},
+			expectStr:  "lb=65000:1.25e+07",
+			expectJSON: `{"type":64,"sub_type":4,"name":"lb","transitive":false,"value":"65000:1.25e+07","global_admin":"65000","bandwidth":12500000}`,
+		},
+		{
+			name:       "color with co bits",
+			input:      []byte{0x03, 0x0b, 0x40, 0x00, 0x00, 0x00, 0x00, 0x64},
+			expectStr:  "color=1:100",
+			expectJSON: `{"type":3,"sub_type":11,"name":"color","transitive":true,"value":"1:100","color":100,"color_only":1}`,
+		},
+		{
+			name:       "vxlan encapsulation",
+			input:      []byte{0x03, 0x0c, 0x00, 0x00, 0x00, 0x00, 0x00, 0x08},
+			expectStr:  "encap=vxlan",
+			expectJSON: `{"type":3,"sub_type":12,"name":"encap","transitive":true,"value":"vxlan","tunnel_type":8}`,
+		},
+		{
+			name:       "flowspec traffic rate",
+			input:      []byte{0x80, 0x06, 0xfd, 0xe8, 0x00, 0x00, 0x00, 0x00},
+			expectStr:  "traffic-rate=65000:0",
+			expectJSON: `{"type":128,"sub_type":6,"name":"traffic-rate","transitive":true,"value":"65000:0","global_admin":"65000","bandwidth":0}`,
+		},
+		{
+			name:       "flowspec traffic action",
+			input:      []byte{0x80, 0x07, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03},
+			expectStr:  "traffic-action=sample,terminal",
+			expectJSON: `{"type":128,"sub_type":7,"name":"traffic-action","transitive":true,"value":"sample,terminal","terminal":true,"sample":true}`,
+		},
+		{
+			name:       "flowspec redirect to ipv4 specific route target",
+			input:      []byte{0x81, 0x08, 0x0a, 0x00, 0x00, 0x01, 0x00, 0x02},
+			expectStr:  "redirect=10.0.0.1:2",
+			expectJSON: `{"type":129,"sub_type":8,"name":"redirect","transitive":true,"value":"10.0.0.1:2","global_admin":"10.0.0.1","local_admin":2}`,
+		},
+		{
+			name:       "flowspec traffic marking",
+			input:      []byte{0x80, 0x09, 0x00, 0x00, 0x00, 0x00, 0x00, 0x2e},
+			expectStr:  "traffic-marking=46",
+			expectJSON: `{"type":128,"sub_type":9,"name":"traffic-marking","transitive":true,"value":"46","dscp":46}`,
+		},
+		{
+			name:       "evpn router mac",
+			input:      []byte{0x06, 0x03, 0x00, 0x11, 0x22, 0x33, 0x44, 0x55},
+			expectStr:  "rmac=00:11:22:33:44:55",
+			expectJSON: `{"type":6,"sub_type":3,"name":"rmac","transitive":true,"value":"00:11:22:33:44:55","mac":"00:11:22:33:44:55"}`,
+		},
+		{
+			name:       "evpn sticky mac mobility",
+			input:      []byte{0x06, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x05},
+			expectStr:  "mmb=1:5",
+			expectJSON: `{"type":6,"sub_type":0,"name":"mmb","transitive":true,"value":"1:5","sequence":5,"sticky":true}`,
+		},
+		{
+			name:       "evpn esi label",
+			input:      []byte{0x06, 0x01, 0x01, 0x00, 0x00, 0x00, 0x3e, 0x81},
+			expectStr:  "lb=1:16001",
+			expectJSON: `{"type":6,"sub_type":1,"name":"esi-label","transitive":true,"value":"1:16001","label":16001,"single_active":true}`,
+		},
+		{
+			name:       "type without sub type",
+			input:      []byte{0x04, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01},
+			expectStr:  "unknown=Type: 4 Value: [ 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01 ]",
+			expectJSON: `{"type":4,"name":"unknown","transitive":true,"value":"Type: 4 Value: [ 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01 ]","raw":"00000000000001"}`,
+		},
+		{
+			name:       "unknown sub type",
+			input:      []byte{0x03, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01},
+			expectStr:  "unknown=Type: 3 Subtype: 1 Value: [ 0x00, 0x00, 0x00, 0x00, 0x00, 0x01 ]",
+			expectJSON: `{"type":3,"sub_type":1,"name":"unknown","transitive":true,"value":"Type: 3 Subtype: 1 Value: [ 0x00, 0x00, 0x00, 0x00, 0x00, 0x01 ]","raw":"000000000001"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exts, err := UnmarshalBGPExtCommunity(tt.input)
+			if err != nil {
+				t.Fatalf("supposed to succeed but failed with error: %+v", err)
+			}
+			if s := exts[0].String(); s != tt.expectStr {
+				t.Errorf("expected extended community string %q but got %q", tt.expectStr, s)
+			}
+			j, err := json.Marshal(exts[0])
+			if err != nil {
+				t.Fatalf("failed to marshal extended community with error: %+v", err)
+			}
+			if string(j) != tt.expectJSON {
+				t.Errorf("expected extended community json %s but got %s", tt.expectJSON, j)
+			}
+		})
+	}
+}
+
+func TestGetAttrIPv6ExtCommunity(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      []byte
+		expectStr  []string
+		expectJSON string
+		fail       bool
+	}{
+		{
+			name: "route target and flowspec redirect",
+			input: []byte{0x00, 0x02, 0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x01, 0x00, 0x64,
+				0x00, 0x0d, 0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x02, 0x00, 0x00},
+			expectStr: []string{"rt=[2001:db8::1]:100", "redirect=[2001:db8::2]:0"},
+			expectJSON: `[{"type":0,"sub_type":2,"name":"rt","transitive":true,"value":"[2001:db8::1]:100","global_admin":"2001:db8::1","local_admin":100},` +
+				`{"type":0,"sub_type":13,"name":"redirect","transitive":true,"value":"[2001:db8::2]:0","global_admin":"2001:db8::2","local_admin":0}]`,
+		},
+		{
+			name:  "invalid attribute length",
+			input: []byte{0x00, 0x02, 0x20, 0x01},
+			fail:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			up := &Update{PathAttributes: []PathAttribute{{AttributeType: 25, Attribute: tt.input}}}
+			exts, err := up.GetAttrIPv6ExtCommunity()
+			if err != nil && !tt.fail {
+				t.Fatalf("supposed to succeed but failed with error: %+v", err)
+			}
+			if err == nil && tt.fail {
+				t.Fatalf("supposed to fail but succeeded")
+			}
+			if err != nil {
+				return
+			}
+			for i, ext := range exts {
+				if s := ext.String(); s != tt.expectStr[i] {
+					t.Errorf("expected ipv6 extended community string %q but got %q", tt.expectStr[i], s)
+				}
+			}
+			j, err := json.Marshal(exts)
+			if err != nil {
+				t.Fatalf("failed to marshal ipv6 extended communities with error: %+v", err)
+			}
+			if string(j) != tt.expectJSON {
+				t.Errorf("expected ipv6 extended communities json %s but got %s", tt.expectJSON, j)
+			}
+		})
+	}
+}
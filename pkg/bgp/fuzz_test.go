@@ -0,0 +1,121 @@
+package bgp
+
+import "testing"
+
+func FuzzUnmarshalBGPUpdate(f *testing.F) {
+	// Origin, AS_PATH, Next Hop and IPv4 NLRI
+	f.Add([]byte{0x00, 0x00, 0x00, 0x14, 0x40, 0x01, 0x01, 0x00, 0x40, 0x02, 0x06, 0x02, 0x01, 0x00, 0x00, 0xfd, 0xe8, 0x40, 0x03, 0x04, 0x0a, 0x00, 0x00, 0x01, 0x18, 0x0a, 0x01, 0x02}, false)
+	// MP_REACH_NLRI with IPv4 unicast NLRI
+	f.Add([]byte{0x00, 0x00, 0x00, 0x10, 0x80, 0x0e, 0x0d, 0x00, 0x01, 0x01, 0x04, 0x0a, 0x00, 0x00, 0x01, 0x00, 0x18, 0x0a, 0x01, 0x02}, false)
+	// MP_UNREACH_NLRI with IPv4 unicast NLRI
+	f.Add([]byte{0x00, 0x00, 0x00, 0x0a, 0x80, 0x0f, 0x07, 0x00, 0x01, 0x01, 0x18, 0x0a, 0x01, 0x02}, false)
+	// IPv4 NLRI with Path Identifier
+	f.Add([]byte{0x00, 0x00, 0x00, 0x14, 0x40, 0x01, 0x01, 0x00, 0x40, 0x02, 0x06, 0x02, 0x01, 0x00, 0x00, 0xfd, 0xe8, 0x40, 0x03, 0x04, 0x0a, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01, 0x18, 0x0a, 0x01, 0x02}, true)
+	f.Fuzz(func(t *testing.T, b []byte, pathID bool) {
+		addPath := map[int]bool{}
+		if pathID {
+			addPath = map[int]bool{1: true, 2: true, 16: true, 17: true, 18: true, 19: true, 24: true}
+		}
+		u, err := UnmarshalBGPUpdate(b, addPath)
+		if err != nil {
+			return
+		}
+		_ = u.String()
+		u.GetAllAttributeID()
+		u.GetBaseAttrHash()
+		u.GetAttrOrigin()
+		u.GetAttrASPath(true)
+		u.GetAttrASPath(false)
+		u.GetAttrAS4Path()
+		u.GetAttrNextHop()
+		u.GetAttrMED()
+		u.GetAttrLocalPref()
+		u.GetAttrAtomicAggregate()
+		u.GetAttrAggregator()
+		u.GetAttrAS4Aggregator()
+		u.GetAttrCommunity()
+		u.GetAttrCommunityString()
+		u.GetAttrOriginatorID()
+		u.GetAttrClusterListID()
+		u.GetAttrExtCommunity()
+		u.GetExtCommunityRT()
+		u.GetAttrIPv6ExtCommunity()
+		u.GetAttrExtCommunityString()
+		u.IsEndOfRIB()
+		u.GetAttrLargeCommunityString()
+		u.GetNLRI29()
+		u.GetAttrPrefixSID()
+		for _, pa := range u.PathAttributes {
+			var mp MPNLRI
+			switch pa.AttributeType {
+			case 14:
+				mp, err = UnmarshalMPReachNLRI(pa.Attribute, u.AddPath)
+			case 15:
+				mp, err = UnmarshalMPUnReachNLRI(pa.Attribute, u.AddPath)
+			default:
+				continue
+			}
+			if err != nil {
+				continue
+			}
+			_ = mp.String()
+			mp.GetAFISAFIType()
+			mp.IsIPv6NLRI()
+			mp.GetNextHop()
+			mp.GetNLRI71()
+			mp.GetNLRIL3VPN()
+			mp.GetNLRIEVPN()
+			mp.GetNLRIUnicast()
+			mp.GetNLRILU()
+		}
+	})
+}
+
+func FuzzUnmarshalBGPOpenMessage(f *testing.F) {
+	// OPEN with Multiprotocol and 4-octet AS capabilities
+	f.Add([]byte{0x00, 0x1d, 0x01, 0x04, 0x5b, 0xa0, 0x00, 0xb4, 0x0a, 0x00, 0x00, 0x01, 0x0e, 0x02, 0x0c, 0x01, 0x04, 0x00, 0x01, 0x00, 0x01, 0x41, 0x04, 0x00, 0x00, 0x5b, 0xa0})
+	f.Fuzz(func(t *testing.T, b []byte) {
+		o, err := UnmarshalBGPOpenMessage(b)
+		if err != nil {
+			return
+		}
+		o.GetCapabilities()
+		o.Is4BytesASCapable()
+		o.IsMultiLabelCapable()
+	})
+}
+
+func FuzzUnmarshalBGPNotificationMessage(f *testing.F) {
+	// Cease, Administrative Shutdown with communication
+	f.Add([]byte{0x00, 0x1a, 0x03, 0x06, 0x02, 0x04, 't', 'e', 's', 't'})
+	f.Fuzz(func(t *testing.T, b []byte) {
+		n, err := UnmarshalBGPNotificationMessage(b)
+		if err != nil {
+			return
+		}
+		_ = n.String()
+		n.GetErrorCodeString()
+		n.GetErrorSubCodeString()
+		n.GetShutdownCommunication()
+	})
+}
+
+func FuzzUnmarshalBGPExtCommunity(f *testing.F) {
+	f.Add([]byte{0x00, 0x02, 0x00, 0x64, 0x00, 0x00, 0x00, 0x01})
+	// Extended community type without sub type
+	f.Add([]byte{0x04, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01})
+	// Flowspec traffic-action
+	f.Add([]byte{0x80, 0x07, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03})
+	f.Fuzz(func(t *testing.T, b []byte) {
+		exts, err := UnmarshalBGPExtCommunity(b)
+		if err != nil {
+			return
+		}
+		for _, ext := range exts {
+			_ = ext.String()
+			if _, err := ext.MarshalJSON(); err != nil {
+				t.Fatalf("fail to marshal extended community with error: %+v", err)
+			}
+		}
+	})
+}
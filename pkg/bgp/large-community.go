@@ -0,0 +1,34 @@
+package bgp
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// LargeCommunity defines BGP Large Community, https://tools.ietf.org/html/rfc8092
+type LargeCommunity struct {
+	GlobalAdmin uint32
+	LocalData1  uint32
+	LocalData2  uint32
+}
+
+func (lc *LargeCommunity) String() string {
+	return fmt.Sprintf("%d:%d:%d", lc.GlobalAdmin, lc.LocalData1, lc.LocalData2)
+}
+
+// UnmarshalBGPLargeCommunity builds a slice of Large Communities from LARGE_COMMUNITY attribute
+func UnmarshalBGPLargeCommunity(b []byte) ([]LargeCommunity, error) {
+	if len(b)%12 != 0 {
+		return nil, decoder.Malformed("bgp large community", "invalid attribute length %d, expected a multiple of 12", len(b))
+	}
+	lcs := make([]LargeCommunity, 0, len(b)/12)
+	for p := 0; p < len(b); p += 12 {
+		lcs = append(lcs, LargeCommunity{
+			GlobalAdmin: binary.BigEndian.Uint32(b[p : p+4]),
+			LocalData1:  binary.BigEndian.Uint32(b[p+4 : p+8]),
+			LocalData2:  binary.BigEndian.Uint32(b[p+8 : p+12]),
+		})
+	}
+
+	return lcs, nil
+}
@@ -20,6 +20,8 @@ type MPReachNLRI struct {
 	NextHopAddressLength uint8
 	NextHopAddress       []byte
 	NLRI                 []byte
+	// AddPath is true when NLRI carry Path Identifiers
+	AddPath bool
 }
 
 // GetAFISAFIType returns underlaying NLRI's type based on AFI/SAFI
@@ -60,6 +62,9 @@ func (mp *MPReachNLRI) IsIPv6NLRI() bool {
 func (mp *MPReachNLRI) GetNextHop() string {
 	if mp.AddressFamilyID == 1 && mp.SubAddressFamilyID == 128 {
 		// In case of L3VPN AFI 1 SAFI 128, next hop is encoded as RD (Always 0, 8 bytes) + ipv4 address
+		if mp.NextHopAddressLength < 4 {
+			return "invalid"
+		}
 		return net.IP(mp.NextHopAddress[mp.NextHopAddressLength-4:]).To4().String()
 	}
 	if mp.NextHopAddressLength == 4 {
@@ -87,7 +92,7 @@ func (mp *MPReachNLRI) GetNLRI71() (*ls.NLRI71, error) {
 // GetNLRIL3VPN check for presense of NLRI L3VPN AFI 1 and SAFI 128 in the NLRI 14 NLRI data and if exists, instantiate L3VPN object
 func (mp *MPReachNLRI) GetNLRIL3VPN() (*l3vpn.NLRI, error) {
 	if mp.AddressFamilyID == 1 && mp.SubAddressFamilyID == 128 {
-		nlri, err := l3vpn.UnmarshalL3VPNNLRI(mp.NLRI)
+		nlri, err := l3vpn.UnmarshalL3VPNNLRI(mp.NLRI, mp.AddPath)
 		if err != nil {
 			return nil, err
 		}
@@ -101,7 +106,7 @@ func (mp *MPReachNLRI) GetNLRIL3VPN() (*l3vpn.NLRI, error) {
 // GetNLRIEVPN check for presense of NLRI EVPN AFI 25 and SAFI 70 in the NLRI 14 NLRI data and if exists, instantiate EVPN object
 func (mp *MPReachNLRI) GetNLRIEVPN() (*evpn.Route, error) {
 	if mp.AddressFamilyID == 25 && mp.SubAddressFamilyID == 70 {
-		route, err := evpn.UnmarshalEVPNNLRI(mp.NLRI)
+		route, err := evpn.UnmarshalEVPNNLRI(mp.NLRI, mp.AddPath)
 		if err != nil {
 			return nil, err
 		}
@@ -115,7 +120,7 @@ func (mp *MPReachNLRI) GetNLRIEVPN() (*evpn.Route, error) {
 // GetNLRIUnicast check for presense of NLRI EVPN AFI 1 or 2  and SAFI 1 in the NLRI 14 NLRI data and if exists, instantiate Unicast object
 func (mp *MPReachNLRI) GetNLRIUnicast() (*unicast.MPUnicastNLRI, error) {
 	if (mp.AddressFamilyID == 1 || mp.AddressFamilyID == 2) && mp.SubAddressFamilyID == 1 {
-		nlri, err := unicast.UnmarshalUnicastNLRI(mp.NLRI)
+		nlri, err := unicast.UnmarshalUnicastNLRI(mp.NLRI, mp.AddPath)
 		if err != nil {
 			return nil, err
 		}
@@ -129,7 +134,7 @@ func (mp *MPReachNLRI) GetNLRIUnicast() (*unicast.MPUnicastNLRI, error) {
 // GetNLRILU check for presense of NLRI EVPN AFI 1 or 2  and SAFI 4 in the NLRI 14 NLRI data and if exists, instantiate Unicast object
 func (mp *MPReachNLRI) GetNLRILU() (*unicast.MPUnicastNLRI, error) {
 	if (mp.AddressFamilyID == 1 || mp.AddressFamilyID == 2) && mp.SubAddressFamilyID == 4 {
-		nlri, err := unicast.UnmarshalLUNLRI(mp.NLRI)
+		nlri, err := unicast.UnmarshalLUNLRI(mp.NLRI, mp.AddPath)
 		if err != nil {
 			return nil, err
 		}
@@ -140,9 +145,14 @@ func (mp *MPReachNLRI) GetNLRILU() (*unicast.MPUnicastNLRI, error) {
 	return nil, fmt.Errorf("not found")
 }
 
-// UnmarshalMPReachNLRI builds MP Reach NLRI attributes
-func UnmarshalMPReachNLRI(b []byte) (MPNLRI, error) {
+// UnmarshalMPReachNLRI builds MP Reach NLRI attributes, addPath carries the types of AFI/SAFI
+// for which NLRI carry Path Identifiers.
+func UnmarshalMPReachNLRI(b []byte, addPath map[int]bool) (MPNLRI, error) {
 	glog.V(6).Infof("MPReachNLRI Raw: %s", tools.MessageHex(b))
+	// AFI, SAFI and Next Hop Length
+	if err := decoder.CheckLength("mp_reach_nlri", b, 4); err != nil {
+		return nil, err
+	}
 	mp := MPReachNLRI{}
 	p := 0
 	mp.AddressFamilyID = binary.BigEndian.Uint16(b[p : p+2])
@@ -151,12 +161,17 @@ func UnmarshalMPReachNLRI(b []byte) (MPNLRI, error) {
 	p++
 	mp.NextHopAddressLength = uint8(b[p])
 	p++
+	// Next Hop is followed by 1 reserved byte
+	if err := decoder.CheckLength("mp_reach_nlri next hop", b[p:], int(mp.NextHopAddressLength)+1); err != nil {
+		return nil, err
+	}
 	mp.NextHopAddress = b[p : p+int(mp.NextHopAddressLength)]
 	p += int(mp.NextHopAddressLength)
 	// Skip reserved byte
 	p++
 	mp.NLRI = make([]byte, len(b[p:]))
 	copy(mp.NLRI, b[p:])
+	mp.AddPath = addPath[mp.GetAFISAFIType()]
 
 	return &mp, nil
 }
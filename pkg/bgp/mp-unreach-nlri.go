@@ -17,6 +17,8 @@ type MPUnReachNLRI struct {
 	AddressFamilyID    uint16
 	SubAddressFamilyID uint8
 	WithdrawnRoutes    []byte
+	// AddPath is true when Withdrawn Routes carry Path Identifiers
+	AddPath bool
 }
 
 // GetAFISAFIType returns underlaying NLRI's type based on AFI/SAFI
@@ -59,7 +61,7 @@ func (mp *MPUnReachNLRI) GetNLRI71() (*ls.NLRI71, error) {
 // GetNLRIL3VPN check for presense of NLRI L3VPN AFI 1 and SAFI 128 in the NLRI 14 NLRI data and if exists, instantiate L3VPN object
 func (mp *MPUnReachNLRI) GetNLRIL3VPN() (*l3vpn.NLRI, error) {
 	if mp.AddressFamilyID == 1 && mp.SubAddressFamilyID == 128 {
-		nlri, err := l3vpn.UnmarshalL3VPNNLRI(mp.WithdrawnRoutes)
+		nlri, err := l3vpn.UnmarshalL3VPNNLRI(mp.WithdrawnRoutes, mp.AddPath)
 		if err != nil {
 			return nil, err
 		}
@@ -73,7 +75,7 @@ func (mp *MPUnReachNLRI) GetNLRIL3VPN() (*l3vpn.NLRI, error) {
 // GetNLRIEVPN check for presense of NLRI EVPN AFI 25 and SAFI 70 in the NLRI 14 NLRI data and if exists, instantiate EVPN object
 func (mp *MPUnReachNLRI) GetNLRIEVPN() (*evpn.Route, error) {
 	if mp.AddressFamilyID == 25 && mp.SubAddressFamilyID == 70 {
-		route, err := evpn.UnmarshalEVPNNLRI(mp.WithdrawnRoutes)
+		route, err := evpn.UnmarshalEVPNNLRI(mp.WithdrawnRoutes, mp.AddPath)
 		if err != nil {
 			return nil, err
 		}
@@ -87,7 +89,7 @@ func (mp *MPUnReachNLRI) GetNLRIEVPN() (*evpn.Route, error) {
 // GetNLRIUnicast check for presense of NLRI EVPN AFI 1 or 2  and SAFI 1 in the NLRI 14 NLRI data and if exists, instantiate Unicast object
 func (mp *MPUnReachNLRI) GetNLRIUnicast() (*unicast.MPUnicastNLRI, error) {
 	if (mp.AddressFamilyID == 1 || mp.AddressFamilyID == 2) && mp.SubAddressFamilyID == 1 {
-		nlri, err := unicast.UnmarshalUnicastNLRI(mp.WithdrawnRoutes)
+		nlri, err := unicast.UnmarshalUnicastNLRI(mp.WithdrawnRoutes, mp.AddPath)
 		if err != nil {
 			return nil, err
 		}
@@ -101,7 +103,7 @@ func (mp *MPUnReachNLRI) GetNLRIUnicast() (*unicast.MPUnicastNLRI, error) {
 // GetNLRILU check for presense of NLRI EVPN AFI 1 or 2  and SAFI 4 in the NLRI 14 NLRI data and if exists, instantiate Unicast object
 func (mp *MPUnReachNLRI) GetNLRILU() (*unicast.MPUnicastNLRI, error) {
 	if (mp.AddressFamilyID == 1 || mp.AddressFamilyID == 2) && mp.SubAddressFamilyID == 4 {
-		nlri, err := unicast.UnmarshalLUNLRI(mp.WithdrawnRoutes)
+		nlri, err := unicast.UnmarshalLUNLRI(mp.WithdrawnRoutes, mp.AddPath)
 		if err != nil {
 			return nil, err
 		}
@@ -112,9 +114,13 @@ func (mp *MPUnReachNLRI) GetNLRILU() (*unicast.MPUnicastNLRI, error) {
 	return nil, fmt.Errorf("not found")
 }
 
-// UnmarshalMPUnReachNLRI builds MP Reach NLRI attributes
-func UnmarshalMPUnReachNLRI(b []byte) (MPNLRI, error) {
+// UnmarshalMPUnReachNLRI builds MP Reach NLRI attributes, addPath carries the types of AFI/SAFI
+// for which Withdrawn Routes carry Path Identifiers.
+func UnmarshalMPUnReachNLRI(b []byte, addPath map[int]bool) (MPNLRI, error) {
 	glog.V(5).Infof("MPUnReachNLRI Raw: %s", tools.MessageHex(b))
+	if err := decoder.CheckLength("mp_unreach_nlri", b, 3); err != nil {
+		return nil, err
+	}
 	mp := MPUnReachNLRI{}
 	p := 0
 	mp.AddressFamilyID = binary.BigEndian.Uint16(b[p : p+2])
@@ -124,6 +130,7 @@ func UnmarshalMPUnReachNLRI(b []byte) (MPNLRI, error) {
 	glog.Infof("MP_UNREACH_NLRI for AFI: %d SAFI: %d", mp.AddressFamilyID, mp.SubAddressFamilyID)
 	mp.WithdrawnRoutes = make([]byte, len(b[p:]))
 	copy(mp.WithdrawnRoutes, b[p:])
+	mp.AddPath = addPath[mp.GetAFISAFIType()]
 
 	return &mp, nil
 }
@@ -62,7 +62,7 @@ func (ls *NLRI) GetAllAttribute() []uint16 {
 // GetNodeFlags reeturns Flag Bits TLV carries a bit mask describing node attributes.
 func (ls *NLRI) GetNodeFlags() uint8 {
 	for _, tlv := range ls.LS {
-		if tlv.Type != 1024 {
+		if tlv.Type != 1024 || len(tlv.Value) == 0 {
 			continue
 		}
 		return uint8(tlv.Value[0])
@@ -88,7 +88,7 @@ func (ls *NLRI) GetISISAreaID() string {
 		if tlv.Type != 1027 {
 			continue
 		}
-		for p := 0; p < len(tlv.Value); {
+		for p := 0; p+3 <= len(tlv.Value); {
 			s += fmt.Sprintf("%02x.", tlv.Value[p])
 			s += fmt.Sprintf("%02x", tlv.Value[p+1])
 			s += fmt.Sprintf("%02x", tlv.Value[p+2])
@@ -299,7 +299,7 @@ func (ls *NLRI) GetNodeSRv6CapabilitiesTLV() string {
 // GetAdminGroup returns Administrative group (color)
 func (ls *NLRI) GetAdminGroup() uint32 {
 	for _, tlv := range ls.LS {
-		if tlv.Type != 1088 {
+		if tlv.Type != 1088 || len(tlv.Value) != 4 {
 			continue
 		}
 		return binary.BigEndian.Uint32(tlv.Value)
@@ -311,7 +311,7 @@ func (ls *NLRI) GetAdminGroup() uint32 {
 // GetTEDefaultMetric returns value of TE Default Metric
 func (ls *NLRI) GetTEDefaultMetric() uint32 {
 	for _, tlv := range ls.LS {
-		if tlv.Type != 1092 {
+		if tlv.Type != 1092 || len(tlv.Value) != 4 {
 			continue
 		}
 		return binary.BigEndian.Uint32(tlv.Value)
@@ -337,7 +337,7 @@ func (ls *NLRI) GetIGPMetric() uint32 {
 // GetMaxLinkBandwidth returns value of Maximum Link Bandwidth encoded in 32 bits in IEEE floating point format
 func (ls *NLRI) GetMaxLinkBandwidth() uint32 {
 	for _, tlv := range ls.LS {
-		if tlv.Type != 1089 {
+		if tlv.Type != 1089 || len(tlv.Value) != 4 {
 			continue
 		}
 		return binary.BigEndian.Uint32(tlv.Value)
@@ -349,7 +349,7 @@ func (ls *NLRI) GetMaxLinkBandwidth() uint32 {
 // GetMaxReservableLinkBandwidth returns value of Maximum Reservable Link Bandwidth encoded in 32 bits in IEEE floating point format
 func (ls *NLRI) GetMaxReservableLinkBandwidth() uint32 {
 	for _, tlv := range ls.LS {
-		if tlv.Type != 1090 {
+		if tlv.Type != 1090 || len(tlv.Value) != 4 {
 			continue
 		}
 		return binary.BigEndian.Uint32(tlv.Value)
@@ -365,7 +365,7 @@ func (ls *NLRI) GetUnreservedLinkBandwidth() []uint32 {
 		if tlv.Type != 1091 {
 			continue
 		}
-		for p := 0; p < len(tlv.Value); {
+		for p := 0; p+4 <= len(tlv.Value); {
 			unResrved = append(unResrved, binary.BigEndian.Uint32(tlv.Value[p:p+4]))
 			p += 4
 		}
@@ -378,7 +378,7 @@ func (ls *NLRI) GetUnreservedLinkBandwidth() []uint32 {
 // GetLinkProtectionType returns value of Link Protection Type
 func (ls *NLRI) GetLinkProtectionType() uint16 {
 	for _, tlv := range ls.LS {
-		if tlv.Type != 1093 {
+		if tlv.Type != 1093 || len(tlv.Value) != 2 {
 			continue
 		}
 		return binary.BigEndian.Uint16(tlv.Value)
@@ -390,7 +390,7 @@ func (ls *NLRI) GetLinkProtectionType() uint16 {
 // GetLinkMPLSProtocolMask returns value of MPLS Protocol Mask
 func (ls *NLRI) GetLinkMPLSProtocolMask() uint8 {
 	for _, tlv := range ls.LS {
-		if tlv.Type != 1094 {
+		if tlv.Type != 1094 || len(tlv.Value) == 0 {
 			continue
 		}
 		return uint8(tlv.Value[0])
@@ -407,7 +407,7 @@ func (ls *NLRI) GetSRLG() []uint32 {
 		if tlv.Type != 1096 {
 			continue
 		}
-		for p := 0; p < len(tlv.Value); {
+		for p := 0; p+4 <= len(tlv.Value); {
 			srlg = append(srlg, binary.BigEndian.Uint32(tlv.Value[p:p+4]))
 			p += 4
 		}
@@ -480,7 +480,7 @@ func (ls *NLRI) GetSRv6SIDStructure() *srv6.SIDStructure {
 // GetUnidirLinkDelay returns value of Unidirectional Link Delay
 func (ls *NLRI) GetUnidirLinkDelay() uint32 {
 	for _, tlv := range ls.LS {
-		if tlv.Type != 1114 {
+		if tlv.Type != 1114 || len(tlv.Value) != 4 {
 			continue
 		}
 		return binary.BigEndian.Uint32(tlv.Value)
@@ -490,10 +490,11 @@ func (ls *NLRI) GetUnidirLinkDelay() uint32 {
 }
 
 // GetUnidirLinkDelayMinMax returns minimum and maximum delay values between two
-//   directly connected IGP link-state neighbors of MUnidirectional Link Delay
+//
+//	directly connected IGP link-state neighbors of MUnidirectional Link Delay
 func (ls *NLRI) GetUnidirLinkDelayMinMax() []uint32 {
 	for _, tlv := range ls.LS {
-		if tlv.Type != 1115 {
+		if tlv.Type != 1115 || len(tlv.Value) != 8 {
 			continue
 		}
 		return []uint32{binary.BigEndian.Uint32(tlv.Value[:4]), binary.BigEndian.Uint32(tlv.Value[4:])}
@@ -506,7 +507,7 @@ func (ls *NLRI) GetUnidirLinkDelayMinMax() []uint32 {
 // directly connected IGP link-state neighbor
 func (ls *NLRI) GetUnidirDelayVariation() uint32 {
 	for _, tlv := range ls.LS {
-		if tlv.Type != 1116 {
+		if tlv.Type != 1116 || len(tlv.Value) != 4 {
 			continue
 		}
 		return binary.BigEndian.Uint32(tlv.Value)
@@ -519,7 +520,7 @@ func (ls *NLRI) GetUnidirDelayVariation() uint32 {
 // directly connected IGP link-state neighbor
 func (ls *NLRI) GetUnidirLinkLoss() uint32 {
 	for _, tlv := range ls.LS {
-		if tlv.Type != 1117 {
+		if tlv.Type != 1117 || len(tlv.Value) != 4 {
 			continue
 		}
 		return binary.BigEndian.Uint32(tlv.Value)
@@ -532,7 +533,7 @@ func (ls *NLRI) GetUnidirLinkLoss() uint32 {
 // directly connected IGP link-state neighbor
 func (ls *NLRI) GetUnidirResidualBandwidth() uint32 {
 	for _, tlv := range ls.LS {
-		if tlv.Type != 1118 {
+		if tlv.Type != 1118 || len(tlv.Value) != 4 {
 			continue
 		}
 		return binary.BigEndian.Uint32(tlv.Value)
@@ -545,7 +546,7 @@ func (ls *NLRI) GetUnidirResidualBandwidth() uint32 {
 // directly connected IGP link-state neighbor
 func (ls *NLRI) GetUnidirAvailableBandwidth() uint32 {
 	for _, tlv := range ls.LS {
-		if tlv.Type != 1119 {
+		if tlv.Type != 1119 || len(tlv.Value) != 4 {
 			continue
 		}
 		return binary.BigEndian.Uint32(tlv.Value)
@@ -558,7 +559,7 @@ func (ls *NLRI) GetUnidirAvailableBandwidth() uint32 {
 // directly connected IGP link-state neighbor
 func (ls *NLRI) GetUnidirUtilizedBandwidth() uint32 {
 	for _, tlv := range ls.LS {
-		if tlv.Type != 1120 {
+		if tlv.Type != 1120 || len(tlv.Value) != 4 {
 			continue
 		}
 		return binary.BigEndian.Uint32(tlv.Value)
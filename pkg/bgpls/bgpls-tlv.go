@@ -12,6 +12,9 @@ import (
 	"github.com/sbezverk/gobmp/pkg/tools"
 )
 
+// decoder builds errors of decoding BGP-LS attribute TLVs
+const decoder tools.Decoder = "bgpls"
+
 // TLV defines BGP-LS TLV object
 // https://tootlv.ietf.org/html/rfc7752#section-3.3
 type TLV struct {
@@ -93,9 +96,17 @@ func (tlv *TLV) String() string {
 		s += cap.String()
 	case 1088:
 		s += fmt.Sprintf("   BGP-LS TLV Type: %d (Administrative group (color))\n", tlv.Type)
+		if len(tlv.Value) != 4 {
+			s += "      invalid length\n"
+			break
+		}
 		s += fmt.Sprintf("      Administrative group (color): %d\n", binary.BigEndian.Uint32(tlv.Value))
 	case 1089:
 		s += fmt.Sprintf("   BGP-LS TLV Type: %d (Maximum link bandwidth)\n", tlv.Type)
+		if len(tlv.Value) != 4 {
+			s += "      invalid length\n"
+			break
+		}
 		s += fmt.Sprintf("      Maximum link bandwidth: %d\n", binary.BigEndian.Uint32(tlv.Value))
 	case 1090:
 		s += fmt.Sprintf("   BGP-LS TLV Type: %d (Max. reservable link bandwidth)\n", tlv.Type)
@@ -105,6 +116,10 @@ func (tlv *TLV) String() string {
 		s += fmt.Sprintf("      Unreserved bandwidth: %s\n", tools.MessageHex(tlv.Value))
 	case 1095:
 		s += fmt.Sprintf("   BGP-LS TLV Type: %d (IGP Metric)\n", tlv.Type)
+		if len(tlv.Value) != 4 {
+			s += "      invalid length\n"
+			break
+		}
 		m := binary.BigEndian.Uint32(tlv.Value)
 		s += fmt.Sprintf("      IGP Metric: %d\n", m)
 	case 1092:
@@ -128,6 +143,10 @@ func (tlv *TLV) String() string {
 		s += endx.String()
 	case 1155:
 		s += fmt.Sprintf("   BGP-LS TLV Type: %d (Prefix Metric)\n", tlv.Type)
+		if len(tlv.Value) != 4 {
+			s += "      invalid length\n"
+			break
+		}
 		m := binary.BigEndian.Uint32(tlv.Value)
 		s += fmt.Sprintf("      Prefix Metric: %d\n", m)
 	case 1158:
@@ -161,17 +180,29 @@ func (tlv *TLV) String() string {
 		s += fmt.Sprintf("      Color: %s\n", tools.MessageHex(tlv.Value))
 	case 1250:
 		s += fmt.Sprintf("   BGP-LS TLV Type: %d (SRv6 Endpoint Function)\n", tlv.Type)
+		if len(tlv.Value) < 4 {
+			s += "      invalid length\n"
+			break
+		}
 		s += fmt.Sprintf("      Endpoint Behavior: %s\n", tools.MessageHex(tlv.Value[:2]))
 		s += fmt.Sprintf("      Flag: %02x\n", tlv.Value[2])
 		s += fmt.Sprintf("      Algorithm: %d\n", tlv.Value[3])
 	case 1251:
 		s += fmt.Sprintf("   BGP-LS TLV Type: %d (SRv6 BGP Peer Node SID)\n", tlv.Type)
+		if len(tlv.Value) < 12 {
+			s += "      invalid length\n"
+			break
+		}
 		s += fmt.Sprintf("      Flag: %02x\n", tlv.Value[0])
 		s += fmt.Sprintf("      Weight: %d\n", tlv.Value[1])
 		s += fmt.Sprintf("      Peer AS Number: %d\n", binary.BigEndian.Uint32(tlv.Value[4:8]))
 		s += fmt.Sprintf("      Peer BGP Identifier: %s\n", tools.MessageHex(tlv.Value[8:12]))
 	case 1252:
 		s += fmt.Sprintf("   BGP-LS TLV Type: %d (SRv6 SID Structure)\n", tlv.Type)
+		if len(tlv.Value) < 4 {
+			s += "      invalid length\n"
+			break
+		}
 		s += fmt.Sprintf("      LB Length: %d\n", tlv.Value[0])
 		s += fmt.Sprintf("      LN Length: %d\n", tlv.Value[1])
 		s += fmt.Sprintf("      Function Length: %d\n", tlv.Value[2])
@@ -192,11 +223,17 @@ func UnmarshalBGPLSTLV(b []byte) ([]TLV, error) {
 	glog.V(6).Infof("BGPLSTLV Raw: %s", tools.MessageHex(b))
 	lstlvs := make([]TLV, 0)
 	for p := 0; p < len(b); {
+		if err := decoder.CheckLength("bgp-ls tlv", b[p:], 4); err != nil {
+			return nil, err
+		}
 		lstlv := TLV{}
 		lstlv.Type = binary.BigEndian.Uint16(b[p : p+2])
 		p += 2
 		lstlv.Length = binary.BigEndian.Uint16(b[p : p+2])
 		p += 2
+		if err := decoder.CheckLength("bgp-ls tlv", b[p:], int(lstlv.Length)); err != nil {
+			return nil, err
+		}
 		lstlv.Value = make([]byte, lstlv.Length)
 		copy(lstlv.Value, b[p:p+int(lstlv.Length)])
 		p += int(lstlv.Length)
@@ -0,0 +1,52 @@
+package bgpls
+
+import "testing"
+
+func FuzzUnmarshalBGPLSNLRI(f *testing.F) {
+	// Node Name, Administrative Group, Max Link Bandwidth and IGP Metric
+	f.Add([]byte{0x04, 0x02, 0x00, 0x02, 'r', '1', 0x04, 0x40, 0x00, 0x04, 0x00, 0x00, 0x00, 0x01, 0x04, 0x41, 0x00, 0x04, 0x4c, 0xee, 0x6b, 0x28, 0x04, 0x47, 0x00, 0x03, 0x00, 0x00, 0x0a})
+	f.Fuzz(func(t *testing.T, b []byte) {
+		n, err := UnmarshalBGPLSNLRI(b)
+		if err != nil {
+			return
+		}
+		_ = n.String()
+		n.GetMTID()
+		n.GetAllAttribute()
+		n.GetNodeFlags()
+		n.GetNodeName()
+		n.GetISISAreaID()
+		n.GetLocalIPv4RouterID()
+		n.GetLocalIPv6RouterID()
+		n.GetRemoteIPv4RouterID()
+		n.GetRemoteIPv6RouterID()
+		n.GetNodeMSD()
+		n.GetLinkMSD()
+		n.GetNodeSRCapabilities()
+		n.GetSRAlgorithm()
+		n.GetNodeSRLocalBlock()
+		n.GetLSPrefixSID()
+		n.GetNodeSRv6CapabilitiesTLV()
+		n.GetAdminGroup()
+		n.GetTEDefaultMetric()
+		n.GetIGPMetric()
+		n.GetMaxLinkBandwidth()
+		n.GetMaxReservableLinkBandwidth()
+		n.GetUnreservedLinkBandwidth()
+		n.GetLinkProtectionType()
+		n.GetLinkMPLSProtocolMask()
+		n.GetSRLG()
+		n.GetLinkName()
+		n.GetSRv6EndpointBehavior()
+		n.GetSRv6BGPPeerNodeSID()
+		n.GetSRv6SIDStructure()
+		n.GetUnidirLinkDelay()
+		n.GetUnidirLinkDelayMinMax()
+		n.GetUnidirDelayVariation()
+		n.GetUnidirLinkLoss()
+		n.GetUnidirResidualBandwidth()
+		n.GetUnidirAvailableBandwidth()
+		n.GetUnidirUtilizedBandwidth()
+		n.GetSRAdjacencySID()
+	})
+}
@@ -2,7 +2,6 @@ package bmp
 
 import (
 	"encoding/binary"
-	"fmt"
 
 	"github.com/golang/glog"
 	"github.com/sbezverk/gobmp/pkg/tools"
@@ -18,12 +17,18 @@ type CommonHeader struct {
 // UnmarshalCommonHeader processes Common Header and returns BMPCommonHeader object
 func UnmarshalCommonHeader(b []byte) (*CommonHeader, error) {
 	glog.V(6).Infof("BMP CommonHeader Raw: %s", tools.MessageHex(b))
+	if err := decoder.CheckLength("bmp common header", b, CommonHeaderLength); err != nil {
+		return nil, err
+	}
 	ch := &CommonHeader{}
 	if b[0] != 3 {
-		return nil, fmt.Errorf("invalid version in common header, expected 3 found %d", b[0])
+		return nil, decoder.Malformed("bmp common header", "invalid version, expected 3 found %d", b[0])
 	}
 	ch.Version = b[0]
 	ch.MessageLength = int32(binary.BigEndian.Uint32(b[1:5]))
+	if ch.MessageLength < CommonHeaderLength {
+		return nil, decoder.Malformed("bmp common header", "invalid message length %d", ch.MessageLength)
+	}
 	ch.MessageType = b[5]
 	// *  Type = 0: Route Monitoring
 	// *  Type = 1: Statistics Report
@@ -41,7 +46,7 @@ func UnmarshalCommonHeader(b []byte) (*CommonHeader, error) {
 	case 5:
 	case 6:
 	default:
-		return nil, fmt.Errorf("invalid message type in common header, expected between 0 and 6 found %d", b[5])
+		return nil, decoder.Malformed("bmp common header", "invalid message type, expected between 0 and 6 found %d", b[5])
 	}
 
 	return ch, nil
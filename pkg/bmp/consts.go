@@ -1,11 +1,25 @@
 package bmp
 
+import "github.com/sbezverk/gobmp/pkg/tools"
+
+// decoder builds errors of decoding BMP messages
+const decoder tools.Decoder = "bmp"
+
 const (
 	// CommonHeaderLength defines the length of BMP's Common header
 	CommonHeaderLength = 6
 	// PerPeerHeaderLength defines the length of BMP's Per Peer Header
 	PerPeerHeaderLength = 42
 
+	// PeerTypeGlobal defines Global Instance Peer type
+	PeerTypeGlobal = 0
+	// PeerTypeRD defines RD Instance Peer type
+	PeerTypeRD = 1
+	// PeerTypeLocal defines Local Instance Peer type
+	PeerTypeLocal = 2
+	// PeerTypeLocRIB defines Loc-RIB Instance Peer type per rfc9069
+	PeerTypeLocRIB = 3
+
 	// RouteMonitorMsg defines BMP Route Monitor message type
 	RouteMonitorMsg = 0
 	// StatsReportMsg defines BMP Statistics Report message
@@ -36,4 +50,12 @@ const (
 	LSSRv6SIDMsg = 13
 	// EVPNMsg defines BMP Route Monitoring message carrying EVPN NLRI
 	EVPNMsg = 14
+	// StatsMsg defines BMP Statistics Report message
+	StatsMsg = 15
+	// MirrorMsg defines BMP Route Mirroring message carrying mirrored BGP PDU or lost messages event
+	MirrorMsg = 16
+	// RouterEventMsg defines BMP Initiation/Termination message
+	RouterEventMsg = 17
+	// BMPRawMsg defines raw BMP message as received from the router, wrapped in OpenBMP binary header
+	BMPRawMsg = 18
 )
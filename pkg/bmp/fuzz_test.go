@@ -0,0 +1,126 @@
+package bmp
+
+import "testing"
+
+var marker = []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+
+// bgpMessage builds BGP message with the marker, the length and the type followed by the body
+func bgpMessage(t byte, body ...byte) []byte {
+	l := 19 + len(body)
+	b := append([]byte{}, marker...)
+	b = append(b, byte(l>>8), byte(l), t)
+	return append(b, body...)
+}
+
+func FuzzUnmarshalCommonHeader(f *testing.F) {
+	f.Add([]byte{0x03, 0x00, 0x00, 0x00, 0x06, 0x04})
+	f.Fuzz(func(t *testing.T, b []byte) {
+		UnmarshalCommonHeader(b)
+	})
+}
+
+func FuzzUnmarshalPerPeerHeader(f *testing.F) {
+	f.Add([]byte{0, 0x80, 0, 0, 0, 0, 0, 0, 0, 0, 0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0xfd, 0xe8, 10, 0, 0, 1, 0x5e, 0x62, 0x81, 0xab, 0, 0, 0xd7, 0x7e})
+	f.Add([]byte{3, 0x80, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xfd, 0xe8, 10, 0, 0, 1, 0x5e, 0x62, 0x81, 0xab, 0, 0, 0xd7, 0x7e})
+	f.Fuzz(func(t *testing.T, b []byte) {
+		ph, err := UnmarshalPerPeerHeader(b)
+		if err != nil {
+			return
+		}
+		ph.GetPeerHash()
+		ph.GetPeerAddrString()
+		_ = ph.PeerDistinguisher.String()
+	})
+}
+
+func FuzzUnmarshalPeerUpMessage(f *testing.F) {
+	// Local Address, Local and Remote ports
+	seed := []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 10, 0, 0, 1, 0, 179, 0xc3, 0x50}
+	open := []byte{0x04, 0xfd, 0xe8, 0x00, 0xb4, 10, 0, 0, 1, 0x00}
+	seed = append(seed, bgpMessage(1, open...)...)
+	seed = append(seed, bgpMessage(1, open...)...)
+	// Table Name Informational TLV
+	seed = append(seed, 0x00, 0x03, 0x00, 0x04, 't', 'e', 's', 't')
+	f.Add(seed)
+	f.Fuzz(func(t *testing.T, b []byte) {
+		pu, err := UnmarshalPeerUpMessage(b)
+		if err != nil {
+			return
+		}
+		pu.GetTableName()
+	})
+}
+
+func FuzzUnmarshalPeerDownMessage(f *testing.F) {
+	f.Add(append([]byte{1}, bgpMessage(3, 0x06, 0x02)...))
+	f.Add([]byte{2, 0x00, 0x01})
+	f.Add([]byte{6, 0x00, 0x03, 0x00, 0x04, 't', 'e', 's', 't'})
+	f.Fuzz(func(t *testing.T, b []byte) {
+		pd, err := UnmarshalPeerDownMessage(b)
+		if err != nil {
+			return
+		}
+		pd.GetTableName()
+		pd.GetReasonString()
+	})
+}
+
+func FuzzUnmarshalBMPRouteMonitorMessage(f *testing.F) {
+	f.Add(bgpMessage(2, 0x00, 0x00, 0x00, 0x0b, 0x40, 0x01, 0x01, 0x00, 0x40, 0x03, 0x04, 0x0a, 0x00, 0x00, 0x01, 0x18, 0x0a, 0x01, 0x02), false)
+	f.Add(bgpMessage(2, 0x00, 0x00, 0x00, 0x0b, 0x40, 0x01, 0x01, 0x00, 0x40, 0x03, 0x04, 0x0a, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01, 0x18, 0x0a, 0x01, 0x02), true)
+	f.Fuzz(func(t *testing.T, b []byte, pathID bool) {
+		rm, err := UnmarshalBMPRouteMonitorMessage(b, map[int]bool{1: pathID})
+		if err != nil {
+			return
+		}
+		_ = rm.String()
+	})
+}
+
+func FuzzUnmarshalBMPStatsReportMessage(f *testing.F) {
+	f.Add([]byte{0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x04, 0x00, 0x00, 0x00, 0x01, 0x00, 0x07, 0x00, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0a})
+	f.Fuzz(func(t *testing.T, b []byte) {
+		UnmarshalBMPStatsReportMessage(b)
+	})
+}
+
+func FuzzUnmarshalInitiationMessage(f *testing.F) {
+	f.Add([]byte{0x00, 0x02, 0x00, 0x02, 'r', '1', 0x00, 0x01, 0x00, 0x02, 'o', 's'})
+	f.Fuzz(func(t *testing.T, b []byte) {
+		im, err := UnmarshalInitiationMessage(b)
+		if err != nil {
+			return
+		}
+		im.GetSysName()
+		im.GetSysDescr()
+		im.GetString()
+	})
+}
+
+func FuzzUnmarshalTerminationMessage(f *testing.F) {
+	f.Add([]byte{0x00, 0x01, 0x00, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 'b', 'y'})
+	f.Fuzz(func(t *testing.T, b []byte) {
+		tm, err := UnmarshalTerminationMessage(b)
+		if err != nil {
+			return
+		}
+		tm.GetString()
+		tm.GetReason()
+		tm.GetReasonString()
+	})
+}
+
+func FuzzUnmarshalRouteMirrorMessage(f *testing.F) {
+	msg := bgpMessage(2, 0x00, 0x00, 0x00, 0x00)
+	seed := []byte{0x00, 0x00, byte(len(msg) >> 8), byte(len(msg))}
+	seed = append(seed, msg...)
+	f.Add(append(seed, 0x00, 0x01, 0x00, 0x02, 0x00, 0x00))
+	f.Fuzz(func(t *testing.T, b []byte) {
+		rm, err := UnmarshalRouteMirrorMessage(b, nil)
+		if err != nil {
+			return
+		}
+		rm.GetBGPMessageType()
+		rm.GetUpdate()
+	})
+}
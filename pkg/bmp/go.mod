@@ -12,6 +12,7 @@ replace (
 	github.com/sbezverk/gobmp/pkg/kafka => ../kafka
 	github.com/sbezverk/gobmp/pkg/ls => ../ls
 	github.com/sbezverk/gobmp/pkg/message => ../message
+	github.com/sbezverk/gobmp/pkg/metrics => ../metrics
 	github.com/sbezverk/gobmp/pkg/parser => ../parser
 	github.com/sbezverk/gobmp/pkg/pub => ../pub
 	github.com/sbezverk/gobmp/pkg/sr => ../sr
@@ -2,12 +2,22 @@ package bmp
 
 import (
 	"encoding/binary"
-	"fmt"
 
 	"github.com/golang/glog"
 	"github.com/sbezverk/gobmp/pkg/tools"
 )
 
+const (
+	// InfoTLVString defines Informational TLV carrying free-form UTF-8 string
+	InfoTLVString = 0
+	// InfoTLVSysDescr defines Informational TLV carrying sysDescr
+	InfoTLVSysDescr = 1
+	// InfoTLVSysName defines Informational TLV carrying sysName
+	InfoTLVSysName = 2
+	// InfoTLVTableName defines Informational TLV carrying VRF/Table Name per rfc9069
+	InfoTLVTableName = 3
+)
+
 // InformationalTLV defines Informational TLV per rfc7854
 type InformationalTLV struct {
 	InformationType   int16
@@ -20,21 +30,35 @@ func UnmarshalTLV(b []byte) ([]InformationalTLV, error) {
 	glog.V(6).Infof("BMP Informational TLV Raw: %s", tools.MessageHex(b))
 	tlvs := make([]InformationalTLV, 0)
 	for i := 0; i < len(b); {
+		if err := decoder.CheckLength("bmp informational tlv", b[i:], 4); err != nil {
+			return nil, err
+		}
 		// Extracting TLV type 2 bytes
 		t := int16(binary.BigEndian.Uint16(b[i : i+2]))
 		// Extracting TLV length
 		l := int16(binary.BigEndian.Uint16(b[i+2 : i+4]))
-		if l > int16(len(b)-(i+4)) {
-			return nil, fmt.Errorf("invalid tlv length %d", l)
+		if err := decoder.CheckLength("bmp informational tlv", b[i+4:], int(uint16(l))); err != nil {
+			return nil, err
 		}
-		v := b[i+4 : i+4+int(l)]
+		v := b[i+4 : i+4+int(uint16(l))]
 		tlvs = append(tlvs, InformationalTLV{
 			InformationType:   t,
 			InformationLength: l,
 			Information:       v,
 		})
-		i += 4 + int(l)
+		i += 4 + int(uint16(l))
 	}
 
 	return tlvs, nil
 }
+
+// getInformationString returns the value of the first Informational TLV of a type t as a string
+func getInformationString(tlvs []InformationalTLV, t int16) string {
+	for _, tlv := range tlvs {
+		if tlv.InformationType == t {
+			return string(tlv.Information)
+		}
+	}
+
+	return ""
+}
@@ -1,9 +1,6 @@
 package bmp
 
 import (
-	"encoding/binary"
-	"fmt"
-
 	"github.com/golang/glog"
 	"github.com/sbezverk/gobmp/pkg/tools"
 )
@@ -13,35 +10,39 @@ type InitiationMessage struct {
 	TLV []InformationalTLV
 }
 
+// GetSysName returns sysName carried in Initiation message
+func (im *InitiationMessage) GetSysName() string {
+	return getInformationString(im.TLV, InfoTLVSysName)
+}
+
+// GetSysDescr returns sysDescr carried in Initiation message
+func (im *InitiationMessage) GetSysDescr() string {
+	return getInformationString(im.TLV, InfoTLVSysDescr)
+}
+
+// GetString returns free-form string carried in Initiation message
+func (im *InitiationMessage) GetString() string {
+	return getInformationString(im.TLV, InfoTLVString)
+}
+
 // UnmarshalInitiationMessage processes Initiation Message and returns BMPInitiationMessage object
 func UnmarshalInitiationMessage(b []byte) (*InitiationMessage, error) {
 	glog.V(6).Infof("BMP Initiation Message Raw: %s", tools.MessageHex(b))
-	im := &InitiationMessage{
-		TLV: make([]InformationalTLV, 0),
+	tlvs, err := UnmarshalTLV(b)
+	if err != nil {
+		return nil, err
 	}
-	for i := 0; i < len(b); {
-		// Extracting TLV type 2 bytes
-		t := int16(binary.BigEndian.Uint16(b[i : i+2]))
-		switch t {
-		case 0:
-		case 1:
-		case 2:
+	for _, tlv := range tlvs {
+		switch tlv.InformationType {
+		case InfoTLVString:
+		case InfoTLVSysDescr:
+		case InfoTLVSysName:
 		default:
-			return nil, fmt.Errorf("invalid tlv type, expected between 0 and 2 found %d", t)
-		}
-		// Extracting TLV length
-		l := int16(binary.BigEndian.Uint16(b[i+2 : i+4]))
-		if l > int16(len(b)-(i+4)) {
-			return nil, fmt.Errorf("invalid tlv length %d", l)
+			return nil, decoder.Malformed("bmp initiation message", "invalid tlv type, expected between 0 and 2 found %d", tlv.InformationType)
 		}
-		v := b[i+4 : i+4+int(l)]
-		im.TLV = append(im.TLV, InformationalTLV{
-			InformationType:   t,
-			InformationLength: l,
-			Information:       v,
-		})
-		i += 4 + int(l)
 	}
 
-	return im, nil
+	return &InitiationMessage{
+		TLV: tlvs,
+	}, nil
 }
@@ -1,31 +1,109 @@
 package bmp
 
 import (
+	"encoding/binary"
 	"fmt"
 
 	"github.com/golang/glog"
+	"github.com/sbezverk/gobmp/pkg/bgp"
 	"github.com/sbezverk/gobmp/pkg/tools"
 )
 
+const (
+	// PeerDownLocalNotification defines Peer Down reason, local system closed the session, Notification PDU follows
+	PeerDownLocalNotification = 1
+	// PeerDownLocalFSMEvent defines Peer Down reason, local system closed the session, FSM event follows
+	PeerDownLocalFSMEvent = 2
+	// PeerDownRemoteNotification defines Peer Down reason, remote system closed the session, Notification PDU follows
+	PeerDownRemoteNotification = 3
+	// PeerDownRemoteNoData defines Peer Down reason, remote system closed the session without a notification
+	PeerDownRemoteNoData = 4
+	// PeerDownDeconfigured defines Peer Down reason, the peer was de-configured
+	PeerDownDeconfigured = 5
+	// PeerDownLocalTLV defines Peer Down reason, local system closed the session, TLV data follows
+	PeerDownLocalTLV = 6
+)
+
 // PeerDownMessage defines BMPPeerDownMessage per rfc7854
 type PeerDownMessage struct {
-	Reason uint8
-	Data   []byte
+	Reason       uint8
+	Data         []byte
+	Notification *bgp.NotificationMessage
+	FSMEvent     uint16
+	Information  []InformationalTLV
+}
+
+// GetTableName returns VRF/Table Name carried in Peer Down message of Loc-RIB Instance Peer
+func (pd *PeerDownMessage) GetTableName() string {
+	return getInformationString(pd.Information, InfoTLVTableName)
+}
+
+// GetReasonString returns the description of Peer Down reason
+func (pd *PeerDownMessage) GetReasonString() string {
+	switch pd.Reason {
+	case PeerDownLocalNotification:
+		return "Local system closed the session, notification sent"
+	case PeerDownLocalFSMEvent:
+		return "Local system closed the session, no notification"
+	case PeerDownRemoteNotification:
+		return "Remote system closed the session with a notification"
+	case PeerDownRemoteNoData:
+		return "Remote system closed the session without a notification"
+	case PeerDownDeconfigured:
+		return "Peer de-configured"
+	case PeerDownLocalTLV:
+		return "Local system closed the session"
+	}
+
+	return fmt.Sprintf("Unknown reason (%d)", pd.Reason)
 }
 
 // UnmarshalPeerDownMessage processes Peer Down message and returns BMPPeerDownMessage object
 func UnmarshalPeerDownMessage(b []byte) (*PeerDownMessage, error) {
 	glog.V(6).Infof("BMP Peer Down Message Raw: %s", tools.MessageHex(b))
+	if err := decoder.CheckLength("bmp peer down message", b, 1); err != nil {
+		return nil, err
+	}
 	pdw := &PeerDownMessage{
 		Data: make([]byte, len(b)-1),
 	}
 	p := 0
 	pdw.Reason = b[p]
 	p++
-	if pdw.Reason < 1 || pdw.Reason > 5 {
-		return nil, fmt.Errorf("invalid reason code %d in Peer Down message", pdw.Reason)
+	if pdw.Reason < 1 || pdw.Reason > 6 {
+		return nil, decoder.Malformed("bmp peer down message", "invalid reason code %d", pdw.Reason)
 	}
 	copy(pdw.Data, b[p:])
+	switch pdw.Reason {
+	case PeerDownLocalNotification:
+		fallthrough
+	case PeerDownRemoteNotification:
+		// Data carries complete BGP Notification PDU, skipping 16 bytes of marker,
+		// failure to decode the notification is not fatal, the session is down regardless.
+		if len(pdw.Data) < 16 {
+			glog.Warningf("Peer Down message with reason %d carries invalid notification: %s", pdw.Reason, tools.MessageHex(pdw.Data))
+			break
+		}
+		n, err := bgp.UnmarshalBGPNotificationMessage(pdw.Data[16:])
+		if err != nil {
+			glog.Warningf("fail to recover BGP Notification from Peer Down message with error: %+v", err)
+			break
+		}
+		pdw.Notification = n
+	case PeerDownLocalFSMEvent:
+		if len(pdw.Data) != 2 {
+			glog.Warningf("Peer Down message with reason %d carries invalid FSM event: %s", pdw.Reason, tools.MessageHex(pdw.Data))
+			break
+		}
+		pdw.FSMEvent = binary.BigEndian.Uint16(pdw.Data)
+	case PeerDownLocalTLV:
+		// Reason 6, Local system closed, TLV data follows, rfc9069 section 5.4
+		tlvs, err := UnmarshalTLV(pdw.Data)
+		if err != nil {
+			return nil, err
+		}
+		pdw.Information = tlvs
+	}
 
 	return pdw, nil
 }
@@ -18,10 +18,19 @@ type PeerUpMessage struct {
 	Information  []InformationalTLV
 }
 
+// GetTableName returns VRF/Table Name carried in Peer Up message of Loc-RIB Instance Peer
+func (pu *PeerUpMessage) GetTableName() string {
+	return getInformationString(pu.Information, InfoTLVTableName)
+}
+
 // UnmarshalPeerUpMessage processes Peer Up message and returns BMPPeerUpMessage object
 func UnmarshalPeerUpMessage(b []byte) (*PeerUpMessage, error) {
 	glog.V(6).Infof("BMP Peer Up Message Raw: %s", tools.MessageHex(b))
 	var err error
+	// Local Address, Local and Remote Ports, first marker and Sent OPEN message's length
+	if err := decoder.CheckLength("bmp peer up message", b, 38); err != nil {
+		return nil, err
+	}
 	pu := &PeerUpMessage{
 		LocalAddress: make([]byte, 16),
 		SentOpen:     &bgp.OpenMessage{},
@@ -37,26 +46,36 @@ func UnmarshalPeerUpMessage(b []byte) (*PeerUpMessage, error) {
 	p += 2
 	// Skip first marker 16 bytes
 	p += 16
-	l1 := int16(binary.BigEndian.Uint16(b[p : p+2]))
-	pu.SentOpen, err = bgp.UnmarshalBGPOpenMessage(b[p : p+int(l1-16)])
+	l1 := int(binary.BigEndian.Uint16(b[p : p+2]))
+	if l1 < bgp.HeaderLength || p+l1-16 > len(b) {
+		return nil, decoder.Malformed("bmp peer up message", "invalid sent open message length %d", l1)
+	}
+	pu.SentOpen, err = bgp.UnmarshalBGPOpenMessage(b[p : p+l1-16])
 	if err != nil {
 		return nil, err
 	}
 	// Moving pointer to the next marker
-	p += int(l1) - 16
+	p += l1 - 16
+	// Second marker and Received OPEN message's length
+	if err := decoder.CheckLength("bmp peer up message", b[p:], 18); err != nil {
+		return nil, err
+	}
 	// Skip second marker
 	p += 16
-	l2 := int16(binary.BigEndian.Uint16(b[p : p+2]))
-	pu.ReceivedOpen, err = bgp.UnmarshalBGPOpenMessage(b[p : p+int(l2-16)])
+	l2 := int(binary.BigEndian.Uint16(b[p : p+2]))
+	if l2 < bgp.HeaderLength || p+l2-16 > len(b) {
+		return nil, decoder.Malformed("bmp peer up message", "invalid received open message length %d", l2)
+	}
+	pu.ReceivedOpen, err = bgp.UnmarshalBGPOpenMessage(b[p : p+l2-16])
 	if err != nil {
 		return nil, err
 	}
-	p += int(l2) - 16
+	p += l2 - 16
 	// Last part is optional Informational TLVs
 	if len(b) > int(p) {
 		// Since pointer p does not point to the end of buffer,
 		// then processing Informational TLVs
-		tlvs, err := UnmarshalTLV(b[p : len(b)-int(p)])
+		tlvs, err := UnmarshalTLV(b[p:])
 		if err != nil {
 			return nil, err
 		}
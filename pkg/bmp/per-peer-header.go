@@ -16,22 +16,30 @@ type PeerDistinguisher struct {
 	pd []byte
 }
 
+// String returns Peer Distinguisher formatted as Route Distinguisher of rfc4364 section 4.2, the values
+// of unknown types are returned as hex string
 func (pd *PeerDistinguisher) String() string {
-	var s string
 	v := binary.BigEndian.Uint64(pd.pd)
 	if v == 0 {
 		return "0:0"
 	}
-	s = "not implemented"
+	switch binary.BigEndian.Uint16(pd.pd[0:2]) {
+	case 0:
+		return fmt.Sprintf("%d:%d", binary.BigEndian.Uint16(pd.pd[2:4]), binary.BigEndian.Uint32(pd.pd[4:8]))
+	case 1:
+		return fmt.Sprintf("%s:%d", net.IP(pd.pd[2:6]).To4().String(), binary.BigEndian.Uint16(pd.pd[6:8]))
+	case 2:
+		return fmt.Sprintf("%d:%d", binary.BigEndian.Uint32(pd.pd[2:6]), binary.BigEndian.Uint16(pd.pd[6:8]))
+	}
 
-	return s
+	return fmt.Sprintf("%x", pd.pd)
 }
 
 func (pd *PeerDistinguisher) copy(b []byte) {
 	if pd.pd == nil || len(pd.pd) == 0 {
 		return
 	}
-	copy(pd.pd, b[:len(pd.pd)-1])
+	copy(pd.pd, b)
 }
 
 func newPeerDistinguisher() *PeerDistinguisher {
@@ -46,6 +54,8 @@ type PerPeerHeader struct {
 	FlagV             bool
 	FlagL             bool
 	FlagA             bool
+	FlagO             bool
+	FlagF             bool
 	PeerDistinguisher *PeerDistinguisher
 	PeerAddress       []byte
 	PeerAS            int32
@@ -56,6 +66,9 @@ type PerPeerHeader struct {
 // UnmarshalPerPeerHeader processes Per-Peer header
 func UnmarshalPerPeerHeader(b []byte) (*PerPeerHeader, error) {
 	glog.V(6).Infof("BMP Per Peer Header Raw: %s", tools.MessageHex(b))
+	if err := decoder.CheckLength("bmp per peer header", b, PerPeerHeaderLength); err != nil {
+		return nil, err
+	}
 	pph := &PerPeerHeader{
 		PeerDistinguisher: newPeerDistinguisher(),
 		PeerAddress:       make([]byte, 16),
@@ -65,17 +78,26 @@ func UnmarshalPerPeerHeader(b []byte) (*PerPeerHeader, error) {
 	// *  Peer Type = 0: Global Instance Peer
 	// *  Peer Type = 1: RD Instance Peer
 	// *  Peer Type = 2: Local Instance Peer
+	// *  Peer Type = 3: Loc-RIB Instance Peer
 	switch b[0] {
-	case 0:
-	case 1:
-	case 2:
+	case PeerTypeGlobal:
+	case PeerTypeRD:
+	case PeerTypeLocal:
+	case PeerTypeLocRIB:
 	default:
-		return nil, fmt.Errorf("invalid peer type, expected between 0 and 2 found %d", b[0])
+		return nil, decoder.Malformed("bmp per peer header", "invalid peer type, expected between 0 and 3 found %d", b[0])
 	}
 	pph.PeerType = b[0]
-	pph.FlagV = b[1]&0x80 == 0x80
-	pph.FlagL = b[1]&0x40 == 0x40
-	pph.FlagA = b[1]&0x20 == 0x20
+	if pph.PeerType == PeerTypeLocRIB {
+		// Loc-RIB Instance Peer defines only F flag, rfc9069 section 4.2
+		pph.FlagF = b[1]&0x80 == 0x80
+	} else {
+		pph.FlagV = b[1]&0x80 == 0x80
+		pph.FlagL = b[1]&0x40 == 0x40
+		pph.FlagA = b[1]&0x20 == 0x20
+		// O flag indicates Adj-RIB-Out, rfc8671 section 4
+		pph.FlagO = b[1]&0x10 == 0x10
+	}
 	// RD 8 bytes
 	pph.PeerDistinguisher.copy(b[2:10])
 	// Peer Address 16 bytes but for IPv4 case only last 4 bytes needed
@@ -102,6 +124,37 @@ func (p *PerPeerHeader) GetPeerHash() string {
 	return fmt.Sprintf("%x", md5.Sum(data))
 }
 
+// IsLocRIB returns true if the peer is Loc-RIB Instance Peer
+func (p *PerPeerHeader) IsLocRIB() bool {
+	return p.PeerType == PeerTypeLocRIB
+}
+
+// IsLocRIBFiltered returns true if Loc-RIB Instance Peer's routes are filtered
+func (p *PerPeerHeader) IsLocRIBFiltered() bool {
+	return p.IsLocRIB() && p.FlagF
+}
+
+// IsPrepolicy returns true if the peer's routes are reported before the policy was applied
+func (p *PerPeerHeader) IsPrepolicy() bool {
+	return !p.IsLocRIB() && !p.FlagL
+}
+
+// IsAdjRIBIn returns true if the peer's routes are reported from Adj-RIB-In
+func (p *PerPeerHeader) IsAdjRIBIn() bool {
+	return !p.IsLocRIB() && !p.FlagO
+}
+
+// IsAdjRIBOut returns true if the peer's routes are reported from Adj-RIB-Out
+func (p *PerPeerHeader) IsAdjRIBOut() bool {
+	return !p.IsLocRIB() && p.FlagO
+}
+
+// Is4BytesASPath returns true if AS_PATH of the routes carried in Route Monitoring messages is formatted with
+// 4 bytes AS numbers, A flag is set when the legacy 2 bytes AS_PATH format is used, rfc7854 section 4.2
+func (p *PerPeerHeader) Is4BytesASPath() bool {
+	return !p.FlagA
+}
+
 // GetPeerAddrString returns a string representation of Peer address
 func (p *PerPeerHeader) GetPeerAddrString() string {
 	if p.FlagV {
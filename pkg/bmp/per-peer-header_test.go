@@ -0,0 +1,129 @@
+package bmp
+
+import (
+	"testing"
+)
+
+func TestUnmarshalPerPeerHeader(t *testing.T) {
+	tests := []struct {
+		name             string
+		input            []byte
+		isLocRIB         bool
+		isLocRIBFiltered bool
+		flagV            bool
+		prepolicy        bool
+		adjRIBIn         bool
+		adjRIBOut        bool
+		fail             bool
+	}{
+		{
+			name:      "global instance ipv6 peer",
+			input:     []byte{0, 0x80, 0, 0, 0, 0, 0, 0, 0, 0, 0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0xfd, 0xe8, 10, 0, 0, 1, 0x5e, 0x62, 0x81, 0xab, 0, 0, 0xd7, 0x7e},
+			flagV:     true,
+			prepolicy: true,
+			adjRIBIn:  true,
+		},
+		{
+			name:      "global instance ipv4 peer post-policy adj-rib-out",
+			input:     []byte{0, 0x50, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 10, 0, 0, 2, 0, 0, 0xfd, 0xe8, 10, 0, 0, 2, 0x5e, 0x62, 0x81, 0xab, 0, 0, 0xd7, 0x7e},
+			adjRIBOut: true,
+		},
+		{
+			name:      "global instance ipv4 peer pre-policy adj-rib-out",
+			input:     []byte{0, 0x10, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 10, 0, 0, 2, 0, 0, 0xfd, 0xe8, 10, 0, 0, 2, 0x5e, 0x62, 0x81, 0xab, 0, 0, 0xd7, 0x7e},
+			prepolicy: true,
+			adjRIBOut: true,
+		},
+		{
+			name:             "loc-rib instance peer filtered",
+			input:            []byte{3, 0x80, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xfd, 0xe8, 10, 0, 0, 1, 0x5e, 0x62, 0x81, 0xab, 0, 0, 0xd7, 0x7e},
+			isLocRIB:         true,
+			isLocRIBFiltered: true,
+		},
+		{
+			name:     "loc-rib instance peer",
+			input:    []byte{3, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xfd, 0xe8, 10, 0, 0, 1, 0x5e, 0x62, 0x81, 0xab, 0, 0, 0xd7, 0x7e},
+			isLocRIB: true,
+		},
+		{
+			name:  "invalid peer type",
+			input: []byte{4, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xfd, 0xe8, 10, 0, 0, 1, 0x5e, 0x62, 0x81, 0xab, 0, 0, 0xd7, 0x7e},
+			fail:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ph, err := UnmarshalPerPeerHeader(tt.input)
+			if err != nil && !tt.fail {
+				t.Fatalf("supposed to succeed but failed with error: %+v", err)
+			}
+			if err == nil && tt.fail {
+				t.Fatalf("supposed to fail but succeeded")
+			}
+			if err != nil {
+				return
+			}
+			if ph.IsLocRIB() != tt.isLocRIB {
+				t.Errorf("expected Loc-RIB %t got %t", tt.isLocRIB, ph.IsLocRIB())
+			}
+			if ph.IsLocRIBFiltered() != tt.isLocRIBFiltered {
+				t.Errorf("expected Loc-RIB filtered %t got %t", tt.isLocRIBFiltered, ph.IsLocRIBFiltered())
+			}
+			if ph.FlagV != tt.flagV {
+				t.Errorf("expected V flag %t got %t", tt.flagV, ph.FlagV)
+			}
+			if ph.IsPrepolicy() != tt.prepolicy {
+				t.Errorf("expected pre-policy %t got %t", tt.prepolicy, ph.IsPrepolicy())
+			}
+			if ph.IsAdjRIBIn() != tt.adjRIBIn {
+				t.Errorf("expected Adj-RIB-In %t got %t", tt.adjRIBIn, ph.IsAdjRIBIn())
+			}
+			if ph.IsAdjRIBOut() != tt.adjRIBOut {
+				t.Errorf("expected Adj-RIB-Out %t got %t", tt.adjRIBOut, ph.IsAdjRIBOut())
+			}
+		})
+	}
+}
+
+func TestPeerDistinguisherString(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  []byte
+		expect string
+	}{
+		{
+			name:   "zero",
+			input:  []byte{0, 0, 0, 0, 0, 0, 0, 0},
+			expect: "0:0",
+		},
+		{
+			name:   "type 0",
+			input:  []byte{0, 0, 0xfd, 0xe8, 0, 0, 0, 100},
+			expect: "65000:100",
+		},
+		{
+			name:   "type 1",
+			input:  []byte{0, 1, 192, 0, 2, 1, 0, 100},
+			expect: "192.0.2.1:100",
+		},
+		{
+			name:   "type 2",
+			input:  []byte{0, 2, 0, 1, 0, 0, 0, 100},
+			expect: "65536:100",
+		},
+		{
+			name:   "unknown type",
+			input:  []byte{0xff, 0xff, 0, 0, 0, 0, 0, 1},
+			expect: "ffff000000000001",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pd := newPeerDistinguisher()
+			pd.copy(tt.input)
+			if s := pd.String(); s != tt.expect {
+				t.Fatalf("expected %q but got %q", tt.expect, s)
+			}
+		})
+	}
+}
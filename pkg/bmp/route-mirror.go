@@ -0,0 +1,87 @@
+package bmp
+
+import (
+	"encoding/binary"
+
+	"github.com/golang/glog"
+	"github.com/sbezverk/gobmp/pkg/bgp"
+	"github.com/sbezverk/gobmp/pkg/tools"
+)
+
+const (
+	// MirrorTLVBGPMessage defines Route Mirroring TLV carrying mirrored BGP PDU
+	MirrorTLVBGPMessage = 0
+	// MirrorTLVInformation defines Route Mirroring TLV carrying 2 bytes information code
+	MirrorTLVInformation = 1
+	// MirrorInfoErroredPDU defines information code signalling that the mirrored PDU was found to be errored
+	MirrorInfoErroredPDU = 0
+	// MirrorInfoMessagesLost defines information code signalling that one or more messages were lost
+	MirrorInfoMessagesLost = 1
+)
+
+// RouteMirror defines a structure of BMP Route Mirroring message per rfc7854 section 4.7
+type RouteMirror struct {
+	TLV          []InformationalTLV
+	BGPMessage   []byte
+	ErroredPDU   bool
+	MessagesLost bool
+	// AddPath keeps the types of AFI/SAFI for which the mirrored BGP Update carries Path Identifiers
+	AddPath map[int]bool
+}
+
+// GetBGPMessageType returns the type of mirrored BGP PDU, 0 is returned when there is no mirrored BGP PDU
+func (rm *RouteMirror) GetBGPMessageType() uint8 {
+	if len(rm.BGPMessage) < 19 {
+		return 0
+	}
+	return rm.BGPMessage[18]
+}
+
+// GetUpdate decodes mirrored BGP PDU if it is BGP Update and returns bgp.Update object.
+func (rm *RouteMirror) GetUpdate() (*bgp.Update, error) {
+	if t := rm.GetBGPMessageType(); t != 2 {
+		return nil, decoder.Malformed("bmp route mirroring message", "mirrored bgp message of type %d is not an update", t)
+	}
+	return bgp.UnmarshalBGPUpdate(rm.BGPMessage[19:], rm.AddPath)
+}
+
+// UnmarshalRouteMirrorMessage builds BMP Route Mirroring object, addPath carries the types of AFI/SAFI
+// for which the peer negotiated ADD-PATH, it is used to decode the mirrored BGP Update.
+func UnmarshalRouteMirrorMessage(b []byte, addPath map[int]bool) (*RouteMirror, error) {
+	glog.V(6).Infof("BMP Route Mirroring Message Raw: %s", tools.MessageHex(b))
+	tlvs, err := UnmarshalTLV(b)
+	if err != nil {
+		return nil, err
+	}
+	rm := RouteMirror{
+		TLV:     tlvs,
+		AddPath: addPath,
+	}
+	for _, tlv := range tlvs {
+		switch tlv.InformationType {
+		case MirrorTLVBGPMessage:
+			// BGP PDU starts with 16 bytes of marker followed by 2 bytes of length and 1 byte of type
+			if err := decoder.CheckLength("bmp route mirroring message", tlv.Information, 19); err != nil {
+				return nil, err
+			}
+			if l := int(binary.BigEndian.Uint16(tlv.Information[16:18])); l != len(tlv.Information) {
+				return nil, decoder.Malformed("bmp route mirroring message", "mirrored bgp message length %d does not match tlv length %d", l, len(tlv.Information))
+			}
+			rm.BGPMessage = tlv.Information
+		case MirrorTLVInformation:
+			if len(tlv.Information) != 2 {
+				return nil, decoder.Malformed("bmp route mirroring message", "invalid length %d of route mirroring information tlv", len(tlv.Information))
+			}
+			switch binary.BigEndian.Uint16(tlv.Information) {
+			case MirrorInfoErroredPDU:
+				rm.ErroredPDU = true
+			case MirrorInfoMessagesLost:
+				rm.MessagesLost = true
+			}
+		default:
+			glog.V(5).Infof("unknown route mirroring tlv type %d, skipping it", tlv.InformationType)
+		}
+	}
+
+	return &rm, nil
+}
@@ -0,0 +1,111 @@
+package bmp
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sbezverk/gobmp/pkg/tools"
+)
+
+func TestUnmarshalRouteMirrorMessage(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        []byte
+		erroredPDU   bool
+		messagesLost bool
+		msgType      uint8
+		fail         error
+	}{
+		{
+			name: "errored bgp update",
+			input: []byte{
+				0, 1, 0, 2, 0, 0,
+				0, 0, 0, 30,
+				0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0, 30, 2,
+				0, 0, 0, 3, 0x40, 1, 0, 24, 10, 0, 0,
+			},
+			erroredPDU: true,
+			msgType:    2,
+		},
+		{
+			name: "messages lost",
+			input: []byte{
+				0, 1, 0, 2, 0, 1,
+			},
+			messagesLost: true,
+		},
+		{
+			name: "invalid bgp message length",
+			input: []byte{
+				0, 0, 0, 20,
+				0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0, 23, 2, 0,
+			},
+			fail: tools.ErrMalformed,
+		},
+		{
+			name: "truncated bgp message",
+			input: []byte{
+				0, 0, 0, 4,
+				0xff, 0xff, 0xff, 0xff,
+			},
+			fail: tools.ErrTruncated,
+		},
+		{
+			name: "invalid information tlv length",
+			input: []byte{
+				0, 1, 0, 1, 0,
+			},
+			fail: tools.ErrMalformed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rm, err := UnmarshalRouteMirrorMessage(tt.input, nil)
+			if err != nil && tt.fail == nil {
+				t.Fatalf("supposed to succeed but failed with error: %+v", err)
+			}
+			if err == nil && tt.fail != nil {
+				t.Fatalf("supposed to fail but succeeded")
+			}
+			if err != nil && !errors.Is(err, tt.fail) {
+				t.Fatalf("expected %+v error but got: %+v", tt.fail, err)
+			}
+			if err != nil {
+				return
+			}
+			if rm.ErroredPDU != tt.erroredPDU {
+				t.Errorf("expected errored pdu %t got %t", tt.erroredPDU, rm.ErroredPDU)
+			}
+			if rm.MessagesLost != tt.messagesLost {
+				t.Errorf("expected messages lost %t got %t", tt.messagesLost, rm.MessagesLost)
+			}
+			if rm.GetBGPMessageType() != tt.msgType {
+				t.Errorf("expected bgp message type %d got %d", tt.msgType, rm.GetBGPMessageType())
+			}
+			if tt.msgType != 2 {
+				return
+			}
+			u, err := rm.GetUpdate()
+			if err != nil {
+				t.Fatalf("failed to decode mirrored update with error: %+v", err)
+			}
+			if len(u.NLRI) != 1 || u.NLRI[0].Length != 24 {
+				t.Errorf("expected a single /24 nlri got %+v", u.NLRI)
+			}
+		})
+	}
+}
+
+func TestRouteMirrorGetUpdateNotUpdate(t *testing.T) {
+	// Mirrored BGP Keepalive
+	rm, err := UnmarshalRouteMirrorMessage([]byte{
+		0, 0, 0, 19,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0, 19, 4,
+	}, nil)
+	if err != nil {
+		t.Fatalf("supposed to succeed but failed with error: %+v", err)
+	}
+	if _, err := rm.GetUpdate(); !errors.Is(err, tools.ErrMalformed) {
+		t.Fatalf("expected %+v error but got: %+v", tools.ErrMalformed, err)
+	}
+}
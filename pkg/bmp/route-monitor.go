@@ -20,16 +20,24 @@ func (rm *RouteMonitor) String() string {
 	return s
 }
 
-// UnmarshalBMPRouteMonitorMessage builds BMP Route Monitor object
-func UnmarshalBMPRouteMonitorMessage(b []byte) (*RouteMonitor, error) {
+// UnmarshalBMPRouteMonitorMessage builds BMP Route Monitor object, addPath carries the types of AFI/SAFI
+// for which the monitored peer's NLRI are prefixed by Path Identifiers.
+func UnmarshalBMPRouteMonitorMessage(b []byte, addPath map[int]bool) (*RouteMonitor, error) {
 	glog.V(6).Infof("BMP Route Monitor Message Raw: %s", tools.MessageHex(b))
+	// Marker, Length and Type
+	if err := decoder.CheckLength("bmp route monitor message", b, bgp.HeaderLength); err != nil {
+		return nil, err
+	}
 	rm := RouteMonitor{}
 	p := 0
 	// Skip 16 bytes of a marker
 	p += 16
-	l := binary.BigEndian.Uint16(b[p : p+2])
+	l := int(binary.BigEndian.Uint16(b[p : p+2]))
 	p += 2
-	u, err := bgp.UnmarshalBGPUpdate(b[p+1 : p+int(l-18)])
+	if l < bgp.HeaderLength || l > len(b) {
+		return nil, decoder.Malformed("bmp route monitor message", "invalid bgp update message length %d", l)
+	}
+	u, err := bgp.UnmarshalBGPUpdate(b[p+1:l], addPath)
 	if err != nil {
 		return nil, err
 	}
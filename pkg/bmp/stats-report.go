@@ -2,34 +2,148 @@ package bmp
 
 import (
 	"encoding/binary"
-	"fmt"
 
 	"github.com/golang/glog"
 	"github.com/sbezverk/gobmp/pkg/tools"
 )
 
+// Stat types defined by rfc7854 and rfc8671
+const (
+	// StatRejectedPrefixes defines the number of prefixes rejected by inbound policy
+	StatRejectedPrefixes = 0
+	// StatDuplicatePrefixes defines the number of (known) duplicate prefix advertisements
+	StatDuplicatePrefixes = 1
+	// StatDuplicateWithdraws defines the number of (known) duplicate withdraws
+	StatDuplicateWithdraws = 2
+	// StatInvalidatedClusterList defines the number of updates invalidated due to CLUSTER_LIST loop
+	StatInvalidatedClusterList = 3
+	// StatInvalidatedASPath defines the number of updates invalidated due to AS_PATH loop
+	StatInvalidatedASPath = 4
+	// StatInvalidatedOriginatorID defines the number of updates invalidated due to ORIGINATOR_ID
+	StatInvalidatedOriginatorID = 5
+	// StatInvalidatedASConfed defines the number of updates invalidated due to AS_CONFED loop
+	StatInvalidatedASConfed = 6
+	// StatAdjRIBIn defines the number of routes in Adj-RIBs-In
+	StatAdjRIBIn = 7
+	// StatLocRIB defines the number of routes in Loc-RIB
+	StatLocRIB = 8
+	// StatAFISAFIAdjRIBIn defines the number of routes in per-AFI/SAFI Adj-RIB-In
+	StatAFISAFIAdjRIBIn = 9
+	// StatAFISAFILocRIB defines the number of routes in per-AFI/SAFI Loc-RIB
+	StatAFISAFILocRIB = 10
+	// StatTreatAsWithdrawUpdates defines the number of updates subjected to treat-as-withdraw
+	StatTreatAsWithdrawUpdates = 11
+	// StatTreatAsWithdrawPrefixes defines the number of prefixes subjected to treat-as-withdraw
+	StatTreatAsWithdrawPrefixes = 12
+	// StatDuplicateUpdates defines the number of duplicate update messages received
+	StatDuplicateUpdates = 13
+	// StatPrePolicyAdjRIBOut defines the number of routes in pre-policy Adj-RIB-Out
+	StatPrePolicyAdjRIBOut = 14
+	// StatPostPolicyAdjRIBOut defines the number of routes in post-policy Adj-RIB-Out
+	StatPostPolicyAdjRIBOut = 15
+	// StatAFISAFIPrePolicyAdjRIBOut defines the number of routes in per-AFI/SAFI pre-policy Adj-RIB-Out
+	StatAFISAFIPrePolicyAdjRIBOut = 16
+	// StatAFISAFIPostPolicyAdjRIBOut defines the number of routes in per-AFI/SAFI post-policy Adj-RIB-Out
+	StatAFISAFIPostPolicyAdjRIBOut = 17
+)
+
+// Stat defines a single decoded Stats Report TLV. Counters (32 bits) and
+// gauges (64 bits) are both stored in Value, AFI and SAFI are only set for per-AFI/SAFI stat types.
+type Stat struct {
+	Type  uint16
+	AFI   uint16
+	SAFI  uint8
+	Value uint64
+}
+
+// IsAFISAFI returns true if the stat is reported per AFI/SAFI
+func (s *Stat) IsAFISAFI() bool {
+	switch s.Type {
+	case StatAFISAFIAdjRIBIn:
+	case StatAFISAFILocRIB:
+	case StatAFISAFIPrePolicyAdjRIBOut:
+	case StatAFISAFIPostPolicyAdjRIBOut:
+	default:
+		return false
+	}
+	return true
+}
+
 // StatsReport defines BMP Stats message structure
 type StatsReport struct {
-	StatsCount int32
+	StatsCount uint32
 	StatsTLV   []InformationalTLV
+	Stats      []Stat
 }
 
 // UnmarshalBMPStatsReportMessage builds BMP Stats Reports object
 func UnmarshalBMPStatsReportMessage(b []byte) (*StatsReport, error) {
 	glog.V(6).Infof("BMP Stats Report Message Raw: %s", tools.MessageHex(b))
+	if err := decoder.CheckLength("bmp stats report message", b, 4); err != nil {
+		return nil, err
+	}
 	sr := StatsReport{}
 	p := 0
-	l := int32(binary.BigEndian.Uint32(b[p : p+4]))
-	if l > int32(len(b)) {
-		return nil, fmt.Errorf("invalid length of Stats Report %d", l)
-	}
-	sr.StatsCount = l
+	sr.StatsCount = binary.BigEndian.Uint32(b[p : p+4])
 	p += 4
 	tlvs, err := UnmarshalTLV(b[p:])
 	if err != nil {
 		return nil, err
 	}
+	if uint32(len(tlvs)) != sr.StatsCount {
+		return nil, decoder.Malformed("bmp stats report message", "stats count %d does not match %d stats carried", sr.StatsCount, len(tlvs))
+	}
 	sr.StatsTLV = tlvs
+	sr.Stats = make([]Stat, 0, len(tlvs))
+	for _, tlv := range tlvs {
+		s, err := makeStat(tlv)
+		if err != nil {
+			return nil, err
+		}
+		if s == nil {
+			glog.V(5).Infof("unknown stat type %d, skipping it", tlv.InformationType)
+			continue
+		}
+		sr.Stats = append(sr.Stats, *s)
+	}
 
 	return &sr, nil
 }
+
+// makeStat decodes Stats Report TLV's value according to its type, nil is returned
+// for unknown types.
+func makeStat(tlv InformationalTLV) (*Stat, error) {
+	s := &Stat{
+		Type: uint16(tlv.InformationType),
+	}
+	v := tlv.Information
+	switch s.Type {
+	case StatRejectedPrefixes, StatDuplicatePrefixes, StatDuplicateWithdraws,
+		StatInvalidatedClusterList, StatInvalidatedASPath, StatInvalidatedOriginatorID,
+		StatInvalidatedASConfed, StatTreatAsWithdrawUpdates, StatTreatAsWithdrawPrefixes,
+		StatDuplicateUpdates:
+		// 32-bit Counter
+		if len(v) != 4 {
+			return nil, decoder.Malformed("bmp stats report message", "invalid length %d of stat type %d, expected 4", len(v), s.Type)
+		}
+		s.Value = uint64(binary.BigEndian.Uint32(v))
+	case StatAdjRIBIn, StatLocRIB, StatPrePolicyAdjRIBOut, StatPostPolicyAdjRIBOut:
+		// 64-bit Gauge
+		if len(v) != 8 {
+			return nil, decoder.Malformed("bmp stats report message", "invalid length %d of stat type %d, expected 8", len(v), s.Type)
+		}
+		s.Value = binary.BigEndian.Uint64(v)
+	case StatAFISAFIAdjRIBIn, StatAFISAFILocRIB, StatAFISAFIPrePolicyAdjRIBOut, StatAFISAFIPostPolicyAdjRIBOut:
+		// 2 bytes AFI, 1 byte SAFI, followed by 64-bit Gauge
+		if len(v) != 11 {
+			return nil, decoder.Malformed("bmp stats report message", "invalid length %d of stat type %d, expected 11", len(v), s.Type)
+		}
+		s.AFI = binary.BigEndian.Uint16(v[0:2])
+		s.SAFI = v[2]
+		s.Value = binary.BigEndian.Uint64(v[3:])
+	default:
+		return nil, nil
+	}
+
+	return s, nil
+}
@@ -0,0 +1,123 @@
+package bmp
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/sbezverk/gobmp/pkg/tools"
+)
+
+func TestUnmarshalBMPStatsReportMessage(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  []byte
+		expect []Stat
+		fail   error
+	}{
+		{
+			name: "counter and gauge",
+			input: []byte{
+				0, 0, 0, 2,
+				0, 0, 0, 4, 0, 0, 0, 5,
+				0, 7, 0, 8, 0, 0, 0, 0, 0, 0, 0x3, 0xe8,
+			},
+			expect: []Stat{
+				{Type: StatRejectedPrefixes, Value: 5},
+				{Type: StatAdjRIBIn, Value: 1000},
+			},
+		},
+		{
+			name: "per afi safi gauges",
+			input: []byte{
+				0, 0, 0, 2,
+				0, 9, 0, 11, 0, 1, 1, 0, 0, 0, 0, 0, 0, 0, 0x64,
+				0, 17, 0, 11, 0, 2, 1, 0, 0, 0, 0, 0, 0, 0, 0x10,
+			},
+			expect: []Stat{
+				{Type: StatAFISAFIAdjRIBIn, AFI: 1, SAFI: 1, Value: 100},
+				{Type: StatAFISAFIPostPolicyAdjRIBOut, AFI: 2, SAFI: 1, Value: 16},
+			},
+		},
+		{
+			name: "unknown type is skipped",
+			input: []byte{
+				0, 0, 0, 2,
+				0, 100, 0, 2, 1, 1,
+				0, 13, 0, 4, 0, 0, 0, 1,
+			},
+			expect: []Stat{
+				{Type: StatDuplicateUpdates, Value: 1},
+			},
+		},
+		{
+			name: "stats count exceeds carried stats",
+			input: []byte{
+				0, 0, 0, 3,
+				0, 0, 0, 4, 0, 0, 0, 5,
+			},
+			fail: tools.ErrMalformed,
+		},
+		{
+			name: "stats count with high bit set",
+			input: []byte{
+				0x80, 0, 0, 1,
+				0, 0, 0, 4, 0, 0, 0, 5,
+			},
+			fail: tools.ErrMalformed,
+		},
+		{
+			name: "stats count below carried stats",
+			input: []byte{
+				0, 0, 0, 1,
+				0, 0, 0, 4, 0, 0, 0, 5,
+				0, 13, 0, 4, 0, 0, 0, 1,
+			},
+			fail: tools.ErrMalformed,
+		},
+		{
+			name: "invalid counter length",
+			input: []byte{
+				0, 0, 0, 1,
+				0, 1, 0, 8, 0, 0, 0, 0, 0, 0, 0, 1,
+			},
+			fail: tools.ErrMalformed,
+		},
+		{
+			name: "invalid per afi safi gauge length",
+			input: []byte{
+				0, 0, 0, 1,
+				0, 9, 0, 8, 0, 0, 0, 0, 0, 0, 0, 1,
+			},
+			fail: tools.ErrMalformed,
+		},
+		{
+			name: "truncated stat",
+			input: []byte{
+				0, 0, 0, 1,
+				0, 7, 0, 8, 0, 0, 0, 0,
+			},
+			fail: tools.ErrTruncated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr, err := UnmarshalBMPStatsReportMessage(tt.input)
+			if err != nil && tt.fail == nil {
+				t.Fatalf("supposed to succeed but failed with error: %+v", err)
+			}
+			if err == nil && tt.fail != nil {
+				t.Fatalf("supposed to fail but succeeded")
+			}
+			if err != nil && !errors.Is(err, tt.fail) {
+				t.Fatalf("expected %+v error but got: %+v", tt.fail, err)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(tt.expect, sr.Stats) {
+				t.Errorf("expected stats %+v do not match with computed stats %+v", tt.expect, sr.Stats)
+			}
+		})
+	}
+}
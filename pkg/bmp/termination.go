@@ -0,0 +1,78 @@
+package bmp
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/golang/glog"
+	"github.com/sbezverk/gobmp/pkg/tools"
+)
+
+const (
+	// TermTLVString defines Termination TLV carrying free-form UTF-8 string
+	TermTLVString = 0
+	// TermTLVReason defines Termination TLV carrying 2 bytes reason code
+	TermTLVReason = 1
+)
+
+// TerminationMessage defines BMP Termination Message per rfc7854
+type TerminationMessage struct {
+	TLV []InformationalTLV
+}
+
+// GetString returns free-form string carried in Termination message
+func (tm *TerminationMessage) GetString() string {
+	return getInformationString(tm.TLV, TermTLVString)
+}
+
+// GetReason returns Termination reason code, the second returned value is false if
+// the message does not carry the reason.
+func (tm *TerminationMessage) GetReason() (uint16, bool) {
+	for _, tlv := range tm.TLV {
+		if tlv.InformationType == TermTLVReason {
+			return binary.BigEndian.Uint16(tlv.Information), true
+		}
+	}
+
+	return 0, false
+}
+
+// GetReasonString returns the description of Termination reason
+func (tm *TerminationMessage) GetReasonString() string {
+	r, ok := tm.GetReason()
+	if !ok {
+		return ""
+	}
+	switch r {
+	case 0:
+		return "Session administratively closed"
+	case 1:
+		return "Unspecified reason"
+	case 2:
+		return "Out of resources"
+	case 3:
+		return "Redundant connection"
+	case 4:
+		return "Session permanently administratively closed"
+	}
+
+	return fmt.Sprintf("Unknown reason (%d)", r)
+}
+
+// UnmarshalTerminationMessage processes Termination Message and returns BMP Termination Message object
+func UnmarshalTerminationMessage(b []byte) (*TerminationMessage, error) {
+	glog.V(6).Infof("BMP Termination Message Raw: %s", tools.MessageHex(b))
+	tlvs, err := UnmarshalTLV(b)
+	if err != nil {
+		return nil, err
+	}
+	for _, tlv := range tlvs {
+		if tlv.InformationType == TermTLVReason && tlv.InformationLength != 2 {
+			return nil, decoder.Malformed("bmp termination message", "invalid termination reason tlv length %d", tlv.InformationLength)
+		}
+	}
+
+	return &TerminationMessage{
+		TLV: tlvs,
+	}, nil
+}
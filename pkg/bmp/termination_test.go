@@ -0,0 +1,68 @@
+package bmp
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sbezverk/gobmp/pkg/tools"
+)
+
+func TestUnmarshalTerminationMessage(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  []byte
+		info   string
+		reason string
+		fail   error
+	}{
+		{
+			name:   "string and reason",
+			input:  []byte{0, 0, 0, 4, 'b', 'y', 'e', '!', 0, 1, 0, 2, 0, 3},
+			info:   "bye!",
+			reason: "Redundant connection",
+		},
+		{
+			name:   "unknown reason",
+			input:  []byte{0, 1, 0, 2, 0, 9},
+			reason: "Unknown reason (9)",
+		},
+		{
+			name:  "no reason",
+			input: []byte{0, 0, 0, 2, 'o', 'k'},
+			info:  "ok",
+		},
+		{
+			name:  "invalid reason length",
+			input: []byte{0, 1, 0, 1, 0},
+			fail:  tools.ErrMalformed,
+		},
+		{
+			name:  "truncated tlv",
+			input: []byte{0, 0, 0, 4, 'b', 'y'},
+			fail:  tools.ErrTruncated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tm, err := UnmarshalTerminationMessage(tt.input)
+			if err != nil && tt.fail == nil {
+				t.Fatalf("supposed to succeed but failed with error: %+v", err)
+			}
+			if err == nil && tt.fail != nil {
+				t.Fatalf("supposed to fail but succeeded")
+			}
+			if err != nil && !errors.Is(err, tt.fail) {
+				t.Fatalf("expected %+v error but got: %+v", tt.fail, err)
+			}
+			if err != nil {
+				return
+			}
+			if tm.GetString() != tt.info {
+				t.Errorf("expected info %q got %q", tt.info, tm.GetString())
+			}
+			if tm.GetReasonString() != tt.reason {
+				t.Errorf("expected reason %q got %q", tt.reason, tm.GetReasonString())
+			}
+		})
+	}
+}
@@ -29,6 +29,11 @@ func (pw *pubwriter) PublishMessage(msgType int, msgHash []byte, msg []byte) err
 	return nil
 }
 
+// Close does nothing as messages are written to standard out without buffering
+func (pw *pubwriter) Close() error {
+	return nil
+}
+
 // NewDumper returns a new instance of standard out  dumper
 func NewDumper() pub.Publisher {
 	pw := pubwriter{
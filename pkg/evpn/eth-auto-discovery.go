@@ -1,6 +1,8 @@
 package evpn
 
-import "github.com/sbezverk/gobmp/pkg/base"
+import (
+	"github.com/sbezverk/gobmp/pkg/base"
+)
 
 // EthAutoDiscovery defines a structure of Route type 1
 // (Ethernet Auto Discovery route type)
@@ -55,6 +57,10 @@ func (t *EthAutoDiscovery) getLabel() []*base.Label {
 // UnmarshalEVPNEthAutoDiscovery instantiates new instance of a Ethernet Auto Discovery route type object
 func UnmarshalEVPNEthAutoDiscovery(b []byte) (*EthAutoDiscovery, error) {
 	var err error
+	// RD, ESI and Ethernet Tag
+	if err := decoder.CheckLength("evpn ethernet auto-discovery route", b, 22); err != nil {
+		return nil, err
+	}
 	t := EthAutoDiscovery{}
 	p := 0
 	t.RD, err = base.MakeRD(b[p : p+8])
@@ -72,8 +78,8 @@ func UnmarshalEVPNEthAutoDiscovery(b []byte) (*EthAutoDiscovery, error) {
 	p += 4
 	bos := false
 	// Loop through labels until hit Bottom of the stack or reach the end of slice
-	for !bos && p < len(b) {
-		l, err := base.MakeLabel(b[p:])
+	for !bos && p+3 <= len(b) {
+		l, err := base.MakeLabel(b[p : p+3])
 		if err != nil {
 			return nil, err
 		}
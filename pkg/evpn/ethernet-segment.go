@@ -1,6 +1,8 @@
 package evpn
 
-import "github.com/sbezverk/gobmp/pkg/base"
+import (
+	"github.com/sbezverk/gobmp/pkg/base"
+)
 
 // EthernetSegment defines a structure of Route type 4
 // (Ethernet Segment Route)
@@ -55,6 +57,10 @@ func (t *EthernetSegment) getLabel() []*base.Label {
 // UnmarshalEVPNEthernetSegment instantiates new instance of an Ethernet Segment Route object
 func UnmarshalEVPNEthernetSegment(b []byte) (*EthernetSegment, error) {
 	var err error
+	// RD, ESI and IP Address Length
+	if err := decoder.CheckLength("evpn ethernet segment route", b, 19); err != nil {
+		return nil, err
+	}
 	t := EthernetSegment{}
 	p := 0
 	t.RD, err = base.MakeRD(b[p : p+8])
@@ -70,6 +76,9 @@ func UnmarshalEVPNEthernetSegment(b []byte) (*EthernetSegment, error) {
 	t.IPAddrLength = b[p]
 	p++
 	l := int(t.IPAddrLength / 8)
+	if err := decoder.CheckLength("evpn ethernet segment route", b[p:], l); err != nil {
+		return nil, err
+	}
 	if t.IPAddrLength != 0 {
 		t.IPAddr = make([]byte, l)
 		copy(t.IPAddr, b[p:p+l])
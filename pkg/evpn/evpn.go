@@ -1,6 +1,7 @@
 package evpn
 
 import (
+	"encoding/binary"
 	"fmt"
 
 	"github.com/golang/glog"
@@ -8,6 +9,9 @@ import (
 	"github.com/sbezverk/gobmp/pkg/tools"
 )
 
+// decoder builds errors of decoding EVPN NLRI
+const decoder tools.Decoder = "evpn"
+
 // RouteTypeSpec defines a method to get a route type specific information
 type RouteTypeSpec interface {
 	GetRouteTypeSpec() interface{}
@@ -30,6 +34,8 @@ type Route struct {
 // NLRI defines a single EVPN NLRI object
 // https://tools.ietf.org/html/rfc7432
 type NLRI struct {
+	// PathID is the Path Identifier of the route received from ADD-PATH peer (RFC 7911)
+	PathID    uint32
 	RouteType uint8
 	Length    uint8
 	RouteTypeSpec
@@ -90,8 +96,9 @@ func (n *NLRI) GetEVPNLabel() []uint32 {
 	return label
 }
 
-// UnmarshalEVPNNLRI instantiates an EVPN NLRI object
-func UnmarshalEVPNNLRI(b []byte) (*Route, error) {
+// UnmarshalEVPNNLRI instantiates an EVPN NLRI object, when pathID is true, every route is prefixed
+// by 4 bytes Path Identifier.
+func UnmarshalEVPNNLRI(b []byte, pathID bool) (*Route, error) {
 	glog.V(5).Infof("EVPN NLRI Raw: %s", tools.MessageHex(b))
 	r := Route{
 		Route: make([]*NLRI, 0),
@@ -99,11 +106,24 @@ func UnmarshalEVPNNLRI(b []byte) (*Route, error) {
 	for p := 0; p < len(b); {
 		var err error
 		n := &NLRI{}
+		if pathID {
+			if err := decoder.CheckLength("evpn nlri path id", b[p:], 4); err != nil {
+				return nil, err
+			}
+			n.PathID = binary.BigEndian.Uint32(b[p : p+4])
+			p += 4
+		}
+		if err := decoder.CheckLength("evpn nlri", b[p:], 2); err != nil {
+			return nil, err
+		}
 		n.RouteType = b[p]
 		p++
 		n.Length = b[p]
 		p++
 		l := int(n.Length)
+		if err := decoder.CheckLength("evpn nlri", b[p:], l); err != nil {
+			return nil, err
+		}
 		switch n.RouteType {
 		case 1:
 			n.RouteTypeSpec, err = UnmarshalEVPNEthAutoDiscovery(b[p : p+l])
@@ -131,7 +151,7 @@ func UnmarshalEVPNNLRI(b []byte) (*Route, error) {
 				return nil, err
 			}
 		default:
-			return nil, fmt.Errorf("unknown route type %d", n.RouteType)
+			return nil, decoder.Malformed("evpn nlri", "unknown route type %d", n.RouteType)
 		}
 		r.Route = append(r.Route, n)
 		p += l
@@ -17,7 +17,9 @@ func TestUnmarshalEVPNNLRI(t *testing.T) {
 	tests := []struct {
 		name   string
 		input  []byte
+		pathID bool
 		expect *Route
+		fail   bool
 	}{
 		{
 			name:  "real type 3 route nlri",
@@ -173,13 +175,84 @@ func TestUnmarshalEVPNNLRI(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:  "type 5 route nlri",
+			input: []byte{0x05, 0x22, 0x00, 0x01, 0xac, 0x1f, 0x65, 0x06, 0x00, 0x00, 0x00, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x00, 0x00, 0x00, 0x00, 0x18, 0x0a, 0x01, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x18, 0xa9, 0x71},
+			expect: &Route{
+				Route: []*NLRI{
+					{
+						RouteType: 5,
+						Length:    0x22,
+						RouteTypeSpec: &IPPrefix{
+							RD:           rd,
+							ESI:          esi3,
+							EthTag:       []byte{0, 0, 0, 0},
+							IPAddrLength: 24,
+							IPAddr:       []byte{10, 1, 2, 0},
+							GWIPAddr:     []byte{0, 0, 0, 0},
+							Label: []*base.Label{
+								{
+									Value: 101015,
+									Exp:   0,
+									BoS:   true,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:  "truncated route length",
+			input: []byte{0x03, 0x11, 0x00, 0x00, 0x00, 0xc8, 0x00, 0x00},
+			fail:  true,
+		},
+		{
+			name:  "truncated type 3 route",
+			input: []byte{0x03, 0x0d, 0x00, 0x00, 0x00, 0xc8, 0x00, 0x00, 0x00, 0x32, 0x00, 0x00, 0x00, 0x00, 0x20},
+			fail:  true,
+		},
+		{
+			name:  "invalid type 5 route length",
+			input: []byte{0x05, 0x04, 0x00, 0x01, 0xac, 0x1f},
+			fail:  true,
+		},
+		{
+			name:   "type 3 route nlri with path id",
+			input:  []byte{0x00, 0x00, 0x00, 0x05, 0x03, 0x11, 0x00, 0x00, 0x00, 0xc8, 0x00, 0x00, 0x00, 0x32, 0x00, 0x00, 0x00, 0x00, 0x20, 0xac, 0x1f, 0x65, 0x06},
+			pathID: true,
+			expect: &Route{
+				Route: []*NLRI{
+					{
+						PathID:    5,
+						RouteType: 3,
+						Length:    17,
+						RouteTypeSpec: &InclusiveMulticastEthTag{
+							RD: &base.RD{
+								Type:  0,
+								Value: []byte{0x00, 0xc8, 0x00, 0x00, 0x00, 0x32},
+							},
+							EthTag:       []byte{0, 0, 0, 0},
+							IPAddrLength: 32,
+							IPAddr:       []byte{172, 31, 101, 6},
+						},
+					},
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := UnmarshalEVPNNLRI(tt.input)
-			if err != nil {
+			got, err := UnmarshalEVPNNLRI(tt.input, tt.pathID)
+			if err != nil && !tt.fail {
 				t.Fatalf("test failed with error: %+v", err)
 			}
+			if err == nil && tt.fail {
+				t.Fatalf("test supposed to fail but succeeded")
+			}
+			if err != nil {
+				return
+			}
 			if !reflect.DeepEqual(tt.expect, got) {
 				t.Fatalf("test failed as expected nlri %+v does not match actual nlri %+v", tt.expect, got)
 			}
@@ -0,0 +1,30 @@
+package evpn
+
+import "testing"
+
+func FuzzUnmarshalEVPNNLRI(f *testing.F) {
+	f.Add([]byte{0x01, 0x19, 0x00, 0x00, 0x00, 0xc8, 0x00, 0x00, 0x00, 0x32, 0x00, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x00, 0x00, 0x00, 0x00, 0x18, 0xa9, 0xb1}, false)
+	f.Add([]byte{0x02, 0x21, 0x00, 0x00, 0x00, 0xc8, 0x00, 0x00, 0x00, 0x32, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x30, 0x00, 0x81, 0xc4, 0xbc, 0x77, 0x8a, 0x00, 0x18, 0xa9, 0x71}, false)
+	f.Add([]byte{0x03, 0x11, 0x00, 0x00, 0x00, 0xc8, 0x00, 0x00, 0x00, 0x32, 0x00, 0x00, 0x00, 0x00, 0x20, 0xac, 0x1f, 0x65, 0x06}, false)
+	f.Add([]byte{0x04, 0x17, 0x00, 0x01, 0xac, 0x1f, 0x65, 0x06, 0x00, 0x00, 0x00, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x20, 0xac, 0x1f, 0x65, 0x06}, false)
+	f.Add([]byte{0x05, 0x22, 0x00, 0x01, 0xac, 0x1f, 0x65, 0x06, 0x00, 0x00, 0x00, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x00, 0x00, 0x00, 0x00, 0x18, 0x0a, 0x01, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x18, 0xa9, 0x71}, false)
+	f.Add([]byte{0x00, 0x00, 0x00, 0x01, 0x03, 0x11, 0x00, 0x00, 0x00, 0xc8, 0x00, 0x00, 0x00, 0x32, 0x00, 0x00, 0x00, 0x00, 0x20, 0xac, 0x1f, 0x65, 0x06}, true)
+	f.Fuzz(func(t *testing.T, b []byte, pathID bool) {
+		r, err := UnmarshalEVPNNLRI(b, pathID)
+		if err != nil {
+			return
+		}
+		for _, n := range r.Route {
+			n.GetEVPNRouteType()
+			n.GetEVPNRD()
+			n.GetEVPNESI()
+			n.GetEVPNTAG()
+			n.GetEVPNMAC()
+			n.GetEVPNMACLength()
+			n.GetEVPNIPAddr()
+			n.GetEVPNIPLength()
+			n.GetEVPNGWAddr()
+			n.GetEVPNLabel()
+		}
+	})
+}
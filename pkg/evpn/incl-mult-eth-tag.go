@@ -1,6 +1,8 @@
 package evpn
 
-import "github.com/sbezverk/gobmp/pkg/base"
+import (
+	"github.com/sbezverk/gobmp/pkg/base"
+)
 
 // InclusiveMulticastEthTag defines a structure of Route type 3
 // (Inclusive Multicast Ethernet Tag Route type)
@@ -54,6 +56,10 @@ func (t *InclusiveMulticastEthTag) getLabel() []*base.Label {
 // UnmarshalEVPNInclusiveMulticastEthTag instantiates new instance of an Inclusive Multicast Ethernet Tag Route type object
 func UnmarshalEVPNInclusiveMulticastEthTag(b []byte) (*InclusiveMulticastEthTag, error) {
 	var err error
+	// RD, Ethernet Tag and IP Address Length
+	if err := decoder.CheckLength("evpn inclusive multicast ethernet tag route", b, 13); err != nil {
+		return nil, err
+	}
 	t := InclusiveMulticastEthTag{}
 	p := 0
 	t.RD, err = base.MakeRD(b[p : p+8])
@@ -67,6 +73,9 @@ func UnmarshalEVPNInclusiveMulticastEthTag(b []byte) (*InclusiveMulticastEthTag,
 	t.IPAddrLength = b[p]
 	p++
 	l := int(t.IPAddrLength / 8)
+	if err := decoder.CheckLength("evpn inclusive multicast ethernet tag route", b[p:], l); err != nil {
+		return nil, err
+	}
 	if t.IPAddrLength != 0 {
 		t.IPAddr = make([]byte, l)
 		copy(t.IPAddr, b[p:p+l])
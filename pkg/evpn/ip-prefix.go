@@ -1,6 +1,8 @@
 package evpn
 
-import "github.com/sbezverk/gobmp/pkg/base"
+import (
+	"github.com/sbezverk/gobmp/pkg/base"
+)
 
 // IPPrefix defines a structure of Route type 5
 // (IP Prefix route)
@@ -58,6 +60,17 @@ func (t *IPPrefix) getLabel() []*base.Label {
 // UnmarshalEVPNIPPrefix instantiates new IP Prefix route type object
 func UnmarshalEVPNIPPrefix(b []byte) (*IPPrefix, error) {
 	var err error
+	// IP Prefix and Gateway IP Address are both either 4 bytes for IPv4 or 16 bytes for IPv6,
+	// the route's length is 34 bytes or 58 bytes respectively, rfc9136 section 3.1
+	var al int
+	switch len(b) {
+	case 34:
+		al = 4
+	case 58:
+		al = 16
+	default:
+		return nil, decoder.Malformed("evpn ip prefix route", "invalid length %d, expected 34 or 58", len(b))
+	}
 	t := IPPrefix{}
 	p := 0
 	t.RD, err = base.MakeRD(b[p : p+8])
@@ -72,19 +85,19 @@ func UnmarshalEVPNIPPrefix(b []byte) (*IPPrefix, error) {
 	p += 10
 	t.EthTag = make([]byte, 4)
 	copy(t.EthTag, b[p:p+4])
+	p += 4
 	t.IPAddrLength = b[p]
 	p++
-	l := int(t.IPAddrLength / 8)
-	t.IPAddr = make([]byte, l)
-	copy(t.IPAddr, b[p:p+l])
-	p += l
-	t.GWIPAddr = make([]byte, l)
-	copy(t.GWIPAddr, b[p:p+l])
-	p += l
+	t.IPAddr = make([]byte, al)
+	copy(t.IPAddr, b[p:p+al])
+	p += al
+	t.GWIPAddr = make([]byte, al)
+	copy(t.GWIPAddr, b[p:p+al])
+	p += al
 	bos := false
 	// Loop through labels until hit Bottom of the stack or reach the end of slice
-	for !bos && p < len(b) {
-		l, err := base.MakeLabel(b[p:])
+	for !bos && p+3 <= len(b) {
+		l, err := base.MakeLabel(b[p : p+3])
 		if err != nil {
 			return nil, err
 		}
@@ -1,6 +1,8 @@
 package evpn
 
-import "github.com/sbezverk/gobmp/pkg/base"
+import (
+	"github.com/sbezverk/gobmp/pkg/base"
+)
 
 // MACIPAdvertisement defines a structure of Route type 2
 // (MAC IP Advertisement route)
@@ -59,6 +61,10 @@ func (t *MACIPAdvertisement) getLabel() []*base.Label {
 // UnmarshalEVPNMACIPAdvertisement instantiates new instance of a Ethernet Auto Discovery route type object
 func UnmarshalEVPNMACIPAdvertisement(b []byte) (*MACIPAdvertisement, error) {
 	var err error
+	// RD, ESI, Ethernet Tag and MAC Address Length
+	if err := decoder.CheckLength("evpn mac/ip advertisement route", b, 23); err != nil {
+		return nil, err
+	}
 	t := MACIPAdvertisement{}
 	p := 0
 	t.RD, err = base.MakeRD(b[p : p+8])
@@ -77,6 +83,10 @@ func UnmarshalEVPNMACIPAdvertisement(b []byte) (*MACIPAdvertisement, error) {
 	t.MACAddrLength = b[p]
 	p++
 	l := int(t.MACAddrLength / 8)
+	// MAC Address is followed by IP Address Length
+	if err := decoder.CheckLength("evpn mac/ip advertisement route", b[p:], l+1); err != nil {
+		return nil, err
+	}
 	if l != 0 {
 		t.MACAddr, err = MakeMACAddress(b[p : p+l])
 		if err != nil {
@@ -87,12 +97,15 @@ func UnmarshalEVPNMACIPAdvertisement(b []byte) (*MACIPAdvertisement, error) {
 	t.IPAddrLength = b[p]
 	p++
 	l = int(t.IPAddrLength / 8)
+	if err := decoder.CheckLength("evpn mac/ip advertisement route", b[p:], l); err != nil {
+		return nil, err
+	}
 	if t.IPAddrLength != 0 {
 		t.IPAddr = make([]byte, l)
 		copy(t.IPAddr, b[p:p+l])
 		p += l
 	}
-	for i := 0; p < len(b); i++ {
+	for i := 0; p+3 <= len(b); i++ {
 		l, err := base.MakeLabel(b[p : p+3])
 		if err != nil {
 			return nil, err
@@ -0,0 +1,167 @@
+package gobmpsrv
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/sbezverk/gobmp/pkg/message"
+	"github.com/sbezverk/gobmp/pkg/pub"
+)
+
+// ReconnectConfig defines how the collector reconnects to routers in active mode
+type ReconnectConfig struct {
+	// InitialDelay is the delay before the first reconnect attempt, the delay doubles after each failed attempt
+	InitialDelay time.Duration
+	// MaxDelay is the maximum delay between reconnect attempts
+	MaxDelay time.Duration
+	// DialTimeout is the timeout of a single connection attempt
+	DialTimeout time.Duration
+}
+
+// DefaultReconnectConfig returns default reconnect configuration
+func DefaultReconnectConfig() ReconnectConfig {
+	return ReconnectConfig{
+		InitialDelay: time.Second,
+		MaxDelay:     time.Minute,
+		DialTimeout:  10 * time.Second,
+	}
+}
+
+// Validate returns error if the delays cannot be used for exponential backoff, zero initial delay would never grow
+// and reconnect in a tight loop.
+func (c ReconnectConfig) Validate() error {
+	if c.InitialDelay <= 0 {
+		return fmt.Errorf("invalid reconnect initial delay %s, must be positive", c.InitialDelay)
+	}
+	if c.MaxDelay < c.InitialDelay {
+		return fmt.Errorf("invalid reconnect max delay %s, must not be less than initial delay %s", c.MaxDelay, c.InitialDelay)
+	}
+
+	return nil
+}
+
+const (
+	// TargetConnecting is the state of a target while the collector dials it
+	TargetConnecting = "connecting"
+	// TargetConnected is the state of a target with established BMP session
+	TargetConnected = "connected"
+	// TargetDisconnected is the state of a target waiting for the next reconnect attempt
+	TargetDisconnected = "disconnected"
+)
+
+// TargetStatus defines the connection status of a router the collector dials in active mode
+type TargetStatus struct {
+	Address string `json:"address"`
+	State   string `json:"state"`
+	// Since is the time of the last state change
+	Since time.Time `json:"since"`
+	// Connects is the number of established BMP sessions
+	Connects uint64 `json:"connects"`
+	// Failures is the number of failed connection attempts
+	Failures  uint64 `json:"failures"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+// target keeps the connection status of a router the collector dials in active mode
+type target struct {
+	sync.Mutex
+	status TargetStatus
+}
+
+func (t *target) setState(state string, err error) {
+	t.Lock()
+	defer t.Unlock()
+	t.status.State = state
+	t.status.Since = time.Now()
+	switch {
+	case state == TargetConnected:
+		t.status.Connects++
+		t.status.LastError = ""
+	case err != nil:
+		t.status.Failures++
+		t.status.LastError = err.Error()
+	}
+}
+
+func (t *target) view() TargetStatus {
+	t.Lock()
+	defer t.Unlock()
+	return t.status
+}
+
+// dialer connects to the target, runs BMP session over the connection and reconnects with exponential backoff
+// when the connection fails or the session is closed.
+func (srv *bmpServer) dialer(t *target) {
+	addr := t.view().Address
+	delay := srv.reconnect.InitialDelay
+	for {
+		t.setState(TargetConnecting, nil)
+		conn, err := net.DialTimeout("tcp", addr, srv.reconnect.DialTimeout)
+		if err != nil {
+			glog.Warningf("fail to connect to router %s with error: %+v", addr, err)
+			t.setState(TargetDisconnected, err)
+		} else {
+			glog.Infof("connected to router %s", addr)
+			t.setState(TargetConnected, nil)
+			start := time.Now()
+			srv.bmpWorker(conn, message.Session{})
+			t.setState(TargetDisconnected, nil)
+			// Session lasted long enough to consider the router healthy, starting backoff over
+			if time.Since(start) > srv.reconnect.MaxDelay {
+				delay = srv.reconnect.InitialDelay
+			}
+		}
+		// Adding up to 20% of jitter to avoid reconnecting to all routers at once
+		wait := delay + time.Duration(rand.Int63n(int64(delay)/5+1))
+		glog.V(5).Infof("reconnecting to router %s in %s", addr, wait)
+		select {
+		case <-srv.stop:
+			return
+		case <-time.After(wait):
+		}
+		if delay *= 2; delay > srv.reconnect.MaxDelay {
+			delay = srv.reconnect.MaxDelay
+		}
+	}
+}
+
+// NewBMPActiveServer instantiates a new instance of BMP Server in active mode, the server dials out
+// to the routers listed in targets instead of listening for incoming connections.
+func NewBMPActiveServer(targets []string, intercept InterceptConfig, p pub.Publisher, raw RawConfig, record RecordConfig, queues QueueConfig, framing FramingConfig, reconnect ReconnectConfig) (BMPServer, error) {
+	if err := reconnect.Validate(); err != nil {
+		return nil, err
+	}
+	if err := intercept.validate(); err != nil {
+		return nil, err
+	}
+	bmp := bmpServer{
+		stop:      make(chan struct{}),
+		abort:     make(chan struct{}),
+		intercept: intercept,
+		publisher: p,
+		raw:       raw,
+		record:    record,
+		queues:    queues,
+		framing:   framing,
+		reconnect: reconnect,
+	}
+	for _, addr := range targets {
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			glog.Errorf("invalid router address %s with error: %+v", addr, err)
+			return nil, err
+		}
+		bmp.targets = append(bmp.targets, &target{
+			status: TargetStatus{
+				Address: addr,
+				State:   TargetDisconnected,
+				Since:   time.Now(),
+			},
+		})
+	}
+
+	return &bmp, nil
+}
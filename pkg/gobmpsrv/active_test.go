@@ -0,0 +1,113 @@
+package gobmpsrv
+
+import (
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+type nopPublisher struct{}
+
+func (p *nopPublisher) PublishMessage(msgType int, msgHash []byte, msg []byte) error {
+	return nil
+}
+
+func (p *nopPublisher) Close() error {
+	return nil
+}
+
+func TestActiveReconnect(t *testing.T) {
+	router, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("fail to setup router listener with error: %+v", err)
+	}
+	defer router.Close()
+	// Router sends Initiation message and closes the session, the collector is expected to reconnect
+	go func() {
+		for {
+			conn, err := router.Accept()
+			if err != nil {
+				return
+			}
+			conn.Write(initiation)
+			conn.Close()
+		}
+	}()
+	reconnect := ReconnectConfig{
+		InitialDelay: 10 * time.Millisecond,
+		MaxDelay:     20 * time.Millisecond,
+		DialTimeout:  time.Second,
+	}
+	srv, err := NewBMPActiveServer([]string{router.Addr().String()}, InterceptConfig{}, &nopPublisher{}, RawConfig{}, RecordConfig{}, DefaultQueueConfig(), DefaultFramingConfig(), reconnect)
+	if err != nil {
+		t.Fatalf("fail to setup active bmp server with error: %+v", err)
+	}
+	srv.Start()
+	defer srv.Stop()
+
+	rStats := getRouterStats("127.0.0.1")
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		s := srv.(*bmpServer).targets[0].view()
+		if s.Connects >= 3 && atomic.LoadUint64(&rStats.messages[bmp.InitiationMsg]) >= 3 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("collector did not reconnect to router, status: %+v", srv.(*bmpServer).targets[0].view())
+}
+
+func TestActiveInvalidTarget(t *testing.T) {
+	if _, err := NewBMPActiveServer([]string{"192.0.2.1"}, InterceptConfig{}, &nopPublisher{}, RawConfig{}, RecordConfig{}, DefaultQueueConfig(), DefaultFramingConfig(), DefaultReconnectConfig()); err == nil {
+		t.Fatalf("expected failure for router address without port")
+	}
+}
+
+func TestReconnectConfigValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config ReconnectConfig
+		fail   bool
+	}{
+		{
+			name:   "default",
+			config: DefaultReconnectConfig(),
+		},
+		{
+			name:   "equal delays",
+			config: ReconnectConfig{InitialDelay: time.Second, MaxDelay: time.Second},
+		},
+		{
+			name:   "zero initial delay",
+			config: ReconnectConfig{MaxDelay: time.Second},
+			fail:   true,
+		},
+		{
+			name:   "negative initial delay",
+			config: ReconnectConfig{InitialDelay: -time.Second, MaxDelay: time.Second},
+			fail:   true,
+		},
+		{
+			name:   "max delay below initial delay",
+			config: ReconnectConfig{InitialDelay: time.Minute, MaxDelay: time.Second},
+			fail:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if err != nil && !tt.fail {
+				t.Fatalf("supposed to succeed but failed with error: %+v", err)
+			}
+			if err == nil && tt.fail {
+				t.Fatalf("supposed to fail but succeeded")
+			}
+		})
+	}
+	if _, err := NewBMPActiveServer([]string{"192.0.2.1:5000"}, InterceptConfig{}, &nopPublisher{}, RawConfig{}, RecordConfig{}, DefaultQueueConfig(), DefaultFramingConfig(), ReconnectConfig{}); err == nil {
+		t.Fatalf("expected failure for zero reconnect delays")
+	}
+}
@@ -0,0 +1,122 @@
+package gobmpsrv
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/sbezverk/gobmp/pkg/message"
+)
+
+// adminPrefix is the path prefix of all admin API endpoints
+const adminPrefix = "/api/v1/"
+
+// sessionInfo defines the format of BMP session information exposed by the admin API
+type sessionInfo struct {
+	RemoteAddr    string            `json:"remote_addr"`
+	Label         string            `json:"label,omitempty"`
+	RouterName    string            `json:"router_name,omitempty"`
+	RouterIP      string            `json:"router_ip,omitempty"`
+	Start         time.Time         `json:"start"`
+	UptimeSeconds float64           `json:"uptime_seconds"`
+	Messages      map[string]uint64 `json:"messages"`
+	Peers         int               `json:"peers"`
+	PeersUp       int               `json:"peers_up"`
+	// PeerList is returned only when a single session is requested
+	PeerList []message.PeerStatus `json:"peer_list,omitempty"`
+}
+
+func (s *sessionStats) info(addr string, withPeers bool) sessionInfo {
+	status := s.producer.Status()
+	info := sessionInfo{
+		RemoteAddr:    addr,
+		Label:         s.label,
+		RouterName:    status.RouterName,
+		RouterIP:      status.RouterIP,
+		Start:         s.start.UTC(),
+		UptimeSeconds: time.Since(s.start).Seconds(),
+		Messages:      make(map[string]uint64, len(s.messages)),
+		Peers:         len(status.Peers),
+	}
+	for t, name := range messageTypeNames {
+		info.Messages[name] = atomic.LoadUint64(&s.messages[t])
+	}
+	for _, peer := range status.Peers {
+		if peer.State == message.PeerStateUp {
+			info.PeersUp++
+		}
+	}
+	if withPeers {
+		info.PeerList = status.Peers
+	}
+
+	return info
+}
+
+func sessionsInfo() []sessionInfo {
+	sessions.Lock()
+	defer sessions.Unlock()
+	v := make([]sessionInfo, 0, len(sessions.m))
+	for addr, s := range sessions.m {
+		v = append(v, s.info(addr, false))
+	}
+	sort.Slice(v, func(i, j int) bool { return v[i].RemoteAddr < v[j].RemoteAddr })
+
+	return v
+}
+
+func sessionInfoByAddr(addr string) (sessionInfo, bool) {
+	sessions.Lock()
+	defer sessions.Unlock()
+	s, ok := sessions.m[addr]
+	if !ok {
+		return sessionInfo{}, false
+	}
+
+	return s.info(addr, true), true
+}
+
+// NewAdminHandler returns the handler of read-only HTTP/JSON admin API, the API serves:
+//
+//	GET /api/v1/sessions lists established BMP sessions
+//	GET /api/v1/sessions/<remote address> returns BMP session with the peers reported by the router
+//	GET /api/v1/targets lists routers the collector dials in active mode
+func NewAdminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(adminPrefix+"sessions", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, sessionsInfo())
+	})
+	mux.HandleFunc(adminPrefix+"sessions/", func(w http.ResponseWriter, r *http.Request) {
+		addr := strings.TrimPrefix(r.URL.Path, adminPrefix+"sessions/")
+		info, ok := sessionInfoByAddr(addr)
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "session " + addr + " not found"})
+			return
+		}
+		writeJSON(w, http.StatusOK, info)
+	})
+	mux.HandleFunc(adminPrefix+"targets", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, TargetsStatus())
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "admin API is read-only"})
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		glog.Errorf("fail to write admin API response with error: %+v", err)
+	}
+}
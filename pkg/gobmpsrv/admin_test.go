@@ -0,0 +1,91 @@
+package gobmpsrv
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sbezverk/gobmp/pkg/message"
+)
+
+// Peer Up message of peer 192.168.80.103 AS 5070
+var peerUp = []byte{3, 0, 0, 0, 234, 3, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 192, 168, 80, 103, 0, 0, 19, 206, 57, 112, 1, 254, 94, 98, 129, 171, 0, 0, 215, 126, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 192, 168, 80, 128, 0, 179, 131, 152, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 0, 91, 1, 4, 19, 206, 0, 90, 192, 168, 8, 8, 62, 2, 6, 1, 4, 0, 1, 0, 1, 2, 6, 1, 4, 0, 1, 0, 4, 2, 6, 1, 4, 0, 1, 0, 128, 2, 2, 128, 0, 2, 2, 2, 0, 2, 6, 65, 4, 0, 0, 19, 206, 2, 20, 5, 18, 0, 1, 0, 1, 0, 2, 0, 1, 0, 2, 0, 2, 0, 1, 0, 128, 0, 2, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 0, 75, 1, 4, 19, 206, 0, 90, 57, 112, 1, 254, 46, 2, 44, 2, 0, 1, 4, 0, 1, 0, 1, 1, 4, 0, 2, 0, 1, 1, 4, 0, 1, 0, 4, 1, 4, 0, 2, 0, 4, 1, 4, 0, 1, 0, 128, 1, 4, 0, 2, 0, 128, 65, 4, 0, 0, 19, 206}
+
+func getJSON(t *testing.T, url string, v interface{}) int {
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("fail to get %s with error: %+v", url, err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("fail to decode response of %s with error: %+v", url, err)
+	}
+
+	return resp.StatusCode
+}
+
+func TestAdminAPI(t *testing.T) {
+	srv, err := NewBMPServer([]ListenerConfig{{Address: "127.0.0.1:0", Label: "admin"}}, InterceptConfig{}, &nopPublisher{}, RawConfig{}, RecordConfig{}, DefaultQueueConfig(), DefaultFramingConfig(), SecurityConfig{})
+	if err != nil {
+		t.Fatalf("fail to setup bmp server with error: %+v", err)
+	}
+	srv.Start()
+	defer srv.Stop()
+	client, err := net.Dial("tcp", srv.(*bmpServer).incoming[0].Addr().String())
+	if err != nil {
+		t.Fatalf("fail to connect with error: %+v", err)
+	}
+	defer client.Close()
+	if _, err := client.Write(concat(initiation, peerUp)); err != nil {
+		t.Fatalf("fail to send messages with error: %+v", err)
+	}
+	api := httptest.NewServer(NewAdminHandler())
+	defer api.Close()
+
+	addr := client.LocalAddr().String()
+	var session sessionInfo
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		var sessions []sessionInfo
+		if code := getJSON(t, api.URL+"/api/v1/sessions", &sessions); code != http.StatusOK {
+			t.Fatalf("expected status %d but got %d", http.StatusOK, code)
+		}
+		for _, s := range sessions {
+			if s.RemoteAddr == addr {
+				session = s
+			}
+		}
+		if session.PeersUp == 1 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if session.RouterName != "r1" || session.Label != "admin" || session.PeersUp != 1 || session.Messages["peer_up"] != 1 {
+		t.Fatalf("unexpected session %+v", session)
+	}
+	if code := getJSON(t, api.URL+"/api/v1/sessions/"+addr, &session); code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d", http.StatusOK, code)
+	}
+	if len(session.PeerList) != 1 {
+		t.Fatalf("expected 1 peer but got %+v", session.PeerList)
+	}
+	if peer := session.PeerList[0]; peer.RemoteIP != "192.168.80.103" || peer.RemoteASN != 5070 || peer.State != message.PeerStateUp || peer.RcvCapabilities == "" {
+		t.Fatalf("unexpected peer %+v", peer)
+	}
+	var e map[string]string
+	if code := getJSON(t, api.URL+"/api/v1/sessions/192.0.2.1:179", &e); code != http.StatusNotFound {
+		t.Fatalf("expected status %d for unknown session but got %d", http.StatusNotFound, code)
+	}
+	resp, err := http.Post(api.URL+"/api/v1/sessions", "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatalf("fail to post with error: %+v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("expected status %d for POST but got %d", http.StatusMethodNotAllowed, resp.StatusCode)
+	}
+}
@@ -0,0 +1,185 @@
+package gobmpsrv
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/golang/glog"
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+// RecoveryPolicy defines how a BMP session recovers from a framing error, when the received bytes
+// do not form a valid BMP Common Header.
+type RecoveryPolicy int
+
+const (
+	// RecoveryResync skips bytes until a valid BMP Common Header is found
+	RecoveryResync RecoveryPolicy = iota
+	// RecoveryClose closes the BMP session
+	RecoveryClose
+)
+
+func (r RecoveryPolicy) String() string {
+	switch r {
+	case RecoveryResync:
+		return "resync"
+	case RecoveryClose:
+		return "close"
+	}
+
+	return fmt.Sprintf("unknown(%d)", int(r))
+}
+
+// ParseRecoveryPolicy returns RecoveryPolicy for its string representation, "resync" or "close"
+func ParseRecoveryPolicy(s string) (RecoveryPolicy, error) {
+	switch s {
+	case "resync":
+		return RecoveryResync, nil
+	case "close":
+		return RecoveryClose, nil
+	}
+
+	return 0, fmt.Errorf("invalid recovery policy %q, expected \"resync\" or \"close\"", s)
+}
+
+// FramingConfig defines the validation of BMP messages framing and the recovery from framing errors
+type FramingConfig struct {
+	// Recovery is the policy applied when a framing error is detected
+	Recovery RecoveryPolicy
+	// MaxMessageLength is the largest BMP message length accepted as valid
+	MaxMessageLength int
+	// MaxResyncBytes is the number of bytes skipped while searching for a valid BMP Common Header,
+	// after which the session is closed
+	MaxResyncBytes int
+}
+
+// DefaultFramingConfig returns default framing configuration
+func DefaultFramingConfig() FramingConfig {
+	return FramingConfig{
+		Recovery: RecoveryResync,
+		// BGP Message carried in BMP message can be up to 65535 bytes long, rfc8654
+		MaxMessageLength: 1 << 17,
+		MaxResyncBytes:   1 << 20,
+	}
+}
+
+var errFraming = errors.New("framing error")
+
+// framer reads BMP messages from a session, validating the framing of each message
+type framer struct {
+	r      *bufio.Reader
+	config FramingConfig
+	stats  *routerStats
+}
+
+func newFramer(r io.Reader, config FramingConfig, stats *routerStats) *framer {
+	return &framer{
+		// Buffer must fit the largest message followed by the next message's header to confirm resynchronization
+		r:      bufio.NewReaderSize(r, config.MaxMessageLength+bmp.CommonHeaderLength+1),
+		config: config,
+		stats:  stats,
+	}
+}
+
+// next returns the next BMP message, in case of a framing error and resync recovery policy,
+// the bytes preceding the next valid BMP Common Header are discarded.
+func (f *framer) next() ([]byte, error) {
+	header, err := f.header()
+	if err != nil {
+		if !errors.Is(err, errFraming) {
+			return nil, err
+		}
+		f.stats.addFramingError()
+		if f.config.Recovery != RecoveryResync {
+			return nil, err
+		}
+		glog.Warningf("%+v, resynchronizing", err)
+		if header, err = f.resync(); err != nil {
+			return nil, err
+		}
+	}
+	msg := make([]byte, header.MessageLength)
+	if _, err := io.ReadFull(f.r, msg); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+// resync discards bytes until a valid BMP Common Header is found or MaxResyncBytes is reached
+func (f *framer) resync() (*bmp.CommonHeader, error) {
+	for skipped := 1; skipped <= f.config.MaxResyncBytes; skipped++ {
+		if _, err := f.r.Discard(1); err != nil {
+			return nil, err
+		}
+		header, err := f.header()
+		if err == nil {
+			err = f.confirm(header)
+		}
+		if err == nil {
+			f.stats.addResync(skipped)
+			glog.Infof("resynchronized BMP stream after skipping %d bytes", skipped)
+			return header, nil
+		}
+		if !errors.Is(err, errFraming) {
+			return nil, err
+		}
+	}
+
+	return nil, fmt.Errorf("%w: no valid BMP Common Header found within %d bytes", errFraming, f.config.MaxResyncBytes)
+}
+
+// header peeks and validates BMP Common Header without consuming it
+func (f *framer) header() (*bmp.CommonHeader, error) {
+	return f.headerAt(0)
+}
+
+// headerAt peeks and validates BMP Common Header found at offset without consuming it
+func (f *framer) headerAt(offset int) (*bmp.CommonHeader, error) {
+	b, err := f.r.Peek(offset + bmp.CommonHeaderLength)
+	if err != nil {
+		return nil, err
+	}
+	header, err := bmp.UnmarshalCommonHeader(b[offset:])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errFraming, err)
+	}
+	if int(header.MessageLength) > f.config.MaxMessageLength {
+		return nil, fmt.Errorf("%w: message length %d exceeds maximum %d", errFraming, header.MessageLength, f.config.MaxMessageLength)
+	}
+	switch header.MessageType {
+	case bmp.InitiationMsg:
+	case bmp.TerminationMsg:
+	default:
+		// All other messages carry Per Peer Header, its first byte is the Peer Type
+		if header.MessageLength < bmp.CommonHeaderLength+bmp.PerPeerHeaderLength {
+			return nil, fmt.Errorf("%w: message type %d is too short, length %d", errFraming, header.MessageType, header.MessageLength)
+		}
+		b, err := f.r.Peek(offset + bmp.CommonHeaderLength + 1)
+		if err != nil {
+			return nil, err
+		}
+		if pt := b[offset+bmp.CommonHeaderLength]; pt > bmp.PeerTypeLocRIB {
+			return nil, fmt.Errorf("%w: invalid peer type %d", errFraming, pt)
+		}
+	}
+
+	return header, nil
+}
+
+// confirm validates BMP Common Header following the message, a valid header found in the middle of
+// garbage is not trusted until the next message's header is valid too or the stream ends with the message.
+func (f *framer) confirm(header *bmp.CommonHeader) error {
+	l := int(header.MessageLength)
+	_, err := f.headerAt(l)
+	if errors.Is(err, io.EOF) {
+		if b, _ := f.r.Peek(l + 1); len(b) == l {
+			return nil
+		}
+		return fmt.Errorf("%w: stream ends in the middle of message", errFraming)
+	}
+
+	return err
+}
@@ -0,0 +1,107 @@
+package gobmpsrv
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+var (
+	// Initiation message with sysName TLV
+	initiation = []byte{3, 0, 0, 0, 12, 4, 0, 2, 0, 2, 'r', '1'}
+	// Termination message with Reason TLV
+	termination = []byte{3, 0, 0, 0, 12, 5, 0, 1, 0, 2, 0, 0}
+)
+
+func concat(b ...[]byte) []byte {
+	r := []byte{}
+	for _, s := range b {
+		r = append(r, s...)
+	}
+	return r
+}
+
+func TestFramer(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []byte
+		recovery RecoveryPolicy
+		expect   [][]byte
+		skipped  uint64
+		fail     bool
+	}{
+		{
+			name:   "valid stream",
+			input:  concat(initiation, termination),
+			expect: [][]byte{initiation, termination},
+		},
+		{
+			name:     "resync after garbage",
+			input:    concat(initiation, []byte{0xff, 3, 0, 0}, termination),
+			recovery: RecoveryResync,
+			expect:   [][]byte{initiation, termination},
+			skipped:  4,
+		},
+		{
+			name:     "resync after message too long",
+			input:    concat([]byte{3, 0xff, 0, 0, 12, 4}, initiation),
+			recovery: RecoveryResync,
+			expect:   [][]byte{initiation},
+			skipped:  6,
+		},
+		{
+			name:     "resync skips invalid peer type",
+			input:    concat([]byte{3, 0, 0, 0, 48, 0, 9}, initiation),
+			recovery: RecoveryResync,
+			expect:   [][]byte{initiation},
+			skipped:  7,
+		},
+		{
+			name:     "close on garbage",
+			input:    concat(initiation, []byte{0xff, 3, 0, 0}, termination),
+			recovery: RecoveryClose,
+			expect:   [][]byte{initiation},
+			fail:     true,
+		},
+		{
+			name:     "resync limit reached",
+			input:    concat(initiation, bytes.Repeat([]byte{0xff}, 32), termination),
+			recovery: RecoveryResync,
+			expect:   [][]byte{initiation},
+			fail:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultFramingConfig()
+			config.Recovery = tt.recovery
+			config.MaxMessageLength = 4096
+			config.MaxResyncBytes = 16
+			stats := &routerStats{}
+			f := newFramer(bytes.NewReader(tt.input), config, stats)
+			got := [][]byte{}
+			var err error
+			for {
+				var msg []byte
+				if msg, err = f.next(); err != nil {
+					break
+				}
+				got = append(got, msg)
+			}
+			if tt.fail && !errors.Is(err, errFraming) {
+				t.Fatalf("expected framing error but got: %+v", err)
+			}
+			if !tt.fail && err != io.EOF {
+				t.Fatalf("expected end of stream but got: %+v", err)
+			}
+			if !reflect.DeepEqual(tt.expect, got) {
+				t.Fatalf("expected messages %v do not match actual messages %v", tt.expect, got)
+			}
+			if stats.skippedBytes != tt.skipped {
+				t.Fatalf("expected %d skipped bytes but got %d", tt.skipped, stats.skippedBytes)
+			}
+		})
+	}
+}
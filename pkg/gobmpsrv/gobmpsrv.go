@@ -1,9 +1,14 @@
 package gobmpsrv
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"net"
+	"sync"
+	"time"
 
 	"github.com/golang/glog"
 	"github.com/sbezverk/gobmp/pkg/bmp"
@@ -12,122 +17,364 @@ import (
 	"github.com/sbezverk/gobmp/pkg/pub"
 )
 
+// QueueConfig defines the sizes of per BMP session queues and the number of workers producing
+// messages of a BMP session. When queues are full, reading from the router's session is paused
+// until the publisher catches up.
+type QueueConfig struct {
+	// ParserQueueDepth is the number of raw BMP messages waiting to be parsed
+	ParserQueueDepth int
+	// ProducerQueueDepth is the number of parsed BMP messages waiting to be dispatched to workers
+	ProducerQueueDepth int
+	// Workers is the number of workers producing messages in parallel
+	Workers int
+	// WorkerQueueDepth is the number of messages waiting in each worker's queue
+	WorkerQueueDepth int
+}
+
+// DefaultQueueConfig returns default queues configuration
+func DefaultQueueConfig() QueueConfig {
+	return QueueConfig{
+		ParserQueueDepth:   1024,
+		ProducerQueueDepth: 1024,
+		Workers:            8,
+		WorkerQueueDepth:   1024,
+	}
+}
+
+// RawConfig defines publishing of BMP messages as received from routers to bmp_raw topic
+type RawConfig struct {
+	// Enabled when true, every BMP message is published wrapped in OpenBMP binary header
+	// in addition to the parsed messages
+	Enabled bool
+	// AdminID identifies the collector in OpenBMP binary header
+	AdminID string
+}
+
 // BMPServer defines methods to manage BMP Server
 type BMPServer interface {
 	Start()
+	// Stop stops the server immediately, messages of BMP sessions waiting in the queues are dropped
 	Stop()
+	// Shutdown stops accepting new BMP sessions, closes established sessions and waits until all messages
+	// received from the sessions are produced or ctx is done.
+	Shutdown(ctx context.Context) error
 }
 
 type bmpServer struct {
-	intercept       bool
-	publisher       pub.Publisher
-	producer        message.Producer
-	sourcePort      int
-	destinationPort int
-	incoming        net.Listener
-	stop            chan struct{}
+	sync.Mutex
+	intercept InterceptConfig
+	publisher pub.Publisher
+	raw       RawConfig
+	record    RecordConfig
+	producer  message.Producer
+	incoming  []*listener
+	queues    QueueConfig
+	framing   FramingConfig
+	reconnect ReconnectConfig
+	targets   []*target
+	security  SecurityConfig
+	admission *admission
+	// stop is closed when the server stops accepting new BMP sessions
+	stop    chan struct{}
+	stopped bool
+	// abort is closed when the sessions must stop without producing the messages waiting in the queues
+	abort     chan struct{}
+	abortOnce sync.Once
+	// sessions tracks BMP sessions which are running or producing the messages waiting in the queues
+	sessions sync.WaitGroup
 }
 
 func (srv *bmpServer) Start() {
-	// Starting bmp server server
-	glog.Infof("Starting gobmp server on %s, intercept mode: %t\n", srv.incoming.Addr().String(), srv.intercept)
-	go srv.server()
+	for _, l := range srv.incoming {
+		// Starting bmp server server
+		glog.Infof("Starting gobmp server on %s, label: %q, intercept destinations: %v\n", l.Addr().String(), l.label, srv.intercept.Destinations)
+		go srv.server(l)
+	}
+	for _, t := range srv.targets {
+		glog.Infof("Starting gobmp active session to %s, intercept destinations: %v\n", t.view().Address, srv.intercept.Destinations)
+		addTarget(t)
+		go srv.dialer(t)
+	}
 }
 
 func (srv *bmpServer) Stop() {
 	glog.Infof("Stopping gobmp server\n")
+	srv.close()
+	srv.abortOnce.Do(func() { close(srv.abort) })
+}
+
+func (srv *bmpServer) Shutdown(ctx context.Context) error {
+	glog.Infof("Shutting down gobmp server\n")
+	srv.close()
+	drained := make(chan struct{})
+	go func() {
+		srv.sessions.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		glog.Infof("all BMP sessions are closed and drained")
+		return nil
+	case <-ctx.Done():
+		srv.abortOnce.Do(func() { close(srv.abort) })
+		return fmt.Errorf("fail to drain BMP sessions with error: %w", ctx.Err())
+	}
+}
+
+// close stops accepting and dialing new BMP sessions, established sessions are closed by their workers
+func (srv *bmpServer) close() {
+	srv.Lock()
+	defer srv.Unlock()
+	if srv.stopped {
+		return
+	}
+	srv.stopped = true
 	close(srv.stop)
-	return
+	for _, l := range srv.incoming {
+		l.Close()
+	}
+}
+
+// openSession registers a new BMP session, it returns false if the server is stopped
+func (srv *bmpServer) openSession() bool {
+	srv.Lock()
+	defer srv.Unlock()
+	if srv.stopped {
+		return false
+	}
+	srv.sessions.Add(1)
+
+	return true
 }
 
-func (srv *bmpServer) server() {
+func (srv *bmpServer) server(l *listener) {
 	for {
-		client, err := srv.incoming.Accept()
+		client, err := l.Accept()
 		if err != nil {
-			glog.Errorf("fail to accept client connection with error: %+v", err)
+			select {
+			case <-srv.stop:
+				return
+			default:
+			}
+			glog.Errorf("fail to accept client connection on %s with error: %+v", l.Addr(), err)
 			continue
 		}
-		glog.V(5).Infof("client %+v accepted, calling bmpWorker", client.RemoteAddr())
-		go srv.bmpWorker(client)
+		go srv.accept(client, l.label)
 	}
 }
 
-func (srv *bmpServer) bmpWorker(client net.Conn) {
-	defer client.Close()
-	var server net.Conn
-	var err error
-	if srv.intercept {
-		server, err = net.Dial("tcp", ":"+fmt.Sprintf("%d", srv.destinationPort))
-		if err != nil {
-			glog.Errorf("failed to connect to destination with error: %+v", err)
+// tlsHandshakeTimeout defines how long the listener waits for the router to complete TLS handshake
+const tlsHandshakeTimeout = 10 * time.Second
+
+// accept admits the client connection according to the listener's SecurityConfig and runs BMP session,
+// label identifies the listener which accepted the connection.
+func (srv *bmpServer) accept(client net.Conn, label string) {
+	ip := client.RemoteAddr().(*net.TCPAddr).IP
+	name, reason := srv.admission.admit(ip)
+	if reason != "" {
+		addRejected(reason)
+		glog.Warningf("rejecting client %+v, reason: %s", client.RemoteAddr(), reason)
+		client.Close()
+		return
+	}
+	defer srv.admission.release(ip)
+	if srv.security.TLS != nil {
+		tlsClient := tls.Server(client, srv.security.TLS)
+		client.SetDeadline(time.Now().Add(tlsHandshakeTimeout))
+		if err := tlsClient.Handshake(); err != nil {
+			addRejected(rejectTLSHandshake)
+			glog.Warningf("rejecting client %+v, reason: %s, error: %+v", client.RemoteAddr(), rejectTLSHandshake, err)
+			client.Close()
 			return
 		}
-		defer server.Close()
-		glog.V(5).Infof("connection to destination server %v established, start intercepting", server.RemoteAddr())
+		client.SetDeadline(time.Time{})
+		client = tlsClient
+	}
+	glog.V(5).Infof("client %+v accepted, calling bmpWorker", client.RemoteAddr())
+	srv.bmpWorker(client, message.Session{RouterName: name, Label: label})
+}
+
+// bmpWorker runs BMP session over the client connection, session carries the router's name and
+// the listener's label attached to all messages of the session, the session's address is set by bmpWorker.
+// When the session ends, bmpWorker returns after all messages received from the client are produced.
+func (srv *bmpServer) bmpWorker(client net.Conn, session message.Session) {
+	defer client.Close()
+	if !srv.openSession() {
+		glog.Warningf("server is stopped, closing client %+v", client.RemoteAddr())
+		return
+	}
+	defer srv.sessions.Done()
+	done := make(chan struct{})
+	defer close(done)
+	// Closing the client connection when the server stops, it unblocks reading from the client
+	go func() {
+		select {
+		case <-srv.stop:
+			client.Close()
+		case <-done:
+		}
+	}()
+	// In intercept mode, BMP messages are copied to each destination by a dedicated forwarder
+	forwarders := make([]*forwarder, 0, len(srv.intercept.Destinations))
+	for _, addr := range srv.intercept.Destinations {
+		f := newForwarder(addr, srv.intercept, srv.abort)
+		go f.run()
+		defer f.close()
+		forwarders = append(forwarders, f)
 	}
-	var producerQueue chan bmp.Message
-	prod := message.NewProducer(srv.publisher)
+	sessionAddr, _, _ := net.SplitHostPort(client.RemoteAddr().String())
+	session.Addr = sessionAddr
+	prod := message.NewProducer(srv.publisher, session, srv.queues.Workers, srv.queues.WorkerQueueDepth)
 	prodStop := make(chan struct{})
-	producerQueue = make(chan bmp.Message)
+	producerQueue := make(chan bmp.Message, srv.queues.ProducerQueueDepth)
+	producerDone := make(chan struct{})
 	// Starting messages producer per client with dedicated work queue
-	go prod.Producer(producerQueue, prodStop)
+	go func() {
+		prod.Producer(producerQueue, prodStop)
+		close(producerDone)
+	}()
 
-	parserQueue := make(chan []byte)
+	// Raw BMP messages are published in the order they were received by a dedicated producer
+	var rawQueue chan []byte
+	rawDone := make(chan struct{})
+	if srv.raw.Enabled {
+		rawQueue = make(chan []byte, srv.queues.ProducerQueueDepth)
+		rawProd := message.NewRawProducer(srv.publisher, session, srv.raw.AdminID)
+		go func() {
+			rawProd.Producer(rawQueue, prodStop)
+			close(rawDone)
+		}()
+	} else {
+		close(rawDone)
+	}
+	parserQueue := make(chan []byte, srv.queues.ParserQueueDepth)
 	parsStop := make(chan struct{})
 	// Starting parser per client with dedicated work queue
-	go parser.Parser(parserQueue, producerQueue, parsStop)
+	rStats := getRouterStats(sessionAddr)
+	go parser.Parser(parserQueue, producerQueue, parsStop, rStats.addDecodeError)
+	stats := &sessionStats{
+		parserQueue:   parserQueue,
+		rawQueue:      rawQueue,
+		producerQueue: producerQueue,
+		producer:      prod,
+		label:         session.Label,
+		start:         time.Now(),
+	}
+	addSession(client.RemoteAddr().String(), stats)
 	defer func() {
-		glog.V(5).Infof("all done with client %+v", client.RemoteAddr())
-		close(parsStop)
-		close(prodStop)
+		// Closing parser queue stops the parser and then the producer, after all messages waiting
+		// in the queues are produced.
+		close(parserQueue)
+		if rawQueue != nil {
+			close(rawQueue)
+		}
+		select {
+		case <-producerDone:
+			select {
+			case <-rawDone:
+				glog.V(5).Infof("all done with client %+v", client.RemoteAddr())
+			case <-srv.abort:
+				glog.Warningf("dropping %d raw messages of client %+v", len(rawQueue), client.RemoteAddr())
+				close(prodStop)
+			}
+		case <-srv.abort:
+			glog.Warningf("dropping %d messages of client %+v", len(parserQueue)+len(producerQueue)+prod.QueueLength()+len(rawQueue), client.RemoteAddr())
+			close(parsStop)
+			close(prodStop)
+		}
+		deleteSession(client.RemoteAddr().String())
 	}()
+	var r io.Reader = client
+	if srv.record.Dir != "" {
+		rec := newRecorder(srv.record, client.RemoteAddr().String(), session.Label)
+		defer rec.Close()
+		r = io.TeeReader(client, rec)
+	}
+	f := newFramer(r, srv.framing, rStats)
 	for {
-		headerMsg := make([]byte, bmp.CommonHeaderLength)
-		if _, err := io.ReadAtLeast(client, headerMsg, bmp.CommonHeaderLength); err != nil {
+		fullMsg, err := f.next()
+		if err != nil {
+			if errors.Is(err, errFraming) {
+				rStats.addClosedSession()
+				glog.Errorf("closing session with client %+v, %+v", client.RemoteAddr(), err)
+				return
+			}
 			glog.Errorf("fail to read from client %+v with error: %+v", client.RemoteAddr(), err)
 			return
 		}
-		// Recovering common header first
-		header, err := bmp.UnmarshalCommonHeader(headerMsg[:bmp.CommonHeaderLength])
-		if err != nil {
-			glog.Errorf("fail to recover BMP message Common Header with error: %+v", err)
-			continue
+		rStats.addMessage(fullMsg[5], len(fullMsg))
+		stats.addMessage(fullMsg[5])
+		for _, f := range forwarders {
+			f.send(fullMsg)
 		}
-		// Allocating space for the message body
-		msg := make([]byte, int(header.MessageLength)-bmp.CommonHeaderLength)
-		if _, err := io.ReadFull(client, msg); err != nil {
-			glog.Errorf("fail to read from client %+v with error: %+v", client.RemoteAddr(), err)
+		if rawQueue != nil && !srv.enqueue(rawQueue, fullMsg, stats, client) {
 			return
 		}
-
-		fullMsg := make([]byte, int(header.MessageLength))
-		copy(fullMsg, headerMsg)
-		copy(fullMsg[bmp.CommonHeaderLength:], msg)
-		// Sending information to the server only in intercept mode
-		if srv.intercept {
-			if _, err := server.Write(fullMsg); err != nil {
-				glog.Errorf("fail to write to server %+v with error: %+v", server.RemoteAddr(), err)
-				return
-			}
+		if !srv.enqueue(parserQueue, fullMsg, stats, client) {
+			return
 		}
-		parserQueue <- fullMsg
 	}
 }
 
-// NewBMPServer instantiates a new instance of BMP Server
-func NewBMPServer(sPort, dPort int, intercept bool, p pub.Publisher) (BMPServer, error) {
-	incoming, err := net.Listen("tcp", fmt.Sprintf(":%d", sPort))
-	if err != nil {
-		glog.Errorf("fail to setup listener on port %d with error: %+v", sPort, err)
+// enqueue sends the message to the session's queue, when the queue is full, enqueue blocks until there is a room,
+// meanwhile nothing is read from the router. It returns false if the server was stopped while blocked.
+func (srv *bmpServer) enqueue(queue chan []byte, msg []byte, stats *sessionStats, client net.Conn) bool {
+	select {
+	case queue <- msg:
+		return true
+	default:
+	}
+	start := time.Now()
+	select {
+	case queue <- msg:
+	case <-srv.abort:
+		return false
+	}
+	stats.addStall(time.Since(start))
+	glog.V(5).Infof("reading from client %+v was stalled for %s", client.RemoteAddr(), time.Since(start))
+
+	return true
+}
+
+// NewBMPServer instantiates a new instance of BMP Server listening on all listeners, intercept defines the destinations
+// BMP messages are copied to, raw defines publishing of BMP messages as received, record defines recording of BMP sessions
+// to files, queues defines the sizes of per session queues, framing defines how BMP sessions recover from framing errors
+// and security defines how routers' connections are authenticated and admitted.
+func NewBMPServer(listeners []ListenerConfig, intercept InterceptConfig, p pub.Publisher, raw RawConfig, record RecordConfig, queues QueueConfig, framing FramingConfig, security SecurityConfig) (BMPServer, error) {
+	if len(listeners) == 0 {
+		return nil, fmt.Errorf("no listeners configured")
+	}
+	if err := intercept.validate(); err != nil {
 		return nil, err
 	}
+	lc := net.ListenConfig{}
+	if len(security.MD5Keys) != 0 {
+		lc.Control = md5Control(security.MD5Keys)
+	}
+	incoming := make([]*listener, 0, len(listeners))
+	for _, l := range listeners {
+		ln, err := lc.Listen(context.Background(), "tcp", l.Address)
+		if err != nil {
+			glog.Errorf("fail to setup listener on %s with error: %+v", l.Address, err)
+			for _, ln := range incoming {
+				ln.Close()
+			}
+			return nil, err
+		}
+		incoming = append(incoming, &listener{Listener: ln, label: l.Label})
+	}
 	bmp := bmpServer{
-		stop:            make(chan struct{}),
-		sourcePort:      sPort,
-		destinationPort: dPort,
-		intercept:       intercept,
-		publisher:       p,
-		incoming:        incoming,
+		stop:      make(chan struct{}),
+		abort:     make(chan struct{}),
+		intercept: intercept,
+		publisher: p,
+		raw:       raw,
+		record:    record,
+		incoming:  incoming,
+		queues:    queues,
+		framing:   framing,
+		security:  security,
+		admission: newAdmission(security),
 	}
 
 	return &bmp, nil
@@ -0,0 +1,344 @@
+package gobmpsrv
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+// InterceptConfig defines the destinations BMP messages are copied to in intercept mode, every BMP session
+// is forwarded to each destination over a separate connection with its own queue, a destination which is slow
+// or unreachable does not affect the router's session or other destinations.
+type InterceptConfig struct {
+	// Destinations is the list of host:port of the collectors BMP messages are copied to, intercept mode
+	// is disabled when the list is empty
+	Destinations []string
+	// QueueDepth is the number of BMP messages per session waiting to be sent to a destination, when full,
+	// new messages are dropped
+	QueueDepth int
+	// WriteTimeout is the time a write to a destination can take before the connection is considered broken
+	WriteTimeout time.Duration
+	// Reconnect defines how the collector reconnects to destinations
+	Reconnect ReconnectConfig
+}
+
+// DefaultInterceptConfig returns default intercept configuration without destinations
+func DefaultInterceptConfig() InterceptConfig {
+	return InterceptConfig{
+		QueueDepth:   4096,
+		WriteTimeout: 30 * time.Second,
+		Reconnect:    DefaultReconnectConfig(),
+	}
+}
+
+// validate returns error if the reconnect delays of enabled intercept mode cannot be used for exponential backoff
+func (c InterceptConfig) validate() error {
+	if len(c.Destinations) == 0 {
+		return nil
+	}
+	if err := c.Reconnect.Validate(); err != nil {
+		return fmt.Errorf("intercept: %w", err)
+	}
+
+	return nil
+}
+
+// ParseDestinations parses comma separated list of intercept destinations' host:port
+func ParseDestinations(s string) ([]string, error) {
+	list := make([]string, 0)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		_, port, err := net.SplitHostPort(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid destination %q with error: %+v", entry, err)
+		}
+		if p, err := strconv.Atoi(port); err != nil || p <= 0 || p > 65535 {
+			return nil, fmt.Errorf("invalid destination %q, port must be between 1 and 65535", entry)
+		}
+		list = append(list, entry)
+	}
+
+	return list, nil
+}
+
+// destinationStats keeps the counters of BMP messages forwarded to a destination over all sessions,
+// the counters are exposed through expvar under "gobmp_intercept" key.
+type destinationStats struct {
+	forwarded uint64
+	bytes     uint64
+	// dropped is the number of messages not sent because the destination's queue was full or the connection failed
+	dropped uint64
+	// connects and failures are the numbers of established and failed connections to the destination
+	connects uint64
+	failures uint64
+	// connected is the number of sessions currently connected to the destination
+	connected int64
+}
+
+// destinationStatsView defines the format of a destination statistics exposed for monitoring
+type destinationStatsView struct {
+	Forwarded uint64 `json:"forwarded"`
+	Bytes     uint64 `json:"bytes"`
+	Dropped   uint64 `json:"dropped"`
+	Connects  uint64 `json:"connects"`
+	Failures  uint64 `json:"failures"`
+	Connected int64  `json:"connected_sessions"`
+}
+
+func (s *destinationStats) view() destinationStatsView {
+	return destinationStatsView{
+		Forwarded: atomic.LoadUint64(&s.forwarded),
+		Bytes:     atomic.LoadUint64(&s.bytes),
+		Dropped:   atomic.LoadUint64(&s.dropped),
+		Connects:  atomic.LoadUint64(&s.connects),
+		Failures:  atomic.LoadUint64(&s.failures),
+		Connected: atomic.LoadInt64(&s.connected),
+	}
+}
+
+var destinations = struct {
+	sync.Mutex
+	m map[string]*destinationStats
+}{
+	m: make(map[string]*destinationStats),
+}
+
+// getDestinationStats returns the statistics of a destination, the statistics are accumulated over all sessions
+func getDestinationStats(addr string) *destinationStats {
+	destinations.Lock()
+	defer destinations.Unlock()
+	s, ok := destinations.m[addr]
+	if !ok {
+		s = &destinationStats{}
+		destinations.m[addr] = s
+	}
+
+	return s
+}
+
+func destinationsView() interface{} {
+	destinations.Lock()
+	defer destinations.Unlock()
+	v := make(map[string]destinationStatsView, len(destinations.m))
+	for addr, s := range destinations.m {
+		v[addr] = s.view()
+	}
+
+	return v
+}
+
+// forwarder copies BMP messages of a session to a destination. When the connection to the destination fails,
+// forwarder reconnects with exponential backoff and replays the last Initiation message and Peer Up messages
+// of the peers which are up, so the destination receives a consistent BMP stream over the new connection.
+// The replayed state is tracked when messages are sent to the forwarder, including the messages dropped
+// while the destination is unreachable.
+type forwarder struct {
+	addr   string
+	config InterceptConfig
+	stats  *destinationStats
+	queue  chan []byte
+	// done is closed when the session ends, forwarder sends the messages waiting in the queue and exits
+	done chan struct{}
+	// stop is closed when the server stops, forwarder exits without sending the messages waiting in the queue
+	stop <-chan struct{}
+	// mu protects the replayed state, it is updated by the session and read by the forwarder after reconnect
+	mu         sync.Mutex
+	initiation []byte
+	// peers keeps Peer Up messages by the peer's identity in the Per Peer Header
+	peers map[string][]byte
+	// lost is set when a message was dropped, the messages left in the queue are discarded at reconnect
+	lost bool
+}
+
+func newForwarder(addr string, config InterceptConfig, stop <-chan struct{}) *forwarder {
+	return &forwarder{
+		addr:   addr,
+		config: config,
+		stats:  getDestinationStats(addr),
+		queue:  make(chan []byte, config.QueueDepth),
+		done:   make(chan struct{}),
+		stop:   stop,
+		peers:  make(map[string][]byte),
+	}
+}
+
+// send queues the message for sending to the destination, the message is dropped if the queue is full
+func (f *forwarder) send(msg []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.track(msg)
+	select {
+	case f.queue <- msg:
+	default:
+		f.lost = true
+		atomic.AddUint64(&f.stats.dropped, 1)
+	}
+}
+
+// close is called when the session ends, no messages can be sent after close
+func (f *forwarder) close() {
+	close(f.done)
+}
+
+// track updates the state replayed to the destination after reconnect, must be called with the lock held
+func (f *forwarder) track(msg []byte) {
+	if len(msg) < bmp.CommonHeaderLength {
+		return
+	}
+	switch msg[5] {
+	case bmp.InitiationMsg:
+		f.initiation = msg
+	case bmp.TerminationMsg:
+		f.initiation = nil
+		f.peers = make(map[string][]byte)
+	case bmp.PeerUpMsg, bmp.PeerDownMsg:
+		// Peer identity is the Per Peer Header without the timestamp
+		if len(msg) < bmp.CommonHeaderLength+bmp.PerPeerHeaderLength {
+			return
+		}
+		key := string(msg[bmp.CommonHeaderLength : bmp.CommonHeaderLength+bmp.PerPeerHeaderLength-8])
+		if msg[5] == bmp.PeerUpMsg {
+			f.peers[key] = msg
+		} else {
+			delete(f.peers, key)
+		}
+	}
+}
+
+// replay returns the Initiation message followed by Peer Up messages of the peers which are up. When messages
+// were dropped, the queued messages preceding the dropped ones are discarded, the replay carries their state.
+func (f *forwarder) replay() [][]byte {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.lost {
+		f.drop()
+		f.lost = false
+	}
+	msgs := make([][]byte, 0, len(f.peers)+1)
+	if f.initiation != nil {
+		msgs = append(msgs, f.initiation)
+	}
+	keys := make([]string, 0, len(f.peers))
+	for key := range f.peers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		msgs = append(msgs, f.peers[key])
+	}
+
+	return msgs
+}
+
+func (f *forwarder) write(conn net.Conn, msg []byte) error {
+	if f.config.WriteTimeout != 0 {
+		conn.SetWriteDeadline(time.Now().Add(f.config.WriteTimeout))
+	}
+	if _, err := conn.Write(msg); err != nil {
+		return err
+	}
+	atomic.AddUint64(&f.stats.forwarded, 1)
+	atomic.AddUint64(&f.stats.bytes, uint64(len(msg)))
+
+	return nil
+}
+
+// dial connects to the destination, it retries with exponential backoff until it succeeds, the session
+// ends or the server stops, nil is returned in the latter cases.
+func (f *forwarder) dial() net.Conn {
+	delay := f.config.Reconnect.InitialDelay
+	for {
+		conn, err := net.DialTimeout("tcp", f.addr, f.config.Reconnect.DialTimeout)
+		if err == nil {
+			atomic.AddUint64(&f.stats.connects, 1)
+			glog.V(5).Infof("connection to destination server %s established, start intercepting", f.addr)
+			return conn
+		}
+		atomic.AddUint64(&f.stats.failures, 1)
+		glog.Warningf("fail to connect to destination %s with error: %+v", f.addr, err)
+		wait := delay + time.Duration(rand.Int63n(int64(delay)/5+1))
+		select {
+		case <-f.stop:
+			return nil
+		case <-f.done:
+			return nil
+		case <-time.After(wait):
+		}
+		if delay *= 2; delay > f.config.Reconnect.MaxDelay {
+			delay = f.config.Reconnect.MaxDelay
+		}
+	}
+}
+
+// run sends queued messages to the destination until the session ends or the server stops
+func (f *forwarder) run() {
+	for {
+		conn := f.dial()
+		if conn == nil {
+			f.drop()
+			return
+		}
+		atomic.AddInt64(&f.stats.connected, 1)
+		done, err := f.forward(conn)
+		atomic.AddInt64(&f.stats.connected, -1)
+		conn.Close()
+		if done {
+			return
+		}
+		glog.Errorf("fail to write to destination %s with error: %+v, reconnecting", f.addr, err)
+	}
+}
+
+// forward replays the session's state and sends queued messages over the connection, it returns true
+// when the session ended or the server stopped and false with the error when the connection failed.
+func (f *forwarder) forward(conn net.Conn) (bool, error) {
+	for _, msg := range f.replay() {
+		if err := f.write(conn, msg); err != nil {
+			return false, err
+		}
+	}
+	for {
+		var msg []byte
+		select {
+		case msg = <-f.queue:
+		case <-f.stop:
+			f.drop()
+			return true, nil
+		case <-f.done:
+			// Session ended, sending messages left in the queue
+			select {
+			case msg = <-f.queue:
+			default:
+				return true, nil
+			}
+		}
+		if err := f.write(conn, msg); err != nil {
+			atomic.AddUint64(&f.stats.dropped, 1)
+			return false, err
+		}
+	}
+}
+
+// drop discards the messages waiting in the queue
+func (f *forwarder) drop() {
+	for {
+		select {
+		case <-f.queue:
+			atomic.AddUint64(&f.stats.dropped, 1)
+		default:
+			return
+		}
+	}
+}
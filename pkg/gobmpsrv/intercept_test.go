@@ -0,0 +1,235 @@
+package gobmpsrv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseDestinations(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		expect []string
+		fail   bool
+	}{
+		{
+			name:   "local port",
+			input:  ":5050",
+			expect: []string{":5050"},
+		},
+		{
+			name:   "multiple destinations",
+			input:  "192.0.2.1:5000, collector.example.net:5000,[2001:db8::1]:5000",
+			expect: []string{"192.0.2.1:5000", "collector.example.net:5000", "[2001:db8::1]:5000"},
+		},
+		{
+			name:  "missing port",
+			input: "192.0.2.1",
+			fail:  true,
+		},
+		{
+			name:  "invalid port",
+			input: "192.0.2.1:0",
+			fail:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDestinations(tt.input)
+			if err != nil {
+				if !tt.fail {
+					t.Fatalf("expected to succeed but failed with error: %+v", err)
+				}
+				return
+			}
+			if tt.fail {
+				t.Fatalf("expected to fail but succeeded")
+			}
+			if !reflect.DeepEqual(tt.expect, got) {
+				t.Fatalf("expected destinations %+v but got %+v", tt.expect, got)
+			}
+		})
+	}
+}
+
+// readBMP reads a single BMP message from the connection
+func readBMP(t *testing.T, conn net.Conn) []byte {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	header := make([]byte, 6)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		t.Fatalf("fail to read BMP common header with error: %+v", err)
+	}
+	msg := make([]byte, binary.BigEndian.Uint32(header[1:5]))
+	copy(msg, header)
+	if _, err := io.ReadFull(conn, msg[6:]); err != nil {
+		t.Fatalf("fail to read BMP message with error: %+v", err)
+	}
+
+	return msg
+}
+
+func TestInterceptReconnect(t *testing.T) {
+	destination, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("fail to setup destination with error: %+v", err)
+	}
+	defer destination.Close()
+	// Nothing listens on the address of the second destination
+	unreachable, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("fail to setup destination with error: %+v", err)
+	}
+	unreachable.Close()
+	intercept := DefaultInterceptConfig()
+	intercept.Destinations = []string{destination.Addr().String(), unreachable.Addr().String()}
+	intercept.Reconnect = ReconnectConfig{InitialDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond, DialTimeout: time.Second}
+	intercept.QueueDepth = 4
+	srv, err := NewBMPServer([]ListenerConfig{{Address: "127.0.0.1:0"}}, intercept, &nopPublisher{}, RawConfig{}, RecordConfig{}, DefaultQueueConfig(), DefaultFramingConfig(), SecurityConfig{})
+	if err != nil {
+		t.Fatalf("fail to setup bmp server with error: %+v", err)
+	}
+	srv.Start()
+	defer srv.Stop()
+	client, err := net.Dial("tcp", srv.(*bmpServer).incoming[0].Addr().String())
+	if err != nil {
+		t.Fatalf("fail to connect with error: %+v", err)
+	}
+	defer client.Close()
+	if _, err := client.Write(concat(initiation, peerUp)); err != nil {
+		t.Fatalf("fail to send messages with error: %+v", err)
+	}
+	conn, err := destination.Accept()
+	if err != nil {
+		t.Fatalf("fail to accept intercepted session with error: %+v", err)
+	}
+	for _, expect := range [][]byte{initiation, peerUp} {
+		if got := readBMP(t, conn); !bytes.Equal(expect, got) {
+			t.Fatalf("expected message %v but got %v", expect, got)
+		}
+	}
+	// Breaking the connection, forwarder must reconnect and replay Initiation and Peer Up messages
+	conn.Close()
+	accepted := make(chan net.Conn)
+	go func() {
+		conn, err := destination.Accept()
+		if err != nil {
+			return
+		}
+		accepted <- conn
+	}()
+	// Writes over the broken connection may succeed until the connection reset is received,
+	// sending Stats Report messages until the forwarder reconnects
+	stats := concat([]byte{3, 0, 0, 0, 0, 1}, peerUp[6:48], []byte{0, 0, 0, 0})
+	binary.BigEndian.PutUint32(stats[1:5], uint32(len(stats)))
+	var reconnected net.Conn
+	for reconnected == nil {
+		if _, err := client.Write(stats); err != nil {
+			t.Fatalf("fail to send message with error: %+v", err)
+		}
+		select {
+		case reconnected = <-accepted:
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+	defer reconnected.Close()
+	for _, expect := range [][]byte{initiation, peerUp} {
+		if got := readBMP(t, reconnected); !bytes.Equal(expect, got) {
+			t.Fatalf("expected replayed message %v but got %v", expect, got)
+		}
+	}
+	if _, err := client.Write(stats); err != nil {
+		t.Fatalf("fail to send message with error: %+v", err)
+	}
+	if got := readBMP(t, reconnected); !bytes.Equal(stats, got) {
+		t.Fatalf("expected Stats Report message after replay but got %v", got)
+	}
+	// Unreachable destination does not affect the router's session
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		v := getDestinationStats(unreachable.Addr().String()).view()
+		if v.Failures > 0 && v.Dropped > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if v := getDestinationStats(unreachable.Addr().String()).view(); v.Failures == 0 || v.Dropped == 0 || v.Forwarded != 0 {
+		t.Fatalf("expected failures and drops for unreachable destination but got %+v", v)
+	}
+	if v := getDestinationStats(destination.Addr().String()).view(); v.Connected != 1 || v.Connects != 2 {
+		t.Fatalf("expected reconnected destination but got %+v", v)
+	}
+}
+
+func TestInterceptInvalidReconnect(t *testing.T) {
+	intercept := DefaultInterceptConfig()
+	intercept.Reconnect.InitialDelay = 0
+	// Reconnect delays are not used when intercept mode is disabled
+	srv, err := NewBMPServer([]ListenerConfig{{Address: "127.0.0.1:0"}}, intercept, &nopPublisher{}, RawConfig{}, RecordConfig{}, DefaultQueueConfig(), DefaultFramingConfig(), SecurityConfig{})
+	if err != nil {
+		t.Fatalf("supposed to succeed but failed with error: %+v", err)
+	}
+	srv.Stop()
+	intercept.Destinations = []string{"127.0.0.1:5050"}
+	if _, err := NewBMPServer([]ListenerConfig{{Address: "127.0.0.1:0"}}, intercept, &nopPublisher{}, RawConfig{}, RecordConfig{}, DefaultQueueConfig(), DefaultFramingConfig(), SecurityConfig{}); err == nil {
+		t.Fatalf("expected failure for zero intercept reconnect initial delay")
+	}
+	if _, err := NewBMPActiveServer([]string{"192.0.2.1:5000"}, intercept, &nopPublisher{}, RawConfig{}, RecordConfig{}, DefaultQueueConfig(), DefaultFramingConfig(), DefaultReconnectConfig()); err == nil {
+		t.Fatalf("expected failure for zero intercept reconnect initial delay")
+	}
+}
+
+func TestInterceptReplayAfterDrops(t *testing.T) {
+	// Nothing listens on the destination's address until the queue is full
+	unreachable, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("fail to setup destination with error: %+v", err)
+	}
+	addr := unreachable.Addr().String()
+	unreachable.Close()
+	config := DefaultInterceptConfig()
+	config.Reconnect = ReconnectConfig{InitialDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond, DialTimeout: time.Second}
+	config.QueueDepth = 1
+	stop := make(chan struct{})
+	defer close(stop)
+	f := newForwarder(addr, config, stop)
+	go f.run()
+	// Second peer differs from the first one by the last byte of the peer address
+	peerUp2 := concat(peerUp)
+	peerUp2[31]++
+	peerDown := concat([]byte{3, 0, 0, 0, 49, 2}, peerUp[6:48], []byte{4})
+	stats := concat([]byte{3, 0, 0, 0, 52, 1}, peerUp[6:48], []byte{0, 0, 0, 0})
+	// Initiation fills the queue, Peer Up and Peer Down messages are dropped
+	for _, msg := range [][]byte{initiation, peerUp, peerUp2, peerDown} {
+		f.send(msg)
+	}
+	if v := f.stats.view(); v.Dropped != 3 {
+		t.Fatalf("expected 3 dropped messages but got %+v", v)
+	}
+	destination, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatalf("fail to setup destination with error: %+v", err)
+	}
+	defer destination.Close()
+	conn, err := destination.Accept()
+	if err != nil {
+		t.Fatalf("fail to accept intercepted session with error: %+v", err)
+	}
+	defer conn.Close()
+	// Replay carries the state of the dropped messages
+	for _, expect := range [][]byte{initiation, peerUp2} {
+		if got := readBMP(t, conn); !bytes.Equal(expect, got) {
+			t.Fatalf("expected replayed message %v but got %v", expect, got)
+		}
+	}
+	// The stale queued Initiation is not sent again after replay
+	f.send(stats)
+	if got := readBMP(t, conn); !bytes.Equal(stats, got) {
+		t.Fatalf("expected Stats Report message after replay but got %v", got)
+	}
+}
@@ -0,0 +1,54 @@
+package gobmpsrv
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// ListenerConfig defines the address BMP listener binds to and the label identifying routers
+// connecting to the listener.
+type ListenerConfig struct {
+	// Address is the host:port to bind to, the host can be IPv4 or IPv6 address, IPv6 address must be
+	// enclosed in square brackets, empty host binds to all addresses.
+	Address string
+	// Label when not empty, is attached to all messages of the sessions accepted by the listener
+	Label string
+}
+
+// listener is a bound BMP listener with the label of its sessions
+type listener struct {
+	net.Listener
+	label string
+}
+
+// ParseListeners parses comma separated list of listeners' addresses, each address can be followed
+// by "=" and the label of the listener, for example ":5000,192.0.2.1:5001=region1,[2001:db8::1]:5001=region2".
+func ParseListeners(s string) ([]ListenerConfig, error) {
+	list := make([]ListenerConfig, 0)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		host, port, err := net.SplitHostPort(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid listener %q with error: %+v", entry, err)
+		}
+		if host != "" && net.ParseIP(host) == nil {
+			return nil, fmt.Errorf("invalid listener %q, bind address must be an IP address", entry)
+		}
+		if p, err := strconv.Atoi(port); err != nil || p < 0 || p > 65535 {
+			return nil, fmt.Errorf("invalid listener %q, invalid port %q", entry, port)
+		}
+		l := ListenerConfig{Address: parts[0]}
+		if len(parts) == 2 {
+			l.Label = parts[1]
+		}
+		list = append(list, l)
+	}
+
+	return list, nil
+}
@@ -0,0 +1,170 @@
+package gobmpsrv
+
+import (
+	"encoding/json"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sbezverk/gobmp/pkg/bmp"
+	"github.com/sbezverk/gobmp/pkg/message"
+)
+
+func TestParseListeners(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		expect []ListenerConfig
+		fail   bool
+	}{
+		{
+			name:   "port only",
+			input:  ":5000",
+			expect: []ListenerConfig{{Address: ":5000"}},
+		},
+		{
+			name:  "ipv4 and ipv6 with labels",
+			input: "192.0.2.1:5000=region1, [2001:db8::1]:5001=region2",
+			expect: []ListenerConfig{
+				{Address: "192.0.2.1:5000", Label: "region1"},
+				{Address: "[2001:db8::1]:5001", Label: "region2"},
+			},
+		},
+		{
+			name:  "missing port",
+			input: "192.0.2.1",
+			fail:  true,
+		},
+		{
+			name:  "ipv6 without brackets",
+			input: "2001:db8::1:5000",
+			fail:  true,
+		},
+		{
+			name:  "host name",
+			input: "localhost:5000",
+			fail:  true,
+		},
+		{
+			name:  "invalid port",
+			input: ":65536",
+			fail:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseListeners(tt.input)
+			if err != nil {
+				if !tt.fail {
+					t.Fatalf("expected to succeed but failed with error: %+v", err)
+				}
+				return
+			}
+			if tt.fail {
+				t.Fatalf("expected to fail but succeeded")
+			}
+			if !reflect.DeepEqual(tt.expect, got) {
+				t.Fatalf("expected listeners %+v but got %+v", tt.expect, got)
+			}
+		})
+	}
+}
+
+// recordingPublisher keeps published Router Event messages
+type recordingPublisher struct {
+	sync.Mutex
+	events []message.RouterEvent
+}
+
+func (p *recordingPublisher) PublishMessage(msgType int, msgHash []byte, msg []byte) error {
+	if msgType != bmp.RouterEventMsg {
+		return nil
+	}
+	var e message.RouterEvent
+	if err := json.Unmarshal(msg, &e); err != nil {
+		return err
+	}
+	p.Lock()
+	defer p.Unlock()
+	p.events = append(p.events, e)
+
+	return nil
+}
+
+func (p *recordingPublisher) Close() error {
+	return nil
+}
+
+func (p *recordingPublisher) labels() map[string]bool {
+	p.Lock()
+	defer p.Unlock()
+	labels := make(map[string]bool)
+	for _, e := range p.events {
+		labels[e.Label] = true
+	}
+
+	return labels
+}
+
+func TestListenersLabel(t *testing.T) {
+	listeners := []ListenerConfig{
+		{Address: "127.0.0.1:0", Label: "v4"},
+		{Address: "[::1]:0", Label: "v6"},
+	}
+	if ln, err := net.Listen("tcp", "[::1]:0"); err != nil {
+		t.Logf("IPv6 is not available, testing IPv4 listener only: %+v", err)
+		listeners = listeners[:1]
+	} else {
+		ln.Close()
+	}
+	p := &recordingPublisher{}
+	srv, err := NewBMPServer(listeners, InterceptConfig{}, p, RawConfig{}, RecordConfig{}, DefaultQueueConfig(), DefaultFramingConfig(), SecurityConfig{})
+	if err != nil {
+		t.Fatalf("fail to setup bmp server with error: %+v", err)
+	}
+	srv.Start()
+	defer srv.Stop()
+	for _, l := range srv.(*bmpServer).incoming {
+		client, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			t.Fatalf("fail to connect to %s with error: %+v", l.Addr(), err)
+		}
+		defer client.Close()
+		if _, err := client.Write(initiation); err != nil {
+			t.Fatalf("fail to send initiation message with error: %+v", err)
+		}
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		labels := p.labels()
+		found := 0
+		for _, l := range listeners {
+			if labels[l.Label] {
+				found++
+			}
+		}
+		if found == len(listeners) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("expected Router Event messages labeled by all listeners, got labels: %+v", p.labels())
+}
+
+func TestNewBMPServerListenFailure(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("fail to setup listener with error: %+v", err)
+	}
+	defer ln.Close()
+	// Second listener's address is in use, the first listener must be released
+	listeners := []ListenerConfig{{Address: "127.0.0.1:0"}, {Address: ln.Addr().String()}}
+	if _, err := NewBMPServer(listeners, InterceptConfig{}, &nopPublisher{}, RawConfig{}, RecordConfig{}, DefaultQueueConfig(), DefaultFramingConfig(), SecurityConfig{}); err == nil {
+		t.Fatalf("expected failure for address already in use")
+	}
+	if _, err := NewBMPServer(nil, InterceptConfig{}, &nopPublisher{}, RawConfig{}, RecordConfig{}, DefaultQueueConfig(), DefaultFramingConfig(), SecurityConfig{}); err == nil {
+		t.Fatalf("expected failure when no listeners are configured")
+	}
+}
@@ -0,0 +1,79 @@
+package gobmpsrv
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	// tcpMD5SigExt defines TCP_MD5SIG_EXT socket option which supports keys per prefix
+	tcpMD5SigExt = 32
+	// tcpMD5SigFlagPrefix defines TCP_MD5SIG_FLAG_PREFIX flag of TCP_MD5SIG_EXT option
+	tcpMD5SigFlagPrefix = 1
+)
+
+// tcpMD5Sig mirrors struct tcp_md5sig of linux/tcp.h
+type tcpMD5Sig struct {
+	addr      [128]byte
+	flags     uint8
+	prefixLen uint8
+	keyLen    uint16
+	ifIndex   int32
+	key       [tcpMD5MaxKeyLength]byte
+}
+
+// md5Control returns a function setting TCP-MD5 signature keys on the listener's socket
+func md5Control(keys []MD5Key) func(network, address string, c syscall.RawConn) error {
+	return func(network, address string, c syscall.RawConn) error {
+		var serr error
+		if err := c.Control(func(fd uintptr) {
+			for _, key := range keys {
+				// IPv6 keys do not apply to listeners bound to IPv4 address
+				if network != "tcp6" && key.Prefix.IP.To4() == nil {
+					continue
+				}
+				if serr = setTCPMD5(int(fd), network == "tcp6", key); serr != nil {
+					return
+				}
+			}
+		}); err != nil {
+			return err
+		}
+
+		return serr
+	}
+}
+
+func setTCPMD5(fd int, ipv6 bool, key MD5Key) error {
+	if len(key.Key) > tcpMD5MaxKeyLength {
+		return fmt.Errorf("TCP-MD5 key for %s exceeds %d bytes", key.Prefix, tcpMD5MaxKeyLength)
+	}
+	sig := tcpMD5Sig{
+		flags: tcpMD5SigFlagPrefix,
+	}
+	sig.keyLen = uint16(copy(sig.key[:], key.Key))
+	ones, _ := key.Prefix.Mask.Size()
+	ip4 := key.Prefix.IP.To4()
+	switch {
+	case !ipv6 && ip4 != nil:
+		sa := (*syscall.RawSockaddrInet4)(unsafe.Pointer(&sig.addr))
+		sa.Family = syscall.AF_INET
+		copy(sa.Addr[:], ip4)
+	case ipv6:
+		// IPv6 socket accepts IPv4 connections as IPv4-mapped IPv6 addresses, the kernel expects
+		// IPv4 prefix length for IPv4-mapped addresses
+		sa := (*syscall.RawSockaddrInet6)(unsafe.Pointer(&sig.addr))
+		sa.Family = syscall.AF_INET6
+		copy(sa.Addr[:], key.Prefix.IP.To16())
+	default:
+		return fmt.Errorf("TCP-MD5 key for %s cannot be set on IPv4 socket", key.Prefix)
+	}
+	sig.prefixLen = uint8(ones)
+	b := (*[unsafe.Sizeof(sig)]byte)(unsafe.Pointer(&sig))[:]
+	if err := syscall.SetsockoptString(fd, syscall.IPPROTO_TCP, tcpMD5SigExt, string(b)); err != nil {
+		return fmt.Errorf("fail to set TCP-MD5 key for %s with error: %w", key.Prefix, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,25 @@
+package gobmpsrv
+
+import (
+	"errors"
+	"net"
+	"syscall"
+	"testing"
+)
+
+func TestMD5Listener(t *testing.T) {
+	keys := []MD5Key{
+		{Prefix: &net.IPNet{IP: net.ParseIP("192.0.2.0").To4(), Mask: net.CIDRMask(24, 32)}, Key: "secret"},
+		{Prefix: &net.IPNet{IP: net.ParseIP("2001:db8::"), Mask: net.CIDRMask(32, 128)}, Key: "secret6"},
+	}
+	srv, err := NewBMPServer([]ListenerConfig{{Address: "127.0.0.1:0"}, {Address: ":0"}}, InterceptConfig{}, &nopPublisher{}, RawConfig{}, RecordConfig{}, DefaultQueueConfig(), DefaultFramingConfig(), SecurityConfig{MD5Keys: keys})
+	if errors.Is(err, syscall.ENOPROTOOPT) || errors.Is(err, syscall.ENOENT) {
+		t.Skipf("kernel does not support TCP-MD5 signatures: %+v", err)
+	}
+	if err != nil {
+		t.Fatalf("fail to setup bmp server with TCP-MD5 keys with error: %+v", err)
+	}
+	for _, l := range srv.(*bmpServer).incoming {
+		l.Close()
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !linux
+// +build !linux
+
+package gobmpsrv
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// md5Control returns a function failing to set TCP-MD5 signature keys, TCP-MD5 is supported only on Linux
+func md5Control(keys []MD5Key) func(network, address string, c syscall.RawConn) error {
+	return func(network, address string, c syscall.RawConn) error {
+		return fmt.Errorf("TCP-MD5 signatures are supported only on Linux")
+	}
+}
@@ -0,0 +1,110 @@
+package gobmpsrv
+
+import (
+	"sync/atomic"
+
+	"github.com/sbezverk/gobmp/pkg/metrics"
+)
+
+// routersSamples returns a sample per router and message type with the value returned by f,
+// f is called with routers' lock held.
+func routersSamples(f func(s *routerStats, msgType int) uint64) []metrics.Sample {
+	routers.Lock()
+	defer routers.Unlock()
+	samples := make([]metrics.Sample, 0, len(routers.m)*len(messageTypeNames))
+	for addr, s := range routers.m {
+		for t, name := range messageTypeNames {
+			samples = append(samples, metrics.Sample{Labels: []string{addr, name}, Value: float64(f(s, t))})
+		}
+	}
+
+	return samples
+}
+
+// routerSamples returns a sample per router with the value returned by f
+func routerSamples(f func(s *routerStats) uint64) []metrics.Sample {
+	routers.Lock()
+	defer routers.Unlock()
+	samples := make([]metrics.Sample, 0, len(routers.m))
+	for addr, s := range routers.m {
+		samples = append(samples, metrics.Sample{Labels: []string{addr}, Value: float64(f(s))})
+	}
+
+	return samples
+}
+
+// destinationsSamples returns a sample per intercept destination with the value returned by f
+func destinationsSamples(f func(v destinationStatsView) float64) []metrics.Sample {
+	destinations.Lock()
+	defer destinations.Unlock()
+	samples := make([]metrics.Sample, 0, len(destinations.m))
+	for addr, s := range destinations.m {
+		samples = append(samples, metrics.Sample{Labels: []string{addr}, Value: f(s.view())})
+	}
+
+	return samples
+}
+
+// queuesSamples returns the lengths of parser, producer, producer's workers and raw queues of every session
+func queuesSamples() []metrics.Sample {
+	sessions.Lock()
+	defer sessions.Unlock()
+	samples := make([]metrics.Sample, 0, len(sessions.m)*4)
+	for addr, s := range sessions.m {
+		v := s.view()
+		samples = append(samples,
+			metrics.Sample{Labels: []string{addr, "parser"}, Value: float64(v.ParserQueueLength)},
+			metrics.Sample{Labels: []string{addr, "producer"}, Value: float64(v.ProducerQueueLength)},
+			metrics.Sample{Labels: []string{addr, "workers"}, Value: float64(v.WorkersQueueLength)},
+			metrics.Sample{Labels: []string{addr, "raw"}, Value: float64(v.RawQueueLength)},
+		)
+	}
+
+	return samples
+}
+
+func init() {
+	metrics.NewCounterFunc("gobmp_bmp_messages_total", "Number of BMP messages received by router and message type.", func() []metrics.Sample {
+		return routersSamples(func(s *routerStats, t int) uint64 { return atomic.LoadUint64(&s.messages[t]) })
+	}, "router", "type")
+	metrics.NewCounterFunc("gobmp_bmp_decode_errors_total", "Number of BMP messages failed to decode by router and message type.", func() []metrics.Sample {
+		return routersSamples(func(s *routerStats, t int) uint64 { return atomic.LoadUint64(&s.decodeErrors[t]) })
+	}, "router", "type")
+	metrics.NewCounterFunc("gobmp_bmp_received_bytes_total", "Number of bytes of BMP messages received by router.", func() []metrics.Sample {
+		return routerSamples(func(s *routerStats) uint64 { return atomic.LoadUint64(&s.bytes) })
+	}, "router")
+	metrics.NewCounterFunc("gobmp_bmp_framing_errors_total", "Number of invalid BMP Common Headers found in routers' streams.", func() []metrics.Sample {
+		return routerSamples(func(s *routerStats) uint64 { return atomic.LoadUint64(&s.framingErrors) })
+	}, "router")
+	metrics.NewGaugeFunc("gobmp_sessions", "Number of established BMP sessions.", func() []metrics.Sample {
+		sessions.Lock()
+		defer sessions.Unlock()
+		return []metrics.Sample{{Value: float64(len(sessions.m))}}
+	})
+	metrics.NewGaugeFunc("gobmp_queue_length", "Number of messages waiting in BMP session's queues.", queuesSamples, "session", "queue")
+	metrics.NewCounterFunc("gobmp_session_stalls_total", "Number of times BMP session's read loop was blocked by a full queue.", func() []metrics.Sample {
+		sessions.Lock()
+		defer sessions.Unlock()
+		samples := make([]metrics.Sample, 0, len(sessions.m))
+		for addr, s := range sessions.m {
+			samples = append(samples, metrics.Sample{Labels: []string{addr}, Value: float64(atomic.LoadUint64(&s.stalls))})
+		}
+		return samples
+	}, "session")
+	metrics.NewCounterFunc("gobmp_rejected_connections_total", "Number of connection attempts rejected by BMP listeners by reason.", func() []metrics.Sample {
+		samples := make([]metrics.Sample, 0, 3)
+		for reason, n := range rejectedView().(map[string]uint64) {
+			samples = append(samples, metrics.Sample{Labels: []string{reason}, Value: float64(n)})
+		}
+		return samples
+	}, "reason")
+	metrics.NewCounterFunc("gobmp_intercept_forwarded_total", "Number of BMP messages copied to intercept destination.", func() []metrics.Sample {
+		return destinationsSamples(func(v destinationStatsView) float64 { return float64(v.Forwarded) })
+	}, "destination")
+	metrics.NewCounterFunc("gobmp_intercept_dropped_total", "Number of BMP messages dropped because intercept destination was slow or unreachable.", func() []metrics.Sample {
+		return destinationsSamples(func(v destinationStatsView) float64 { return float64(v.Dropped) })
+	}, "destination")
+	metrics.NewGaugeFunc("gobmp_intercept_connected_sessions", "Number of BMP sessions connected to intercept destination.", func() []metrics.Sample {
+		return destinationsSamples(func(v destinationStatsView) float64 { return float64(v.Connected) })
+	}, "destination")
+}
@@ -0,0 +1,63 @@
+package gobmpsrv
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sbezverk/gobmp/pkg/metrics"
+)
+
+func TestMetrics(t *testing.T) {
+	srv, err := NewBMPServer([]ListenerConfig{{Address: "127.0.0.1:0"}}, InterceptConfig{}, &nopPublisher{}, RawConfig{}, RecordConfig{}, DefaultQueueConfig(), DefaultFramingConfig(), SecurityConfig{})
+	if err != nil {
+		t.Fatalf("fail to setup bmp server with error: %+v", err)
+	}
+	srv.Start()
+	defer srv.Stop()
+	client, err := net.Dial("tcp", srv.(*bmpServer).incoming[0].Addr().String())
+	if err != nil {
+		t.Fatalf("fail to connect with error: %+v", err)
+	}
+	defer client.Close()
+	if _, err := client.Write(initiation); err != nil {
+		t.Fatalf("fail to send initiation message with error: %+v", err)
+	}
+	h := httptest.NewServer(metrics.Handler())
+	defer h.Close()
+
+	expect := []string{
+		`gobmp_bmp_messages_total{router="127.0.0.1",type="initiation"} `,
+		`gobmp_queue_length{session="` + client.LocalAddr().String() + `",queue="parser"} `,
+		"# TYPE gobmp_decode_errors_total counter",
+		"go_goroutines ",
+	}
+	var body string
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		resp, err := h.Client().Get(h.URL)
+		if err != nil {
+			t.Fatalf("fail to get metrics with error: %+v", err)
+		}
+		b, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("fail to read metrics with error: %+v", err)
+		}
+		body = string(b)
+		found := 0
+		for _, e := range expect {
+			if strings.Contains(body, e) {
+				found++
+			}
+		}
+		if found == len(expect) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("expected metrics %q in:\n%s", expect, body)
+}
@@ -0,0 +1,81 @@
+package gobmpsrv
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sbezverk/gobmp/pkg/bmp"
+	"github.com/sbezverk/gobmp/pkg/message"
+)
+
+// rawPublisher keeps published raw BMP messages
+type rawPublisher struct {
+	sync.Mutex
+	msgs [][]byte
+}
+
+func (p *rawPublisher) PublishMessage(msgType int, msgHash []byte, msg []byte) error {
+	if msgType != bmp.BMPRawMsg {
+		return nil
+	}
+	p.Lock()
+	defer p.Unlock()
+	p.msgs = append(p.msgs, msg)
+
+	return nil
+}
+
+func (p *rawPublisher) Close() error {
+	return nil
+}
+
+func TestRawMessages(t *testing.T) {
+	p := &rawPublisher{}
+	srv, err := NewBMPServer([]ListenerConfig{{Address: "127.0.0.1:0", Label: "region1"}}, InterceptConfig{}, p, RawConfig{Enabled: true, AdminID: "collector1"}, RecordConfig{}, DefaultQueueConfig(), DefaultFramingConfig(), SecurityConfig{})
+	if err != nil {
+		t.Fatalf("fail to setup bmp server with error: %+v", err)
+	}
+	srv.Start()
+	client, err := net.Dial("tcp", srv.(*bmpServer).incoming[0].Addr().String())
+	if err != nil {
+		t.Fatalf("fail to connect with error: %+v", err)
+	}
+	defer client.Close()
+	if _, err := client.Write(concat(initiation, peerUp)); err != nil {
+		t.Fatalf("fail to send messages with error: %+v", err)
+	}
+	// Waiting for the session to read the messages, Shutdown publishes all raw messages read from the session
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if info, ok := sessionInfoByAddr(client.LocalAddr().String()); ok && info.Messages["peer_up"] == 1 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		t.Fatalf("fail to shutdown with error: %+v", err)
+	}
+	p.Lock()
+	defer p.Unlock()
+	if len(p.msgs) != 2 {
+		t.Fatalf("expected 2 raw messages but got %d", len(p.msgs))
+	}
+	for i, expect := range [][]byte{initiation, peerUp} {
+		h, msg, err := message.UnmarshalOpenBMPRaw(p.msgs[i])
+		if err != nil {
+			t.Fatalf("fail to unmarshal raw message with error: %+v", err)
+		}
+		if !bytes.Equal(expect, msg) {
+			t.Fatalf("expected raw message %v but got %v", expect, msg)
+		}
+		if h.RouterIP.String() != "127.0.0.1" || h.RouterGroup != "region1" || h.CollectorAdminID != "collector1" {
+			t.Fatalf("unexpected openbmp header %+v", *h)
+		}
+	}
+}
@@ -0,0 +1,334 @@
+package gobmpsrv
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// RecordConfig defines recording of BMP sessions to files, every session is recorded to its own files,
+// a file is rotated when it reaches MaxFileSize or MaxFileAge.
+type RecordConfig struct {
+	// Dir is the directory the recordings are written to, recording is disabled when Dir is empty
+	Dir string
+	// MaxFileSize is the size in bytes after which a new file is started, 0 means no limit
+	MaxFileSize int64
+	// MaxFileAge is the time after which a new file is started, 0 means no limit
+	MaxFileAge time.Duration
+}
+
+// DefaultRecordConfig returns default recording configuration with recording disabled
+func DefaultRecordConfig() RecordConfig {
+	return RecordConfig{
+		MaxFileSize: 100 << 20,
+		MaxFileAge:  time.Hour,
+	}
+}
+
+const (
+	// recordMagic starts every recording file
+	recordMagic   = "GOBMPREC"
+	recordVersion = 1
+	// RecordFileExt is the extension of recording files
+	RecordFileExt = ".bmprec"
+)
+
+// A recording file starts with the header:
+//
+//	magic (8 bytes) | version (2 bytes) | session start, unix nanoseconds (8 bytes) | file sequence number (4 bytes)
+//	| remote address length (2 bytes) | remote address | label length (2 bytes) | label
+//
+// followed by records, each record carries bytes read from the router in a single read:
+//
+//	time of the read, unix nanoseconds (8 bytes) | length (4 bytes) | bytes
+
+// recordHeader defines the header of a recording file
+type recordHeader struct {
+	remoteAddr string
+	label      string
+	start      time.Time
+	seq        uint32
+}
+
+func (h *recordHeader) serialize() []byte {
+	b := make([]byte, 0, 26+len(h.remoteAddr)+len(h.label))
+	b = append(b, recordMagic...)
+	b = append(b, 0, recordVersion)
+	b = append(b, make([]byte, 12)...)
+	binary.BigEndian.PutUint64(b[10:], uint64(h.start.UnixNano()))
+	binary.BigEndian.PutUint32(b[18:], h.seq)
+	b = append(b, byte(len(h.remoteAddr)>>8), byte(len(h.remoteAddr)))
+	b = append(b, h.remoteAddr...)
+	b = append(b, byte(len(h.label)>>8), byte(len(h.label)))
+	b = append(b, h.label...)
+
+	return b
+}
+
+func readRecordHeader(r io.Reader) (*recordHeader, error) {
+	b := make([]byte, 24)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, fmt.Errorf("fail to read recording header with error: %+v", err)
+	}
+	if string(b[:8]) != recordMagic {
+		return nil, fmt.Errorf("not a BMP recording, invalid magic %q", b[:8])
+	}
+	if v := binary.BigEndian.Uint16(b[8:10]); v != recordVersion {
+		return nil, fmt.Errorf("unsupported BMP recording version %d", v)
+	}
+	h := &recordHeader{
+		start: time.Unix(0, int64(binary.BigEndian.Uint64(b[10:18]))),
+		seq:   binary.BigEndian.Uint32(b[18:22]),
+	}
+	addr := make([]byte, binary.BigEndian.Uint16(b[22:24]))
+	if _, err := io.ReadFull(r, addr); err != nil {
+		return nil, fmt.Errorf("fail to read recording header with error: %+v", err)
+	}
+	h.remoteAddr = string(addr)
+	if _, err := io.ReadFull(r, b[:2]); err != nil {
+		return nil, fmt.Errorf("fail to read recording header with error: %+v", err)
+	}
+	label := make([]byte, binary.BigEndian.Uint16(b[:2]))
+	if _, err := io.ReadFull(r, label); err != nil {
+		return nil, fmt.Errorf("fail to read recording header with error: %+v", err)
+	}
+	h.label = string(label)
+
+	return h, nil
+}
+
+// recorder writes bytes read from a BMP session to rotating recording files, recorder never fails
+// the session, when writing fails, the error is logged and recording of the session stops.
+type recorder struct {
+	config  RecordConfig
+	header  recordHeader
+	file    *os.File
+	w       *bufio.Writer
+	size    int64
+	opened  time.Time
+	failed  bool
+	scratch [12]byte
+}
+
+func newRecorder(config RecordConfig, remoteAddr, label string) *recorder {
+	return &recorder{
+		config: config,
+		header: recordHeader{
+			remoteAddr: remoteAddr,
+			label:      label,
+			start:      time.Now(),
+		},
+	}
+}
+
+// fileName returns the name of the next recording file, the name carries the router's address and the time
+// the file was started.
+func (r *recorder) fileName(now time.Time) string {
+	addr := strings.NewReplacer(":", "_", "[", "", "]", "").Replace(r.header.remoteAddr)
+	return filepath.Join(r.config.Dir, fmt.Sprintf("%s_%s_%04d%s", addr, now.UTC().Format("20060102T150405.000000000Z"), r.header.seq, RecordFileExt))
+}
+
+func (r *recorder) rotate(now time.Time) error {
+	if err := r.closeFile(); err != nil {
+		return err
+	}
+	if r.opened.IsZero() {
+		r.header.seq = 0
+	} else {
+		r.header.seq++
+	}
+	f, err := os.OpenFile(r.fileName(now), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	r.file = f
+	r.w = bufio.NewWriter(f)
+	r.opened = now
+	h := r.header.serialize()
+	r.size = int64(len(h))
+	_, err = r.w.Write(h)
+
+	return err
+}
+
+// Write records bytes read from the session, it always succeeds
+func (r *recorder) Write(b []byte) (int, error) {
+	if r.failed || len(b) == 0 {
+		return len(b), nil
+	}
+	now := time.Now()
+	if err := r.write(now, b); err != nil {
+		glog.Errorf("fail to record session of %s with error: %+v, recording stopped", r.header.remoteAddr, err)
+		r.failed = true
+		r.closeFile()
+	}
+
+	return len(b), nil
+}
+
+func (r *recorder) write(now time.Time, b []byte) error {
+	if r.file == nil ||
+		(r.config.MaxFileSize != 0 && r.size >= r.config.MaxFileSize) ||
+		(r.config.MaxFileAge != 0 && now.Sub(r.opened) >= r.config.MaxFileAge) {
+		if err := r.rotate(now); err != nil {
+			return err
+		}
+	}
+	binary.BigEndian.PutUint64(r.scratch[:8], uint64(now.UnixNano()))
+	binary.BigEndian.PutUint32(r.scratch[8:], uint32(len(b)))
+	if _, err := r.w.Write(r.scratch[:]); err != nil {
+		return err
+	}
+	if _, err := r.w.Write(b); err != nil {
+		return err
+	}
+	r.size += int64(len(r.scratch) + len(b))
+
+	return nil
+}
+
+func (r *recorder) closeFile() error {
+	if r.file == nil {
+		return nil
+	}
+	f := r.file
+	r.file = nil
+	if err := r.w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// Close flushes and closes the current recording file
+func (r *recorder) Close() error {
+	return r.closeFile()
+}
+
+// recordingStream is a StreamSource reading a session recorded in one or more files
+type recordingStream struct {
+	header recordHeader
+	files  []string
+	file   *os.File
+	r      *bufio.Reader
+}
+
+// Session returns the remote address and the label of the recorded session
+func (s *recordingStream) Session() (string, string) {
+	return s.header.remoteAddr, s.header.label
+}
+
+// Next returns the next chunk of the recorded session, it moves to the next file of the session
+// when the current file ends.
+func (s *recordingStream) Next() (time.Time, []byte, error) {
+	for {
+		if s.r == nil {
+			if len(s.files) == 0 {
+				return time.Time{}, nil, io.EOF
+			}
+			f, err := os.Open(s.files[0])
+			if err != nil {
+				return time.Time{}, nil, err
+			}
+			s.file = f
+			s.r = bufio.NewReader(f)
+			if _, err := readRecordHeader(s.r); err != nil {
+				return time.Time{}, nil, fmt.Errorf("fail to read %s with error: %+v", s.files[0], err)
+			}
+			s.files = s.files[1:]
+		}
+		var h [12]byte
+		if _, err := io.ReadFull(s.r, h[:]); err != nil {
+			if err != io.EOF {
+				glog.Warningf("recording %s is truncated: %+v", s.file.Name(), err)
+			}
+			s.file.Close()
+			s.r = nil
+			continue
+		}
+		b := make([]byte, binary.BigEndian.Uint32(h[8:]))
+		if _, err := io.ReadFull(s.r, b); err != nil {
+			glog.Warningf("recording %s is truncated: %+v", s.file.Name(), err)
+			s.file.Close()
+			s.r = nil
+			continue
+		}
+
+		return time.Unix(0, int64(binary.BigEndian.Uint64(h[:8]))), b, nil
+	}
+}
+
+// Close closes the file being read
+func (s *recordingStream) Close() error {
+	if s.r == nil {
+		return nil
+	}
+	s.r = nil
+
+	return s.file.Close()
+}
+
+// OpenRecordings opens recording files, the paths can be files or directories, all recording files of
+// a directory are opened. Files of the same session are returned as a single stream.
+func OpenRecordings(paths []string) ([]StreamSource, error) {
+	files := make([]string, 0)
+	for _, p := range paths {
+		fi, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if !fi.IsDir() {
+			files = append(files, p)
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(p, "*"+RecordFileExt))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	type sessionFile struct {
+		name   string
+		header *recordHeader
+	}
+	sessions := make(map[string][]sessionFile)
+	keys := make([]string, 0)
+	for _, name := range files {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		h, err := readRecordHeader(bufio.NewReader(f))
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("fail to read %s with error: %+v", name, err)
+		}
+		key := fmt.Sprintf("%s/%d", h.remoteAddr, h.start.UnixNano())
+		if _, ok := sessions[key]; !ok {
+			keys = append(keys, key)
+		}
+		sessions[key] = append(sessions[key], sessionFile{name: name, header: h})
+	}
+	sort.Strings(keys)
+	streams := make([]StreamSource, 0, len(keys))
+	for _, key := range keys {
+		sf := sessions[key]
+		sort.Slice(sf, func(i, j int) bool { return sf[i].header.seq < sf[j].header.seq })
+		s := &recordingStream{header: *sf[0].header}
+		for _, f := range sf {
+			s.files = append(s.files, f.name)
+		}
+		streams = append(streams, s)
+	}
+
+	return streams, nil
+}
@@ -0,0 +1,152 @@
+package gobmpsrv
+
+import (
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+// typesPublisher counts published messages by type
+type typesPublisher struct {
+	sync.Mutex
+	types map[int]int
+}
+
+func (p *typesPublisher) PublishMessage(msgType int, msgHash []byte, msg []byte) error {
+	p.Lock()
+	defer p.Unlock()
+	if p.types == nil {
+		p.types = make(map[int]int)
+	}
+	p.types[msgType]++
+
+	return nil
+}
+
+func (p *typesPublisher) Close() error {
+	return nil
+}
+
+func TestRecordReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gobmp-record")
+	if err != nil {
+		t.Fatalf("fail to create directory with error: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+	// Every read is recorded to a new file
+	record := RecordConfig{Dir: dir, MaxFileSize: 1}
+	srv, err := NewBMPServer([]ListenerConfig{{Address: "127.0.0.1:0", Label: "region1"}}, InterceptConfig{}, &nopPublisher{}, RawConfig{}, record, DefaultQueueConfig(), DefaultFramingConfig(), SecurityConfig{})
+	if err != nil {
+		t.Fatalf("fail to setup bmp server with error: %+v", err)
+	}
+	srv.Start()
+	defer srv.Stop()
+	client, err := net.Dial("tcp", srv.(*bmpServer).incoming[0].Addr().String())
+	if err != nil {
+		t.Fatalf("fail to connect with error: %+v", err)
+	}
+	addr := client.LocalAddr().String()
+	for _, msg := range [][]byte{initiation, peerUp} {
+		if _, err := client.Write(msg); err != nil {
+			t.Fatalf("fail to send message with error: %+v", err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	client.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, ok := sessionInfoByAddr(addr); !ok {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*"+RecordFileExt))
+	if len(files) < 2 {
+		t.Fatalf("expected recording rotated to at least 2 files but got %v", files)
+	}
+	streams, err := OpenRecordings([]string{dir})
+	if err != nil {
+		t.Fatalf("fail to open recordings with error: %+v", err)
+	}
+	if len(streams) != 1 {
+		t.Fatalf("expected 1 recorded session but got %d", len(streams))
+	}
+	if a, label := streams[0].Session(); a != addr || label != "region1" {
+		t.Fatalf("expected session of %s labeled region1 but got %s labeled %s", addr, a, label)
+	}
+	p := &typesPublisher{}
+	Replay(streams, 0, p, RawConfig{}, DefaultQueueConfig(), DefaultFramingConfig())
+	if p.types[bmp.RouterEventMsg] != 1 || p.types[bmp.PeerStateChangeMsg] != 1 {
+		t.Fatalf("expected Router Event and Peer Up messages to be replayed but got %+v", p.types)
+	}
+}
+
+// memStream is a StreamSource of chunks kept in memory
+type memStream struct {
+	times  []time.Time
+	chunks [][]byte
+}
+
+func (s *memStream) Session() (string, string) {
+	return "192.0.2.1:179", ""
+}
+
+func (s *memStream) Next() (time.Time, []byte, error) {
+	if len(s.chunks) == 0 {
+		return time.Time{}, nil, io.EOF
+	}
+	t, c := s.times[0], s.chunks[0]
+	s.times, s.chunks = s.times[1:], s.chunks[1:]
+
+	return t, c, nil
+}
+
+func (s *memStream) Close() error {
+	return nil
+}
+
+func TestReplaySpeed(t *testing.T) {
+	tests := []struct {
+		name  string
+		speed float64
+		min   time.Duration
+		max   time.Duration
+	}{
+		{
+			name:  "scaled speed",
+			speed: 4,
+			min:   100 * time.Millisecond,
+			max:   400 * time.Millisecond,
+		},
+		{
+			name:  "as fast as possible",
+			speed: 0,
+			max:   100 * time.Millisecond,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now()
+			s := &memStream{
+				times:  []time.Time{now, now.Add(400 * time.Millisecond)},
+				chunks: [][]byte{initiation, peerUp},
+			}
+			p := &typesPublisher{}
+			start := time.Now()
+			Replay([]StreamSource{s}, tt.speed, p, RawConfig{}, DefaultQueueConfig(), DefaultFramingConfig())
+			if d := time.Since(start); d < tt.min || d > tt.max {
+				t.Fatalf("expected replay to take between %s and %s but it took %s", tt.min, tt.max, d)
+			}
+			if p.types[bmp.PeerStateChangeMsg] != 1 {
+				t.Fatalf("expected Peer Up message to be replayed but got %+v", p.types)
+			}
+		})
+	}
+}
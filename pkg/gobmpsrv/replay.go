@@ -0,0 +1,121 @@
+package gobmpsrv
+
+import (
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/sbezverk/gobmp/pkg/message"
+	"github.com/sbezverk/gobmp/pkg/pub"
+)
+
+// StreamSource defines a source of BMP stream of a router's session captured earlier
+type StreamSource interface {
+	// Session returns the remote address and the label of the captured session
+	Session() (addr string, label string)
+	// Next returns the next chunk of the stream and the time it was received, io.EOF is returned
+	// at the end of the stream
+	Next() (time.Time, []byte, error)
+	Close() error
+}
+
+// replayConn is the collector's side of a replayed session, it reports the captured router's address
+// as the remote address.
+type replayConn struct {
+	net.Conn
+	remote net.Addr
+}
+
+func (c *replayConn) RemoteAddr() net.Addr {
+	return c.remote
+}
+
+// replayAddr returns the address of the captured session, an address which cannot be parsed
+// is replaced by an unspecified address.
+func replayAddr(addr string) net.Addr {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return &net.TCPAddr{IP: net.IPv4zero}
+	}
+	p, _ := strconv.Atoi(port)
+	ip := net.ParseIP(host)
+	if ip == nil {
+		ip = net.IPv4zero
+	}
+
+	return &net.TCPAddr{IP: ip, Port: p}
+}
+
+// Replay feeds captured BMP streams through the parser and the producer as sessions of a live router would be,
+// the messages are published to p. The streams are replayed concurrently, chunks are fed at the times they were
+// originally received divided by speed, the speed of 0 replays the streams as fast as possible. Replay returns
+// after all streams are replayed and all their messages are produced.
+func Replay(streams []StreamSource, speed float64, p pub.Publisher, raw RawConfig, queues QueueConfig, framing FramingConfig) {
+	srv := &bmpServer{
+		stop:      make(chan struct{}),
+		abort:     make(chan struct{}),
+		publisher: p,
+		raw:       raw,
+		queues:    queues,
+		framing:   framing,
+	}
+	type first struct {
+		t     time.Time
+		chunk []byte
+		err   error
+	}
+	// Reading the first chunk of every stream to align all streams to the earliest one
+	firsts := make([]first, len(streams))
+	var base time.Time
+	for i, s := range streams {
+		firsts[i].t, firsts[i].chunk, firsts[i].err = s.Next()
+		if firsts[i].err == nil && (base.IsZero() || firsts[i].t.Before(base)) {
+			base = firsts[i].t
+		}
+	}
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i, s := range streams {
+		addr, label := s.Session()
+		if firsts[i].err != nil {
+			if firsts[i].err != io.EOF {
+				glog.Errorf("fail to replay session of %s with error: %+v", addr, firsts[i].err)
+			}
+			s.Close()
+			continue
+		}
+		collector, router := net.Pipe()
+		wg.Add(2)
+		go func(s StreamSource, f first) {
+			defer wg.Done()
+			defer s.Close()
+			defer router.Close()
+			t, chunk, err := f.t, f.chunk, f.err
+			for err == nil {
+				if speed > 0 {
+					if d := time.Duration(float64(t.Sub(base))/speed) - time.Since(start); d > 0 {
+						time.Sleep(d)
+					}
+				}
+				if _, err := router.Write(chunk); err != nil {
+					glog.Errorf("fail to replay session of %s with error: %+v", addr, err)
+					return
+				}
+				t, chunk, err = s.Next()
+			}
+			if err != io.EOF {
+				glog.Errorf("fail to read session of %s with error: %+v", addr, err)
+			}
+		}(s, firsts[i])
+		go func(conn net.Conn, addr, label string) {
+			defer wg.Done()
+			glog.Infof("replaying session of %s", addr)
+			srv.bmpWorker(&replayConn{Conn: conn, remote: replayAddr(addr)}, message.Session{Label: label})
+			glog.Infof("session of %s is replayed", addr)
+		}(collector, addr, label)
+	}
+	wg.Wait()
+}
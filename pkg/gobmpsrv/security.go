@@ -0,0 +1,248 @@
+package gobmpsrv
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// SecurityConfig defines how BMP listener authenticates and admits routers' connections
+type SecurityConfig struct {
+	// TLS when not nil, BMP sessions are accepted over TLS, client certificates are verified
+	// when TLS.ClientAuth requires it.
+	TLS *tls.Config
+	// Allowlist when not empty, only connections from the listed source prefixes are accepted
+	Allowlist []AllowedSource
+	// MD5Keys defines TCP-MD5 signature keys per source prefix, supported only on Linux
+	MD5Keys []MD5Key
+	// MaxConnectionsPerSource limits the number of concurrent sessions from a single source address,
+	// 0 means no limit
+	MaxConnectionsPerSource int
+}
+
+// AllowedSource defines a source prefix allowed to connect and the name identifying routers
+// connecting from the prefix.
+type AllowedSource struct {
+	Prefix *net.IPNet
+	// Name when not empty, is attached to all messages of the router's sessions instead of
+	// the name the router reports in Initiation message
+	Name string
+}
+
+// MD5Key defines TCP-MD5 signature key used by routers connecting from the prefix, rfc2385
+type MD5Key struct {
+	Prefix *net.IPNet
+	Key    string
+}
+
+// tcpMD5MaxKeyLength defines the maximum length of TCP-MD5 signature key
+const tcpMD5MaxKeyLength = 80
+
+// parsePrefix parses a prefix in CIDR notation or a single IP address
+func parsePrefix(s string) (*net.IPNet, error) {
+	if !strings.Contains(s, "/") {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid address %q", s)
+		}
+		if ip4 := ip.To4(); ip4 != nil {
+			return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+	}
+	_, prefix, err := net.ParseCIDR(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return prefix, nil
+}
+
+// ParseAllowlist parses comma separated list of source prefixes, each prefix can be followed
+// by "=" and the name identifying routers connecting from the prefix, for example "10.0.0.0/8,192.0.2.1=r1".
+func ParseAllowlist(s string) ([]AllowedSource, error) {
+	list := make([]AllowedSource, 0)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		prefix, err := parsePrefix(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowlist entry %q with error: %+v", entry, err)
+		}
+		src := AllowedSource{Prefix: prefix}
+		if len(parts) == 2 {
+			src.Name = parts[1]
+		}
+		list = append(list, src)
+	}
+
+	return list, nil
+}
+
+// LoadMD5Keys loads TCP-MD5 signature keys from the file, each line of the file carries
+// a source prefix and the key separated by a white space, empty lines and lines starting with "#" are ignored.
+func LoadMD5Keys(file string) ([]MD5Key, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	keys := make([]MD5Key, 0)
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		s := strings.TrimSpace(scanner.Text())
+		if s == "" || strings.HasPrefix(s, "#") {
+			continue
+		}
+		fields := strings.Fields(s)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid TCP-MD5 key at line %d, expected prefix and key", line)
+		}
+		prefix, err := parsePrefix(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid TCP-MD5 key prefix at line %d with error: %+v", line, err)
+		}
+		if len(fields[1]) > tcpMD5MaxKeyLength {
+			return nil, fmt.Errorf("TCP-MD5 key at line %d exceeds %d bytes", line, tcpMD5MaxKeyLength)
+		}
+		keys = append(keys, MD5Key{Prefix: prefix, Key: fields[1]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// LoadTLSConfig builds TLS configuration for BMP listener from the server's certificate and key files,
+// when clientCAFile is not empty, routers must present a certificate signed by one of the CAs (mutual TLS).
+func LoadTLSConfig(certFile, keyFile, clientCAFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("fail to load server certificate with error: %+v", err)
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if clientCAFile != "" {
+		pem, err := ioutil.ReadFile(clientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("fail to load client CA with error: %+v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no valid certificates found in %s", clientCAFile)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return config, nil
+}
+
+const (
+	rejectNotAllowed      = "not_allowed"
+	rejectConnectionLimit = "connection_limit"
+	rejectTLSHandshake    = "tls_handshake"
+)
+
+// rejected counts connection attempts rejected by BMP listeners by reason, exposed through expvar
+// under "gobmp_rejected" key.
+var rejected = struct {
+	notAllowed      uint64
+	connectionLimit uint64
+	tlsHandshake    uint64
+}{}
+
+func addRejected(reason string) {
+	switch reason {
+	case rejectNotAllowed:
+		atomic.AddUint64(&rejected.notAllowed, 1)
+	case rejectConnectionLimit:
+		atomic.AddUint64(&rejected.connectionLimit, 1)
+	case rejectTLSHandshake:
+		atomic.AddUint64(&rejected.tlsHandshake, 1)
+	}
+}
+
+func rejectedView() interface{} {
+	return map[string]uint64{
+		rejectNotAllowed:      atomic.LoadUint64(&rejected.notAllowed),
+		rejectConnectionLimit: atomic.LoadUint64(&rejected.connectionLimit),
+		rejectTLSHandshake:    atomic.LoadUint64(&rejected.tlsHandshake),
+	}
+}
+
+// admission tracks the number of sessions per source address and admits new connections
+// according to the listener's SecurityConfig.
+type admission struct {
+	sync.Mutex
+	config  SecurityConfig
+	sources map[string]int
+}
+
+func newAdmission(config SecurityConfig) *admission {
+	return &admission{
+		config:  config,
+		sources: make(map[string]int),
+	}
+}
+
+// admit checks if the connection from the source address is allowed, when allowed, it returns
+// the name configured for the source, otherwise the reason of rejection. release must be called
+// when the admitted session is closed.
+func (a *admission) admit(ip net.IP) (name string, reason string) {
+	name, ok := a.lookup(ip)
+	if !ok {
+		return "", rejectNotAllowed
+	}
+	a.Lock()
+	defer a.Unlock()
+	if a.config.MaxConnectionsPerSource > 0 && a.sources[ip.String()] >= a.config.MaxConnectionsPerSource {
+		return "", rejectConnectionLimit
+	}
+	a.sources[ip.String()]++
+
+	return name, ""
+}
+
+func (a *admission) release(ip net.IP) {
+	a.Lock()
+	defer a.Unlock()
+	if a.sources[ip.String()]--; a.sources[ip.String()] <= 0 {
+		delete(a.sources, ip.String())
+	}
+}
+
+// lookup returns the name of the most specific allowlist prefix matching the address
+func (a *admission) lookup(ip net.IP) (string, bool) {
+	if len(a.config.Allowlist) == 0 {
+		return "", true
+	}
+	found := false
+	name := ""
+	best := -1
+	for _, src := range a.config.Allowlist {
+		if !src.Prefix.Contains(ip) {
+			continue
+		}
+		if ones, _ := src.Prefix.Mask.Size(); ones > best {
+			best = ones
+			name = src.Name
+			found = true
+		}
+	}
+
+	return name, found
+}
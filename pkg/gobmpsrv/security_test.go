@@ -0,0 +1,156 @@
+package gobmpsrv
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+func TestAdmission(t *testing.T) {
+	allowlist, err := ParseAllowlist("10.0.0.0/8, 10.1.0.0/16=core,192.0.2.1=r1,2001:db8::/32=v6")
+	if err != nil {
+		t.Fatalf("fail to parse allowlist with error: %+v", err)
+	}
+	a := newAdmission(SecurityConfig{
+		Allowlist:               allowlist,
+		MaxConnectionsPerSource: 1,
+	})
+	tests := []struct {
+		name   string
+		ip     string
+		expect string
+		reason string
+	}{
+		{
+			name: "prefix without name",
+			ip:   "10.2.0.1",
+		},
+		{
+			name:   "most specific prefix",
+			ip:     "10.1.0.1",
+			expect: "core",
+		},
+		{
+			name:   "host address",
+			ip:     "192.0.2.1",
+			expect: "r1",
+		},
+		{
+			name:   "ipv6 prefix",
+			ip:     "2001:db8::1",
+			expect: "v6",
+		},
+		{
+			name:   "not in allowlist",
+			ip:     "192.0.2.2",
+			reason: rejectNotAllowed,
+		},
+		{
+			name:   "connection limit",
+			ip:     "192.0.2.1",
+			reason: rejectConnectionLimit,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, reason := a.admit(net.ParseIP(tt.ip))
+			if reason != tt.reason {
+				t.Fatalf("expected rejection reason %q but got %q", tt.reason, reason)
+			}
+			if name != tt.expect {
+				t.Fatalf("expected name %q but got %q", tt.expect, name)
+			}
+		})
+	}
+	a.release(net.ParseIP("192.0.2.1"))
+	if _, reason := a.admit(net.ParseIP("192.0.2.1")); reason != "" {
+		t.Fatalf("expected source to be admitted after release but got rejection reason %q", reason)
+	}
+}
+
+func TestParseAllowlistInvalid(t *testing.T) {
+	for _, s := range []string{"10.0.0.0/33", "router1", "10.0.0.1=r1,300.0.0.1"} {
+		if _, err := ParseAllowlist(s); err == nil {
+			t.Errorf("expected allowlist %q to fail", s)
+		}
+	}
+}
+
+func selfSignedCert(t *testing.T) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("fail to generate key with error: %+v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "gobmp"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("fail to create certificate with error: %+v", err)
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestTLSListener(t *testing.T) {
+	cert := selfSignedCert(t)
+	security := SecurityConfig{
+		TLS: &tls.Config{Certificates: []tls.Certificate{cert}},
+	}
+	srv, err := NewBMPServer([]ListenerConfig{{Address: "127.0.0.1:0"}}, InterceptConfig{}, &nopPublisher{}, RawConfig{}, RecordConfig{}, DefaultQueueConfig(), DefaultFramingConfig(), security)
+	if err != nil {
+		t.Fatalf("fail to setup bmp server with error: %+v", err)
+	}
+	srv.Start()
+	defer srv.Stop()
+	port := srv.(*bmpServer).incoming[0].Addr().(*net.TCPAddr).Port
+	addr := net.JoinHostPort("127.0.0.1", fmt.Sprint(port))
+
+	// Plain TCP client fails TLS handshake
+	rejectedBefore := atomic.LoadUint64(&rejected.tlsHandshake)
+	plain, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("fail to connect with error: %+v", err)
+	}
+	plain.Write(initiation)
+	plain.Close()
+
+	pool := x509.NewCertPool()
+	leaf, _ := x509.ParseCertificate(cert.Certificate[0])
+	pool.AddCert(leaf)
+	client, err := tls.Dial("tcp", addr, &tls.Config{RootCAs: pool})
+	if err != nil {
+		t.Fatalf("fail to establish TLS session with error: %+v", err)
+	}
+	defer client.Close()
+	rStats := getRouterStats("127.0.0.1")
+	initiations := atomic.LoadUint64(&rStats.messages[bmp.InitiationMsg])
+	if _, err := client.Write(initiation); err != nil {
+		t.Fatalf("fail to send initiation message with error: %+v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if atomic.LoadUint64(&rStats.messages[bmp.InitiationMsg]) > initiations && atomic.LoadUint64(&rejected.tlsHandshake) > rejectedBefore {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("expected initiation message received over TLS and plain TCP client rejected")
+}
@@ -0,0 +1,97 @@
+package gobmpsrv
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// slowPublisher counts published messages, publishing each message takes delay, publishing blocks
+// until release is closed if release is not nil.
+type slowPublisher struct {
+	delay     time.Duration
+	release   chan struct{}
+	published uint64
+}
+
+func (p *slowPublisher) PublishMessage(msgType int, msgHash []byte, msg []byte) error {
+	if p.release != nil {
+		<-p.release
+	}
+	time.Sleep(p.delay)
+	atomic.AddUint64(&p.published, 1)
+
+	return nil
+}
+
+func (p *slowPublisher) Close() error {
+	return nil
+}
+
+// startSession starts BMP server and sends count Initiation messages to the server
+func startSession(t *testing.T, p *slowPublisher, count int) (BMPServer, net.Conn) {
+	srv, err := NewBMPServer([]ListenerConfig{{Address: "127.0.0.1:0"}}, InterceptConfig{}, p, RawConfig{}, RecordConfig{}, DefaultQueueConfig(), DefaultFramingConfig(), SecurityConfig{})
+	if err != nil {
+		t.Fatalf("fail to setup bmp server with error: %+v", err)
+	}
+	srv.Start()
+	rStats := getRouterStats("127.0.0.1")
+	expect := atomic.LoadUint64(&rStats.bytes) + uint64(count*len(initiation))
+	client, err := net.Dial("tcp", srv.(*bmpServer).incoming[0].Addr().String())
+	if err != nil {
+		t.Fatalf("fail to connect with error: %+v", err)
+	}
+	for i := 0; i < count; i++ {
+		if _, err := client.Write(initiation); err != nil {
+			t.Fatalf("fail to send initiation message with error: %+v", err)
+		}
+	}
+	// Waiting for all messages to be read from the session
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadUint64(&rStats.bytes) < expect && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	return srv, client
+}
+
+func TestShutdownDrain(t *testing.T) {
+	p := &slowPublisher{delay: time.Millisecond}
+	count := 50
+	srv, client := startSession(t, p, count)
+	defer client.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		t.Fatalf("fail to shutdown with error: %+v", err)
+	}
+	if published := atomic.LoadUint64(&p.published); published != uint64(count) {
+		t.Fatalf("expected %d messages published before shutdown returns but got %d", count, published)
+	}
+	if _, err := net.Dial("tcp", srv.(*bmpServer).incoming[0].Addr().String()); err == nil {
+		t.Fatalf("expected listener to be closed after shutdown")
+	}
+	// Session is closed by the server
+	client.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := client.Read(make([]byte, 1)); err != io.EOF {
+		t.Fatalf("expected session to be closed by the server, error: %+v", err)
+	}
+	// Stop after Shutdown does nothing
+	srv.Stop()
+}
+
+func TestShutdownDeadline(t *testing.T) {
+	p := &slowPublisher{release: make(chan struct{})}
+	defer close(p.release)
+	srv, client := startSession(t, p, 10)
+	defer client.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := srv.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected shutdown to fail with deadline exceeded but got: %+v", err)
+	}
+}
@@ -0,0 +1,246 @@
+package gobmpsrv
+
+import (
+	"expvar"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sbezverk/gobmp/pkg/bmp"
+	"github.com/sbezverk/gobmp/pkg/message"
+)
+
+// sessionStats keeps queue statistics of a BMP session, the statistics are exposed
+// through expvar under "gobmp_sessions" key.
+type sessionStats struct {
+	parserQueue   chan []byte
+	producerQueue chan bmp.Message
+	producer      message.Producer
+	// rawQueue is nil when raw BMP messages are not published
+	rawQueue chan []byte
+	// label identifies the listener which accepted the session
+	label string
+	start time.Time
+	// messages is the number of BMP messages received over the session by message type
+	messages [bmp.RouteMirrorMsg + 1]uint64
+	// stalls is the number of times the read loop was blocked by a full queue
+	stalls uint64
+	// stallTime is the total time in nanoseconds the read loop was blocked by a full queue
+	stallTime int64
+}
+
+// sessionStatsView defines the format of a session statistics exposed for monitoring
+type sessionStatsView struct {
+	Label               string  `json:"label,omitempty"`
+	ParserQueueLength   int     `json:"parser_queue_length"`
+	ParserQueueDepth    int     `json:"parser_queue_depth"`
+	ProducerQueueLength int     `json:"producer_queue_length"`
+	ProducerQueueDepth  int     `json:"producer_queue_depth"`
+	WorkersQueueLength  int     `json:"workers_queue_length"`
+	RawQueueLength      int     `json:"raw_queue_length"`
+	Stalls              uint64  `json:"stalls"`
+	StallSeconds        float64 `json:"stall_seconds"`
+}
+
+func (s *sessionStats) addStall(d time.Duration) {
+	atomic.AddUint64(&s.stalls, 1)
+	atomic.AddInt64(&s.stallTime, int64(d))
+}
+
+func (s *sessionStats) addMessage(msgType uint8) {
+	if int(msgType) < len(s.messages) {
+		atomic.AddUint64(&s.messages[msgType], 1)
+	}
+}
+
+func (s *sessionStats) view() sessionStatsView {
+	return sessionStatsView{
+		Label:               s.label,
+		ParserQueueLength:   len(s.parserQueue),
+		ParserQueueDepth:    cap(s.parserQueue),
+		ProducerQueueLength: len(s.producerQueue),
+		ProducerQueueDepth:  cap(s.producerQueue),
+		WorkersQueueLength:  s.producer.QueueLength(),
+		RawQueueLength:      len(s.rawQueue),
+		Stalls:              atomic.LoadUint64(&s.stalls),
+		StallSeconds:        time.Duration(atomic.LoadInt64(&s.stallTime)).Seconds(),
+	}
+}
+
+var sessions = struct {
+	sync.Mutex
+	m map[string]*sessionStats
+}{
+	m: make(map[string]*sessionStats),
+}
+
+func addSession(key string, s *sessionStats) {
+	sessions.Lock()
+	defer sessions.Unlock()
+	sessions.m[key] = s
+}
+
+func deleteSession(key string) {
+	sessions.Lock()
+	defer sessions.Unlock()
+	delete(sessions.m, key)
+}
+
+func sessionsView() interface{} {
+	sessions.Lock()
+	defer sessions.Unlock()
+	v := make(map[string]sessionStatsView, len(sessions.m))
+	for key, s := range sessions.m {
+		v[key] = s.view()
+	}
+
+	return v
+}
+
+// routerStats keeps the counters of BMP messages received from a router over all its sessions,
+// the counters are exposed through expvar under "gobmp_routers" key.
+type routerStats struct {
+	bytes        uint64
+	messages     [bmp.RouteMirrorMsg + 1]uint64
+	decodeErrors [bmp.RouteMirrorMsg + 1]uint64
+	// framingErrors is the number of times invalid BMP Common Header was found in the stream
+	framingErrors uint64
+	// resyncs is the number of times the stream was resynchronized after a framing error
+	resyncs uint64
+	// skippedBytes is the number of bytes discarded while resynchronizing the stream
+	skippedBytes uint64
+	// closedSessions is the number of sessions closed because of a framing error
+	closedSessions uint64
+}
+
+// routerStatsView defines the format of a router statistics exposed for monitoring
+type routerStatsView struct {
+	Bytes          uint64            `json:"bytes"`
+	Messages       map[string]uint64 `json:"messages"`
+	DecodeErrors   map[string]uint64 `json:"decode_errors"`
+	FramingErrors  uint64            `json:"framing_errors"`
+	Resyncs        uint64            `json:"resyncs"`
+	SkippedBytes   uint64            `json:"skipped_bytes"`
+	ClosedSessions uint64            `json:"closed_sessions"`
+}
+
+var messageTypeNames = [...]string{
+	bmp.RouteMonitorMsg: "route_monitor",
+	bmp.StatsReportMsg:  "stats_report",
+	bmp.PeerDownMsg:     "peer_down",
+	bmp.PeerUpMsg:       "peer_up",
+	bmp.InitiationMsg:   "initiation",
+	bmp.TerminationMsg:  "termination",
+	bmp.RouteMirrorMsg:  "route_mirror",
+}
+
+func (s *routerStats) addMessage(msgType uint8, length int) {
+	atomic.AddUint64(&s.bytes, uint64(length))
+	if int(msgType) < len(s.messages) {
+		atomic.AddUint64(&s.messages[msgType], 1)
+	}
+}
+
+func (s *routerStats) addDecodeError(msgType uint8, _ error) {
+	if int(msgType) < len(s.decodeErrors) {
+		atomic.AddUint64(&s.decodeErrors[msgType], 1)
+	}
+}
+
+func (s *routerStats) addFramingError() {
+	atomic.AddUint64(&s.framingErrors, 1)
+}
+
+func (s *routerStats) addResync(skipped int) {
+	atomic.AddUint64(&s.resyncs, 1)
+	atomic.AddUint64(&s.skippedBytes, uint64(skipped))
+}
+
+func (s *routerStats) addClosedSession() {
+	atomic.AddUint64(&s.closedSessions, 1)
+}
+
+func (s *routerStats) view() routerStatsView {
+	v := routerStatsView{
+		Bytes:          atomic.LoadUint64(&s.bytes),
+		Messages:       make(map[string]uint64, len(s.messages)),
+		DecodeErrors:   make(map[string]uint64, len(s.decodeErrors)),
+		FramingErrors:  atomic.LoadUint64(&s.framingErrors),
+		Resyncs:        atomic.LoadUint64(&s.resyncs),
+		SkippedBytes:   atomic.LoadUint64(&s.skippedBytes),
+		ClosedSessions: atomic.LoadUint64(&s.closedSessions),
+	}
+	for t, name := range messageTypeNames {
+		v.Messages[name] = atomic.LoadUint64(&s.messages[t])
+		v.DecodeErrors[name] = atomic.LoadUint64(&s.decodeErrors[t])
+	}
+
+	return v
+}
+
+var routers = struct {
+	sync.Mutex
+	m map[string]*routerStats
+}{
+	m: make(map[string]*routerStats),
+}
+
+// getRouterStats returns the statistics of a router, the statistics are kept after the router's
+// session is closed and accumulated over the router's sessions.
+func getRouterStats(addr string) *routerStats {
+	routers.Lock()
+	defer routers.Unlock()
+	s, ok := routers.m[addr]
+	if !ok {
+		s = &routerStats{}
+		routers.m[addr] = s
+	}
+
+	return s
+}
+
+func routersView() interface{} {
+	routers.Lock()
+	defer routers.Unlock()
+	v := make(map[string]routerStatsView, len(routers.m))
+	for addr, s := range routers.m {
+		v[addr] = s.view()
+	}
+
+	return v
+}
+
+var targets = struct {
+	sync.Mutex
+	m map[string]*target
+}{
+	m: make(map[string]*target),
+}
+
+func addTarget(t *target) {
+	targets.Lock()
+	defer targets.Unlock()
+	targets.m[t.view().Address] = t
+}
+
+// TargetsStatus returns the connection status of all routers the collector dials in active mode
+func TargetsStatus() []TargetStatus {
+	targets.Lock()
+	defer targets.Unlock()
+	v := make([]TargetStatus, 0, len(targets.m))
+	for _, t := range targets.m {
+		v = append(v, t.view())
+	}
+	sort.Slice(v, func(i, j int) bool { return v[i].Address < v[j].Address })
+
+	return v
+}
+
+func init() {
+	expvar.Publish("gobmp_sessions", expvar.Func(sessionsView))
+	expvar.Publish("gobmp_routers", expvar.Func(routersView))
+	expvar.Publish("gobmp_rejected", expvar.Func(rejectedView))
+	expvar.Publish("gobmp_intercept", expvar.Func(destinationsView))
+	expvar.Publish("gobmp_targets", expvar.Func(func() interface{} { return TargetsStatus() }))
+}
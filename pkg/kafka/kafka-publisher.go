@@ -11,6 +11,7 @@ import (
 
 	"github.com/golang/glog"
 	"github.com/sbezverk/gobmp/pkg/bmp"
+	"github.com/sbezverk/gobmp/pkg/metrics"
 	"github.com/sbezverk/gobmp/pkg/pub"
 	kafka "github.com/segmentio/kafka-go"
 )
@@ -25,6 +26,10 @@ const (
 	lsPrefixMessageTopic  = "gobmp.parsed.ls_prefix"
 	lsSRv6SIDMessageTopic = "gobmp.parsed.ls_srv6_sid"
 	evpnMessageTopic      = "gobmp.parsed.evpn"
+	statsMessageTopic     = "gobmp.parsed.statistics"
+	mirrorMessageTopic    = "gobmp.parsed.route_mirror"
+	routerTopic           = "gobmp.parsed.router"
+	bmpRawTopic           = "gobmp.bmp_raw"
 )
 
 var (
@@ -39,10 +44,19 @@ var (
 		lsPrefixMessageTopic,
 		lsSRv6SIDMessageTopic,
 		evpnMessageTopic,
+		statsMessageTopic,
+		mirrorMessageTopic,
+		routerTopic,
+		bmpRawTopic,
 	}
 )
 
 // topicConnection defines per topic connection and connection related information
+var (
+	publishDuration = metrics.NewHistogramVec("gobmp_publish_duration_seconds", "Time taken to publish a message to Kafka topic.", metrics.DefBuckets, "topic")
+	publishFailures = metrics.NewCounterVec("gobmp_publish_failures_total", "Number of messages failed to publish to Kafka topic.", "topic")
+)
+
 type topicConnection struct {
 	kafkaConn  *kafka.Conn
 	partitions []kafka.Partition
@@ -52,6 +66,7 @@ type publisher struct {
 	sync.Mutex
 	// topics is map of topics' connections, keyed by the topic name
 	topics map[string]*topicConnection
+	closed bool
 }
 
 func (p *publisher) PublishMessage(t int, key []byte, msg []byte) error {
@@ -72,6 +87,14 @@ func (p *publisher) PublishMessage(t int, key []byte, msg []byte) error {
 		return p.produceMessage(lsSRv6SIDMessageTopic, key, msg)
 	case bmp.EVPNMsg:
 		return p.produceMessage(evpnMessageTopic, key, msg)
+	case bmp.StatsMsg:
+		return p.produceMessage(statsMessageTopic, key, msg)
+	case bmp.MirrorMsg:
+		return p.produceMessage(mirrorMessageTopic, key, msg)
+	case bmp.RouterEventMsg:
+		return p.produceMessage(routerTopic, key, msg)
+	case bmp.BMPRawMsg:
+		return p.produceMessage(bmpRawTopic, key, msg)
 	}
 
 	return fmt.Errorf("not implemented")
@@ -80,14 +103,19 @@ func (p *publisher) PublishMessage(t int, key []byte, msg []byte) error {
 func (p *publisher) produceMessage(topic string, key []byte, msg []byte) error {
 	p.Lock()
 	defer p.Unlock()
+	if p.closed {
+		return fmt.Errorf("publisher is closed")
+	}
 	t, ok := p.topics[topic]
 	if !ok {
 		return fmt.Errorf("topic %s in not initialized", topic)
 	}
+	start := time.Now()
 	leaderAddr := fmt.Sprintf("%s:%d", t.partitions[0].Leader.Host, t.partitions[0].Leader.Port)
 	kafkaConn, err := kafka.DefaultDialer.DialLeader(context.TODO(), "tcp", leaderAddr, t.partitions[0].Topic, t.partitions[0].Leader.ID)
 	if err != nil {
 		glog.Errorf("Failed to connect to the topic %s's partition leader with error: %+v", topic, err)
+		publishFailures.With(topic).Inc()
 		return err
 	}
 	n, err := kafkaConn.WriteMessages(kafka.Message{
@@ -97,13 +125,38 @@ func (p *publisher) produceMessage(topic string, key []byte, msg []byte) error {
 	})
 	if err != nil {
 		glog.Errorf("Failed to write test message to the topic %s with error: %+v", topic, err)
+		publishFailures.With(topic).Inc()
 		return err
 	}
+	publishDuration.With(topic).Observe(time.Since(start).Seconds())
 	glog.V(5).Infof("Successfully wrote %d bytes to Kafka topic %s", n, topic)
 
 	return nil
 }
 
+// Close waits for the message being written to complete and closes topics' connections,
+// messages are written synchronously, so no messages are left to flush.
+func (p *publisher) Close() error {
+	p.Lock()
+	defer p.Unlock()
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+	var err error
+	for name, t := range p.topics {
+		if t.kafkaConn == nil {
+			continue
+		}
+		if cerr := t.kafkaConn.Close(); cerr != nil {
+			glog.Errorf("Failed to close connection to the topic %s with error: %+v", name, cerr)
+			err = cerr
+		}
+	}
+
+	return err
+}
+
 // NewKafkaPublisher instantiates a new instance of a Kafka publisher
 func NewKafkaPublisher(kafkaSrv string) (pub.Publisher, error) {
 	glog.Infof("Initializing Kafka producer client")
@@ -2,14 +2,20 @@ package l3vpn
 
 import (
 	"bytes"
+	"encoding/binary"
 
 	"github.com/golang/glog"
 	"github.com/sbezverk/gobmp/pkg/base"
 	"github.com/sbezverk/gobmp/pkg/tools"
 )
 
+// decoder builds errors of decoding L3VPN NLRI
+const decoder tools.Decoder = "l3vpn"
+
 // NLRI defines L3 VPN NLRI object
 type NLRI struct {
+	// PathID is the Path Identifier of the prefix received from ADD-PATH peer (RFC 7911)
+	PathID uint32
 	Length uint8
 	Labels []*base.Label
 	RD     *base.RD
@@ -28,10 +34,22 @@ func (n *NLRI) GetL3VPNPrefix() []byte {
 	return p
 }
 
-// UnmarshalL3VPNNLRI instantiates a L3 VPN NLRI object
-func UnmarshalL3VPNNLRI(b []byte) (*NLRI, error) {
+// UnmarshalL3VPNNLRI instantiates a L3 VPN NLRI object, when pathID is true, NLRI is prefixed
+// by 4 bytes Path Identifier.
+func UnmarshalL3VPNNLRI(b []byte, pathID bool) (*NLRI, error) {
 	glog.V(5).Infof("L3VPN NLRI Raw: %s", tools.MessageHex(b))
 	n := NLRI{}
+	if pathID {
+		if err := decoder.CheckLength("l3vpn nlri path id", b, 4); err != nil {
+			return nil, err
+		}
+		n.PathID = binary.BigEndian.Uint32(b[:4])
+		b = b[4:]
+	}
+	// Length and Compatibility field or at least one label
+	if err := decoder.CheckLength("l3vpn nlri", b, 4); err != nil {
+		return nil, err
+	}
 	p := 0
 	// Getting length of NLRI in bytes
 	n.Length = uint8(b[p] / 8)
@@ -45,7 +63,7 @@ func UnmarshalL3VPNNLRI(b []byte) (*NLRI, error) {
 		// Otherwise getting labels
 		n.Labels = make([]*base.Label, 0)
 		bos := false
-		for !bos && p < len(b) {
+		for !bos && p+3 <= len(b) {
 			l, err := base.MakeLabel(b[p : p+3])
 			if err != nil {
 				return nil, err
@@ -55,6 +73,9 @@ func UnmarshalL3VPNNLRI(b []byte) (*NLRI, error) {
 			bos = l.BoS
 		}
 	}
+	if err := decoder.CheckLength("l3vpn nlri", b[p:], 8); err != nil {
+		return nil, err
+	}
 	rd, err := base.MakeRD(b[p : p+8])
 	if err != nil {
 		return nil, err
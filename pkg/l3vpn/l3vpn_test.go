@@ -12,6 +12,7 @@ func TestUnmarshalL3VPNNLRI(t *testing.T) {
 	tests := []struct {
 		name   string
 		input  []byte
+		pathID bool
 		expect *NLRI
 		fail   bool
 	}{
@@ -66,10 +67,37 @@ func TestUnmarshalL3VPNNLRI(t *testing.T) {
 			},
 			fail: false,
 		},
+		{
+			name:   "nlri with path id",
+			input:  []byte{0, 0, 0, 7, 120, 5, 220, 49, 0, 0, 2, 65, 0, 0, 253, 235, 3, 3, 3, 3},
+			pathID: true,
+			expect: &NLRI{
+				PathID: 7,
+				Length: 15,
+				Labels: []*base.Label{
+					{
+						Value: 24003,
+						Exp:   0,
+						BoS:   true,
+					},
+				},
+				RD: &base.RD{
+					Type:  0,
+					Value: []byte{2, 65, 0, 0, 253, 235},
+				},
+				Prefix: []byte{3, 3, 3, 3},
+			},
+		},
+		{
+			name:   "truncated path id",
+			input:  []byte{0, 0, 7},
+			pathID: true,
+			fail:   true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := UnmarshalL3VPNNLRI(tt.input)
+			got, err := UnmarshalL3VPNNLRI(tt.input, tt.pathID)
 			if err != nil && !tt.fail {
 				t.Fatalf("expected to succeed but failed with error: %+v", err)
 			}
@@ -10,6 +10,9 @@ import (
 	"github.com/sbezverk/gobmp/pkg/tools"
 )
 
+// decoder builds errors of decoding BGP-LS NLRI
+const decoder tools.Decoder = "ls"
+
 // NLRI71 defines Link State NLRI object for SAFI 71
 // https://tools.ietf.org/html/rfc7752#section-3.2
 type NLRI71 struct {
@@ -144,6 +147,9 @@ func (ls *NLRI71) GetSubType() int {
 // UnmarshalLSNLRI71 builds Link State NLRI object ofor SAFI 71
 func UnmarshalLSNLRI71(b []byte) (*NLRI71, error) {
 	glog.V(6).Infof("LSNLRI71 Raw: %s", tools.MessageHex(b))
+	if err := decoder.CheckLength("ls nlri", b, 4); err != nil {
+		return nil, err
+	}
 	ls := NLRI71{}
 	p := 0
 	ls.Type = binary.BigEndian.Uint16(b[p : p+2])
@@ -162,7 +168,10 @@ func UnmarshalLSNLRI71(b []byte) (*NLRI71, error) {
 	case 6:
 		// SRv6 SID NLRI
 	default:
-		return nil, fmt.Errorf("invalid LS NLRI type %d", ls.Type)
+		return nil, decoder.Malformed("ls nlri", "invalid type %d", ls.Type)
+	}
+	if err := decoder.CheckLength("ls nlri", b[p:], int(ls.Length)); err != nil {
+		return nil, err
 	}
 	ls.LS = b[p : p+int(ls.Length)]
 
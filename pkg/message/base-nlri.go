@@ -20,19 +20,32 @@ func (p *producer) nlri(op int, ph *bmp.PerPeerHeader, update *bgp.Update) ([]Un
 	default:
 		return nil, fmt.Errorf("unknown operation %d", op)
 	}
+	routes := update.NLRI
+	if op == DelPrefix {
+		routes = update.WithdrawnRoutes
+	}
 	prfxs := make([]UnicastPrefix, 0)
-	for _, pr := range update.NLRI {
+	for _, pr := range routes {
 		prfx := UnicastPrefix{
-			Action:       operation,
-			RouterHash:   p.speakerHash,
-			RouterIP:     p.speakerIP,
-			BaseAttrHash: update.GetBaseAttrHash(),
-			PeerHash:     ph.GetPeerHash(),
-			PeerASN:      ph.PeerAS,
-			Timestamp:    ph.PeerTimestamp,
-			PrefixLen:    int32(pr.Length),
-			IsAtomicAgg:  update.GetAttrAtomicAggregate(),
-			Aggregator:   fmt.Sprintf("%v", update.GetAttrAS4Aggregator()),
+			Action:           operation,
+			RouterHash:       p.speakerHash,
+			RouterIP:         p.speakerIP,
+			RouterName:       p.routerName,
+			Label:            p.label,
+			SessionAddr:      p.sessionAddr,
+			BaseAttrHash:     update.GetBaseAttrHash(),
+			PeerHash:         ph.GetPeerHash(),
+			PeerASN:          ph.PeerAS,
+			Timestamp:        ph.PeerTimestamp,
+			IsLocRIB:         ph.IsLocRIB(),
+			IsLocRIBFiltered: ph.IsLocRIBFiltered(),
+			IsPrepolicy:      ph.IsPrepolicy(),
+			IsAdjRIBIn:       ph.IsAdjRIBIn(),
+			IsAdjRIBOut:      ph.IsAdjRIBOut(),
+			PrefixLen:        int32(pr.Length),
+			PathID:           int32(pr.PathID),
+			IsAtomicAgg:      update.GetAttrAtomicAggregate(),
+			Aggregator:       fmt.Sprintf("%v", update.GetAttrAS4Aggregator()),
 		}
 		if oid := update.GetAttrOriginatorID(); len(oid) != 0 {
 			prfx.OriginatorID = net.IP(update.GetAttrOriginatorID()).To4().String()
@@ -40,11 +53,12 @@ func (p *producer) nlri(op int, ph *bmp.PerPeerHeader, update *bgp.Update) ([]Un
 		if o := update.GetAttrOrigin(); o != nil {
 			prfx.Origin = *o
 		}
-		prfx.ASPath = update.GetAttrASPath(p.as4Capable)
-		prfx.ASPathCount = int32(len(prfx.ASPath))
-		if ases := update.GetAttrASPath(p.as4Capable); len(ases) != 0 {
-			// Last element in AS_PATH would be the AS of the origin
-			prfx.OriginAS = fmt.Sprintf("%d", ases[len(ases)-1])
+		path := update.GetASPath(ph.Is4BytesASPath())
+		prfx.ASPath = path.Flatten()
+		prfx.ASPathSegments = path
+		prfx.ASPathCount = int32(path.Length())
+		if origin, ok := path.OriginAS(); ok {
+			prfx.OriginAS = fmt.Sprintf("%d", origin)
 		}
 		if med := update.GetAttrMED(); med != nil {
 			prfx.MED = *med
@@ -52,25 +66,28 @@ func (p *producer) nlri(op int, ph *bmp.PerPeerHeader, update *bgp.Update) ([]Un
 		if lp := update.GetAttrLocalPref(); lp != nil {
 			prfx.LocalPref = *lp
 		}
+		prfx.ExtCommunityList = update.GetAttrExtCommunityString()
+		prfx.ExtCommunities = extCommunities(update)
+		prfx.LargeCommunityList = update.GetAttrLargeCommunityString()
 		if ph.FlagV {
-			// IPv6 specific conversions
-			prfx.IsIPv4 = false
 			prfx.PeerIP = net.IP(ph.PeerAddress).To16().String()
-			prfx.Nexthop = net.IP(update.GetAttrNextHop()).To16().String()
-			prfx.IsNexthopIPv4 = false
-			a := make([]byte, 16)
-			copy(a, pr.Prefix)
-			prfx.Prefix = net.IP(a).To16().String()
 		} else {
-			// IPv4 specific conversions
-			prfx.IsIPv4 = true
 			prfx.PeerIP = net.IP(ph.PeerAddress[12:]).To4().String()
-			prfx.Nexthop = net.IP(update.GetAttrNextHop()).To4().String()
+		}
+		// NEXT_HOP attribute is independent of the peer's address family
+		switch nh := update.GetAttrNextHop(); len(nh) {
+		case net.IPv4len:
+			prfx.Nexthop = net.IP(nh).To4().String()
 			prfx.IsNexthopIPv4 = true
-			a := make([]byte, 4)
-			copy(a, pr.Prefix)
-			prfx.Prefix = net.IP(a).To4().String()
+		case net.IPv6len:
+			prfx.Nexthop = net.IP(nh).To16().String()
 		}
+		// NLRI and Withdrawn Routes fields carry only IPv4 prefixes, rfc4271 section 4.3
+		prfx.IsIPv4 = true
+		a := make([]byte, 4)
+		copy(a, pr.Prefix)
+		prfx.Prefix = net.IP(a).To4().String()
+		prfx.Hash = prefixHash(ph, prfx.PathID, prfx.Prefix, fmt.Sprintf("%d", prfx.PrefixLen))
 		prfxs = append(prfxs, prfx)
 	}
 
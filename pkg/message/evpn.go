@@ -29,16 +29,24 @@ func (p *producer) evpn(nlri bgp.MPNLRI, op int, ph *bmp.PerPeerHeader, update *
 	}
 	for _, e := range evpn.Route {
 		prfx := EVPNPrefix{
-			Action:       operation,
-			RouterHash:   p.speakerHash,
-			RouterIP:     p.speakerIP,
-			BaseAttrHash: update.GetBaseAttrHash(),
-			PeerHash:     ph.GetPeerHash(),
-			PeerASN:      ph.PeerAS,
-			Timestamp:    ph.PeerTimestamp,
-			Nexthop:      nlri.GetNextHop(),
-			IsAtomicAgg:  update.GetAttrAtomicAggregate(),
-			Aggregator:   fmt.Sprintf("%v", update.GetAttrAS4Aggregator()),
+			Action:           operation,
+			RouterHash:       p.speakerHash,
+			RouterIP:         p.speakerIP,
+			RouterName:       p.routerName,
+			Label:            p.label,
+			SessionAddr:      p.sessionAddr,
+			BaseAttrHash:     update.GetBaseAttrHash(),
+			PeerHash:         ph.GetPeerHash(),
+			PeerASN:          ph.PeerAS,
+			Timestamp:        ph.PeerTimestamp,
+			IsLocRIB:         ph.IsLocRIB(),
+			IsLocRIBFiltered: ph.IsLocRIBFiltered(),
+			IsPrepolicy:      ph.IsPrepolicy(),
+			IsAdjRIBIn:       ph.IsAdjRIBIn(),
+			IsAdjRIBOut:      ph.IsAdjRIBOut(),
+			Nexthop:          nlri.GetNextHop(),
+			IsAtomicAgg:      update.GetAttrAtomicAggregate(),
+			Aggregator:       fmt.Sprintf("%v", update.GetAttrAS4Aggregator()),
 		}
 		if orid := update.GetAttrOriginatorID(); len(orid) != 0 {
 			prfx.OriginatorID = net.IP(update.GetAttrOriginatorID()).To4().String()
@@ -46,11 +54,12 @@ func (p *producer) evpn(nlri bgp.MPNLRI, op int, ph *bmp.PerPeerHeader, update *
 		if or := update.GetAttrOrigin(); or != nil {
 			prfx.Origin = *or
 		}
-		prfx.ASPath = update.GetAttrASPath(p.as4Capable)
-		prfx.ASPathCount = int32(len(prfx.ASPath))
-		if ases := update.GetAttrASPath(p.as4Capable); len(ases) != 0 {
-			// Last element in AS_PATH would be the AS of the origin
-			prfx.OriginAS = fmt.Sprintf("%d", ases[len(ases)-1])
+		path := update.GetASPath(ph.Is4BytesASPath())
+		prfx.ASPath = path.Flatten()
+		prfx.ASPathSegments = path
+		prfx.ASPathCount = int32(path.Length())
+		if origin, ok := path.OriginAS(); ok {
+			prfx.OriginAS = fmt.Sprintf("%d", origin)
 		}
 		if med := update.GetAttrMED(); med != nil {
 			prfx.MED = *med
@@ -69,15 +78,9 @@ func (p *producer) evpn(nlri bgp.MPNLRI, op int, ph *bmp.PerPeerHeader, update *
 			prfx.PeerIP = net.IP(ph.PeerAddress[12:]).To4().String()
 			prfx.IsNexthopIPv4 = true
 		}
-		exts, err := update.GetAttrExtCommunity()
-		if err == nil {
-			for i, ext := range exts {
-				prfx.ExtCommunityList += ext.String()
-				if i < len(exts)-1 {
-					prfx.ExtCommunityList += ", "
-				}
-			}
-		}
+		prfx.ExtCommunityList = update.GetAttrExtCommunityString()
+		prfx.ExtCommunities = extCommunities(update)
+		prfx.LargeCommunityList = update.GetAttrLargeCommunityString()
 		// Do not want to panic on nil pointer
 		if e != nil {
 			prfx.VPNRD = e.GetEVPNRD()
@@ -123,6 +126,9 @@ func (p *producer) evpn(nlri bgp.MPNLRI, op int, ph *bmp.PerPeerHeader, update *
 				}
 			}
 			prfx.Labels = e.GetEVPNLabel()
+			prfx.PathID = int32(e.PathID)
+			prfx.Hash = prefixHash(ph, prfx.PathID, fmt.Sprintf("%d", prfx.RouteType), prfx.VPNRD, prfx.ESI,
+				fmt.Sprintf("%x", prfx.EthTag), prfx.MAC, prfx.IPAddress)
 		}
 		prfxs = append(prfxs, prfx)
 	}
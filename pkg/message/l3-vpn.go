@@ -29,17 +29,26 @@ func (p *producer) l3vpn(nlri bgp.MPNLRI, op int, ph *bmp.PerPeerHeader, update
 	}
 
 	prfx := L3VPNPrefix{
-		Action:       operation,
-		RouterHash:   p.speakerHash,
-		RouterIP:     p.speakerIP,
-		BaseAttrHash: update.GetBaseAttrHash(),
-		PeerHash:     ph.GetPeerHash(),
-		PeerASN:      ph.PeerAS,
-		Timestamp:    ph.PeerTimestamp,
-		Prefix:       net.IP(nlril3vpn.GetL3VPNPrefix()).To4().String(),
-		Nexthop:      nlri.GetNextHop(),
+		Action:           operation,
+		RouterHash:       p.speakerHash,
+		RouterIP:         p.speakerIP,
+		RouterName:       p.routerName,
+		Label:            p.label,
+		SessionAddr:      p.sessionAddr,
+		BaseAttrHash:     update.GetBaseAttrHash(),
+		PeerHash:         ph.GetPeerHash(),
+		PeerASN:          ph.PeerAS,
+		Timestamp:        ph.PeerTimestamp,
+		IsLocRIB:         ph.IsLocRIB(),
+		IsLocRIBFiltered: ph.IsLocRIBFiltered(),
+		IsPrepolicy:      ph.IsPrepolicy(),
+		IsAdjRIBIn:       ph.IsAdjRIBIn(),
+		IsAdjRIBOut:      ph.IsAdjRIBOut(),
+		Prefix:           net.IP(nlril3vpn.GetL3VPNPrefix()).To4().String(),
+		Nexthop:          nlri.GetNextHop(),
 		// TODO, why 32 is hard coded here?????
 		PrefixLen:   32,
+		PathID:      int32(nlril3vpn.PathID),
 		IsAtomicAgg: update.GetAttrAtomicAggregate(),
 		Aggregator:  fmt.Sprintf("%v", update.GetAttrAS4Aggregator()),
 	}
@@ -49,11 +58,12 @@ func (p *producer) l3vpn(nlri bgp.MPNLRI, op int, ph *bmp.PerPeerHeader, update
 	if o := update.GetAttrOrigin(); o != nil {
 		prfx.Origin = *o
 	}
-	prfx.ASPath = update.GetAttrASPath(p.as4Capable)
-	prfx.ASPathCount = int32(len(prfx.ASPath))
-	if ases := update.GetAttrASPath(p.as4Capable); len(ases) != 0 {
-		// Last element in AS_PATH would be the AS of the origin
-		prfx.OriginAS = fmt.Sprintf("%d", ases[len(ases)-1])
+	path := update.GetASPath(ph.Is4BytesASPath())
+	prfx.ASPath = path.Flatten()
+	prfx.ASPathSegments = path
+	prfx.ASPathCount = int32(path.Length())
+	if origin, ok := path.OriginAS(); ok {
+		prfx.OriginAS = fmt.Sprintf("%d", origin)
 	}
 	if med := update.GetAttrMED(); med != nil {
 		prfx.MED = *med
@@ -76,17 +86,12 @@ func (p *producer) l3vpn(nlri bgp.MPNLRI, op int, ph *bmp.PerPeerHeader, update
 	for _, l := range nlril3vpn.Labels {
 		prfx.Labels = append(prfx.Labels, l.Value)
 	}
-	exts, err := update.GetAttrExtCommunity()
-	if err == nil {
-		for i, ext := range exts {
-			prfx.ExtCommunityList += ext.String()
-			if i < len(exts)-1 {
-				prfx.ExtCommunityList += ", "
-			}
-		}
-	}
+	prfx.ExtCommunityList = update.GetAttrExtCommunityString()
+	prfx.ExtCommunities = extCommunities(update)
+	prfx.LargeCommunityList = update.GetAttrLargeCommunityString()
 	prfx.VPNRD = nlril3vpn.RD.String()
 	prfx.VPNRDType = nlril3vpn.RD.Type
+	prfx.Hash = prefixHash(ph, prfx.PathID, prfx.VPNRD, prfx.Prefix, fmt.Sprintf("%d", prfx.PrefixLen))
 
 	return &prfx, nil
 }
@@ -23,13 +23,21 @@ func (p *producer) lsPrefix(nlri bgp.MPNLRI, op int, ph *bmp.PerPeerHeader, upda
 		return nil, fmt.Errorf("unknown operation %d", op)
 	}
 	msg := LSPrefix{
-		Action:       operation,
-		RouterHash:   p.speakerHash,
-		RouterIP:     p.speakerIP,
-		BaseAttrHash: update.GetBaseAttrHash(),
-		PeerHash:     ph.GetPeerHash(),
-		PeerASN:      ph.PeerAS,
-		Timestamp:    ph.PeerTimestamp,
+		Action:           operation,
+		RouterHash:       p.speakerHash,
+		RouterIP:         p.speakerIP,
+		RouterName:       p.routerName,
+		Label:            p.label,
+		SessionAddr:      p.sessionAddr,
+		BaseAttrHash:     update.GetBaseAttrHash(),
+		PeerHash:         ph.GetPeerHash(),
+		PeerASN:          ph.PeerAS,
+		Timestamp:        ph.PeerTimestamp,
+		IsLocRIB:         ph.IsLocRIB(),
+		IsLocRIBFiltered: ph.IsLocRIBFiltered(),
+		IsPrepolicy:      ph.IsPrepolicy(),
+		IsAdjRIBIn:       ph.IsAdjRIBIn(),
+		IsAdjRIBOut:      ph.IsAdjRIBOut(),
 	}
 	msg.Nexthop = nlri.GetNextHop()
 	msg.PeerIP = ph.GetPeerAddrString()
@@ -65,7 +73,7 @@ func (p *producer) lsPrefix(nlri bgp.MPNLRI, op int, ph *bmp.PerPeerHeader, upda
 			msg.LSPrefixSID = ps
 		}
 	}
-	msg.ASPath = update.GetAttrASPath(p.as4Capable)
+	msg.ASPath = update.GetAttrASPath(ph.Is4BytesASPath())
 	if med := update.GetAttrMED(); med != nil {
 		msg.MED = *med
 	}
@@ -0,0 +1,157 @@
+package message
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+
+	"github.com/golang/glog"
+	"github.com/sbezverk/gobmp/pkg/base"
+	"github.com/sbezverk/gobmp/pkg/bgp"
+	"github.com/sbezverk/gobmp/pkg/bmp"
+	"github.com/sbezverk/gobmp/pkg/unicast"
+)
+
+func (p *producer) produceRouteMirrorMessage(msg bmp.Message) {
+	if msg.PeerHeader == nil {
+		glog.Errorf("perPeerHeader is missing, cannot construct RouteMirror message")
+		return
+	}
+	mirrorMsg, ok := msg.Payload.(*bmp.RouteMirror)
+	if !ok {
+		glog.Errorf("got invalid Payload type in bmp.Message")
+		return
+	}
+	m := RouteMirror{
+		Action:       "mirror",
+		RouterIP:     p.speakerIP,
+		RouterName:   p.routerName,
+		Label:        p.label,
+		SessionAddr:  p.sessionAddr,
+		RouterHash:   p.speakerHash,
+		PeerHash:     msg.PeerHeader.GetPeerHash(),
+		PeerIP:       msg.PeerHeader.GetPeerAddrString(),
+		PeerASN:      msg.PeerHeader.PeerAS,
+		PeerRD:       msg.PeerHeader.PeerDistinguisher.String(),
+		Timestamp:    msg.PeerHeader.PeerTimestamp,
+		IsIPv4:       !msg.PeerHeader.FlagV,
+		ErroredPDU:   mirrorMsg.ErroredPDU,
+		MessagesLost: mirrorMsg.MessagesLost,
+	}
+	if len(mirrorMsg.BGPMessage) == 0 {
+		// No mirrored PDU, the message is only an event signalling that messages were lost
+		m.Action = "lost"
+	} else {
+		m.BGPMessageType = mirrorMsg.GetBGPMessageType()
+		m.BGPMessage = fmt.Sprintf("%x", mirrorMsg.BGPMessage)
+		if m.BGPMessageType == 2 {
+			p.mirroredUpdate(&m, msg.PeerHeader, mirrorMsg)
+		}
+	}
+	if err := p.marshalAndPublish(&m, bmp.MirrorMsg, []byte(m.RouterHash), false); err != nil {
+		glog.Errorf("failed to process RouteMirror message with error: %+v", err)
+		return
+	}
+
+	glog.V(5).Infof("succeeded to push RouteMirror message to kafka")
+}
+
+// mirroredUpdate decodes mirrored BGP Update and populates RouteMirror message with its content,
+// mirrored updates are often malformed, a failure to decode is reported in the message instead of dropping it.
+func (p *producer) mirroredUpdate(m *RouteMirror, ph *bmp.PerPeerHeader, rm *bmp.RouteMirror) {
+	update, err := rm.GetUpdate()
+	if err != nil {
+		m.DecodeError = err.Error()
+		return
+	}
+	m.Attributes = update.GetAllAttributeID()
+	if o := update.GetAttrOrigin(); o != nil {
+		m.Origin = *o
+	}
+	// AS_PATH format of the mirrored PDU is signaled by A flag of Per Peer Header, the same as for Route Monitoring
+	m.ASPath = update.GetAttrASPath(ph.Is4BytesASPath())
+	if nh := update.GetAttrNextHop(); len(nh) != 0 {
+		m.Nexthop = net.IP(nh).String()
+	}
+	if med := update.GetAttrMED(); med != nil {
+		m.MED = *med
+	}
+	if lp := update.GetAttrLocalPref(); lp != nil {
+		m.LocalPref = *lp
+	}
+	m.CommunityList = update.GetAttrCommunityString()
+	m.ExtCommunityList = update.GetAttrExtCommunityString()
+	for _, r := range update.WithdrawnRoutes {
+		m.WithdrawnRoutes = append(m.WithdrawnRoutes, routeString(r))
+	}
+	for _, r := range update.NLRI {
+		m.NLRI = append(m.NLRI, routeString(r))
+	}
+	for _, attr := range update.PathAttributes {
+		if attr.AttributeType == 14 || attr.AttributeType == 15 {
+			m.MPNLRI = append(m.MPNLRI, mirroredMPNLRI(attr, update.AddPath))
+		}
+	}
+}
+
+// mirroredMPNLRI decodes MP_REACH_NLRI or MP_UNREACH_NLRI attribute of mirrored BGP Update
+func mirroredMPNLRI(attr bgp.PathAttribute, addPath map[int]bool) MirroredMPNLRI {
+	mp := MirroredMPNLRI{
+		Action:    "add",
+		Attribute: fmt.Sprintf("%x", attr.Attribute),
+	}
+	if len(attr.Attribute) >= 3 {
+		mp.AFI = binary.BigEndian.Uint16(attr.Attribute[0:2])
+		mp.SAFI = attr.Attribute[2]
+	}
+	var nlri bgp.MPNLRI
+	var err error
+	if attr.AttributeType == 15 {
+		mp.Action = "del"
+		nlri, err = bgp.UnmarshalMPUnReachNLRI(attr.Attribute, addPath)
+	} else {
+		nlri, err = bgp.UnmarshalMPReachNLRI(attr.Attribute, addPath)
+	}
+	if err != nil {
+		mp.DecodeError = err.Error()
+		return mp
+	}
+	mp.Nexthop = nlri.GetNextHop()
+	var u *unicast.MPUnicastNLRI
+	switch mp.SAFI {
+	case 1:
+		u, err = nlri.GetNLRIUnicast()
+	case 4:
+		u, err = nlri.GetNLRILU()
+	default:
+		return mp
+	}
+	if err != nil {
+		mp.DecodeError = err.Error()
+		return mp
+	}
+	for _, pr := range u.NLRI {
+		mp.Routes = append(mp.Routes, mpRouteString(pr, nlri.IsIPv6NLRI()))
+	}
+
+	return mp
+}
+
+// mpRouteString returns prefix/length representation of MP unicast route
+func mpRouteString(pr unicast.MPUnicastPrefix, ipv6 bool) string {
+	if !ipv6 {
+		return routeString(base.Route{Length: pr.Length, Prefix: pr.Prefix})
+	}
+	a := make([]byte, 16)
+	copy(a, pr.Prefix)
+
+	return fmt.Sprintf("%s/%d", net.IP(a).To16().String(), pr.Length)
+}
+
+// routeString returns prefix/length representation of original BGP's IPv4 route
+func routeString(r base.Route) string {
+	a := make([]byte, 4)
+	copy(a, r.Prefix)
+
+	return fmt.Sprintf("%s/%d", net.IP(a).To4().String(), r.Length)
+}
@@ -41,16 +41,25 @@ func (p *producer) unicast(nlri bgp.MPNLRI, op int, ph *bmp.PerPeerHeader, updat
 	}
 	for _, e := range u.NLRI {
 		prfx := UnicastPrefix{
-			Action:       operation,
-			RouterHash:   p.speakerHash,
-			RouterIP:     p.speakerIP,
-			BaseAttrHash: update.GetBaseAttrHash(),
-			PeerHash:     ph.GetPeerHash(),
-			PeerASN:      ph.PeerAS,
-			Timestamp:    ph.PeerTimestamp,
-			PrefixLen:    int32(e.Length),
-			IsAtomicAgg:  update.GetAttrAtomicAggregate(),
-			Aggregator:   fmt.Sprintf("%v", update.GetAttrAS4Aggregator()),
+			Action:           operation,
+			RouterHash:       p.speakerHash,
+			RouterIP:         p.speakerIP,
+			RouterName:       p.routerName,
+			Label:            p.label,
+			SessionAddr:      p.sessionAddr,
+			BaseAttrHash:     update.GetBaseAttrHash(),
+			PeerHash:         ph.GetPeerHash(),
+			PeerASN:          ph.PeerAS,
+			Timestamp:        ph.PeerTimestamp,
+			IsLocRIB:         ph.IsLocRIB(),
+			IsLocRIBFiltered: ph.IsLocRIBFiltered(),
+			IsPrepolicy:      ph.IsPrepolicy(),
+			IsAdjRIBIn:       ph.IsAdjRIBIn(),
+			IsAdjRIBOut:      ph.IsAdjRIBOut(),
+			PrefixLen:        int32(e.Length),
+			PathID:           int32(e.PathID),
+			IsAtomicAgg:      update.GetAttrAtomicAggregate(),
+			Aggregator:       fmt.Sprintf("%v", update.GetAttrAS4Aggregator()),
 		}
 		if oid := update.GetAttrOriginatorID(); len(oid) != 0 {
 			prfx.OriginatorID = net.IP(update.GetAttrOriginatorID()).To4().String()
@@ -58,11 +67,12 @@ func (p *producer) unicast(nlri bgp.MPNLRI, op int, ph *bmp.PerPeerHeader, updat
 		if o := update.GetAttrOrigin(); o != nil {
 			prfx.Origin = *o
 		}
-		prfx.ASPath = update.GetAttrASPath(p.as4Capable)
-		prfx.ASPathCount = int32(len(prfx.ASPath))
-		if ases := update.GetAttrASPath(p.as4Capable); len(ases) != 0 {
-			// Last element in AS_PATH would be the AS of the origin
-			prfx.OriginAS = fmt.Sprintf("%d", ases[len(ases)-1])
+		path := update.GetASPath(ph.Is4BytesASPath())
+		prfx.ASPath = path.Flatten()
+		prfx.ASPathSegments = path
+		prfx.ASPathCount = int32(path.Length())
+		if origin, ok := path.OriginAS(); ok {
+			prfx.OriginAS = fmt.Sprintf("%d", origin)
 		}
 		if med := update.GetAttrMED(); med != nil {
 			prfx.MED = *med
@@ -70,6 +80,9 @@ func (p *producer) unicast(nlri bgp.MPNLRI, op int, ph *bmp.PerPeerHeader, updat
 		if lp := update.GetAttrLocalPref(); lp != nil {
 			prfx.LocalPref = *lp
 		}
+		prfx.ExtCommunityList = update.GetAttrExtCommunityString()
+		prfx.ExtCommunities = extCommunities(update)
+		prfx.LargeCommunityList = update.GetAttrLargeCommunityString()
 		if ph.FlagV {
 			// Peer is IPv6
 			prfx.PeerIP = net.IP(ph.PeerAddress).To16().String()
@@ -93,6 +106,7 @@ func (p *producer) unicast(nlri bgp.MPNLRI, op int, ph *bmp.PerPeerHeader, updat
 			copy(a, e.Prefix)
 			prfx.Prefix = net.IP(a).To4().String()
 		}
+		prfx.Hash = prefixHash(ph, prfx.PathID, prfx.Prefix, fmt.Sprintf("%d", prfx.PrefixLen))
 		if label {
 			for _, l := range e.Label {
 				prfx.Labels = append(prfx.Labels, l.Value)
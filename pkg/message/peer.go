@@ -7,6 +7,7 @@ import (
 	"net"
 
 	"github.com/golang/glog"
+	"github.com/sbezverk/gobmp/pkg/bgp"
 	"github.com/sbezverk/gobmp/pkg/bmp"
 )
 
@@ -30,6 +31,12 @@ func (p *producer) producePeerUpMessage(msg bmp.Message) {
 		LocalPort:      int(peerUpMsg.LocalPort),
 		AdvHolddown:    int(peerUpMsg.SentOpen.HoldTime),
 		RemoteHolddown: int(peerUpMsg.ReceivedOpen.HoldTime),
+		IsLocRIB:       msg.PeerHeader.IsLocRIB(),
+		IsPrepolicy:    msg.PeerHeader.IsPrepolicy(),
+	}
+	if m.IsLocRIB {
+		m.IsLocRIBFiltered = msg.PeerHeader.IsLocRIBFiltered()
+		m.TableName = peerUpMsg.GetTableName()
 	}
 	if msg.PeerHeader.FlagV {
 		m.IsIPv4 = false
@@ -44,10 +51,16 @@ func (p *producer) producePeerUpMessage(msg bmp.Message) {
 		m.RemoteBGPID = net.IP(msg.PeerHeader.PeerBGPID).To4().String()
 		m.LocalBGPID = net.IP(peerUpMsg.SentOpen.BGPID).To4().String()
 	}
-	// Saving local bgp speaker identities.
-	p.speakerIP = m.LocalIP
-	p.speakerHash = fmt.Sprintf("%x", md5.Sum([]byte(p.speakerIP)))
+	// Saving local bgp speaker identities, Loc-RIB Instance Peer does not represent a real BGP session
+	// and its Local Address might be zero filled, in this case it cannot be used as the speaker's identity.
+	if !m.IsLocRIB || !net.ParseIP(m.LocalIP).IsUnspecified() {
+		p.speakerIP = m.LocalIP
+		p.speakerHash = fmt.Sprintf("%x", md5.Sum([]byte(p.speakerIP)))
+	}
 	m.RouterIP = p.speakerIP
+	m.RouterName = p.routerName
+	m.Label = p.label
+	m.SessionAddr = p.sessionAddr
 	m.RouterHash = p.speakerHash
 
 	m.LocalASN = int32(peerUpMsg.SentOpen.MyAS)
@@ -55,13 +68,6 @@ func (p *producer) producePeerUpMessage(msg bmp.Message) {
 		// Local BGP speaker is 4 bytes AS capable
 		m.LocalASN = lasn
 	}
-	p.as4Capable = false
-	_, l4as := peerUpMsg.SentOpen.Is4BytesASCapable()
-	_, r4as := peerUpMsg.ReceivedOpen.Is4BytesASCapable()
-	if l4as && r4as {
-		// Both peers are AS 4 bytes capable
-		p.as4Capable = true
-	}
 	sCaps := peerUpMsg.SentOpen.GetCapabilities()
 	rCaps := peerUpMsg.ReceivedOpen.GetCapabilities()
 	for i, cap := range sCaps {
@@ -76,12 +82,14 @@ func (p *producer) producePeerUpMessage(msg bmp.Message) {
 			m.RcvCapabilities += ", "
 		}
 	}
+	p.state.setRouter(p.routerName, p.speakerIP)
+	p.state.peerUp(msg.PeerHeader, m.AdvCapabilities, m.RcvCapabilities)
 	j, err := json.Marshal(&m)
 	if err != nil {
 		glog.Errorf("failed to Marshal PeerStateChange struct with error: %+v", err)
 		return
 	}
-	if err := p.publisher.PublishMessage(bmp.PeerStateChangeMsg, []byte(m.RouterHash), j); err != nil {
+	if err := p.publish(bmp.PeerStateChangeMsg, []byte(m.RouterHash), j); err != nil {
 		glog.Errorf("failed to push PeerUp message to kafka with error: %+v", err)
 		return
 	}
@@ -100,13 +108,22 @@ func (p *producer) producePeerDownMessage(msg bmp.Message) {
 		return
 	}
 	m := PeerStateChange{
-		Action:     "down",
-		RouterIP:   p.speakerIP,
-		RouterHash: p.speakerHash,
-		BMPReason:  int(peerDownMsg.Reason),
-		RemoteASN:  msg.PeerHeader.PeerAS,
-		PeerRD:     msg.PeerHeader.PeerDistinguisher.String(),
-		Timestamp:  msg.PeerHeader.PeerTimestamp,
+		Action:      "down",
+		RouterIP:    p.speakerIP,
+		RouterName:  p.routerName,
+		Label:       p.label,
+		SessionAddr: p.sessionAddr,
+		RouterHash:  p.speakerHash,
+		BMPReason:   int(peerDownMsg.Reason),
+		RemoteASN:   msg.PeerHeader.PeerAS,
+		PeerRD:      msg.PeerHeader.PeerDistinguisher.String(),
+		Timestamp:   msg.PeerHeader.PeerTimestamp,
+		IsLocRIB:    msg.PeerHeader.IsLocRIB(),
+		IsPrepolicy: msg.PeerHeader.IsPrepolicy(),
+	}
+	if m.IsLocRIB {
+		m.IsLocRIBFiltered = msg.PeerHeader.IsLocRIBFiltered()
+		m.TableName = peerDownMsg.GetTableName()
 	}
 	if msg.PeerHeader.FlagV {
 		m.IsIPv4 = false
@@ -117,14 +134,28 @@ func (p *producer) producePeerDownMessage(msg bmp.Message) {
 		m.RemoteIP = net.IP(msg.PeerHeader.PeerAddress[12:]).To4().String()
 		m.RemoteBGPID = net.IP(msg.PeerHeader.PeerBGPID).To4().String()
 	}
-	m.InfoData = fmt.Sprintf("%s", peerDownMsg.Data)
+	if len(peerDownMsg.Data) != 0 {
+		m.InfoData = fmt.Sprintf("%x", peerDownMsg.Data)
+	}
+	switch {
+	case peerDownMsg.Notification != nil:
+		m.BMPErrorCode = int(peerDownMsg.Notification.ErrorCode)
+		m.BMPErrorSubCode = int(peerDownMsg.Notification.ErrorSubCode)
+		m.ErrorText = peerDownMsg.Notification.String()
+	case peerDownMsg.Reason == bmp.PeerDownLocalFSMEvent && peerDownMsg.FSMEvent != 0:
+		m.FSMEventCode = int(peerDownMsg.FSMEvent)
+		m.ErrorText = bgp.FSMEventString(peerDownMsg.FSMEvent)
+	default:
+		m.ErrorText = peerDownMsg.GetReasonString()
+	}
+	p.state.peerDown(msg.PeerHeader, m.ErrorText)
 
 	j, err := json.Marshal(&m)
 	if err != nil {
 		glog.Errorf("failed to Marshal PeerStateChange struct with error: %+v", err)
 		return
 	}
-	if err := p.publisher.PublishMessage(bmp.PeerStateChangeMsg, []byte(m.RouterHash), j); err != nil {
+	if err := p.publish(bmp.PeerStateChangeMsg, []byte(m.RouterHash), j); err != nil {
 		glog.Errorf("failed to push PeerDown message to kafka with error: %+v", err)
 		return
 	}
@@ -41,6 +41,7 @@ func (p *producer) processMPUpdate(nlri bgp.MPNLRI, operation int, ph *bmp.PerPe
 		msgs, err := p.unicast(nlri, operation, ph, update, labeled)
 		if err != nil {
 			glog.Errorf("failed to produce Unicast Prefix message message with error: %+v", err)
+			CountDecodeError(err)
 			return
 		}
 		// Loop through and publish all collected messages
@@ -49,23 +50,27 @@ func (p *producer) processMPUpdate(nlri bgp.MPNLRI, operation int, ph *bmp.PerPe
 				glog.Errorf("failed to process Unicast Prefix message with error: %+v", err)
 				return
 			}
+			p.state.countPrefixes(ph, operation, 1)
 		}
 	case 18:
 		msg, err := p.l3vpn(nlri, operation, ph, update)
 		if err != nil {
 			glog.Errorf("failed to produce l3vpn message with error: %+v", err)
+			CountDecodeError(err)
 			return
 		}
 		if err := p.marshalAndPublish(&msg, bmp.L3VPNMsg, []byte(msg.RouterHash), false); err != nil {
 			glog.Errorf("failed to process L3VPN message with error: %+v", err)
 			return
 		}
+		p.state.countPrefixes(ph, operation, 1)
 	case 19:
 		glog.Infof("2 IP (IP version 6) : 128 MPLS-labeled VPN address, attributes: %+v", update.GetAllAttributeID())
 	case 24:
 		msgs, err := p.evpn(nlri, operation, ph, update)
 		if err != nil {
 			glog.Errorf("failed to produce evpn message with error: %+v", err)
+			CountDecodeError(err)
 			return
 		}
 		for _, msg := range msgs {
@@ -73,6 +78,7 @@ func (p *producer) processMPUpdate(nlri bgp.MPNLRI, operation int, ph *bmp.PerPe
 				glog.Errorf("failed to process EVPNP message with error: %+v", err)
 				return
 			}
+			p.state.countPrefixes(ph, operation, 1)
 		}
 	case 71:
 		p.processNLRI71SubTypes(nlri, operation, ph, update)
@@ -94,6 +100,7 @@ func (p *producer) processNLRI71SubTypes(nlri bgp.MPNLRI, operation int, ph *bmp
 		msg, err := p.lsNode(nlri, operation, ph, update)
 		if err != nil {
 			glog.Errorf("failed to produce ls_node message with error: %+v", err)
+			CountDecodeError(err)
 			return
 		}
 		if err := p.marshalAndPublish(&msg, bmp.LSNodeMsg, []byte(msg.RouterHash), false); err != nil {
@@ -104,6 +111,7 @@ func (p *producer) processNLRI71SubTypes(nlri bgp.MPNLRI, operation int, ph *bmp
 		msg, err := p.lsLink(nlri, operation, ph, update)
 		if err != nil {
 			glog.Errorf("failed to produce ls_link message with error: %+v", err)
+			CountDecodeError(err)
 			return
 		}
 		if err := p.marshalAndPublish(&msg, bmp.LSLinkMsg, []byte(msg.RouterHash), false); err != nil {
@@ -117,6 +125,7 @@ func (p *producer) processNLRI71SubTypes(nlri bgp.MPNLRI, operation int, ph *bmp
 		msg, err := p.lsPrefix(nlri, operation, ph, update, ipv4Flag)
 		if err != nil {
 			glog.Errorf("failed to produce ls_prefix message with error: %+v", err)
+			CountDecodeError(err)
 			return
 		}
 		if err := p.marshalAndPublish(&msg, bmp.LSPrefixMsg, []byte(msg.RouterHash), false); err != nil {
@@ -127,6 +136,7 @@ func (p *producer) processNLRI71SubTypes(nlri bgp.MPNLRI, operation int, ph *bmp
 		msg, err := p.lsSRv6SID(nlri, operation, ph, update)
 		if err != nil {
 			glog.Errorf("failed to produce ls_srv6_sid message with error: %+v", err)
+			CountDecodeError(err)
 			return
 		}
 		if err := p.marshalAndPublish(&msg, bmp.LSSRv6SIDMsg, []byte(msg.RouterHash), false); err != nil {
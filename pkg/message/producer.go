@@ -1,29 +1,114 @@
 package message
 
 import (
+	"errors"
+	"hash/fnv"
+	"sync"
+
 	"github.com/golang/glog"
 	"github.com/sbezverk/gobmp/pkg/bmp"
+	"github.com/sbezverk/gobmp/pkg/metrics"
 	"github.com/sbezverk/gobmp/pkg/pub"
+	"github.com/sbezverk/gobmp/pkg/tools"
 )
 
+var producedMessages = metrics.NewCounterVec("gobmp_produced_messages_total", "Number of messages produced by message type.", "type")
+
+var decodeErrors = metrics.NewCounterVec("gobmp_decode_errors_total", "Number of decode errors by decoding package and error kind.", "package", "kind")
+
+// CountDecodeError counts tools.DecodeError wrapped by err in gobmp_decode_errors_total metric, it is called where
+// a message failed to decode is dropped. False is returned when err does not wrap tools.DecodeError.
+func CountDecodeError(err error) bool {
+	var de *tools.DecodeError
+	if !errors.As(err, &de) {
+		return false
+	}
+	decodeErrors.With(de.Package, de.Err.Error()).Inc()
+
+	return true
+}
+
+// typeNames maps types of produced messages to the names used in metrics
+var typeNames = map[int]string{
+	bmp.UnicastPrefixMsg:   "unicast_prefix",
+	bmp.LSNodeMsg:          "ls_node",
+	bmp.LSLinkMsg:          "ls_link",
+	bmp.PeerStateChangeMsg: "peer",
+	bmp.L3VPNMsg:           "l3vpn",
+	bmp.LSPrefixMsg:        "ls_prefix",
+	bmp.LSSRv6SIDMsg:       "ls_srv6_sid",
+	bmp.EVPNMsg:            "evpn",
+	bmp.StatsMsg:           "statistics",
+	bmp.MirrorMsg:          "route_mirror",
+	bmp.RouterEventMsg:     "router",
+	bmp.BMPRawMsg:          "bmp_raw",
+}
+
 // Producer defines methods to act as a message producer
 type Producer interface {
 	Producer(queue chan bmp.Message, stop chan struct{})
+	// QueueLength returns the number of messages waiting in workers' queues
+	QueueLength() int
+	// Status returns the state of BMP session and the peers reported by the router
+	Status() SessionStatus
+}
+
+// Session defines the identity of BMP session attached to all messages produced for the session
+type Session struct {
+	// Addr is the address of the router originating BMP session
+	Addr string
+	// RouterName is the router's name configured for the session, when set, it takes precedence
+	// over the name the router reports in Initiation message
+	RouterName string
+	// Label identifies the listener which accepted BMP session, it is attached to all messages
+	// produced for the session
+	Label string
 }
 
 type producer struct {
 	publisher   pub.Publisher
 	speakerIP   string
 	speakerHash string
-	as4Capable  bool
+	// routerName and sessionAddr identify the router originating BMP session
+	routerName  string
+	sessionAddr string
+	label       string
+	// nameConfigured is true when the router's name was configured for the session
+	nameConfigured bool
+	// workers keeps work queues of the pool of workers, all messages of a peer are produced
+	// by the same worker in the order they were received, messages of different peers are produced in parallel.
+	workers []chan bmp.Message
+	// inflight tracks messages dispatched to workers and not yet produced
+	inflight sync.WaitGroup
+	// state keeps the state of the session exposed for monitoring
+	state *sessionState
 }
 
-// Producer dispatches kafka workers upon request received from the channel
+// Producer dispatches messages received from the channel to the pool of workers. Messages which
+// do not belong to a peer (Initiation, Termination) and messages which change the state shared by all peers
+// (Peer Up) are produced only after all previously received messages are produced. When the queue is closed,
+// Producer returns after all messages waiting in the queue are produced, when stop is closed, Producer returns
+// without waiting for dispatched messages and the workers drop messages left in their queues.
 func (p *producer) Producer(queue chan bmp.Message, stop chan struct{}) {
+	for _, q := range p.workers {
+		go p.worker(q, stop)
+	}
+	defer func() {
+		for _, q := range p.workers {
+			close(q)
+		}
+	}()
 	for {
 		select {
-		case msg := <-queue:
-			go p.producingWorker(msg)
+		case msg, ok := <-queue:
+			if !ok {
+				p.waitInflight(stop)
+				return
+			}
+			if !p.dispatch(msg, stop) {
+				glog.Infof("received interrupt, stopping.")
+				return
+			}
 		case <-stop:
 			glog.Infof("received interrupt, stopping.")
 			return
@@ -31,7 +116,90 @@ func (p *producer) Producer(queue chan bmp.Message, stop chan struct{}) {
 	}
 }
 
+// dispatch sends the message to the peer's worker or produces the barrier message, false is returned
+// when stop was closed before the message was dispatched.
+func (p *producer) dispatch(msg bmp.Message, stop chan struct{}) bool {
+	if isBarrier(msg) {
+		if !p.waitInflight(stop) {
+			return false
+		}
+		p.producingWorker(msg)
+		return true
+	}
+	h := fnv.New32a()
+	h.Write([]byte(msg.PeerHeader.GetPeerHash()))
+	p.inflight.Add(1)
+	// Sending blocks when the worker's queue is full, it propagates backpressure to the caller.
+	select {
+	case p.workers[h.Sum32()%uint32(len(p.workers))] <- msg:
+		return true
+	case <-stop:
+		p.inflight.Done()
+		return false
+	}
+}
+
+// waitInflight waits for all dispatched messages to be produced, false is returned when stop was closed first.
+func (p *producer) waitInflight(stop chan struct{}) bool {
+	done := make(chan struct{})
+	go func() {
+		p.inflight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-stop:
+		return false
+	}
+}
+
+func (p *producer) worker(queue chan bmp.Message, stop chan struct{}) {
+	for msg := range queue {
+		select {
+		case <-stop:
+			// Messages left in the queue after stop are dropped, the publisher may be already closed
+		default:
+			p.producingWorker(msg)
+		}
+		p.inflight.Done()
+	}
+}
+
+// QueueLength returns the number of messages waiting in workers' queues
+func (p *producer) QueueLength() int {
+	l := 0
+	for _, q := range p.workers {
+		l += len(q)
+	}
+
+	return l
+}
+
+// Status returns the state of BMP session and the peers reported by the router
+func (p *producer) Status() SessionStatus {
+	return p.state.status()
+}
+
+// isBarrier returns true if the message must be produced only after all preceding messages
+func isBarrier(msg bmp.Message) bool {
+	if msg.PeerHeader == nil {
+		return true
+	}
+	switch msg.Payload.(type) {
+	case *bmp.PeerUpMessage:
+		return true
+	}
+
+	return false
+}
+
 func (p *producer) producingWorker(msg bmp.Message) {
+	defer func() {
+		if r := recover(); r != nil {
+			glog.Errorf("recovered from panic while producing %T message: %v", msg.Payload, r)
+		}
+	}()
 	switch obj := msg.Payload.(type) {
 	case *bmp.PeerUpMessage:
 		p.producePeerUpMessage(msg)
@@ -39,14 +207,57 @@ func (p *producer) producingWorker(msg bmp.Message) {
 		p.producePeerDownMessage(msg)
 	case *bmp.RouteMonitor:
 		p.produceRouteMonitorMessage(msg)
+	case *bmp.StatsReport:
+		p.produceStatsMessage(msg)
+	case *bmp.RouteMirror:
+		p.produceRouteMirrorMessage(msg)
+	case *bmp.InitiationMessage:
+		p.produceInitiationMessage(msg)
+	case *bmp.TerminationMessage:
+		p.produceTerminationMessage(msg)
 	default:
 		glog.Warningf("got Unknown message %T to push to kafka, ignoring it...", obj)
 	}
 }
 
-// NewProducer instantiates a new instance of a producer with Publisher interface
-func NewProducer(publisher pub.Publisher) Producer {
-	return &producer{
-		publisher: publisher,
+// NewProducer instantiates a new instance of a producer with Publisher interface,
+// session identifies the router originating BMP session, workers is the number
+// of workers producing messages in parallel and queueSize is the size of each worker's queue.
+func NewProducer(publisher pub.Publisher, session Session, workers, queueSize int) Producer {
+	if workers < 1 {
+		workers = 1
+	}
+	p := &producer{
+		publisher:      publisher,
+		sessionAddr:    session.Addr,
+		routerName:     session.RouterName,
+		nameConfigured: session.RouterName != "",
+		label:          session.Label,
+		state:          newSessionState(),
+		workers:        make([]chan bmp.Message, workers),
+	}
+	for i := range p.workers {
+		p.workers[i] = make(chan bmp.Message, queueSize)
+	}
+
+	return p
+}
+
+// publish publishes a message of msgType and counts it in gobmp_produced_messages_total metric
+func (p *producer) publish(msgType int, hash []byte, msg []byte) error {
+	if err := p.publisher.PublishMessage(msgType, hash, msg); err != nil {
+		return err
+	}
+	producedMessages.With(TypeName(msgType)).Inc()
+
+	return nil
+}
+
+// TypeName returns the name of a produced message's type, "unknown" is returned for types which are not produced
+func TypeName(msgType int) string {
+	if name, ok := typeNames[msgType]; ok {
+		return name
 	}
+
+	return "unknown"
 }
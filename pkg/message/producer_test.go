@@ -0,0 +1,671 @@
+package message
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sbezverk/gobmp/pkg/bgp"
+	"github.com/sbezverk/gobmp/pkg/bmp"
+	"github.com/sbezverk/gobmp/pkg/metrics"
+)
+
+type recordingPublisher struct {
+	sync.Mutex
+	msgs []recordedMessage
+}
+
+type recordedMessage struct {
+	msgType int
+	msg     []byte
+}
+
+func (r *recordingPublisher) PublishMessage(msgType int, msgHash []byte, msg []byte) error {
+	r.Lock()
+	defer r.Unlock()
+	r.msgs = append(r.msgs, recordedMessage{msgType: msgType, msg: msg})
+	return nil
+}
+
+func (r *recordingPublisher) Close() error {
+	return nil
+}
+
+func testPeerHeader(t *testing.T, peer byte) *bmp.PerPeerHeader {
+	b := make([]byte, bmp.PerPeerHeaderLength)
+	// Peer Address, IPv4 10.0.0.peer
+	copy(b[22:26], []byte{10, 0, 0, peer})
+	// Peer AS 65000
+	copy(b[26:30], []byte{0, 0, 0xfd, 0xe8})
+	// Peer BGP ID
+	copy(b[30:34], []byte{10, 0, 0, peer})
+	ph, err := bmp.UnmarshalPerPeerHeader(b)
+	if err != nil {
+		t.Fatalf("failed to build per peer header with error: %+v", err)
+	}
+	return ph
+}
+
+func TestProducerPerPeerOrdering(t *testing.T) {
+	publisher := &recordingPublisher{}
+	prod := NewProducer(publisher, Session{Addr: "192.0.2.1"}, 4, 16)
+	queue := make(chan bmp.Message)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		prod.Producer(queue, stop)
+		close(done)
+	}()
+	peers := []*bmp.PerPeerHeader{testPeerHeader(t, 1), testPeerHeader(t, 2)}
+	count := 200
+	for i := 1; i <= count; i++ {
+		for _, ph := range peers {
+			queue <- bmp.Message{
+				PeerHeader: ph,
+				Payload: &bmp.StatsReport{
+					Stats: []bmp.Stat{{Type: bmp.StatRejectedPrefixes, Value: uint64(i)}},
+				},
+			}
+		}
+	}
+	queue <- bmp.Message{
+		PeerHeader: peers[0],
+		Payload:    &bmp.PeerDownMessage{Reason: bmp.PeerDownRemoteNoData},
+	}
+	queue <- bmp.Message{
+		Payload: &bmp.TerminationMessage{},
+	}
+	// Closing the queue lets Producer produce all messages, stop aborts producing
+	close(queue)
+	<-done
+
+	publisher.Lock()
+	defer publisher.Unlock()
+	if len(publisher.msgs) != 2*count+2 {
+		t.Fatalf("expected %d messages got %d", 2*count+2, len(publisher.msgs))
+	}
+	last := map[string]uint32{}
+	for i, m := range publisher.msgs[:2*count+1] {
+		if m.msgType == bmp.PeerStateChangeMsg {
+			if last["10.0.0.1"] != uint32(count) {
+				t.Errorf("expected Peer Down after all peer's messages, got it after message %d", last["10.0.0.1"])
+			}
+			continue
+		}
+		if m.msgType != bmp.StatsMsg {
+			t.Fatalf("message %d: expected type %d got %d", i, bmp.StatsMsg, m.msgType)
+		}
+		s := Stats{}
+		if err := json.Unmarshal(m.msg, &s); err != nil {
+			t.Fatalf("failed to unmarshal message with error: %+v", err)
+		}
+		if s.RejectedPrefixes == nil || *s.RejectedPrefixes != last[s.RemoteIP]+1 {
+			t.Fatalf("peer %s: expected sequence %d got %v", s.RemoteIP, last[s.RemoteIP]+1, s.RejectedPrefixes)
+		}
+		last[s.RemoteIP] = *s.RejectedPrefixes
+	}
+	if publisher.msgs[2*count+1].msgType != bmp.RouterEventMsg {
+		t.Errorf("expected Termination to be the last message, got message type %d", publisher.msgs[2*count+1].msgType)
+	}
+}
+
+func TestProducerDrain(t *testing.T) {
+	publisher := &recordingPublisher{}
+	prod := NewProducer(publisher, Session{Addr: "192.0.2.1"}, 4, 16)
+	count := 100
+	queue := make(chan bmp.Message, count)
+	for i := 1; i <= count; i++ {
+		queue <- bmp.Message{
+			PeerHeader: testPeerHeader(t, byte(i%4)),
+			Payload: &bmp.StatsReport{
+				Stats: []bmp.Stat{{Type: bmp.StatRejectedPrefixes, Value: uint64(i)}},
+			},
+		}
+	}
+	close(queue)
+	// Producer is expected to return only after all queued messages are produced
+	prod.Producer(queue, make(chan struct{}))
+
+	publisher.Lock()
+	defer publisher.Unlock()
+	if len(publisher.msgs) != count {
+		t.Fatalf("expected %d messages got %d", count, len(publisher.msgs))
+	}
+}
+
+func TestProducerStatus(t *testing.T) {
+	prod := NewProducer(&recordingPublisher{}, Session{Addr: "192.0.2.1"}, 2, 16)
+	queue := make(chan bmp.Message, 2)
+	queue <- bmp.Message{
+		PeerHeader: testPeerHeader(t, 1),
+		Payload: &bmp.StatsReport{
+			Stats: []bmp.Stat{{Type: bmp.StatAdjRIBIn, Value: 10}},
+		},
+	}
+	queue <- bmp.Message{
+		PeerHeader: testPeerHeader(t, 1),
+		Payload:    &bmp.PeerDownMessage{Reason: bmp.PeerDownRemoteNoData},
+	}
+	close(queue)
+	prod.Producer(queue, make(chan struct{}))
+
+	status := prod.Status()
+	if len(status.Peers) != 1 {
+		t.Fatalf("expected 1 peer got %+v", status.Peers)
+	}
+	peer := status.Peers[0]
+	// Numbers of routes reported before Peer Down are cleared, the peer has no routes
+	if peer.RemoteIP != "10.0.0.1" || peer.State != PeerStateDown || peer.LastDownReason == "" || peer.AdjRIBIn != nil {
+		t.Fatalf("unexpected peer status %+v", peer)
+	}
+}
+
+func TestProducerStatusLocRIBInstances(t *testing.T) {
+	// locRIBPeer returns the per peer header of Loc-RIB instance peer with the peer distinguisher 65000:rd,
+	// Loc-RIB instances share zero peer address, local AS and BGP ID
+	locRIBPeer := func(rd byte) *bmp.PerPeerHeader {
+		b := make([]byte, bmp.PerPeerHeaderLength)
+		b[0] = bmp.PeerTypeLocRIB
+		copy(b[2:10], []byte{0, 0, 0xfd, 0xe8, 0, 0, 0, rd})
+		copy(b[26:30], []byte{0, 0, 0xfd, 0xe8})
+		copy(b[30:34], []byte{192, 0, 2, 1})
+		ph, err := bmp.UnmarshalPerPeerHeader(b)
+		if err != nil {
+			t.Fatalf("failed to build per peer header with error: %+v", err)
+		}
+		return ph
+	}
+	p := NewProducer(&recordingPublisher{}, Session{Addr: "192.0.2.1"}, 1, 1).(*producer)
+	p.produceStatsMessage(bmp.Message{PeerHeader: locRIBPeer(1), Payload: &bmp.StatsReport{Stats: []bmp.Stat{{Type: bmp.StatLocRIB, Value: 10}}}})
+	p.produceStatsMessage(bmp.Message{PeerHeader: locRIBPeer(2), Payload: &bmp.StatsReport{Stats: []bmp.Stat{{Type: bmp.StatLocRIB, Value: 20}}}})
+	p.producePeerDownMessage(bmp.Message{PeerHeader: locRIBPeer(2), Payload: &bmp.PeerDownMessage{Reason: bmp.PeerDownRemoteNoData}})
+	status := p.Status()
+	if len(status.Peers) != 2 {
+		t.Fatalf("expected 2 peers got %+v", status.Peers)
+	}
+	first, second := status.Peers[0], status.Peers[1]
+	if first.PeerRD != "65000:1" || first.State != "" || first.LocRIB == nil || *first.LocRIB != 10 {
+		t.Fatalf("unexpected status of the first loc-rib instance %+v", first)
+	}
+	if second.PeerRD != "65000:2" || second.State != PeerStateDown || second.LocRIB != nil {
+		t.Fatalf("unexpected status of the second loc-rib instance %+v", second)
+	}
+}
+
+func TestProduceRouteMonitorAddPath(t *testing.T) {
+	// Origin, Next Hop and 10.1.2.0/24 advertised with Path Identifiers 1 and 2
+	b := []byte{0, 0, 0, 11, 0x40, 1, 1, 0, 0x40, 3, 4, 10, 0, 0, 1, 0, 0, 0, 1, 24, 10, 1, 2, 0, 0, 0, 2, 24, 10, 1, 2}
+	update, err := bgp.UnmarshalBGPUpdate(b, map[int]bool{1: true})
+	if err != nil {
+		t.Fatalf("failed to build bgp update with error: %+v", err)
+	}
+	publisher := &recordingPublisher{}
+	p := NewProducer(publisher, Session{Addr: "192.0.2.1"}, 1, 1).(*producer)
+	p.produceRouteMonitorMessage(bmp.Message{PeerHeader: testPeerHeader(t, 1), Payload: &bmp.RouteMonitor{Update: update}})
+	if len(publisher.msgs) != 2 {
+		t.Fatalf("expected 2 messages but got %d", len(publisher.msgs))
+	}
+	hashes := make(map[string]bool)
+	for i, m := range publisher.msgs {
+		var prfx UnicastPrefix
+		if err := json.Unmarshal(m.msg, &prfx); err != nil {
+			t.Fatalf("failed to unmarshal unicast prefix with error: %+v", err)
+		}
+		if prfx.Prefix != "10.1.2.0" || prfx.PrefixLen != 24 || prfx.PathID != int32(i+1) {
+			t.Fatalf("expected 10.1.2.0/24 with path id %d but got %s/%d with path id %d", i+1, prfx.Prefix, prfx.PrefixLen, prfx.PathID)
+		}
+		if prfx.Hash == "" || hashes[prfx.Hash] {
+			t.Fatalf("expected unique hash of the path but got %q", prfx.Hash)
+		}
+		hashes[prfx.Hash] = true
+	}
+}
+
+func TestProduceRouteMonitorASPath(t *testing.T) {
+	// Origin, AS_PATH 65001 23456 {65002,65003}, Next Hop, AS4_PATH 100000 {65002,65003} and 10.1.2.0/24
+	b := []byte{0, 0, 0, 45, 0x40, 1, 1, 0, 0x40, 2, 12, 2, 2, 0xfd, 0xe9, 0x5b, 0xa0, 1, 2, 0xfd, 0xea, 0xfd, 0xeb, 0x40, 3, 4, 10, 0, 0, 1,
+		0xc0, 17, 16, 2, 1, 0, 1, 0x86, 0xa0, 1, 2, 0, 0, 0xfd, 0xea, 0, 0, 0xfd, 0xeb, 24, 10, 1, 2}
+	update, err := bgp.UnmarshalBGPUpdate(b, nil)
+	if err != nil {
+		t.Fatalf("failed to build bgp update with error: %+v", err)
+	}
+	ph := testPeerHeader(t, 1)
+	// Legacy 2 bytes AS_PATH format
+	ph.FlagA = true
+	publisher := &recordingPublisher{}
+	p := NewProducer(publisher, Session{Addr: "192.0.2.1"}, 1, 1).(*producer)
+	p.produceRouteMonitorMessage(bmp.Message{PeerHeader: ph, Payload: &bmp.RouteMonitor{Update: update}})
+	if len(publisher.msgs) != 1 {
+		t.Fatalf("expected 1 message but got %d", len(publisher.msgs))
+	}
+	var prfx UnicastPrefix
+	if err := json.Unmarshal(publisher.msgs[0].msg, &prfx); err != nil {
+		t.Fatalf("failed to unmarshal unicast prefix with error: %+v", err)
+	}
+	expect := bgp.ASPath{
+		{Type: bgp.ASSequence, ASN: []uint32{65001, 100000}},
+		{Type: bgp.ASSet, ASN: []uint32{65002, 65003}},
+	}
+	if !reflect.DeepEqual(expect, prfx.ASPathSegments) {
+		t.Fatalf("expected as path %s but got %s", expect, prfx.ASPathSegments)
+	}
+	if prfx.ASPathCount != 3 {
+		t.Fatalf("expected as path count 3 but got %d", prfx.ASPathCount)
+	}
+	// Origin of the aggregate cannot be determined
+	if prfx.OriginAS != "" {
+		t.Fatalf("expected no origin as but got %s", prfx.OriginAS)
+	}
+}
+
+func TestProduceRouteMonitorExtCommunity(t *testing.T) {
+	// Origin, empty AS_PATH, Next Hop, EXTENDED_COMMUNITIES rt=65000:100, IPV6_ADDRESS_SPECIFIC_EXTENDED_COMMUNITY
+	// rt=[2001:db8::1]:100 and 10.1.2.0/24
+	b := []byte{0, 0, 0, 48, 0x40, 1, 1, 0, 0x40, 2, 0, 0x40, 3, 4, 10, 0, 0, 1,
+		0xc0, 16, 8, 0x00, 0x02, 0xfd, 0xe8, 0x00, 0x00, 0x00, 0x64,
+		0xc0, 25, 20, 0x00, 0x02, 0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x01, 0x00, 0x64,
+		24, 10, 1, 2}
+	update, err := bgp.UnmarshalBGPUpdate(b, nil)
+	if err != nil {
+		t.Fatalf("failed to build bgp update with error: %+v", err)
+	}
+	publisher := &recordingPublisher{}
+	p := NewProducer(publisher, Session{Addr: "192.0.2.1"}, 1, 1).(*producer)
+	p.produceRouteMonitorMessage(bmp.Message{PeerHeader: testPeerHeader(t, 1), Payload: &bmp.RouteMonitor{Update: update}})
+	if len(publisher.msgs) != 1 {
+		t.Fatalf("expected 1 message but got %d", len(publisher.msgs))
+	}
+	// Messages are decoded by the consumers into the same structures
+	var prfx UnicastPrefix
+	if err := json.Unmarshal(publisher.msgs[0].msg, &prfx); err != nil {
+		t.Fatalf("failed to unmarshal unicast prefix with error: %+v", err)
+	}
+	if expect := "rt=65000:100, rt=[2001:db8::1]:100"; prfx.ExtCommunityList != expect {
+		t.Fatalf("expected extended community list %q but got %q", expect, prfx.ExtCommunityList)
+	}
+	if len(prfx.ExtCommunities) != 2 || prfx.ExtCommunities[0].GlobalAdmin != "65000" || prfx.ExtCommunities[1].GlobalAdmin != "2001:db8::1" {
+		t.Fatalf("unexpected extended communities %+v", prfx.ExtCommunities)
+	}
+}
+
+func TestProduceRouteMonitorAllNLRI(t *testing.T) {
+	// Withdrawn 10.9.9.0/24, Origin, MP_REACH_NLRI 2001:db8:1::/64, Next Hop, MP_UNREACH_NLRI 2001:db8:2::/64 and 10.1.2.0/24
+	b := []byte{0, 4, 24, 10, 9, 9, 0, 59, 0x40, 1, 1, 0,
+		0x80, 14, 30, 0, 2, 1, 16, 0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 64, 0x20, 0x01, 0x0d, 0xb8, 0, 1, 0, 0,
+		0x40, 3, 4, 10, 0, 0, 1,
+		0x80, 15, 12, 0, 2, 1, 64, 0x20, 0x01, 0x0d, 0xb8, 0, 2, 0, 0,
+		24, 10, 1, 2}
+	update, err := bgp.UnmarshalBGPUpdate(b, nil)
+	if err != nil {
+		t.Fatalf("failed to build bgp update with error: %+v", err)
+	}
+	publisher := &recordingPublisher{}
+	p := NewProducer(publisher, Session{Addr: "192.0.2.1"}, 1, 1).(*producer)
+	p.produceRouteMonitorMessage(bmp.Message{PeerHeader: testPeerHeader(t, 1), Payload: &bmp.RouteMonitor{Update: update}})
+	// Withdrawals are published before advertisements
+	expect := []string{"del 10.9.9.0/24", "del 2001:db8:2::/64", "add 2001:db8:1::/64", "add 10.1.2.0/24"}
+	got := make([]string, 0)
+	for _, m := range publisher.msgs {
+		var prfx UnicastPrefix
+		if err := json.Unmarshal(m.msg, &prfx); err != nil {
+			t.Fatalf("failed to unmarshal unicast prefix with error: %+v", err)
+		}
+		got = append(got, fmt.Sprintf("%s %s/%d", prfx.Action, prfx.Prefix, prfx.PrefixLen))
+	}
+	if !reflect.DeepEqual(expect, got) {
+		t.Fatalf("expected prefixes %v but got %v", expect, got)
+	}
+	status := p.state.status()
+	if len(status.Peers) != 1 || status.Peers[0].AnnouncedTotal != 2 || status.Peers[0].WithdrawnTotal != 2 {
+		t.Fatalf("unexpected peer status %+v", status.Peers)
+	}
+}
+
+func TestProduceRouteMonitorIPv6Peer(t *testing.T) {
+	// Withdrawn 10.9.9.0/24, Origin, Next Hop 10.0.0.1 and 10.1.2.0/24
+	b := []byte{0, 4, 24, 10, 9, 9, 0, 11, 0x40, 1, 1, 0, 0x40, 3, 4, 10, 0, 0, 1, 24, 10, 1, 2}
+	update, err := bgp.UnmarshalBGPUpdate(b, nil)
+	if err != nil {
+		t.Fatalf("failed to build bgp update with error: %+v", err)
+	}
+	// IPv6 peer 2001:db8::1, V flag is set
+	hb := make([]byte, bmp.PerPeerHeaderLength)
+	hb[1] = 0x80
+	copy(hb[10:26], net.ParseIP("2001:db8::1"))
+	copy(hb[26:30], []byte{0, 0, 0xfd, 0xe8})
+	copy(hb[30:34], []byte{10, 0, 0, 1})
+	ph, err := bmp.UnmarshalPerPeerHeader(hb)
+	if err != nil {
+		t.Fatalf("failed to build per peer header with error: %+v", err)
+	}
+	publisher := &recordingPublisher{}
+	p := NewProducer(publisher, Session{Addr: "192.0.2.1"}, 1, 1).(*producer)
+	p.produceRouteMonitorMessage(bmp.Message{PeerHeader: ph, Payload: &bmp.RouteMonitor{Update: update}})
+	expect := []UnicastPrefix{
+		{Action: "del", Prefix: "10.9.9.0", IsIPv4: true, PeerIP: "2001:db8::1", Nexthop: "10.0.0.1", IsNexthopIPv4: true},
+		{Action: "add", Prefix: "10.1.2.0", IsIPv4: true, PeerIP: "2001:db8::1", Nexthop: "10.0.0.1", IsNexthopIPv4: true},
+	}
+	if len(publisher.msgs) != len(expect) {
+		t.Fatalf("expected %d messages but got %d", len(expect), len(publisher.msgs))
+	}
+	for i, m := range publisher.msgs {
+		var prfx UnicastPrefix
+		if err := json.Unmarshal(m.msg, &prfx); err != nil {
+			t.Fatalf("failed to unmarshal unicast prefix with error: %+v", err)
+		}
+		got := UnicastPrefix{Action: prfx.Action, Prefix: prfx.Prefix, IsIPv4: prfx.IsIPv4, PeerIP: prfx.PeerIP,
+			Nexthop: prfx.Nexthop, IsNexthopIPv4: prfx.IsNexthopIPv4}
+		if !reflect.DeepEqual(expect[i], got) {
+			t.Errorf("expected %+v but got %+v", expect[i], got)
+		}
+	}
+}
+
+func TestProduceRouteMonitorEndOfRIB(t *testing.T) {
+	publisher := &recordingPublisher{}
+	p := NewProducer(publisher, Session{Addr: "192.0.2.1"}, 1, 1).(*producer)
+	// IPv4 unicast, EVPN and repeated IPv4 unicast End-of-RIB markers
+	for _, b := range [][]byte{{0, 0, 0, 0}, {0, 0, 0, 6, 0x80, 15, 3, 0, 25, 70}, {0, 0, 0, 0}} {
+		update, err := bgp.UnmarshalBGPUpdate(b, nil)
+		if err != nil {
+			t.Fatalf("failed to build bgp update with error: %+v", err)
+		}
+		p.produceRouteMonitorMessage(bmp.Message{PeerHeader: testPeerHeader(t, 1), Payload: &bmp.RouteMonitor{Update: update}})
+	}
+	if len(publisher.msgs) != 0 {
+		t.Fatalf("expected no messages but got %d", len(publisher.msgs))
+	}
+	status := p.state.status()
+	if len(status.Peers) != 1 || !reflect.DeepEqual(status.Peers[0].EndOfRIB, []string{"1/1", "25/70"}) {
+		t.Fatalf("unexpected peer status %+v", status.Peers)
+	}
+}
+
+func TestProduceStatsReportedZero(t *testing.T) {
+	publisher := &recordingPublisher{}
+	p := NewProducer(publisher, Session{Addr: "192.0.2.1"}, 1, 1).(*producer)
+	p.produceStatsMessage(bmp.Message{
+		PeerHeader: testPeerHeader(t, 1),
+		Payload: &bmp.StatsReport{
+			Stats: []bmp.Stat{
+				{Type: bmp.StatRejectedPrefixes, Value: 0},
+				{Type: bmp.StatAdjRIBIn, Value: 0},
+				{Type: bmp.StatAFISAFIAdjRIBIn, AFI: 1, SAFI: 1, Value: 0},
+			},
+		},
+	})
+	if len(publisher.msgs) != 1 {
+		t.Fatalf("expected 1 message but got %d", len(publisher.msgs))
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(publisher.msgs[0].msg, &m); err != nil {
+		t.Fatalf("failed to unmarshal stats with error: %+v", err)
+	}
+	// Reported zero values are present, not reported ones are absent
+	for _, f := range []string{"rejected_prefixes", "adj_rib_in"} {
+		if v, ok := m[f]; !ok || v != float64(0) {
+			t.Errorf("expected %s to be reported as 0 but got %v", f, v)
+		}
+	}
+	for _, f := range []string{"duplicate_prefixes", "loc_rib"} {
+		if v, ok := m[f]; ok {
+			t.Errorf("expected %s not to be reported but got %v", f, v)
+		}
+	}
+	afiSAFI, ok := m["afi_safi"].([]interface{})
+	if !ok || len(afiSAFI) != 1 {
+		t.Fatalf("expected 1 afi/safi entry but got %v", m["afi_safi"])
+	}
+	entry := afiSAFI[0].(map[string]interface{})
+	if v, ok := entry["adj_rib_in"]; !ok || v != float64(0) {
+		t.Errorf("expected afi/safi adj_rib_in to be reported as 0 but got %v", v)
+	}
+	if _, ok := entry["loc_rib"]; ok {
+		t.Errorf("expected afi/safi loc_rib not to be reported")
+	}
+}
+
+func TestProduceRouteMirrorMPNLRI(t *testing.T) {
+	// MP_REACH_NLRI 2001:db8:1::/64 and MP_UNREACH_NLRI 2001:db8:2::/64
+	body := []byte{0, 0, 0, 48,
+		0x80, 14, 30, 0, 2, 1, 16, 0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 64, 0x20, 0x01, 0x0d, 0xb8, 0, 1, 0, 0,
+		0x80, 15, 12, 0, 2, 1, 64, 0x20, 0x01, 0x0d, 0xb8, 0, 2, 0, 0}
+	pdu := append([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0, byte(19 + len(body)), 2}, body...)
+	publisher := &recordingPublisher{}
+	p := NewProducer(publisher, Session{Addr: "192.0.2.1"}, 1, 1).(*producer)
+	p.produceRouteMirrorMessage(bmp.Message{PeerHeader: testPeerHeader(t, 1), Payload: &bmp.RouteMirror{BGPMessage: pdu}})
+	if len(publisher.msgs) != 1 {
+		t.Fatalf("expected 1 message but got %d", len(publisher.msgs))
+	}
+	var m RouteMirror
+	if err := json.Unmarshal(publisher.msgs[0].msg, &m); err != nil {
+		t.Fatalf("failed to unmarshal route mirror with error: %+v", err)
+	}
+	if m.DecodeError != "" || len(m.MPNLRI) != 2 {
+		t.Fatalf("expected 2 mp nlri but got %+v", m)
+	}
+	reach, unreach := m.MPNLRI[0], m.MPNLRI[1]
+	if reach.Action != "add" || reach.AFI != 2 || reach.SAFI != 1 || reach.Nexthop != "2001:db8::1" ||
+		!reflect.DeepEqual(reach.Routes, []string{"2001:db8:1::/64"}) || reach.Attribute == "" {
+		t.Errorf("unexpected mp_reach_nlri %+v", reach)
+	}
+	if unreach.Action != "del" || unreach.AFI != 2 || unreach.SAFI != 1 || !reflect.DeepEqual(unreach.Routes, []string{"2001:db8:2::/64"}) {
+		t.Errorf("unexpected mp_unreach_nlri %+v", unreach)
+	}
+}
+
+func TestProduceRouteMirrorASPath(t *testing.T) {
+	tests := []struct {
+		name   string
+		flagA  bool
+		attr   []byte
+		expect []uint32
+	}{
+		{
+			name:   "4 bytes as path",
+			attr:   []byte{0x40, 2, 10, 2, 2, 0, 1, 0x86, 0xa0, 0, 0, 0xfd, 0xe9},
+			expect: []uint32{100000, 65001},
+		},
+		{
+			name:   "legacy 2 bytes as path",
+			flagA:  true,
+			attr:   []byte{0x40, 2, 6, 2, 2, 0xfd, 0xe9, 0xfd, 0xea},
+			expect: []uint32{65001, 65002},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := append([]byte{0, 0, 0, byte(len(tt.attr))}, tt.attr...)
+			pdu := append([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+				0, byte(19 + len(body)), 2}, body...)
+			// Peer Up was not received, AS_PATH format is known from Per Peer Header
+			ph := testPeerHeader(t, 1)
+			ph.FlagA = tt.flagA
+			publisher := &recordingPublisher{}
+			p := NewProducer(publisher, Session{Addr: "192.0.2.1"}, 1, 1).(*producer)
+			p.produceRouteMirrorMessage(bmp.Message{PeerHeader: ph, Payload: &bmp.RouteMirror{BGPMessage: pdu}})
+			if len(publisher.msgs) != 1 {
+				t.Fatalf("expected 1 message but got %d", len(publisher.msgs))
+			}
+			var m RouteMirror
+			if err := json.Unmarshal(publisher.msgs[0].msg, &m); err != nil {
+				t.Fatalf("failed to unmarshal route mirror with error: %+v", err)
+			}
+			if !reflect.DeepEqual(tt.expect, m.ASPath) {
+				t.Fatalf("expected as path %v but got %v (decode error %q)", tt.expect, m.ASPath, m.DecodeError)
+			}
+		})
+	}
+}
+
+// blockingPublisher blocks publishing until released
+type blockingPublisher struct {
+	recordingPublisher
+	release chan struct{}
+}
+
+func (b *blockingPublisher) PublishMessage(msgType int, msgHash []byte, msg []byte) error {
+	<-b.release
+	return b.recordingPublisher.PublishMessage(msgType, msgHash, msg)
+}
+
+func TestProducerStopBlocked(t *testing.T) {
+	publisher := &blockingPublisher{release: make(chan struct{})}
+	prod := NewProducer(publisher, Session{Addr: "192.0.2.1"}, 1, 1)
+	queue := make(chan bmp.Message, 4)
+	for i := 1; i <= cap(queue); i++ {
+		queue <- bmp.Message{
+			PeerHeader: testPeerHeader(t, 1),
+			Payload: &bmp.StatsReport{
+				Stats: []bmp.Stat{{Type: bmp.StatRejectedPrefixes, Value: uint64(i)}},
+			},
+		}
+	}
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		prod.Producer(queue, stop)
+		close(done)
+	}()
+	// The worker blocks publishing the first message, the second one fills its queue and dispatching the third blocks
+	for len(queue) != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	close(stop)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Producer blocked on full worker queue did not return after stop")
+	}
+	close(publisher.release)
+	// Messages left in the worker's queue are not published after stop
+	time.Sleep(10 * time.Millisecond)
+	publisher.Lock()
+	defer publisher.Unlock()
+	if len(publisher.msgs) > 1 {
+		t.Fatalf("expected at most 1 message published but got %d", len(publisher.msgs))
+	}
+}
+
+func TestProducerStatusRIBStats(t *testing.T) {
+	p := NewProducer(&recordingPublisher{}, Session{Addr: "192.0.2.1"}, 1, 1).(*producer)
+	reports := [][]bmp.Stat{
+		{
+			{Type: bmp.StatAdjRIBIn, Value: 10},
+			{Type: bmp.StatAFISAFIAdjRIBIn, AFI: 1, SAFI: 1, Value: 7},
+			{Type: bmp.StatAFISAFIAdjRIBIn, AFI: 2, SAFI: 1, Value: 3},
+		},
+		// Later report carries only the numbers which changed
+		{
+			{Type: bmp.StatAdjRIBIn, Value: 8},
+			{Type: bmp.StatAFISAFIAdjRIBIn, AFI: 2, SAFI: 1, Value: 1},
+			{Type: bmp.StatAFISAFILocRIB, AFI: 2, SAFI: 1, Value: 0},
+		},
+	}
+	for _, stats := range reports {
+		p.produceStatsMessage(bmp.Message{PeerHeader: testPeerHeader(t, 1), Payload: &bmp.StatsReport{Stats: stats}})
+	}
+	peer := p.Status().Peers[0]
+	if peer.AdjRIBIn == nil || *peer.AdjRIBIn != 8 || peer.LocRIB != nil {
+		t.Fatalf("expected adj-rib-in 8 and no loc-rib but got %v and %v", peer.AdjRIBIn, peer.LocRIB)
+	}
+	expect := map[string]string{"1/1": "7/-", "2/1": "1/0"}
+	got := make(map[string]string)
+	for _, st := range peer.AFISAFI {
+		gauge := func(v *uint64) string {
+			if v == nil {
+				return "-"
+			}
+			return fmt.Sprintf("%d", *v)
+		}
+		got[fmt.Sprintf("%d/%d", st.AFI, st.SAFI)] = gauge(st.AdjRIBIn) + "/" + gauge(st.LocRIB)
+	}
+	if !reflect.DeepEqual(expect, got) {
+		t.Fatalf("expected afi/safi adj-rib-in/loc-rib %v but got %v", expect, got)
+	}
+}
+
+func TestPrefixHash(t *testing.T) {
+	// peerHeader returns the per peer header of the peer 10.0.0.1 with the peer type, the flags and
+	// the peer distinguisher 65000:rd
+	peerHeader := func(peerType, flags byte, rd byte) *bmp.PerPeerHeader {
+		b := make([]byte, bmp.PerPeerHeaderLength)
+		b[0], b[1] = peerType, flags
+		if rd != 0 {
+			copy(b[2:10], []byte{0, 0, 0xfd, 0xe8, 0, 0, 0, rd})
+		}
+		copy(b[22:26], []byte{10, 0, 0, 1})
+		copy(b[26:30], []byte{0, 0, 0xfd, 0xe8})
+		copy(b[30:34], []byte{10, 0, 0, 1})
+		ph, err := bmp.UnmarshalPerPeerHeader(b)
+		if err != nil {
+			t.Fatalf("failed to build per peer header with error: %+v", err)
+		}
+		return ph
+	}
+	peers := map[string]*bmp.PerPeerHeader{
+		"pre-policy adj-rib-in":    peerHeader(0, 0, 0),
+		"post-policy adj-rib-in":   peerHeader(0, 0x40, 0),
+		"pre-policy adj-rib-out":   peerHeader(0, 0x10, 0),
+		"post-policy adj-rib-out":  peerHeader(0, 0x50, 0),
+		"rd peer pre-policy":       peerHeader(1, 0, 1),
+		"other rd peer pre-policy": peerHeader(1, 0, 2),
+		"loc-rib":                  peerHeader(bmp.PeerTypeLocRIB, 0, 0),
+		"loc-rib filtered":         peerHeader(bmp.PeerTypeLocRIB, 0x80, 0),
+	}
+	hashes := make(map[string]string)
+	for name, ph := range peers {
+		hash := prefixHash(ph, 0, "10.1.2.0", "24")
+		if other, ok := hashes[hash]; ok {
+			t.Fatalf("%s and %s routes have the same hash %s", name, other, hash)
+		}
+		hashes[hash] = name
+	}
+	if prefixHash(peers["pre-policy adj-rib-in"], 0, "10.1.2.0", "24") != prefixHash(peerHeader(0, 0, 0), 0, "10.1.2.0", "24") {
+		t.Fatalf("expected the same hash of the same route")
+	}
+}
+
+// decodeErrorsCount returns the value of gobmp_decode_errors_total metric of the package and kind
+func decodeErrorsCount(t *testing.T, pkg, kind string) string {
+	var b bytes.Buffer
+	if err := metrics.DefaultRegistry.Write(&b); err != nil {
+		t.Fatalf("fail to write metrics with error: %+v", err)
+	}
+	prefix := fmt.Sprintf("gobmp_decode_errors_total{package=%q,kind=%q} ", pkg, kind)
+	for _, line := range strings.Split(b.String(), "\n") {
+		if strings.HasPrefix(line, prefix) {
+			return strings.TrimPrefix(line, prefix)
+		}
+	}
+
+	return "0"
+}
+
+func TestCountDecodeError(t *testing.T) {
+	// Building errors does not count them, decoders may probe data expected to fail
+	for i := 0; i < 10; i++ {
+		bmp.UnmarshalCommonHeader([]byte{3})
+	}
+	if c := decodeErrorsCount(t, "bmp", "truncated"); c != "0" {
+		t.Fatalf("expected no decode errors counted but got %s", c)
+	}
+	_, err := bmp.UnmarshalCommonHeader([]byte{3})
+	if !CountDecodeError(fmt.Errorf("fail to recover BMP message with error: %w", err)) {
+		t.Fatalf("expected wrapped decode error to be counted")
+	}
+	if CountDecodeError(errors.New("not a decode error")) {
+		t.Fatalf("expected error which is not decode error not to be counted")
+	}
+	if c := decodeErrorsCount(t, "bmp", "truncated"); c != "1" {
+		t.Fatalf("expected 1 decode error counted but got %s", c)
+	}
+}
@@ -0,0 +1,185 @@
+package message
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/sbezverk/gobmp/pkg/bmp"
+	"github.com/sbezverk/gobmp/pkg/pub"
+	"github.com/sbezverk/gobmp/pkg/tools"
+)
+
+// decoder builds errors of decoding OpenBMP binary headers
+const decoder tools.Decoder = "message"
+
+const (
+	openBMPMagic        = "OBMP"
+	openBMPMajorVersion = 1
+	openBMPMinorVersion = 7
+	// openBMPFlagRouter marks the message as received from a router, not generated by the collector
+	openBMPFlagRouter = 0x80
+	// openBMPFlagIPv6 marks Router IP as IPv6 address
+	openBMPFlagIPv6 = 0x40
+	// openBMPTypeBMPRaw is the object type of raw BMP message
+	openBMPTypeBMPRaw = 12
+	// openBMPFixedLength is the length of OpenBMP binary header without Collector Admin ID and Router Group
+	openBMPFixedLength = 78
+)
+
+// OpenBMPHeader defines OpenBMP v2 binary header carried by raw BMP messages, the header
+// identifies the collector and the router the BMP message was received from.
+type OpenBMPHeader struct {
+	Timestamp        time.Time
+	CollectorHash    [16]byte
+	CollectorAdminID string
+	RouterHash       [16]byte
+	RouterIP         net.IP
+	RouterGroup      string
+}
+
+// Serialize returns the BMP message prepended by OpenBMP binary header
+func (h *OpenBMPHeader) Serialize(msg []byte) []byte {
+	hl := openBMPFixedLength + len(h.CollectorAdminID) + len(h.RouterGroup)
+	b := make([]byte, hl, hl+len(msg))
+	p := copy(b, openBMPMagic)
+	b[p] = openBMPMajorVersion
+	b[p+1] = openBMPMinorVersion
+	binary.BigEndian.PutUint16(b[p+2:], uint16(hl))
+	binary.BigEndian.PutUint32(b[p+4:], uint32(len(msg)))
+	p += 8
+	b[p] = openBMPFlagRouter
+	ip := h.RouterIP.To4()
+	if ip == nil {
+		b[p] |= openBMPFlagIPv6
+		ip = h.RouterIP.To16()
+	}
+	b[p+1] = openBMPTypeBMPRaw
+	p += 2
+	binary.BigEndian.PutUint32(b[p:], uint32(h.Timestamp.Unix()))
+	binary.BigEndian.PutUint32(b[p+4:], uint32(h.Timestamp.Nanosecond()/1000))
+	p += 8
+	p += copy(b[p:], h.CollectorHash[:])
+	binary.BigEndian.PutUint16(b[p:], uint16(len(h.CollectorAdminID)))
+	p += 2
+	p += copy(b[p:], h.CollectorAdminID)
+	p += copy(b[p:], h.RouterHash[:])
+	// IPv4 address occupies the first 4 bytes of Router IP field
+	copy(b[p:p+16], ip)
+	p += 16
+	binary.BigEndian.PutUint16(b[p:], uint16(len(h.RouterGroup)))
+	p += 2
+	p += copy(b[p:], h.RouterGroup)
+	// Row count, a raw message always carries a single BMP message
+	binary.BigEndian.PutUint32(b[p:], 1)
+
+	return append(b, msg...)
+}
+
+// UnmarshalOpenBMPRaw returns OpenBMP binary header and the BMP message of a raw BMP message
+func UnmarshalOpenBMPRaw(b []byte) (*OpenBMPHeader, []byte, error) {
+	if err := decoder.CheckLength("openbmp header", b, openBMPFixedLength); err != nil {
+		return nil, nil, err
+	}
+	if string(b[:4]) != openBMPMagic {
+		return nil, nil, decoder.Malformed("openbmp header", "invalid magic number %x", b[:4])
+	}
+	if b[4] != openBMPMajorVersion {
+		return nil, nil, decoder.Malformed("openbmp header", "unsupported version %d.%d", b[4], b[5])
+	}
+	hl := int(binary.BigEndian.Uint16(b[6:8]))
+	ml := int(binary.BigEndian.Uint32(b[8:12]))
+	if err := decoder.CheckLength("openbmp raw message", b, hl+ml); err != nil {
+		return nil, nil, err
+	}
+	if b[13] != openBMPTypeBMPRaw {
+		return nil, nil, decoder.Malformed("openbmp header", "unsupported object type %d", b[13])
+	}
+	h := &OpenBMPHeader{
+		Timestamp: time.Unix(int64(binary.BigEndian.Uint32(b[14:18])), int64(binary.BigEndian.Uint32(b[18:22]))*1000).UTC(),
+	}
+	p := 22
+	p += copy(h.CollectorHash[:], b[p:])
+	l := int(binary.BigEndian.Uint16(b[p:]))
+	p += 2
+	// Router Hash, Router IP, Router Group Length and Row Count follow Collector Admin ID
+	if p+l+38 > hl {
+		return nil, nil, decoder.Malformed("openbmp header", "collector admin id length %d exceeds header length %d", l, hl)
+	}
+	h.CollectorAdminID = string(b[p : p+l])
+	p += l
+	p += copy(h.RouterHash[:], b[p:])
+	if b[12]&openBMPFlagIPv6 != 0 {
+		h.RouterIP = net.IP(append([]byte{}, b[p:p+16]...))
+	} else {
+		h.RouterIP = net.IP(append([]byte{}, b[p:p+4]...))
+	}
+	p += 16
+	l = int(binary.BigEndian.Uint16(b[p:]))
+	p += 2
+	if p+l+4 > hl {
+		return nil, nil, decoder.Malformed("openbmp header", "router group length %d exceeds header length %d", l, hl)
+	}
+	h.RouterGroup = string(b[p : p+l])
+
+	return h, b[hl : hl+ml], nil
+}
+
+// RawProducer publishes BMP messages of a session as received from the router, wrapped in
+// OpenBMP binary header, to bmp_raw topic.
+type RawProducer struct {
+	publisher pub.Publisher
+	header    OpenBMPHeader
+	key       []byte
+}
+
+// NewRawProducer instantiates a new instance of a raw messages producer, session identifies the router
+// originating BMP session and adminID identifies the collector.
+func NewRawProducer(publisher pub.Publisher, session Session, adminID string) *RawProducer {
+	collectorHash := md5.Sum([]byte(adminID))
+	r := &RawProducer{
+		publisher: publisher,
+		header: OpenBMPHeader{
+			CollectorHash:    collectorHash,
+			CollectorAdminID: adminID,
+			// As OpenBMP does, the router's hash is computed over the router's IP and the collector's hash
+			RouterHash:  md5.Sum(append([]byte(session.Addr), collectorHash[:]...)),
+			RouterIP:    net.ParseIP(session.Addr),
+			RouterGroup: session.Label,
+		},
+	}
+	if r.header.RouterIP == nil {
+		r.header.RouterIP = net.IPv4zero
+	}
+	r.key = []byte(fmt.Sprintf("%x", r.header.RouterHash))
+
+	return r
+}
+
+// Producer publishes BMP messages received from the queue until the queue is closed or stop is closed
+func (r *RawProducer) Producer(queue chan []byte, stop chan struct{}) {
+	for {
+		select {
+		case msg, ok := <-queue:
+			if !ok {
+				return
+			}
+			r.produce(msg)
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (r *RawProducer) produce(msg []byte) {
+	h := r.header
+	h.Timestamp = time.Now()
+	if err := r.publisher.PublishMessage(bmp.BMPRawMsg, r.key, h.Serialize(msg)); err != nil {
+		glog.Errorf("failed to push raw BMP message to kafka with error: %+v", err)
+		return
+	}
+	producedMessages.With(TypeName(bmp.BMPRawMsg)).Inc()
+}
@@ -0,0 +1,90 @@
+package message
+
+import (
+	"bytes"
+	"crypto/md5"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+func TestOpenBMPRaw(t *testing.T) {
+	msg := []byte{3, 0, 0, 0, 12, 4, 0, 2, 0, 2, 'r', '1'}
+	tests := []struct {
+		name   string
+		header OpenBMPHeader
+		flags  byte
+	}{
+		{
+			name: "ipv4 router",
+			header: OpenBMPHeader{
+				Timestamp:        time.Unix(1600000000, 123456000).UTC(),
+				CollectorHash:    md5.Sum([]byte("collector1")),
+				CollectorAdminID: "collector1",
+				RouterHash:       md5.Sum([]byte("192.0.2.1")),
+				RouterIP:         net.IP{192, 0, 2, 1},
+				RouterGroup:      "region1",
+			},
+			flags: 0x80,
+		},
+		{
+			name: "ipv6 router without group",
+			header: OpenBMPHeader{
+				Timestamp:        time.Unix(1600000000, 0).UTC(),
+				CollectorHash:    md5.Sum([]byte("collector1")),
+				CollectorAdminID: "collector1",
+				RouterIP:         net.ParseIP("2001:db8::1"),
+			},
+			flags: 0xc0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := tt.header.Serialize(msg)
+			if string(b[:4]) != "OBMP" || b[4] != 1 || b[5] != 7 || b[12] != tt.flags || b[13] != 12 {
+				t.Fatalf("invalid openbmp header %v", b[:14])
+			}
+			h, m, err := UnmarshalOpenBMPRaw(b)
+			if err != nil {
+				t.Fatalf("fail to unmarshal raw message with error: %+v", err)
+			}
+			if !bytes.Equal(msg, m) {
+				t.Fatalf("expected bmp message %v but got %v", msg, m)
+			}
+			if !h.Timestamp.Equal(tt.header.Timestamp) || h.CollectorHash != tt.header.CollectorHash || h.CollectorAdminID != tt.header.CollectorAdminID ||
+				h.RouterHash != tt.header.RouterHash || !h.RouterIP.Equal(tt.header.RouterIP) || h.RouterGroup != tt.header.RouterGroup {
+				t.Fatalf("expected header %+v but got %+v", tt.header, *h)
+			}
+			if _, _, err := UnmarshalOpenBMPRaw(b[:len(b)-1]); err == nil {
+				t.Fatalf("expected failure for truncated message")
+			}
+		})
+	}
+}
+
+func TestRawProducer(t *testing.T) {
+	publisher := &recordingPublisher{}
+	r := NewRawProducer(publisher, Session{Addr: "192.0.2.1", Label: "region1"}, "collector1")
+	queue := make(chan []byte, 2)
+	queue <- []byte{3, 0, 0, 0, 6, 4}
+	queue <- []byte{3, 0, 0, 0, 6, 5}
+	close(queue)
+	r.Producer(queue, make(chan struct{}))
+	if len(publisher.msgs) != 2 {
+		t.Fatalf("expected 2 raw messages but got %d", len(publisher.msgs))
+	}
+	for i, rec := range publisher.msgs {
+		if rec.msgType != bmp.BMPRawMsg {
+			t.Fatalf("expected message type %d but got %d", bmp.BMPRawMsg, rec.msgType)
+		}
+		h, m, err := UnmarshalOpenBMPRaw(rec.msg)
+		if err != nil {
+			t.Fatalf("fail to unmarshal raw message with error: %+v", err)
+		}
+		if m[5] != byte(4+i) || h.RouterGroup != "region1" || h.CollectorAdminID != "collector1" || h.RouterIP.String() != "192.0.2.1" {
+			t.Fatalf("unexpected raw message %+v %v", *h, m)
+		}
+	}
+}
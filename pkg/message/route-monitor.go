@@ -1,8 +1,10 @@
 package message
 
 import (
+	"crypto/md5"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/golang/glog"
 	"github.com/sbezverk/gobmp/pkg/bgp"
@@ -17,7 +19,6 @@ const (
 )
 
 func (p *producer) produceRouteMonitorMessage(msg bmp.Message) {
-	var j []byte
 	if msg.PeerHeader == nil {
 		glog.Errorf("perPeerHeader is missing, cannot construct PeerStateChange message")
 		return
@@ -27,55 +28,99 @@ func (p *producer) produceRouteMonitorMessage(msg bmp.Message) {
 		glog.Errorf("got invalid Payload type in bmp.Message")
 		return
 	}
-	if routeMonitorMsg == nil {
+	if routeMonitorMsg == nil || routeMonitorMsg.Update == nil {
 		glog.Errorf("route monitor message is nil")
 		return
 	}
-	if len(routeMonitorMsg.Update.PathAttributes) == 0 {
-		// There is no Path Attributes, just return
+	ph := msg.PeerHeader
+	update := routeMonitorMsg.Update
+	if afi, safi, ok := update.IsEndOfRIB(); ok {
+		glog.V(5).Infof("End-of-RIB for AFI: %d SAFI: %d received from peer: %s", afi, safi, ph.GetPeerHash())
+		p.state.endOfRIB(ph, afi, safi)
 		return
 	}
-	// Using first attribute type to select which nlri processor to call
-	switch routeMonitorMsg.Update.PathAttributes[0].AttributeType {
-	case 14:
-		nlri, err := bgp.UnmarshalMPReachNLRI(routeMonitorMsg.Update.PathAttributes[0].Attribute)
-		if err != nil {
-			glog.Errorf("failed to process MP_REACH_NLRI with error: %+v", err)
+	// Withdrawn routes are processed before reachable routes, MP_REACH_NLRI and MP_UNREACH_NLRI can appear
+	// in any order among path attributes and an update can carry both of them along with original BGP's NLRI.
+	if len(update.WithdrawnRoutes) != 0 {
+		p.produceBaseNLRI(DelPrefix, ph, update)
+	}
+	for _, attr := range update.PathAttributes {
+		if attr.AttributeType != 15 {
+			continue
 		}
-		p.processMPUpdate(nlri, AddPrefix, msg.PeerHeader, routeMonitorMsg.Update)
-	case 15:
-		// MP_UNREACH_NLRI
-		nlri, err := bgp.UnmarshalMPUnReachNLRI(routeMonitorMsg.Update.PathAttributes[0].Attribute)
+		nlri, err := bgp.UnmarshalMPUnReachNLRI(attr.Attribute, update.AddPath)
 		if err != nil {
-			glog.Errorf("failed to process MP_REACH_NLRI with error: %+v", err)
+			glog.Errorf("failed to process MP_UNREACH_NLRI with error: %+v", err)
+			CountDecodeError(err)
+			continue
+		}
+		if mp, ok := nlri.(*bgp.MPUnReachNLRI); ok && len(mp.WithdrawnRoutes) == 0 {
+			// Nothing is withdrawn
+			continue
 		}
-		p.processMPUpdate(nlri, DelPrefix, msg.PeerHeader, routeMonitorMsg.Update)
-	default:
-		// Original BGP's NLRI messages processing
-		msgs := make([]UnicastPrefix, 0)
-		if routeMonitorMsg.Update.WithdrawnRoutesLength != 0 {
-			msg, err := p.nlri(DelPrefix, msg.PeerHeader, routeMonitorMsg.Update)
-			if err != nil {
-				glog.Errorf("failed to produce original NLRI Withdraw message with error: %+v", err)
-				return
-			}
-			msgs = append(msgs, msg...)
+		p.processMPUpdate(nlri, DelPrefix, ph, update)
+	}
+	for _, attr := range update.PathAttributes {
+		if attr.AttributeType != 14 {
+			continue
 		}
-		msg, err := p.nlri(AddPrefix, msg.PeerHeader, routeMonitorMsg.Update)
+		nlri, err := bgp.UnmarshalMPReachNLRI(attr.Attribute, update.AddPath)
 		if err != nil {
-			glog.Errorf("failed to produce original NLRI Withdraw message with error: %+v", err)
+			glog.Errorf("failed to process MP_REACH_NLRI with error: %+v", err)
+			CountDecodeError(err)
+			continue
+		}
+		p.processMPUpdate(nlri, AddPrefix, ph, update)
+	}
+	if len(update.NLRI) != 0 {
+		p.produceBaseNLRI(AddPrefix, ph, update)
+	}
+}
+
+// produceBaseNLRI publishes Unicast Prefix messages for original BGP's NLRI or withdrawn routes depending on the operation
+func (p *producer) produceBaseNLRI(operation int, ph *bmp.PerPeerHeader, update *bgp.Update) {
+	msgs, err := p.nlri(operation, ph, update)
+	if err != nil {
+		glog.Errorf("failed to produce original NLRI message with error: %+v", err)
+		CountDecodeError(err)
+		return
+	}
+	for _, m := range msgs {
+		if err := p.marshalAndPublish(&m, bmp.UnicastPrefixMsg, []byte(m.RouterHash), false); err != nil {
+			glog.Errorf("failed to process Unicast Prefix message with error: %+v", err)
 			return
 		}
-		msgs = append(msgs, msg...)
-		// Loop through and publish all collected messages
-		for _, m := range msgs {
-			if err := p.marshalAndPublish(&m, bmp.UnicastPrefixMsg, []byte(m.RouterHash), false); err != nil {
-				glog.Errorf("failed to process Unicast Prefix message with error: %+v", err)
-				return
-			}
-			glog.V(6).Infof("unicast_prefix message: %s", string(j))
+		p.state.countPrefixes(ph, operation, 1)
+	}
+}
+
+// prefixHash returns the hash identifying a route advertised by the peer, key carries the fields identifying
+// the route within the peer's RIB, the routes of ADD-PATH peers are distinguished by their Path Identifiers.
+// The same route reported from pre and post policy Adj-RIB-In, Adj-RIB-Out, Loc-RIB or by other peer instance,
+// identified by Peer Distinguisher, has a different hash.
+func prefixHash(ph *bmp.PerPeerHeader, pathID int32, key ...string) string {
+	data := fmt.Sprintf("%s-%s-%t-%t-%t-%t-%s-%d", ph.GetPeerHash(), ph.PeerDistinguisher.String(), ph.IsAdjRIBOut(),
+		ph.IsPrepolicy(), ph.IsLocRIB(), ph.IsLocRIBFiltered(), strings.Join(key, "-"), pathID)
+
+	return fmt.Sprintf("%x", md5.Sum([]byte(data)))
+}
+
+// extCommunities returns the structured form of Extended Communities and IPv6 Address Specific Extended Communities
+// found in bgp update
+func extCommunities(update *bgp.Update) []bgp.ExtCommunityDetails {
+	exts := make([]bgp.ExtCommunityDetails, 0)
+	if ecs, err := update.GetAttrExtCommunity(); err == nil {
+		for _, ec := range ecs {
+			exts = append(exts, *ec.Details())
+		}
+	}
+	if ecs, err := update.GetAttrIPv6ExtCommunity(); err == nil {
+		for _, ec := range ecs {
+			exts = append(exts, *ec.Details())
 		}
 	}
+
+	return exts
 }
 
 func (p *producer) marshalAndPublish(msg interface{}, msgType int, hash []byte, debug bool) error {
@@ -83,7 +128,7 @@ func (p *producer) marshalAndPublish(msg interface{}, msgType int, hash []byte,
 	if err != nil {
 		return fmt.Errorf("failed to marshal a message of type %d with error: %+v", msgType, err)
 	}
-	if err := p.publisher.PublishMessage(msgType, hash, j); err != nil {
+	if err := p.publish(msgType, hash, j); err != nil {
 		return fmt.Errorf("failed to push a message of type %d to kafka with error: %+v", msgType, err)
 	}
 	if debug {
@@ -0,0 +1,62 @@
+package message
+
+import (
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+func (p *producer) produceInitiationMessage(msg bmp.Message) {
+	initMsg, ok := msg.Payload.(*bmp.InitiationMessage)
+	if !ok {
+		glog.Errorf("got invalid Payload type in bmp.Message")
+		return
+	}
+	// Saving router's identity, it is attached to all messages produced for this BMP session
+	if !p.nameConfigured {
+		p.routerName = initMsg.GetSysName()
+	}
+	p.state.setRouter(p.routerName, p.speakerIP)
+	m := RouterEvent{
+		Action:      "init",
+		Name:        p.routerName,
+		Description: initMsg.GetSysDescr(),
+		SessionAddr: p.sessionAddr,
+		Label:       p.label,
+		InfoData:    initMsg.GetString(),
+		Timestamp:   time.Now().UTC().Format(time.StampMicro),
+	}
+	if err := p.marshalAndPublish(&m, bmp.RouterEventMsg, []byte(m.SessionAddr), false); err != nil {
+		glog.Errorf("failed to process Initiation message with error: %+v", err)
+		return
+	}
+
+	glog.V(5).Infof("succeeded to push Initiation message to kafka")
+}
+
+func (p *producer) produceTerminationMessage(msg bmp.Message) {
+	termMsg, ok := msg.Payload.(*bmp.TerminationMessage)
+	if !ok {
+		glog.Errorf("got invalid Payload type in bmp.Message")
+		return
+	}
+	m := RouterEvent{
+		Action:      "term",
+		Name:        p.routerName,
+		SessionAddr: p.sessionAddr,
+		Label:       p.label,
+		InfoData:    termMsg.GetString(),
+		TermReason:  termMsg.GetReasonString(),
+		Timestamp:   time.Now().UTC().Format(time.StampMicro),
+	}
+	if r, ok := termMsg.GetReason(); ok {
+		m.TermReasonCode = int(r)
+	}
+	if err := p.marshalAndPublish(&m, bmp.RouterEventMsg, []byte(m.SessionAddr), false); err != nil {
+		glog.Errorf("failed to process Termination message with error: %+v", err)
+		return
+	}
+
+	glog.V(5).Infof("succeeded to push Termination message to kafka")
+}
@@ -0,0 +1,219 @@
+package message
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+const (
+	// PeerStateUp is the state of a peer after Peer Up message
+	PeerStateUp = "up"
+	// PeerStateDown is the state of a peer after Peer Down message
+	PeerStateDown = "down"
+)
+
+// SessionStatus defines the state of BMP session as reported by the router
+type SessionStatus struct {
+	RouterName string       `json:"router_name,omitempty"`
+	RouterIP   string       `json:"router_ip,omitempty"`
+	Peers      []PeerStatus `json:"peers"`
+}
+
+// PeerStatus defines the state of a BGP peer reported by the router over BMP session
+type PeerStatus struct {
+	PeerHash    string `json:"peer_hash"`
+	RemoteIP    string `json:"remote_ip"`
+	RemoteASN   int32  `json:"remote_asn"`
+	RemoteBGPID string `json:"remote_bgp_id"`
+	PeerRD      string `json:"peer_rd,omitempty"`
+	IsLocRIB    bool   `json:"is_loc_rib"`
+	// State is "up" or "down", empty when neither Peer Up nor Peer Down was received for the peer
+	State string `json:"state,omitempty"`
+	// Since is the time of the last Peer Up or Peer Down message
+	Since           time.Time `json:"since"`
+	AdvCapabilities string    `json:"adv_cap,omitempty"`
+	RcvCapabilities string    `json:"recv_cap,omitempty"`
+	LastDownReason  string    `json:"last_down_reason,omitempty"`
+	// AnnouncedTotal and WithdrawnTotal are the counters of unicast, L3VPN and EVPN prefixes announced and withdrawn
+	// by the peer, they grow for the life of BMP session and count duplicates and implicit withdraws
+	AnnouncedTotal uint64 `json:"announced_prefixes_total"`
+	WithdrawnTotal uint64 `json:"withdrawn_prefixes_total"`
+	// AdjRIBIn and LocRIB are the current numbers of the peer's routes, stat types 7 and 8 of the last Stats Report
+	// message, AFISAFI carries per AFI/SAFI numbers. They are nil until reported and are cleared by Peer Up and Peer Down.
+	AdjRIBIn *uint64        `json:"adj_rib_in,omitempty"`
+	LocRIB   *uint64        `json:"loc_rib,omitempty"`
+	AFISAFI  []AFISAFIStats `json:"afi_safi,omitempty"`
+	// EndOfRIB lists AFI/SAFI of End-of-RIB markers received from the peer since the last Peer Up message
+	EndOfRIB []string `json:"end_of_rib,omitempty"`
+}
+
+// sessionState keeps the state of BMP session, it is updated by the producer's workers
+// and read by monitoring.
+type sessionState struct {
+	sync.Mutex
+	routerName string
+	routerIP   string
+	peers      map[string]*PeerStatus
+}
+
+func newSessionState() *sessionState {
+	return &sessionState{
+		peers: make(map[string]*PeerStatus),
+	}
+}
+
+// peer returns the peer's state, the state is allocated if it does not exist yet, must be called with the lock held.
+// Peers are identified by Peer Distinguisher and Peer Hash, Loc-RIB instance peers and the peers of different
+// instances can share the address, AS and BGP ID.
+func (s *sessionState) peer(ph *bmp.PerPeerHeader) *PeerStatus {
+	hash := ph.GetPeerHash()
+	key := ph.PeerDistinguisher.String() + "-" + hash
+	if peer, ok := s.peers[key]; ok {
+		return peer
+	}
+	peer := &PeerStatus{
+		PeerHash:  hash,
+		RemoteASN: ph.PeerAS,
+		PeerRD:    ph.PeerDistinguisher.String(),
+		IsLocRIB:  ph.IsLocRIB(),
+	}
+	if ph.FlagV {
+		peer.RemoteIP = net.IP(ph.PeerAddress).To16().String()
+		peer.RemoteBGPID = net.IP(ph.PeerBGPID).To16().String()
+	} else {
+		peer.RemoteIP = net.IP(ph.PeerAddress[12:]).To4().String()
+		peer.RemoteBGPID = net.IP(ph.PeerBGPID).To4().String()
+	}
+	s.peers[key] = peer
+
+	return peer
+}
+
+func (s *sessionState) setRouter(name, ip string) {
+	s.Lock()
+	defer s.Unlock()
+	s.routerName = name
+	s.routerIP = ip
+}
+
+func (s *sessionState) peerUp(ph *bmp.PerPeerHeader, advCaps, rcvCaps string) {
+	s.Lock()
+	defer s.Unlock()
+	peer := s.peer(ph)
+	peer.State = PeerStateUp
+	peer.Since = time.Now().UTC()
+	peer.AdvCapabilities = advCaps
+	peer.RcvCapabilities = rcvCaps
+	peer.EndOfRIB = nil
+	peer.clearRIBStats()
+}
+
+func (s *sessionState) peerDown(ph *bmp.PerPeerHeader, reason string) {
+	s.Lock()
+	defer s.Unlock()
+	peer := s.peer(ph)
+	peer.State = PeerStateDown
+	peer.Since = time.Now().UTC()
+	peer.LastDownReason = reason
+	peer.clearRIBStats()
+}
+
+// clearRIBStats clears the numbers of routes reported for the previous BGP session of the peer
+func (peer *PeerStatus) clearRIBStats() {
+	peer.AdjRIBIn = nil
+	peer.LocRIB = nil
+	peer.AFISAFI = nil
+}
+
+// countPrefixes counts n prefixes announced or withdrawn by the peer depending on the operation
+func (s *sessionState) countPrefixes(ph *bmp.PerPeerHeader, operation int, n int) {
+	s.Lock()
+	defer s.Unlock()
+	peer := s.peer(ph)
+	switch operation {
+	case AddPrefix:
+		peer.AnnouncedTotal += uint64(n)
+	case DelPrefix:
+		peer.WithdrawnTotal += uint64(n)
+	}
+}
+
+// endOfRIB records End-of-RIB marker of AFI/SAFI received from the peer
+func (s *sessionState) endOfRIB(ph *bmp.PerPeerHeader, afi uint16, safi uint8) {
+	s.Lock()
+	defer s.Unlock()
+	peer := s.peer(ph)
+	afiSAFI := fmt.Sprintf("%d/%d", afi, safi)
+	for _, eor := range peer.EndOfRIB {
+		if eor == afiSAFI {
+			return
+		}
+	}
+	peer.EndOfRIB = append(peer.EndOfRIB, afiSAFI)
+}
+
+// setRIBStats records the numbers of routes reported by the router in Stats Report message, the numbers
+// not reported in the message keep their previous values.
+func (s *sessionState) setRIBStats(ph *bmp.PerPeerHeader, m *Stats) {
+	s.Lock()
+	defer s.Unlock()
+	peer := s.peer(ph)
+	if m.AdjRIBIn != nil {
+		peer.AdjRIBIn = m.AdjRIBIn
+	}
+	if m.LocRIB != nil {
+		peer.LocRIB = m.LocRIB
+	}
+	for _, st := range m.AFISAFI {
+		if st.AdjRIBIn == nil && st.LocRIB == nil {
+			continue
+		}
+		i := 0
+		for ; i < len(peer.AFISAFI); i++ {
+			if peer.AFISAFI[i].AFI == st.AFI && peer.AFISAFI[i].SAFI == st.SAFI {
+				break
+			}
+		}
+		if i == len(peer.AFISAFI) {
+			peer.AFISAFI = append(peer.AFISAFI, AFISAFIStats{AFI: st.AFI, SAFI: st.SAFI})
+		}
+		if st.AdjRIBIn != nil {
+			peer.AFISAFI[i].AdjRIBIn = st.AdjRIBIn
+		}
+		if st.LocRIB != nil {
+			peer.AFISAFI[i].LocRIB = st.LocRIB
+		}
+	}
+}
+
+func (s *sessionState) status() SessionStatus {
+	s.Lock()
+	defer s.Unlock()
+	status := SessionStatus{
+		RouterName: s.routerName,
+		RouterIP:   s.routerIP,
+		Peers:      make([]PeerStatus, 0, len(s.peers)),
+	}
+	for _, peer := range s.peers {
+		ps := *peer
+		ps.EndOfRIB = append([]string(nil), peer.EndOfRIB...)
+		ps.AFISAFI = append([]AFISAFIStats(nil), peer.AFISAFI...)
+		status.Peers = append(status.Peers, ps)
+	}
+	sort.Slice(status.Peers, func(i, j int) bool {
+		if status.Peers[i].RemoteIP != status.Peers[j].RemoteIP {
+			return status.Peers[i].RemoteIP < status.Peers[j].RemoteIP
+		}
+		if status.Peers[i].PeerRD != status.Peers[j].PeerRD {
+			return status.Peers[i].PeerRD < status.Peers[j].PeerRD
+		}
+		return status.Peers[i].PeerHash < status.Peers[j].PeerHash
+	})
+
+	return status
+}
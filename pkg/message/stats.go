@@ -0,0 +1,113 @@
+package message
+
+import (
+	"net"
+
+	"github.com/golang/glog"
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+func (p *producer) produceStatsMessage(msg bmp.Message) {
+	if msg.PeerHeader == nil {
+		glog.Errorf("perPeerHeader is missing, cannot construct Stats message")
+		return
+	}
+	statsMsg, ok := msg.Payload.(*bmp.StatsReport)
+	if !ok {
+		glog.Errorf("got invalid Payload type in bmp.Message")
+		return
+	}
+	m := Stats{
+		RouterIP:    p.speakerIP,
+		RouterName:  p.routerName,
+		Label:       p.label,
+		SessionAddr: p.sessionAddr,
+		RouterHash:  p.speakerHash,
+		PeerHash:    msg.PeerHeader.GetPeerHash(),
+		RemoteASN:   msg.PeerHeader.PeerAS,
+		PeerRD:      msg.PeerHeader.PeerDistinguisher.String(),
+		Timestamp:   msg.PeerHeader.PeerTimestamp,
+	}
+	if msg.PeerHeader.FlagV {
+		m.IsIPv4 = false
+		m.RemoteIP = net.IP(msg.PeerHeader.PeerAddress).To16().String()
+		m.RemoteBGPID = net.IP(msg.PeerHeader.PeerBGPID).To16().String()
+	} else {
+		m.IsIPv4 = true
+		m.RemoteIP = net.IP(msg.PeerHeader.PeerAddress[12:]).To4().String()
+		m.RemoteBGPID = net.IP(msg.PeerHeader.PeerBGPID).To4().String()
+	}
+	for _, s := range statsMsg.Stats {
+		switch s.Type {
+		case bmp.StatRejectedPrefixes:
+			m.RejectedPrefixes = counter(s.Value)
+		case bmp.StatDuplicatePrefixes:
+			m.DuplicatePrefixes = counter(s.Value)
+		case bmp.StatDuplicateWithdraws:
+			m.DuplicateWithdraws = counter(s.Value)
+		case bmp.StatInvalidatedClusterList:
+			m.InvalidatedClusterList = counter(s.Value)
+		case bmp.StatInvalidatedASPath:
+			m.InvalidatedASPath = counter(s.Value)
+		case bmp.StatInvalidatedOriginatorID:
+			m.InvalidatedOriginatorID = counter(s.Value)
+		case bmp.StatInvalidatedASConfed:
+			m.InvalidatedASConfed = counter(s.Value)
+		case bmp.StatTreatAsWithdrawUpdates:
+			m.TreatAsWithdrawUpdates = counter(s.Value)
+		case bmp.StatTreatAsWithdrawPrefixes:
+			m.TreatAsWithdrawPrefixes = counter(s.Value)
+		case bmp.StatDuplicateUpdates:
+			m.DuplicateUpdates = counter(s.Value)
+		case bmp.StatAdjRIBIn:
+			m.AdjRIBIn = gauge(s.Value)
+		case bmp.StatLocRIB:
+			m.LocRIB = gauge(s.Value)
+		case bmp.StatPrePolicyAdjRIBOut:
+			m.PrePolicyAdjRIBOut = gauge(s.Value)
+		case bmp.StatPostPolicyAdjRIBOut:
+			m.PostPolicyAdjRIBOut = gauge(s.Value)
+		case bmp.StatAFISAFIAdjRIBIn:
+			m.getAFISAFI(s.AFI, s.SAFI).AdjRIBIn = gauge(s.Value)
+		case bmp.StatAFISAFILocRIB:
+			m.getAFISAFI(s.AFI, s.SAFI).LocRIB = gauge(s.Value)
+		case bmp.StatAFISAFIPrePolicyAdjRIBOut:
+			m.getAFISAFI(s.AFI, s.SAFI).PrePolicyAdjRIBOut = gauge(s.Value)
+		case bmp.StatAFISAFIPostPolicyAdjRIBOut:
+			m.getAFISAFI(s.AFI, s.SAFI).PostPolicyAdjRIBOut = gauge(s.Value)
+		}
+	}
+	p.state.setRIBStats(msg.PeerHeader, &m)
+	if err := p.marshalAndPublish(&m, bmp.StatsMsg, []byte(m.RouterHash), false); err != nil {
+		glog.Errorf("failed to process Stats message with error: %+v", err)
+		return
+	}
+
+	glog.V(5).Infof("succeeded to push Stats message to kafka")
+}
+
+// counter returns 32 bits counter reported in Stats Report message
+func counter(v uint64) *uint32 {
+	c := uint32(v)
+	return &c
+}
+
+// gauge returns 64 bits gauge reported in Stats Report message
+func gauge(v uint64) *uint64 {
+	return &v
+}
+
+// getAFISAFI returns per AFI/SAFI stats entry, the entry is allocated if it does not exist yet.
+func (s *Stats) getAFISAFI(afi uint16, safi uint8) *AFISAFIStats {
+	for i := range s.AFISAFI {
+		if s.AFISAFI[i].AFI == afi && s.AFISAFI[i].SAFI == safi {
+			return &s.AFISAFI[i]
+		}
+	}
+	s.AFISAFI = append(s.AFISAFI, AFISAFIStats{
+		AFI:  afi,
+		SAFI: safi,
+	})
+
+	return &s.AFISAFI[len(s.AFISAFI)-1]
+}
@@ -1,6 +1,7 @@
 package message
 
 import (
+	"github.com/sbezverk/gobmp/pkg/bgp"
 	"github.com/sbezverk/gobmp/pkg/prefixsid"
 	"github.com/sbezverk/gobmp/pkg/sr"
 	"github.com/sbezverk/gobmp/pkg/srv6"
@@ -15,6 +16,9 @@ type PeerStateChange struct {
 	Name             string `json:"name,omitempty"`
 	RemoteBGPID      string `json:"remote_bgp_id,omitempty"`
 	RouterIP         string `json:"router_ip,omitempty"`
+	RouterName       string `json:"router_name,omitempty"`
+	Label            string `json:"label,omitempty"`
+	SessionAddr      string `json:"session_addr,omitempty"`
 	Timestamp        string `json:"timestamp,omitempty"`
 	RemoteASN        int32  `json:"remote_asn,omitempty"`
 	RemoteIP         string `json:"remote_ip,omitempty"`
@@ -33,6 +37,7 @@ type PeerStateChange struct {
 	BMPErrorCode     int    `json:"bmp_error_code,omitempty"`
 	BMPErrorSubCode  int    `json:"bmp_error_sub_code,omitempty"`
 	ErrorText        string `json:"error_text,omitempty"`
+	FSMEventCode     int    `json:"fsm_event_code,omitempty"`
 	IsL3VPN          bool   `json:"is_l"`
 	IsPrepolicy      bool   `json:"isprepolicy"`
 	IsIPv4           bool   `json:"is_ipv4"`
@@ -44,37 +49,46 @@ type PeerStateChange struct {
 // UnicastPrefix defines a message format sent as a result of BMP Route Monitor message
 // which carries BGP Update with original NLRI information.
 type UnicastPrefix struct {
-	Action           string          `json:"action"` // Action can be "add" or "del"
-	Sequence         int             `json:"sequence,omitempty"`
-	Hash             string          `json:"hash,omitempty"`
-	RouterHash       string          `json:"router_hash,omitempty"`
-	RouterIP         string          `json:"router_ip,omitempty"`
-	BaseAttrHash     string          `json:"base_attr_hash,omitempty"`
-	PeerHash         string          `json:"peer_hash,omitempty"`
-	PeerIP           string          `json:"peer_ip,omitempty"`
-	PeerASN          int32           `json:"peer_asn,omitempty"`
-	Timestamp        string          `json:"timestamp,omitempty"`
-	Prefix           string          `json:"prefix,omitempty"`
-	PrefixLen        int32           `json:"prefix_len,omitempty"`
-	IsIPv4           bool            `json:"is_ipv4"`
-	Origin           string          `json:"origin,omitempty"`
-	ASPath           []uint32        `json:"as_path,omitempty"`
-	ASPathCount      int32           `json:"as_path_count,omitempty"`
-	OriginAS         string          `json:"origin_as,omitempty"`
-	Nexthop          string          `json:"nexthop,omitempty"`
-	MED              uint32          `json:"med,omitempty"`
-	LocalPref        uint32          `json:"local_pref,omitempty"`
-	Aggregator       string          `json:"aggregator,omitempty"`
-	CommunityList    string          `json:"community_list,omitempty"`
-	ExtCommunityList string          `json:"ext_community_list,omitempty"`
-	IsAtomicAgg      bool            `json:"is_atomic_agg"`
-	IsNexthopIPv4    bool            `json:"is_nexthop_ipv4"`
-	OriginatorID     string          `json:"originator_id,omitempty"`
-	PathID           int32           `json:"path_id,omitempty"`
-	Labels           []uint32        `json:"labels,omitempty"`
-	IsPrepolicy      bool            `json:"isprepolicy"`
-	IsAdjRIBIn       bool            `json:"is_adj_rib_in"`
-	PrefixSID        *prefixsid.PSid `json:"prefix_sid,omitempty"`
+	Action             string                    `json:"action"` // Action can be "add" or "del"
+	Sequence           int                       `json:"sequence,omitempty"`
+	Hash               string                    `json:"hash,omitempty"`
+	RouterHash         string                    `json:"router_hash,omitempty"`
+	RouterIP           string                    `json:"router_ip,omitempty"`
+	RouterName         string                    `json:"router_name,omitempty"`
+	Label              string                    `json:"label,omitempty"`
+	SessionAddr        string                    `json:"session_addr,omitempty"`
+	BaseAttrHash       string                    `json:"base_attr_hash,omitempty"`
+	PeerHash           string                    `json:"peer_hash,omitempty"`
+	PeerIP             string                    `json:"peer_ip,omitempty"`
+	PeerASN            int32                     `json:"peer_asn,omitempty"`
+	Timestamp          string                    `json:"timestamp,omitempty"`
+	Prefix             string                    `json:"prefix,omitempty"`
+	PrefixLen          int32                     `json:"prefix_len,omitempty"`
+	IsIPv4             bool                      `json:"is_ipv4"`
+	Origin             string                    `json:"origin,omitempty"`
+	ASPath             []uint32                  `json:"as_path,omitempty"`
+	ASPathSegments     bgp.ASPath                `json:"as_path_segments,omitempty"`
+	ASPathCount        int32                     `json:"as_path_count,omitempty"`
+	OriginAS           string                    `json:"origin_as,omitempty"`
+	Nexthop            string                    `json:"nexthop,omitempty"`
+	MED                uint32                    `json:"med,omitempty"`
+	LocalPref          uint32                    `json:"local_pref,omitempty"`
+	Aggregator         string                    `json:"aggregator,omitempty"`
+	CommunityList      string                    `json:"community_list,omitempty"`
+	ExtCommunityList   string                    `json:"ext_community_list,omitempty"`
+	ExtCommunities     []bgp.ExtCommunityDetails `json:"ext_communities,omitempty"`
+	LargeCommunityList string                    `json:"large_community_list,omitempty"`
+	IsAtomicAgg        bool                      `json:"is_atomic_agg"`
+	IsNexthopIPv4      bool                      `json:"is_nexthop_ipv4"`
+	OriginatorID       string                    `json:"originator_id,omitempty"`
+	PathID             int32                     `json:"path_id,omitempty"`
+	Labels             []uint32                  `json:"labels,omitempty"`
+	IsPrepolicy        bool                      `json:"isprepolicy"`
+	IsAdjRIBIn         bool                      `json:"is_adj_rib_in"`
+	IsAdjRIBOut        bool                      `json:"is_adj_rib_out"`
+	IsLocRIB           bool                      `json:"is_locrib"`
+	IsLocRIBFiltered   bool                      `json:"is_locrib_filtered"`
+	PrefixSID          *prefixsid.PSid           `json:"prefix_sid,omitempty"`
 }
 
 // LSNode defines a structure of LS Node message
@@ -84,6 +98,9 @@ type LSNode struct {
 	Hash                string   `json:"hash,omitempty"`
 	RouterHash          string   `json:"router_hash,omitempty"`
 	RouterIP            string   `json:"router_ip,omitempty"`
+	RouterName          string   `json:"router_name,omitempty"`
+	Label               string   `json:"label,omitempty"`
+	SessionAddr         string   `json:"session_addr,omitempty"`
 	BaseAttrHash        string   `json:"base_attr_hash,omitempty"`
 	PeerHash            string   `json:"peer_hash,omitempty"`
 	PeerIP              string   `json:"peer_ip,omitempty"`
@@ -111,6 +128,9 @@ type LSNode struct {
 	NodeMSD             string   `json:"node_msd,omitempty"`
 	IsPrepolicy         bool     `json:"isprepolicy"`
 	IsAdjRIBIn          bool     `json:"is_adj_rib_in"`
+	IsAdjRIBOut         bool     `json:"is_adj_rib_out"`
+	IsLocRIB            bool     `json:"is_locrib"`
+	IsLocRIBFiltered    bool     `json:"is_locrib_filtered"`
 }
 
 // LSLink defines a structure of LS link message
@@ -120,6 +140,9 @@ type LSLink struct {
 	Hash                  string               `json:"hash,omitempty"`
 	RouterHash            string               `json:"router_hash,omitempty"`
 	RouterIP              string               `json:"router_ip,omitempty"`
+	RouterName            string               `json:"router_name,omitempty"`
+	Label                 string               `json:"label,omitempty"`
+	SessionAddr           string               `json:"session_addr,omitempty"`
 	BaseAttrHash          string               `json:"base_attr_hash,omitempty"`
 	PeerHash              string               `json:"peer_hash,omitempty"`
 	PeerIP                string               `json:"peer_ip,omitempty"`
@@ -160,6 +183,9 @@ type LSLink struct {
 	SRv6BGPPeerNodeSID    *srv6.BGPPeerNodeSID `json:"srv6_bgp_peer_node_sid,omitempty"`
 	IsPrepolicy           bool                 `json:"isprepolicy"`
 	IsAdjRIBIn            bool                 `json:"is_adj_rib_in"`
+	IsAdjRIBOut           bool                 `json:"is_adj_rib_out"`
+	IsLocRIB              bool                 `json:"is_locrib"`
+	IsLocRIBFiltered      bool                 `json:"is_locrib_filtered"`
 	LSAdjacencySID        *sr.AdjacencySIDTLV  `json:"ls_adjacency_sid,omitempty"`
 	LinkMSD               string               `json:"link_msd,omitempty"`
 	UnidirLinkDelay       uint32               `json:"unidir_link_delay,omitempty"`
@@ -173,77 +199,92 @@ type LSLink struct {
 
 // L3VPNPrefix defines the structure of Layer 3 VPN message
 type L3VPNPrefix struct {
-	Action           string   `json:"action"` // Action can be "add" or "del"
-	Sequence         int      `json:"sequence,omitempty"`
-	Hash             string   `json:"hash,omitempty"`
-	RouterHash       string   `json:"router_hash,omitempty"`
-	RouterIP         string   `json:"router_ip,omitempty"`
-	BaseAttrHash     string   `json:"base_attr_hash,omitempty"`
-	PeerHash         string   `json:"peer_hash,omitempty"`
-	PeerIP           string   `json:"peer_ip,omitempty"`
-	PeerASN          int32    `json:"peer_asn,omitempty"`
-	Timestamp        string   `json:"timestamp,omitempty"`
-	Prefix           string   `json:"prefix,omitempty"`
-	PrefixLen        int32    `json:"prefix_len,omitempty"`
-	IsIPv4           bool     `json:"is_ipv4"`
-	Origin           string   `json:"origin,omitempty"`
-	ASPath           []uint32 `json:"as_path,omitempty"`
-	ASPathCount      int32    `json:"as_path_count,omitempty"`
-	OriginAS         string   `json:"origin_as,omitempty"`
-	Nexthop          string   `json:"nexthop,omitempty"`
-	MED              uint32   `json:"med,omitempty"`
-	LocalPref        uint32   `json:"local_pref,omitempty"`
-	Aggregator       string   `json:"aggregator,omitempty"`
-	CommunityList    string   `json:"community_list,omitempty"`
-	ExtCommunityList string   `json:"ext_community_list,omitempty"`
-	ClusterList      string   `json:"cluster_list,omitempty"`
-	IsAtomicAgg      bool     `json:"is_atomic_agg"`
-	IsNexthopIPv4    bool     `json:"is_nexthop_ipv4"`
-	OriginatorID     string   `json:"originator_id,omitempty"`
-	PathID           int32    `json:"path_id,omitempty"`
-	Labels           []uint32 `json:"labels,omitempty"`
-	IsPrepolicy      bool     `json:"isprepolicy"`
-	IsAdjRIBIn       bool     `json:"is_adj_rib_in"`
-	VPNRD            string   `json:"vpn_rd,omitempty"`
-	VPNRDType        uint16   `json:"vpn_rd_type"`
+	Action             string                    `json:"action"` // Action can be "add" or "del"
+	Sequence           int                       `json:"sequence,omitempty"`
+	Hash               string                    `json:"hash,omitempty"`
+	RouterHash         string                    `json:"router_hash,omitempty"`
+	RouterIP           string                    `json:"router_ip,omitempty"`
+	RouterName         string                    `json:"router_name,omitempty"`
+	Label              string                    `json:"label,omitempty"`
+	SessionAddr        string                    `json:"session_addr,omitempty"`
+	BaseAttrHash       string                    `json:"base_attr_hash,omitempty"`
+	PeerHash           string                    `json:"peer_hash,omitempty"`
+	PeerIP             string                    `json:"peer_ip,omitempty"`
+	PeerASN            int32                     `json:"peer_asn,omitempty"`
+	Timestamp          string                    `json:"timestamp,omitempty"`
+	Prefix             string                    `json:"prefix,omitempty"`
+	PrefixLen          int32                     `json:"prefix_len,omitempty"`
+	IsIPv4             bool                      `json:"is_ipv4"`
+	Origin             string                    `json:"origin,omitempty"`
+	ASPath             []uint32                  `json:"as_path,omitempty"`
+	ASPathSegments     bgp.ASPath                `json:"as_path_segments,omitempty"`
+	ASPathCount        int32                     `json:"as_path_count,omitempty"`
+	OriginAS           string                    `json:"origin_as,omitempty"`
+	Nexthop            string                    `json:"nexthop,omitempty"`
+	MED                uint32                    `json:"med,omitempty"`
+	LocalPref          uint32                    `json:"local_pref,omitempty"`
+	Aggregator         string                    `json:"aggregator,omitempty"`
+	CommunityList      string                    `json:"community_list,omitempty"`
+	ExtCommunityList   string                    `json:"ext_community_list,omitempty"`
+	ExtCommunities     []bgp.ExtCommunityDetails `json:"ext_communities,omitempty"`
+	LargeCommunityList string                    `json:"large_community_list,omitempty"`
+	ClusterList        string                    `json:"cluster_list,omitempty"`
+	IsAtomicAgg        bool                      `json:"is_atomic_agg"`
+	IsNexthopIPv4      bool                      `json:"is_nexthop_ipv4"`
+	OriginatorID       string                    `json:"originator_id,omitempty"`
+	PathID             int32                     `json:"path_id,omitempty"`
+	Labels             []uint32                  `json:"labels,omitempty"`
+	IsPrepolicy        bool                      `json:"isprepolicy"`
+	IsAdjRIBIn         bool                      `json:"is_adj_rib_in"`
+	IsAdjRIBOut        bool                      `json:"is_adj_rib_out"`
+	IsLocRIB           bool                      `json:"is_locrib"`
+	IsLocRIBFiltered   bool                      `json:"is_locrib_filtered"`
+	VPNRD              string                    `json:"vpn_rd,omitempty"`
+	VPNRDType          uint16                    `json:"vpn_rd_type"`
 }
 
 // LSPrefix defines a structure of LS Prefix message
 type LSPrefix struct {
-	Action        string           `json:"action"`
-	Sequence      int              `json:"sequence,omitempty"`
-	Hash          string           `json:"hash,omitempty"`
-	RouterHash    string           `json:"router_hash,omitempty"`
-	RouterIP      string           `json:"router_ip,omitempty"`
-	BaseAttrHash  string           `json:"base_attr_hash,omitempty"`
-	PeerHash      string           `json:"peer_hash,omitempty"`
-	PeerIP        string           `json:"peer_ip,omitempty"`
-	PeerASN       int32            `json:"peer_asn,omitempty"`
-	Timestamp     string           `json:"timestamp,omitempty"`
-	IGPRouterID   string           `json:"igp_router_id,omitempty"`
-	RouterID      string           `json:"router_id,omitempty"`
-	RoutingID     string           `json:"routing_id,omitempty"`
-	LSID          uint32           `json:"ls_id,omitempty"`
-	OSPFAreaID    string           `json:"ospf_area_id,omitempty"`
-	ISISAreaID    string           `json:"isis_area_id,omitempty"`
-	Protocol      string           `json:"protocol,omitempty"`
-	ASPath        []uint32         `json:"as_path,omitempty"`
-	LocalPref     uint32           `json:"local_pref,omitempty"`
-	MED           uint32           `json:"med,omitempty"`
-	Nexthop       string           `json:"nexthop,omitempty"`
-	LocalNodeHash string           `json:"local_node_hash,omitempty"`
-	MTID          []uint16         `json:"mt_id,omitempty"`
-	OSPFRouteType uint8            `json:"ospf_route_type,omitempty"`
-	IGPFlags      uint8            `json:"igp_flags,omitempty"`
-	RouteTag      uint8            `json:"route_tag,omitempty"`
-	ExtRouteTag   uint8            `json:"ext_route_tag,omitempty"`
-	OSPFFwdAddr   string           `json:"ospf_fwd_addr,omitempty"`
-	IGPMetric     uint32           `json:"igp_metric,omitempty"`
-	Prefix        string           `json:"prefix,omitempty"`
-	PrefixLen     int32            `json:"prefix_len,omitempty"`
-	IsPrepolicy   bool             `json:"isprepolicy"`
-	IsAdjRIBIn    bool             `json:"is_adj_rib_in"`
-	LSPrefixSID   *sr.PrefixSIDTLV `json:"ls_prefix_sid,omitempty"`
+	Action           string           `json:"action"`
+	Sequence         int              `json:"sequence,omitempty"`
+	Hash             string           `json:"hash,omitempty"`
+	RouterHash       string           `json:"router_hash,omitempty"`
+	RouterIP         string           `json:"router_ip,omitempty"`
+	RouterName       string           `json:"router_name,omitempty"`
+	Label            string           `json:"label,omitempty"`
+	SessionAddr      string           `json:"session_addr,omitempty"`
+	BaseAttrHash     string           `json:"base_attr_hash,omitempty"`
+	PeerHash         string           `json:"peer_hash,omitempty"`
+	PeerIP           string           `json:"peer_ip,omitempty"`
+	PeerASN          int32            `json:"peer_asn,omitempty"`
+	Timestamp        string           `json:"timestamp,omitempty"`
+	IGPRouterID      string           `json:"igp_router_id,omitempty"`
+	RouterID         string           `json:"router_id,omitempty"`
+	RoutingID        string           `json:"routing_id,omitempty"`
+	LSID             uint32           `json:"ls_id,omitempty"`
+	OSPFAreaID       string           `json:"ospf_area_id,omitempty"`
+	ISISAreaID       string           `json:"isis_area_id,omitempty"`
+	Protocol         string           `json:"protocol,omitempty"`
+	ASPath           []uint32         `json:"as_path,omitempty"`
+	LocalPref        uint32           `json:"local_pref,omitempty"`
+	MED              uint32           `json:"med,omitempty"`
+	Nexthop          string           `json:"nexthop,omitempty"`
+	LocalNodeHash    string           `json:"local_node_hash,omitempty"`
+	MTID             []uint16         `json:"mt_id,omitempty"`
+	OSPFRouteType    uint8            `json:"ospf_route_type,omitempty"`
+	IGPFlags         uint8            `json:"igp_flags,omitempty"`
+	RouteTag         uint8            `json:"route_tag,omitempty"`
+	ExtRouteTag      uint8            `json:"ext_route_tag,omitempty"`
+	OSPFFwdAddr      string           `json:"ospf_fwd_addr,omitempty"`
+	IGPMetric        uint32           `json:"igp_metric,omitempty"`
+	Prefix           string           `json:"prefix,omitempty"`
+	PrefixLen        int32            `json:"prefix_len,omitempty"`
+	IsPrepolicy      bool             `json:"isprepolicy"`
+	IsAdjRIBIn       bool             `json:"is_adj_rib_in"`
+	IsAdjRIBOut      bool             `json:"is_adj_rib_out"`
+	IsLocRIB         bool             `json:"is_locrib"`
+	IsLocRIBFiltered bool             `json:"is_locrib_filtered"`
+	LSPrefixSID      *sr.PrefixSIDTLV `json:"ls_prefix_sid,omitempty"`
 }
 
 // LSSRv6SID defines a structure of LS SRv6 SID message
@@ -253,6 +294,9 @@ type LSSRv6SID struct {
 	Hash                 string                 `json:"hash,omitempty"`
 	RouterHash           string                 `json:"router_hash,omitempty"`
 	RouterIP             string                 `json:"router_ip,omitempty"`
+	RouterName           string                 `json:"router_name,omitempty"`
+	Label                string                 `json:"label,omitempty"`
+	SessionAddr          string                 `json:"session_addr,omitempty"`
 	BaseAttrHash         string                 `json:"base_attr_hash,omitempty"`
 	PeerHash             string                 `json:"peer_hash,omitempty"`
 	PeerIP               string                 `json:"peer_ip,omitempty"`
@@ -282,6 +326,9 @@ type LSSRv6SID struct {
 	PrefixLen            int32                  `json:"prefix_len,omitempty"`
 	IsPrepolicy          bool                   `json:"isprepolicy"`
 	IsAdjRIBIn           bool                   `json:"is_adj_rib_in"`
+	IsAdjRIBOut          bool                   `json:"is_adj_rib_out"`
+	IsLocRIB             bool                   `json:"is_locrib"`
+	IsLocRIBFiltered     bool                   `json:"is_locrib_filtered"`
 	SRv6SID              []string               `json:"srv6_sid,omitempty"`
 	SRv6EndpointBehavior *srv6.EndpointBehavior `json:"srv6_endpoint_behavior,omitempty"`
 	SRv6BGPPeerNodeSID   *srv6.BGPPeerNodeSID   `json:"srv6_bgp_peer_node_sid,omitempty"`
@@ -290,46 +337,158 @@ type LSSRv6SID struct {
 
 // EVPNPrefix defines the structure of EVPN message
 type EVPNPrefix struct {
-	Action           string   `json:"action"` // Action can be "add" or "del"
-	Sequence         int      `json:"sequence,omitempty"`
-	Hash             string   `json:"hash,omitempty"`
-	RouterHash       string   `json:"router_hash,omitempty"`
-	RouterIP         string   `json:"router_ip,omitempty"`
-	BaseAttrHash     string   `json:"base_attr_hash,omitempty"`
-	PeerHash         string   `json:"peer_hash,omitempty"`
-	PeerIP           string   `json:"peer_ip,omitempty"`
-	PeerASN          int32    `json:"peer_asn,omitempty"`
-	Timestamp        string   `json:"timestamp,omitempty"`
-	IsIPv4           bool     `json:"is_ipv4"`
-	Origin           string   `json:"origin,omitempty"`
-	ASPath           []uint32 `json:"as_path,omitempty"`
-	ASPathCount      int32    `json:"as_path_count,omitempty"`
-	OriginAS         string   `json:"origin_as,omitempty"`
-	Nexthop          string   `json:"nexthop,omitempty"`
-	MED              uint32   `json:"med,omitempty"`
-	LocalPref        uint32   `json:"local_pref,omitempty"`
-	Aggregator       string   `json:"aggregator,omitempty"`
-	CommunityList    string   `json:"community_list,omitempty"`
-	ExtCommunityList string   `json:"ext_community_list,omitempty"`
-	ClusterList      string   `json:"cluster_list,omitempty"`
-	IsAtomicAgg      bool     `json:"is_atomic_agg"`
-	IsNexthopIPv4    bool     `json:"is_nexthop_ipv4"`
-	OriginatorID     string   `json:"originator_id,omitempty"`
-	PathID           int32    `json:"path_id,omitempty"`
-	Labels           []uint32 `json:"labels,omitempty"`
-	IsPrepolicy      bool     `json:"isprepolicy"`
-	IsAdjRIBIn       bool     `json:"is_adj_rib_in"`
-	VPNRD            string   `json:"vpn_rd,omitempty"`
-	VPNRDType        uint16   `json:"vpn_rd_type"`
-	ESI              string   `json:"eth_segment_id,omitempty"`
-	EthTag           []byte   `json:"eth_tag,omitempty"`
-	IPAddress        string   `json:"ip_address,omitempty"`
-	IPLength         uint8    `json:"ip_len,omitempty"`
-	GWAddress        string   `json:"gw_address,omitempty"`
-	MAC              string   `json:"mac,omitempty"`
-	MACLength        uint8    `json:"mac_len,omitempty"`
-	RouteType        uint8    `json:"route_type,omitempty"`
+	Action             string                    `json:"action"` // Action can be "add" or "del"
+	Sequence           int                       `json:"sequence,omitempty"`
+	Hash               string                    `json:"hash,omitempty"`
+	RouterHash         string                    `json:"router_hash,omitempty"`
+	RouterIP           string                    `json:"router_ip,omitempty"`
+	RouterName         string                    `json:"router_name,omitempty"`
+	Label              string                    `json:"label,omitempty"`
+	SessionAddr        string                    `json:"session_addr,omitempty"`
+	BaseAttrHash       string                    `json:"base_attr_hash,omitempty"`
+	PeerHash           string                    `json:"peer_hash,omitempty"`
+	PeerIP             string                    `json:"peer_ip,omitempty"`
+	PeerASN            int32                     `json:"peer_asn,omitempty"`
+	Timestamp          string                    `json:"timestamp,omitempty"`
+	IsIPv4             bool                      `json:"is_ipv4"`
+	Origin             string                    `json:"origin,omitempty"`
+	ASPath             []uint32                  `json:"as_path,omitempty"`
+	ASPathSegments     bgp.ASPath                `json:"as_path_segments,omitempty"`
+	ASPathCount        int32                     `json:"as_path_count,omitempty"`
+	OriginAS           string                    `json:"origin_as,omitempty"`
+	Nexthop            string                    `json:"nexthop,omitempty"`
+	MED                uint32                    `json:"med,omitempty"`
+	LocalPref          uint32                    `json:"local_pref,omitempty"`
+	Aggregator         string                    `json:"aggregator,omitempty"`
+	CommunityList      string                    `json:"community_list,omitempty"`
+	ExtCommunityList   string                    `json:"ext_community_list,omitempty"`
+	ExtCommunities     []bgp.ExtCommunityDetails `json:"ext_communities,omitempty"`
+	LargeCommunityList string                    `json:"large_community_list,omitempty"`
+	ClusterList        string                    `json:"cluster_list,omitempty"`
+	IsAtomicAgg        bool                      `json:"is_atomic_agg"`
+	IsNexthopIPv4      bool                      `json:"is_nexthop_ipv4"`
+	OriginatorID       string                    `json:"originator_id,omitempty"`
+	PathID             int32                     `json:"path_id,omitempty"`
+	Labels             []uint32                  `json:"labels,omitempty"`
+	IsPrepolicy        bool                      `json:"isprepolicy"`
+	IsAdjRIBIn         bool                      `json:"is_adj_rib_in"`
+	IsAdjRIBOut        bool                      `json:"is_adj_rib_out"`
+	IsLocRIB           bool                      `json:"is_locrib"`
+	IsLocRIBFiltered   bool                      `json:"is_locrib_filtered"`
+	VPNRD              string                    `json:"vpn_rd,omitempty"`
+	VPNRDType          uint16                    `json:"vpn_rd_type"`
+	ESI                string                    `json:"eth_segment_id,omitempty"`
+	EthTag             []byte                    `json:"eth_tag,omitempty"`
+	IPAddress          string                    `json:"ip_address,omitempty"`
+	IPLength           uint8                     `json:"ip_len,omitempty"`
+	GWAddress          string                    `json:"gw_address,omitempty"`
+	MAC                string                    `json:"mac,omitempty"`
+	MACLength          uint8                     `json:"mac_len,omitempty"`
+	RouteType          uint8                     `json:"route_type,omitempty"`
 	// TODO Type 3 carries nlri 22
 	// https://tools.ietf.org/html/rfc6514
 	// Add to the message
 }
+
+// AFISAFIStats defines per AFI/SAFI gauges reported in BMP Stats Report message, gauges which are not
+// reported are nil to distinguish them from the reported zero.
+type AFISAFIStats struct {
+	AFI                 uint16  `json:"afi"`
+	SAFI                uint8   `json:"safi"`
+	AdjRIBIn            *uint64 `json:"adj_rib_in,omitempty"`
+	LocRIB              *uint64 `json:"loc_rib,omitempty"`
+	PrePolicyAdjRIBOut  *uint64 `json:"pre_policy_adj_rib_out,omitempty"`
+	PostPolicyAdjRIBOut *uint64 `json:"post_policy_adj_rib_out,omitempty"`
+}
+
+// Stats defines a message format sent as a result of BMP Stats Report message, counters and gauges which are
+// not reported are nil to distinguish them from the reported zero.
+type Stats struct {
+	Sequence                int            `json:"sequence,omitempty"`
+	RouterHash              string         `json:"router_hash,omitempty"`
+	RouterIP                string         `json:"router_ip,omitempty"`
+	RouterName              string         `json:"router_name,omitempty"`
+	Label                   string         `json:"label,omitempty"`
+	SessionAddr             string         `json:"session_addr,omitempty"`
+	PeerHash                string         `json:"peer_hash,omitempty"`
+	RemoteBGPID             string         `json:"remote_bgp_id,omitempty"`
+	RemoteASN               int32          `json:"remote_asn,omitempty"`
+	RemoteIP                string         `json:"remote_ip,omitempty"`
+	PeerRD                  string         `json:"peer_rd,omitempty"`
+	Timestamp               string         `json:"timestamp,omitempty"`
+	IsIPv4                  bool           `json:"is_ipv4"`
+	RejectedPrefixes        *uint32        `json:"rejected_prefixes,omitempty"`
+	DuplicatePrefixes       *uint32        `json:"duplicate_prefixes,omitempty"`
+	DuplicateWithdraws      *uint32        `json:"duplicate_withdraws,omitempty"`
+	InvalidatedClusterList  *uint32        `json:"invalidated_cluster_list,omitempty"`
+	InvalidatedASPath       *uint32        `json:"invalidated_as_path,omitempty"`
+	InvalidatedOriginatorID *uint32        `json:"invalidated_originator_id,omitempty"`
+	InvalidatedASConfed     *uint32        `json:"invalidated_as_confed,omitempty"`
+	TreatAsWithdrawUpdates  *uint32        `json:"treat_as_withdraw_updates,omitempty"`
+	TreatAsWithdrawPrefixes *uint32        `json:"treat_as_withdraw_prefixes,omitempty"`
+	DuplicateUpdates        *uint32        `json:"duplicate_updates,omitempty"`
+	AdjRIBIn                *uint64        `json:"adj_rib_in,omitempty"`
+	LocRIB                  *uint64        `json:"loc_rib,omitempty"`
+	PrePolicyAdjRIBOut      *uint64        `json:"pre_policy_adj_rib_out,omitempty"`
+	PostPolicyAdjRIBOut     *uint64        `json:"post_policy_adj_rib_out,omitempty"`
+	AFISAFI                 []AFISAFIStats `json:"afi_safi,omitempty"`
+}
+
+// RouteMirror defines a message format sent as a result of BMP Route Mirroring message
+type RouteMirror struct {
+	Action           string           `json:"action"` // Action can be "mirror" or "lost"
+	Sequence         int              `json:"sequence,omitempty"`
+	RouterHash       string           `json:"router_hash,omitempty"`
+	RouterIP         string           `json:"router_ip,omitempty"`
+	RouterName       string           `json:"router_name,omitempty"`
+	Label            string           `json:"label,omitempty"`
+	SessionAddr      string           `json:"session_addr,omitempty"`
+	PeerHash         string           `json:"peer_hash,omitempty"`
+	PeerIP           string           `json:"peer_ip,omitempty"`
+	PeerASN          int32            `json:"peer_asn,omitempty"`
+	PeerRD           string           `json:"peer_rd,omitempty"`
+	Timestamp        string           `json:"timestamp,omitempty"`
+	IsIPv4           bool             `json:"is_ipv4"`
+	ErroredPDU       bool             `json:"errored_pdu"`
+	MessagesLost     bool             `json:"messages_lost"`
+	BGPMessageType   uint8            `json:"bgp_msg_type,omitempty"`
+	BGPMessage       string           `json:"bgp_msg,omitempty"`
+	DecodeError      string           `json:"decode_error,omitempty"`
+	Attributes       []uint8          `json:"attributes,omitempty"`
+	Origin           string           `json:"origin,omitempty"`
+	ASPath           []uint32         `json:"as_path,omitempty"`
+	Nexthop          string           `json:"nexthop,omitempty"`
+	MED              uint32           `json:"med,omitempty"`
+	LocalPref        uint32           `json:"local_pref,omitempty"`
+	CommunityList    string           `json:"community_list,omitempty"`
+	ExtCommunityList string           `json:"ext_community_list,omitempty"`
+	WithdrawnRoutes  []string         `json:"withdrawn_routes,omitempty"`
+	NLRI             []string         `json:"nlri,omitempty"`
+	MPNLRI           []MirroredMPNLRI `json:"mp_nlri,omitempty"`
+}
+
+// MirroredMPNLRI defines MP_REACH_NLRI or MP_UNREACH_NLRI attribute of mirrored BGP Update, routes are listed
+// for unicast and labeled unicast address families, the attribute is always included as hex string.
+type MirroredMPNLRI struct {
+	Action      string   `json:"action"` // Action can be "add" or "del"
+	AFI         uint16   `json:"afi"`
+	SAFI        uint8    `json:"safi"`
+	Nexthop     string   `json:"nexthop,omitempty"`
+	Routes      []string `json:"routes,omitempty"`
+	Attribute   string   `json:"attribute"`
+	DecodeError string   `json:"decode_error,omitempty"`
+}
+
+// RouterEvent defines a message format sent as a result of BMP Initiation or Termination message
+type RouterEvent struct {
+	Action         string `json:"action"` // Action can be "init" or "term"
+	Sequence       int    `json:"sequence,omitempty"`
+	Name           string `json:"name,omitempty"`
+	Description    string `json:"description,omitempty"`
+	SessionAddr    string `json:"session_addr,omitempty"`
+	Label          string `json:"label,omitempty"`
+	InfoData       string `json:"info_data,omitempty"`
+	TermReasonCode int    `json:"term_reason_code"`
+	TermReason     string `json:"term_reason,omitempty"`
+	Timestamp      string `json:"timestamp,omitempty"`
+}
@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+func TestExpositionParse(t *testing.T) {
+	saved := DefaultRegistry
+	DefaultRegistry = NewRegistry()
+	defer func() { DefaultRegistry = saved }()
+
+	c := NewCounterVec("test_messages_total", "Number of messages, \\ and\nnew line.", "router")
+	c.With("a\"b\\c\nd").Add(2)
+	h := NewHistogramVec("test_latency_seconds", "Latency.", []float64{0.1, 0.01, 1}, "topic")
+	h.With("peer").Observe(0.005)
+	h.With("peer").Observe(0.5)
+	h.With("peer").Observe(2)
+	h.With("unicast").Observe(0.05)
+	NewGaugeFunc("test_queue_length", "Queue length.", func() []Sample {
+		return []Sample{{Labels: []string{`q"1`}, Value: 1}}
+	}, "queue")
+
+	var b bytes.Buffer
+	if err := DefaultRegistry.Write(&b); err != nil {
+		t.Fatalf("fail to write metrics with error: %+v", err)
+	}
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(&b)
+	if err != nil {
+		t.Fatalf("fail to parse metrics with error: %+v", err)
+	}
+
+	counter, ok := families["test_messages_total"]
+	if !ok || counter.GetType() != dto.MetricType_COUNTER || len(counter.Metric) != 1 {
+		t.Fatalf("unexpected counter family %+v", counter)
+	}
+	if counter.GetHelp() != "Number of messages, \\ and\nnew line." {
+		t.Fatalf("unexpected counter help %q", counter.GetHelp())
+	}
+	if l := counter.Metric[0].Label[0]; l.GetName() != "router" || l.GetValue() != "a\"b\\c\nd" {
+		t.Fatalf("unexpected counter label %s=%q", l.GetName(), l.GetValue())
+	}
+	if v := counter.Metric[0].GetCounter().GetValue(); v != 2 {
+		t.Fatalf("expected counter 2 but got %g", v)
+	}
+
+	hist, ok := families["test_latency_seconds"]
+	if !ok || hist.GetType() != dto.MetricType_HISTOGRAM || len(hist.Metric) != 2 {
+		t.Fatalf("unexpected histogram family %+v", hist)
+	}
+	peer := hist.Metric[0]
+	if peer.Label[0].GetValue() != "peer" {
+		t.Fatalf("expected histogram of peer topic first but got %q", peer.Label[0].GetValue())
+	}
+	hp := peer.GetHistogram()
+	if hp.GetSampleCount() != 3 || math.Abs(hp.GetSampleSum()-2.505) > 1e-9 {
+		t.Fatalf("expected 3 observations with sum 2.505 but got %d and %g", hp.GetSampleCount(), hp.GetSampleSum())
+	}
+	expect := []struct {
+		bound float64
+		count uint64
+	}{{0.01, 1}, {0.1, 1}, {1, 2}, {math.Inf(1), 3}}
+	if len(hp.Bucket) != len(expect) {
+		t.Fatalf("expected %d buckets but got %d", len(expect), len(hp.Bucket))
+	}
+	for i, e := range expect {
+		if hp.Bucket[i].GetUpperBound() != e.bound || hp.Bucket[i].GetCumulativeCount() != e.count {
+			t.Fatalf("expected bucket le=%g with %d observations but got le=%g with %d", e.bound, e.count,
+				hp.Bucket[i].GetUpperBound(), hp.Bucket[i].GetCumulativeCount())
+		}
+	}
+	if hu := hist.Metric[1].GetHistogram(); hu.GetSampleCount() != 1 || hu.Bucket[1].GetCumulativeCount() != 1 {
+		t.Fatalf("unexpected histogram of unicast topic %+v", hu)
+	}
+
+	gauge, ok := families["test_queue_length"]
+	if !ok || gauge.GetType() != dto.MetricType_GAUGE || gauge.Metric[0].Label[0].GetValue() != `q"1` {
+		t.Fatalf("unexpected gauge family %+v", gauge)
+	}
+}
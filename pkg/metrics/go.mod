@@ -0,0 +1,8 @@
+module github.com/sbezverk/gobmp/pkg/metrics
+
+go 1.14
+
+require (
+	github.com/prometheus/client_model v0.3.0
+	github.com/prometheus/common v0.42.0
+)
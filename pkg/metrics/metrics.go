@@ -0,0 +1,394 @@
+// Package metrics implements counters, gauges and histograms exposed in Prometheus text exposition format.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// DefBuckets defines default histogram buckets in seconds, suitable for latencies of
+// publishing and storing messages.
+var DefBuckets = []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// collector is implemented by all metrics kept in a Registry
+type collector interface {
+	name() string
+	write(w *bufio.Writer)
+}
+
+// Registry keeps metrics and writes them in Prometheus text exposition format
+type Registry struct {
+	sync.Mutex
+	collectors map[string]collector
+}
+
+// NewRegistry returns a new empty Registry
+func NewRegistry() *Registry {
+	return &Registry{
+		collectors: make(map[string]collector),
+	}
+}
+
+// DefaultRegistry is the registry used by the package level constructors and Handler
+var DefaultRegistry = NewRegistry()
+
+func (r *Registry) register(c collector) {
+	r.Lock()
+	defer r.Unlock()
+	if _, ok := r.collectors[c.name()]; ok {
+		panic(fmt.Sprintf("metric %s is already registered", c.name()))
+	}
+	r.collectors[c.name()] = c
+}
+
+// Write writes all metrics of the registry sorted by name
+func (r *Registry) Write(w io.Writer) error {
+	r.Lock()
+	names := make([]string, 0, len(r.collectors))
+	for name := range r.collectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	collectors := make([]collector, 0, len(names))
+	for _, name := range names {
+		collectors = append(collectors, r.collectors[name])
+	}
+	r.Unlock()
+	bw := bufio.NewWriter(w)
+	for _, c := range collectors {
+		c.write(bw)
+	}
+
+	return bw.Flush()
+}
+
+// Handler returns HTTP handler serving the metrics of DefaultRegistry
+func Handler() http.Handler {
+	return HandlerFor(DefaultRegistry)
+}
+
+// HandlerFor returns HTTP handler serving the metrics of the registry
+func HandlerFor(r *Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.Write(w)
+	})
+}
+
+// desc defines the name, the help and the label names of a metric
+type desc struct {
+	metricName string
+	help       string
+	metricType string
+	labels     []string
+}
+
+func (d *desc) name() string {
+	return d.metricName
+}
+
+var (
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+)
+
+func (d *desc) writeHeader(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", d.metricName, helpEscaper.Replace(d.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", d.metricName, d.metricType)
+}
+
+// key returns the key of a labels' values combination, it panics if the number of values
+// does not match the number of the metric's labels.
+func (d *desc) key(values []string) string {
+	if len(values) != len(d.labels) {
+		panic(fmt.Sprintf("metric %s expects %d label values, got %d", d.metricName, len(d.labels), len(values)))
+	}
+
+	return strings.Join(values, "\xff")
+}
+
+// writeSample writes a sample of the metric, suffix is appended to the metric's name and extra is
+// an additional label, used by histograms' buckets.
+func (d *desc) writeSample(w *bufio.Writer, suffix string, values []string, extra string, value float64) {
+	w.WriteString(d.metricName)
+	w.WriteString(suffix)
+	if len(values) != 0 || extra != "" {
+		w.WriteByte('{')
+		for i, v := range values {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, "%s=\"%s\"", d.labels[i], labelValueEscaper.Replace(v))
+		}
+		if extra != "" {
+			if len(values) != 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(extra)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(value))
+	w.WriteByte('\n')
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// series keeps the children of a metric by labels' values
+type series struct {
+	sync.Mutex
+	keys     []string
+	values   map[string][]string
+	children map[string]interface{}
+}
+
+// get returns the child of the labels' values, the child is allocated by create if it does not exist yet
+func (s *series) get(key string, values []string, create func() interface{}) interface{} {
+	s.Lock()
+	defer s.Unlock()
+	if c, ok := s.children[key]; ok {
+		return c
+	}
+	if s.children == nil {
+		s.children = make(map[string]interface{})
+		s.values = make(map[string][]string)
+	}
+	c := create()
+	s.children[key] = c
+	s.values[key] = append([]string(nil), values...)
+	s.keys = append(s.keys, key)
+	sort.Strings(s.keys)
+
+	return c
+}
+
+// each calls f for every child sorted by labels' values
+func (s *series) each(f func(values []string, child interface{})) {
+	s.Lock()
+	keys := append([]string(nil), s.keys...)
+	values := make([][]string, len(keys))
+	children := make([]interface{}, len(keys))
+	for i, k := range keys {
+		values[i] = s.values[k]
+		children[i] = s.children[k]
+	}
+	s.Unlock()
+	for i := range keys {
+		f(values[i], children[i])
+	}
+}
+
+// Counter is a monotonically increasing value
+type Counter struct {
+	v uint64
+}
+
+// Inc increments the counter by 1
+func (c *Counter) Inc() {
+	atomic.AddUint64(&c.v, 1)
+}
+
+// Add increments the counter by n
+func (c *Counter) Add(n uint64) {
+	atomic.AddUint64(&c.v, n)
+}
+
+// CounterVec is a set of counters partitioned by labels' values
+type CounterVec struct {
+	desc
+	series
+}
+
+// NewCounterVec creates CounterVec and registers it in DefaultRegistry
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{desc: desc{metricName: name, help: help, metricType: "counter", labels: labels}}
+	DefaultRegistry.register(c)
+
+	return c
+}
+
+// With returns the counter of the labels' values
+func (c *CounterVec) With(values ...string) *Counter {
+	return c.get(c.key(values), values, func() interface{} { return &Counter{} }).(*Counter)
+}
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	c.writeHeader(w)
+	c.each(func(values []string, child interface{}) {
+		c.writeSample(w, "", values, "", float64(atomic.LoadUint64(&child.(*Counter).v)))
+	})
+}
+
+// Gauge is a value which can go up and down
+type Gauge struct {
+	v int64
+}
+
+// Add adds n to the gauge, n can be negative
+func (g *Gauge) Add(n int64) {
+	atomic.AddInt64(&g.v, n)
+}
+
+// Inc increments the gauge by 1
+func (g *Gauge) Inc() {
+	atomic.AddInt64(&g.v, 1)
+}
+
+// Dec decrements the gauge by 1
+func (g *Gauge) Dec() {
+	atomic.AddInt64(&g.v, -1)
+}
+
+// Set sets the gauge to v
+func (g *Gauge) Set(v int64) {
+	atomic.StoreInt64(&g.v, v)
+}
+
+// GaugeVec is a set of gauges partitioned by labels' values
+type GaugeVec struct {
+	desc
+	series
+}
+
+// NewGaugeVec creates GaugeVec and registers it in DefaultRegistry
+func NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	g := &GaugeVec{desc: desc{metricName: name, help: help, metricType: "gauge", labels: labels}}
+	DefaultRegistry.register(g)
+
+	return g
+}
+
+// With returns the gauge of the labels' values
+func (g *GaugeVec) With(values ...string) *Gauge {
+	return g.get(g.key(values), values, func() interface{} { return &Gauge{} }).(*Gauge)
+}
+
+func (g *GaugeVec) write(w *bufio.Writer) {
+	g.writeHeader(w)
+	g.each(func(values []string, child interface{}) {
+		g.writeSample(w, "", values, "", float64(atomic.LoadInt64(&child.(*Gauge).v)))
+	})
+}
+
+// Histogram counts observations in buckets
+type Histogram struct {
+	sync.Mutex
+	upperBounds []float64
+	// counts are not cumulative, the last element counts observations above the largest bound
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// Observe adds an observation to the histogram
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.upperBounds, v)
+	h.Lock()
+	defer h.Unlock()
+	h.counts[i]++
+	h.sum += v
+	h.count++
+}
+
+// HistogramVec is a set of histograms partitioned by labels' values
+type HistogramVec struct {
+	desc
+	series
+	buckets []float64
+}
+
+// NewHistogramVec creates HistogramVec with buckets upper bounds and registers it in DefaultRegistry
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	b := append([]float64(nil), buckets...)
+	sort.Float64s(b)
+	h := &HistogramVec{desc: desc{metricName: name, help: help, metricType: "histogram", labels: labels}, buckets: b}
+	DefaultRegistry.register(h)
+
+	return h
+}
+
+// With returns the histogram of the labels' values
+func (h *HistogramVec) With(values ...string) *Histogram {
+	return h.get(h.key(values), values, func() interface{} {
+		return &Histogram{upperBounds: h.buckets, counts: make([]uint64, len(h.buckets)+1)}
+	}).(*Histogram)
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	h.writeHeader(w)
+	h.each(func(values []string, child interface{}) {
+		hist := child.(*Histogram)
+		hist.Lock()
+		counts := append([]uint64(nil), hist.counts...)
+		sum, count := hist.sum, hist.count
+		hist.Unlock()
+		cumulative := uint64(0)
+		for i, bound := range h.buckets {
+			cumulative += counts[i]
+			h.writeSample(w, "_bucket", values, `le="`+formatFloat(bound)+`"`, float64(cumulative))
+		}
+		h.writeSample(w, "_bucket", values, `le="+Inf"`, float64(count))
+		h.writeSample(w, "_sum", values, "", sum)
+		h.writeSample(w, "_count", values, "", float64(count))
+	})
+}
+
+// Sample is a value of a metric collected by a function
+type Sample struct {
+	Labels []string
+	Value  float64
+}
+
+// funcCollector is a metric which values are collected by a function at the time of scraping,
+// it exposes values kept elsewhere, for example queues' lengths or existing counters.
+type funcCollector struct {
+	desc
+	collect func() []Sample
+}
+
+// NewGaugeFunc registers in DefaultRegistry a gauge which values are returned by collect
+func NewGaugeFunc(name, help string, collect func() []Sample, labels ...string) {
+	DefaultRegistry.register(&funcCollector{desc: desc{metricName: name, help: help, metricType: "gauge", labels: labels}, collect: collect})
+}
+
+// NewCounterFunc registers in DefaultRegistry a counter which values are returned by collect
+func NewCounterFunc(name, help string, collect func() []Sample, labels ...string) {
+	DefaultRegistry.register(&funcCollector{desc: desc{metricName: name, help: help, metricType: "counter", labels: labels}, collect: collect})
+}
+
+func (f *funcCollector) write(w *bufio.Writer) {
+	f.writeHeader(w)
+	samples := f.collect()
+	sort.Slice(samples, func(i, j int) bool {
+		return strings.Join(samples[i].Labels, "\xff") < strings.Join(samples[j].Labels, "\xff")
+	})
+	for _, s := range samples {
+		f.key(s.Labels)
+		f.writeSample(w, "", s.Labels, "", s.Value)
+	}
+}
+
+func init() {
+	NewGaugeFunc("go_goroutines", "Number of goroutines that currently exist.", func() []Sample {
+		return []Sample{{Value: float64(runtime.NumGoroutine())}}
+	})
+}
@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRegistryWrite(t *testing.T) {
+	// Metrics created by the package level constructors are registered in DefaultRegistry,
+	// replacing it with an empty registry for the test
+	saved := DefaultRegistry
+	DefaultRegistry = NewRegistry()
+	defer func() { DefaultRegistry = saved }()
+
+	c := NewCounterVec("test_messages_total", "Number of messages.", "router", "type")
+	c.With("192.0.2.1", "peer_up").Inc()
+	c.With("192.0.2.1", "route_monitor").Add(3)
+	c.With(`a"b\`, "peer_up").Inc()
+	h := NewHistogramVec("test_latency_seconds", "Latency.", []float64{0.1, 0.01, 1}, "topic")
+	h.With("peer").Observe(0.005)
+	h.With("peer").Observe(0.5)
+	h.With("peer").Observe(2)
+	g := NewGaugeVec("test_inflight", "Messages in flight.", "type")
+	g.With("peer").Add(3)
+	g.With("peer").Dec()
+	g.With("evpn").Set(-1)
+	NewGaugeFunc("test_queue_length", "Queue length.", func() []Sample {
+		return []Sample{{Labels: []string{"b"}, Value: 2}, {Labels: []string{"a"}, Value: 1}}
+	}, "session")
+
+	var b bytes.Buffer
+	if err := DefaultRegistry.Write(&b); err != nil {
+		t.Fatalf("fail to write metrics with error: %+v", err)
+	}
+	expect := strings.Join([]string{
+		"# HELP test_inflight Messages in flight.",
+		"# TYPE test_inflight gauge",
+		`test_inflight{type="evpn"} -1`,
+		`test_inflight{type="peer"} 2`,
+		"# HELP test_latency_seconds Latency.",
+		"# TYPE test_latency_seconds histogram",
+		`test_latency_seconds_bucket{topic="peer",le="0.01"} 1`,
+		`test_latency_seconds_bucket{topic="peer",le="0.1"} 1`,
+		`test_latency_seconds_bucket{topic="peer",le="1"} 2`,
+		`test_latency_seconds_bucket{topic="peer",le="+Inf"} 3`,
+		`test_latency_seconds_sum{topic="peer"} 2.505`,
+		`test_latency_seconds_count{topic="peer"} 3`,
+		"# HELP test_messages_total Number of messages.",
+		"# TYPE test_messages_total counter",
+		`test_messages_total{router="192.0.2.1",type="peer_up"} 1`,
+		`test_messages_total{router="192.0.2.1",type="route_monitor"} 3`,
+		`test_messages_total{router="a\"b\\",type="peer_up"} 1`,
+		"# HELP test_queue_length Queue length.",
+		"# TYPE test_queue_length gauge",
+		`test_queue_length{session="a"} 1`,
+		`test_queue_length{session="b"} 2`,
+		"",
+	}, "\n")
+	if b.String() != expect {
+		t.Fatalf("expected metrics:\n%s\ngot:\n%s", expect, b.String())
+	}
+}
+
+func TestRegisterDuplicate(t *testing.T) {
+	saved := DefaultRegistry
+	DefaultRegistry = NewRegistry()
+	defer func() { DefaultRegistry = saved }()
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected panic registering metric twice")
+		}
+	}()
+	NewCounterVec("test_total", "Test.")
+	NewCounterVec("test_total", "Test.")
+}
@@ -1,16 +1,72 @@
 package parser
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/golang/glog"
+	"github.com/sbezverk/gobmp/pkg/bgp"
 	"github.com/sbezverk/gobmp/pkg/bmp"
+	"github.com/sbezverk/gobmp/pkg/message"
+	"github.com/sbezverk/gobmp/pkg/tools"
 )
 
-// Parser dispatches workers upon request received from the channel
-func Parser(queue chan []byte, producerQueue chan bmp.Message, stop chan struct{}) {
+// peerAddPath defines the types of AFI/SAFI for which a peer's NLRI carry ADD-PATH Path Identifiers,
+// in is used for the routes received from the peer and out for the routes advertised to the peer.
+type peerAddPath struct {
+	in  map[int]bool
+	out map[int]bool
+}
+
+// peerKey returns the key identifying the peer within BMP session
+func peerKey(ph *bmp.PerPeerHeader) string {
+	return ph.PeerDistinguisher.String() + "-" + ph.GetPeerHash()
+}
+
+// routeMonitorAddPath returns the types of AFI/SAFI with ADD-PATH negotiated for routes monitored
+// according to the Per Peer Header.
+func routeMonitorAddPath(peers map[string]*peerAddPath, ph *bmp.PerPeerHeader) map[int]bool {
+	ap, ok := peers[peerKey(ph)]
+	if !ok {
+		return nil
+	}
+	if ph.IsAdjRIBOut() {
+		return ap.out
+	}
+
+	return ap.in
+}
+
+// DecodeErrorHandler is called with the type of BMP message which the parser failed to decode
+type DecodeErrorHandler func(msgType uint8, err error)
+
+// Parser processes messages received from the channel, messages are parsed one by one in the order
+// they were received from BMP session, the order is preserved when messages are sent to producerQueue.
+// If not nil, onError is called for every message failed to decode. When the queue is closed, Parser returns
+// after all messages waiting in the queue are parsed and closes producerQueue, when stop is closed, Parser returns
+// immediately.
+func Parser(queue chan []byte, producerQueue chan bmp.Message, stop chan struct{}, onError DecodeErrorHandler) {
+	// ADD-PATH negotiated by the session's peers, learnt from Peer Up messages
+	peers := make(map[string]*peerAddPath)
 	for {
 		select {
-		case msg := <-queue:
-			go parsingWorker(msg, producerQueue)
+		case msg, ok := <-queue:
+			if !ok {
+				close(producerQueue)
+				return
+			}
+			err := parsingWorker(msg, producerQueue, stop, peers)
+			if err == errStopped {
+				glog.Infof("received interrupt, stopping.")
+				return
+			}
+			if err != nil {
+				glog.Errorf("%+v", err)
+				message.CountDecodeError(err)
+				if onError != nil && len(msg) >= bmp.CommonHeaderLength {
+					onError(msg[5], err)
+				}
+			}
 		case <-stop:
 			glog.Infof("received interrupt, stopping.")
 			return
@@ -18,7 +74,17 @@ func Parser(queue chan []byte, producerQueue chan bmp.Message, stop chan struct{
 	}
 }
 
-func parsingWorker(b []byte, producerQueue chan bmp.Message) {
+// errStopped is returned by parsingWorker when stop was closed while waiting for producerQueue
+var errStopped = errors.New("parser stopped")
+
+func parsingWorker(b []byte, producerQueue chan bmp.Message, stop chan struct{}, peers map[string]*peerAddPath) (err error) {
+	// Decoders validate the input, recovering from a panic is the last resort protecting the session
+	// from a message the decoders failed to validate.
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("recovered from panic while parsing BMP message: %v, message: %s", r, tools.MessageHex(b))
+		}
+	}()
 	perPerHeaderLen := 0
 	// var jsonMsg []byte
 	var bmpMsg bmp.Message
@@ -27,76 +93,92 @@ func parsingWorker(b []byte, producerQueue chan bmp.Message) {
 		bmpMsg.PeerHeader = nil
 		bmpMsg.Payload = nil
 		// Recovering common header first
-		ch, err := bmp.UnmarshalCommonHeader(b[p : p+bmp.CommonHeaderLength])
+		ch, err := bmp.UnmarshalCommonHeader(b[p:])
 		if err != nil {
-			glog.Errorf("fail to recover BMP message Common Header with error: %+v", err)
-			return
+			return fmt.Errorf("fail to recover BMP message Common Header with error: %w", err)
+		}
+		if p+int(ch.MessageLength) > len(b) {
+			return fmt.Errorf("fail to recover BMP message, message length %d exceeds %d bytes available", ch.MessageLength, len(b)-p)
 		}
 		p += bmp.CommonHeaderLength
 		switch ch.MessageType {
 		case bmp.RouteMonitorMsg:
 			if bmpMsg.PeerHeader, err = bmp.UnmarshalPerPeerHeader(b[p : p+int(ch.MessageLength-bmp.CommonHeaderLength)]); err != nil {
-				glog.Errorf("fail to recover BMP Per Peer Header with error: %+v", err)
-				return
+				return fmt.Errorf("fail to recover BMP Per Peer Header with error: %w", err)
 			}
 			perPerHeaderLen = bmp.PerPeerHeaderLength
-			rm, err := bmp.UnmarshalBMPRouteMonitorMessage(b[p+perPerHeaderLen : p+int(ch.MessageLength)-bmp.CommonHeaderLength])
+			rm, err := bmp.UnmarshalBMPRouteMonitorMessage(b[p+perPerHeaderLen:p+int(ch.MessageLength)-bmp.CommonHeaderLength], routeMonitorAddPath(peers, bmpMsg.PeerHeader))
 			if err != nil {
-				glog.Errorf("fail to recover BMP Route Monitoring with error: %+v", err)
-				return
+				return fmt.Errorf("fail to recover BMP Route Monitoring with error: %w", err)
 			}
 			bmpMsg.Payload = rm
 			p += perPerHeaderLen
 		case bmp.StatsReportMsg:
-			_, err := bmp.UnmarshalPerPeerHeader(b[p : p+int(ch.MessageLength-bmp.CommonHeaderLength)])
-			if err != nil {
-				glog.Errorf("fail to recover BMP Per Peer Header with error: %+v", err)
-				return
+			if bmpMsg.PeerHeader, err = bmp.UnmarshalPerPeerHeader(b[p : p+int(ch.MessageLength-bmp.CommonHeaderLength)]); err != nil {
+				return fmt.Errorf("fail to recover BMP Per Peer Header with error: %w", err)
 			}
 			perPerHeaderLen = bmp.PerPeerHeaderLength
-			if _, err = bmp.UnmarshalBMPStatsReportMessage(b[p+perPerHeaderLen : len(b)]); err != nil {
-				glog.Errorf("fail to recover BMP Stats Reports message with error: %+v", err)
-				return
+			if bmpMsg.Payload, err = bmp.UnmarshalBMPStatsReportMessage(b[p+perPerHeaderLen : p+int(ch.MessageLength)-bmp.CommonHeaderLength]); err != nil {
+				return fmt.Errorf("fail to recover BMP Stats Reports message with error: %w", err)
 			}
 			p += perPerHeaderLen
 		case bmp.PeerDownMsg:
 			if bmpMsg.PeerHeader, err = bmp.UnmarshalPerPeerHeader(b[p : p+int(ch.MessageLength-bmp.CommonHeaderLength)]); err != nil {
-				glog.Errorf("fail to recover BMP Per Peer Header with error: %+v", err)
-				return
+				return fmt.Errorf("fail to recover BMP Per Peer Header with error: %w", err)
 			}
 			perPerHeaderLen = bmp.PerPeerHeaderLength
 			if bmpMsg.Payload, err = bmp.UnmarshalPeerDownMessage(b[p+perPerHeaderLen : p+int(ch.MessageLength)-bmp.CommonHeaderLength]); err != nil {
-				glog.Errorf("fail to recover BMP Peer Down message with error: %+v", err)
-				return
+				return fmt.Errorf("fail to recover BMP Peer Down message with error: %w", err)
 			}
+			delete(peers, peerKey(bmpMsg.PeerHeader))
 			p += perPerHeaderLen
 		case bmp.PeerUpMsg:
 			if bmpMsg.PeerHeader, err = bmp.UnmarshalPerPeerHeader(b[p : p+int(ch.MessageLength-bmp.CommonHeaderLength)]); err != nil {
-				glog.Errorf("fail to recover BMP Per Peer Header with error: %+v", err)
-				return
+				return fmt.Errorf("fail to recover BMP Per Peer Header with error: %w", err)
 			}
 			perPerHeaderLen = bmp.PerPeerHeaderLength
 			if bmpMsg.Payload, err = bmp.UnmarshalPeerUpMessage(b[p+perPerHeaderLen : p+int(ch.MessageLength)-bmp.CommonHeaderLength]); err != nil {
-				glog.Errorf("fail to recover BMP Peer Up message with error: %+v", err)
-				return
+				return fmt.Errorf("fail to recover BMP Peer Up message with error: %w", err)
+			}
+			if pu, ok := bmpMsg.Payload.(*bmp.PeerUpMessage); ok && peers != nil {
+				// Path Identifiers are present in the routes received by the monitored router when it advertised
+				// ADD-PATH Receive and the peer advertised ADD-PATH Send, and the other way around for the routes
+				// advertised by the monitored router.
+				peers[peerKey(bmpMsg.PeerHeader)] = &peerAddPath{
+					in:  bgp.NegotiatedAddPath(pu.SentOpen, pu.ReceivedOpen),
+					out: bgp.NegotiatedAddPath(pu.ReceivedOpen, pu.SentOpen),
+				}
 			}
 			p += perPerHeaderLen
 		case bmp.InitiationMsg:
-			if _, err := bmp.UnmarshalInitiationMessage(b[p : p+(int(ch.MessageLength)-bmp.CommonHeaderLength)]); err != nil {
-				glog.Errorf("fail to recover BMP Initiation message with error: %+v", err)
-				return
+			if bmpMsg.Payload, err = bmp.UnmarshalInitiationMessage(b[p : p+(int(ch.MessageLength)-bmp.CommonHeaderLength)]); err != nil {
+				return fmt.Errorf("fail to recover BMP Initiation message with error: %w", err)
 			}
 		case bmp.TerminationMsg:
-			glog.V(5).Infof("Termination message")
-			glog.V(6).Infof("Message: %+v", b)
+			if bmpMsg.Payload, err = bmp.UnmarshalTerminationMessage(b[p : p+(int(ch.MessageLength)-bmp.CommonHeaderLength)]); err != nil {
+				return fmt.Errorf("fail to recover BMP Termination message with error: %w", err)
+			}
 		case bmp.RouteMirrorMsg:
-			glog.V(5).Infof("Route Mirroring message")
-			glog.V(6).Infof("Message: %+v", b)
+			if bmpMsg.PeerHeader, err = bmp.UnmarshalPerPeerHeader(b[p : p+int(ch.MessageLength-bmp.CommonHeaderLength)]); err != nil {
+				return fmt.Errorf("fail to recover BMP Per Peer Header with error: %w", err)
+			}
+			perPerHeaderLen = bmp.PerPeerHeaderLength
+			// Route Mirroring carries messages received from the peer, ADD-PATH is the one negotiated for Adj-RIB-In
+			if bmpMsg.Payload, err = bmp.UnmarshalRouteMirrorMessage(b[p+perPerHeaderLen:p+int(ch.MessageLength)-bmp.CommonHeaderLength], routeMonitorAddPath(peers, bmpMsg.PeerHeader)); err != nil {
+				return fmt.Errorf("fail to recover BMP Route Mirroring message with error: %w", err)
+			}
+			p += perPerHeaderLen
 		}
 		perPerHeaderLen = 0
 		p += (int(ch.MessageLength) - bmp.CommonHeaderLength)
 		if producerQueue != nil && bmpMsg.Payload != nil {
-			producerQueue <- bmpMsg
+			select {
+			case producerQueue <- bmpMsg:
+			case <-stop:
+				return errStopped
+			}
 		}
 	}
+
+	return nil
 }
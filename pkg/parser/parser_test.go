@@ -1,21 +1,213 @@
 package parser
 
-import "testing"
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+// bmpMessage returns BMP message of the type with Per Peer Header of the peer 192.0.2.1 in AS 65001
+func bmpMessage(t uint8, peerType uint8, flags uint8, payload []byte) []byte {
+	b := make([]byte, bmp.CommonHeaderLength+bmp.PerPeerHeaderLength, bmp.CommonHeaderLength+bmp.PerPeerHeaderLength+len(payload))
+	b[0] = 3
+	binary.BigEndian.PutUint32(b[1:5], uint32(cap(b)))
+	b[5] = t
+	ph := b[bmp.CommonHeaderLength:]
+	ph[0], ph[1] = peerType, flags
+	copy(ph[22:26], []byte{192, 0, 2, 1})
+	binary.BigEndian.PutUint32(ph[26:30], 65001)
+	copy(ph[30:34], []byte{192, 0, 2, 1})
+
+	return append(b, payload...)
+}
+
+// bgpMessage returns BGP message of the type with the marker
+func bgpMessage(t uint8, payload []byte) []byte {
+	b := make([]byte, 19, 19+len(payload))
+	for i := 0; i < 16; i++ {
+		b[i] = 0xff
+	}
+	binary.BigEndian.PutUint16(b[16:18], uint16(cap(b)))
+	b[18] = t
+
+	return append(b, payload...)
+}
+
+// addPathOpen returns BGP Open message with ADD-PATH capability for IPv4 unicast
+func addPathOpen(sendReceive uint8) []byte {
+	return bgpMessage(1, []byte{4, 0xfd, 0xe9, 0, 90, 192, 0, 2, 1, 8, 2, 6, 69, 4, 0, 1, 1, sendReceive})
+}
+
+func TestParsingWorkerAddPath(t *testing.T) {
+	peerUp := append(make([]byte, 20), append(addPathOpen(1), addPathOpen(2)...)...)
+	// Update with Origin, Next Hop and 10.1.2.0/24 with Path Identifier 7
+	update := bgpMessage(2, []byte{0, 0, 0, 11, 0x40, 1, 1, 0, 0x40, 3, 4, 10, 0, 0, 1, 0, 0, 0, 7, 24, 10, 1, 2})
+	tests := []struct {
+		name   string
+		input  [][]byte
+		routes int
+		pathID uint32
+	}{
+		{
+			name:   "adj-rib-in route of add-path peer",
+			input:  [][]byte{bmpMessage(bmp.PeerUpMsg, 0, 0, peerUp), bmpMessage(bmp.RouteMonitorMsg, 0, 0, update)},
+			routes: 1,
+			pathID: 7,
+		},
+		{
+			name: "adj-rib-out route of add-path peer, add-path is not negotiated for sent routes",
+			// O flag is set
+			input: [][]byte{bmpMessage(bmp.PeerUpMsg, 0, 0, peerUp), bmpMessage(bmp.RouteMonitorMsg, 0, 0x10, update)},
+			// Path Identifier is decoded as 3 default routes and 7 bits long prefix followed by 10 bits long prefix
+			routes: 5,
+		},
+		{
+			name:   "route of unknown peer",
+			input:  [][]byte{bmpMessage(bmp.RouteMonitorMsg, 0, 0, update)},
+			routes: 5,
+		},
+		{
+			name: "route of add-path peer after peer down",
+			input: [][]byte{
+				bmpMessage(bmp.PeerUpMsg, 0, 0, peerUp),
+				bmpMessage(bmp.PeerDownMsg, 0, 0, []byte{4}),
+				bmpMessage(bmp.RouteMonitorMsg, 0, 0, update),
+			},
+			routes: 5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			peers := make(map[string]*peerAddPath)
+			producerQueue := make(chan bmp.Message, len(tt.input))
+			for _, b := range tt.input {
+				if err := parsingWorker(b, producerQueue, nil, peers); err != nil {
+					t.Fatalf("supposed to succeed but failed with error: %+v", err)
+				}
+			}
+			var rm *bmp.RouteMonitor
+			for len(producerQueue) != 0 {
+				if m, ok := (<-producerQueue).Payload.(*bmp.RouteMonitor); ok {
+					rm = m
+				}
+			}
+			if rm == nil {
+				t.Fatalf("route monitor message was not produced")
+			}
+			if len(rm.Update.NLRI) != tt.routes || rm.Update.NLRI[0].PathID != tt.pathID {
+				t.Fatalf("expected %d routes with path id %d but got %+v", tt.routes, tt.pathID, rm.Update.NLRI)
+			}
+		})
+	}
+}
+
+func TestParsingWorkerRouteMirrorAddPath(t *testing.T) {
+	peerUp := append(make([]byte, 20), append(addPathOpen(1), addPathOpen(2)...)...)
+	// Update with Origin, Next Hop and 10.1.2.0/24 with Path Identifier 7 mirrored in BGP Message TLV
+	update := bgpMessage(2, []byte{0, 0, 0, 11, 0x40, 1, 1, 0, 0x40, 3, 4, 10, 0, 0, 1, 0, 0, 0, 7, 24, 10, 1, 2})
+	mirror := append([]byte{0, bmp.MirrorTLVBGPMessage, byte(len(update) >> 8), byte(len(update))}, update...)
+	peers := make(map[string]*peerAddPath)
+	producerQueue := make(chan bmp.Message, 2)
+	for _, b := range [][]byte{bmpMessage(bmp.PeerUpMsg, 0, 0, peerUp), bmpMessage(bmp.RouteMirrorMsg, 0, 0, mirror)} {
+		if err := parsingWorker(b, producerQueue, nil, peers); err != nil {
+			t.Fatalf("supposed to succeed but failed with error: %+v", err)
+		}
+	}
+	<-producerQueue
+	rm, ok := (<-producerQueue).Payload.(*bmp.RouteMirror)
+	if !ok {
+		t.Fatalf("route mirroring message was not produced")
+	}
+	u, err := rm.GetUpdate()
+	if err != nil {
+		t.Fatalf("failed to decode mirrored update with error: %+v", err)
+	}
+	if len(u.NLRI) != 1 || u.NLRI[0].PathID != 7 {
+		t.Fatalf("expected 1 route with path id 7 but got %+v", u.NLRI)
+	}
+}
 
 func TestParsingWorker(t *testing.T) {
 	tests := []struct {
 		name  string
 		input []byte
+		fail  bool
 	}{
 		{
 			name:  "test 1",
 			input: []byte{3, 0, 0, 0, 32, 4, 0, 1, 0, 10, 32, 55, 46, 50, 46, 49, 46, 50, 51, 73, 0, 2, 0, 8, 120, 114, 118, 57, 107, 45, 114, 49, 3, 0, 0, 0, 234, 3, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 192, 168, 80, 103, 0, 0, 19, 206, 57, 112, 1, 254, 94, 98, 129, 171, 0, 0, 215, 126, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 192, 168, 80, 128, 0, 179, 131, 152, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 0, 91, 1, 4, 19, 206, 0, 90, 192, 168, 8, 8, 62, 2, 6, 1, 4, 0, 1, 0, 1, 2, 6, 1, 4, 0, 1, 0, 4, 2, 6, 1, 4, 0, 1, 0, 128, 2, 2, 128, 0, 2, 2, 2, 0, 2, 6, 65, 4, 0, 0, 19, 206, 2, 20, 5, 18, 0, 1, 0, 1, 0, 2, 0, 1, 0, 2, 0, 2, 0, 1, 0, 128, 0, 2, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 0, 75, 1, 4, 19, 206, 0, 90, 57, 112, 1, 254, 46, 2, 44, 2, 0, 1, 4, 0, 1, 0, 1, 1, 4, 0, 2, 0, 1, 1, 4, 0, 1, 0, 4, 1, 4, 0, 2, 0, 4, 1, 4, 0, 1, 0, 128, 1, 4, 0, 2, 0, 128, 65, 4, 0, 0, 19, 206},
 		},
+		{
+			name:  "truncated peer up",
+			input: []byte{3, 0, 0, 0, 60, 3, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 192, 168, 80, 103, 0, 0, 19, 206, 57, 112, 1, 254, 94, 98, 129, 171, 0, 0, 215, 126, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+			fail:  true,
+		},
+		{
+			name:  "message length exceeds buffer",
+			input: []byte{3, 0, 0, 0, 32, 4, 0, 1, 0, 10},
+			fail:  true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			parsingWorker(tt.input, nil)
+			err := parsingWorker(tt.input, nil, nil, nil)
+			if err != nil && !tt.fail {
+				t.Fatalf("test failed with error: %+v", err)
+			}
+			if err == nil && tt.fail {
+				t.Fatalf("test supposed to fail but succeeded")
+			}
 		})
 	}
 }
+
+func TestParserDrain(t *testing.T) {
+	// BMP Initiation message
+	initiation := []byte{3, 0, 0, 0, 32, 4, 0, 1, 0, 10, 32, 55, 46, 50, 46, 49, 46, 50, 51, 73, 0, 2, 0, 8, 120, 114, 118, 57, 107, 45, 114, 49}
+	queue := make(chan []byte, 3)
+	producerQueue := make(chan bmp.Message, 3)
+	for i := 0; i < cap(queue); i++ {
+		queue <- initiation
+	}
+	close(queue)
+	// Parser is expected to parse all queued messages and to return closing producerQueue
+	Parser(queue, producerQueue, make(chan struct{}), nil)
+	count := 0
+	for msg := range producerQueue {
+		if _, ok := msg.Payload.(*bmp.InitiationMessage); !ok {
+			t.Fatalf("expected Initiation message but got %T", msg.Payload)
+		}
+		count++
+	}
+	if count != cap(queue) {
+		t.Fatalf("expected %d messages but got %d", cap(queue), count)
+	}
+}
+
+func TestParserStopBlocked(t *testing.T) {
+	// BMP Initiation message
+	initiation := []byte{3, 0, 0, 0, 32, 4, 0, 1, 0, 10, 32, 55, 46, 50, 46, 49, 46, 50, 51, 73, 0, 2, 0, 8, 120, 114, 118, 57, 107, 45, 114, 49}
+	queue := make(chan []byte, 2)
+	queue <- initiation
+	queue <- initiation
+	// producerQueue is never read, Parser blocks sending the second message
+	producerQueue := make(chan bmp.Message, 1)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		Parser(queue, producerQueue, stop, nil)
+		close(done)
+	}()
+	for len(queue) != 0 {
+		time.Sleep(time.Millisecond)
+	}
+	close(stop)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Parser blocked on full producer queue did not return after stop")
+	}
+}
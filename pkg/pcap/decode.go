@@ -0,0 +1,192 @@
+package pcap
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strconv"
+)
+
+// Link types of the captured packets
+const (
+	linkTypeNull       = 0
+	linkTypeEthernet   = 1
+	linkTypeRawOpenBSD = 12
+	linkTypeRaw        = 101
+	linkTypeLoop       = 108
+	linkTypeLinuxSLL   = 113
+	linkTypeIPv4       = 228
+	linkTypeIPv6       = 229
+	linkTypeLinuxSLL2  = 276
+)
+
+const (
+	etherTypeIPv4  = 0x0800
+	etherTypeIPv6  = 0x86dd
+	etherTypeVLAN  = 0x8100
+	etherTypeQinQ  = 0x88a8
+	etherTypeQinQ2 = 0x9100
+	protocolTCP    = 6
+	tcpFlagFIN     = 0x01
+	tcpFlagSYN     = 0x02
+	tcpFlagRST     = 0x04
+)
+
+// segment defines TCP segment carried by a captured packet
+type segment struct {
+	srcIP   net.IP
+	dstIP   net.IP
+	srcPort int
+	dstPort int
+	seq     uint32
+	flags   byte
+	payload []byte
+}
+
+func (s *segment) src() string {
+	return net.JoinHostPort(s.srcIP.String(), strconv.Itoa(s.srcPort))
+}
+
+func (s *segment) dst() string {
+	return net.JoinHostPort(s.dstIP.String(), strconv.Itoa(s.dstPort))
+}
+
+// decodeSegment returns TCP segment carried by the packet, nil is returned for packets not carrying
+// TCP over IPv4 or IPv6.
+func decodeSegment(linkType uint32, b []byte) (*segment, error) {
+	var etherType uint16
+	switch linkType {
+	case linkTypeEthernet:
+		if len(b) < 14 {
+			return nil, fmt.Errorf("invalid ethernet frame length %d", len(b))
+		}
+		etherType, b = binary.BigEndian.Uint16(b[12:14]), b[14:]
+		for etherType == etherTypeVLAN || etherType == etherTypeQinQ || etherType == etherTypeQinQ2 {
+			if len(b) < 4 {
+				return nil, fmt.Errorf("invalid vlan tag length %d", len(b))
+			}
+			etherType, b = binary.BigEndian.Uint16(b[2:4]), b[4:]
+		}
+	case linkTypeLinuxSLL:
+		if len(b) < 16 {
+			return nil, fmt.Errorf("invalid linux cooked header length %d", len(b))
+		}
+		etherType, b = binary.BigEndian.Uint16(b[14:16]), b[16:]
+	case linkTypeLinuxSLL2:
+		if len(b) < 20 {
+			return nil, fmt.Errorf("invalid linux cooked v2 header length %d", len(b))
+		}
+		etherType, b = binary.BigEndian.Uint16(b[0:2]), b[20:]
+	case linkTypeNull, linkTypeLoop:
+		// Loopback header carries the address family, the IP version is taken from the IP header instead
+		if len(b) < 4 {
+			return nil, fmt.Errorf("invalid loopback header length %d", len(b))
+		}
+		b = b[4:]
+		fallthrough
+	case linkTypeRaw, linkTypeRawOpenBSD, linkTypeIPv4, linkTypeIPv6:
+		if len(b) == 0 {
+			return nil, fmt.Errorf("empty ip packet")
+		}
+		switch b[0] >> 4 {
+		case 4:
+			etherType = etherTypeIPv4
+		case 6:
+			etherType = etherTypeIPv6
+		}
+	default:
+		return nil, fmt.Errorf("unsupported link type %d", linkType)
+	}
+	switch etherType {
+	case etherTypeIPv4:
+		return decodeIPv4(b)
+	case etherTypeIPv6:
+		return decodeIPv6(b)
+	}
+
+	return nil, nil
+}
+
+func decodeIPv4(b []byte) (*segment, error) {
+	if len(b) < 20 || b[0]>>4 != 4 {
+		return nil, fmt.Errorf("invalid ipv4 packet")
+	}
+	hl := int(b[0]&0x0f) * 4
+	if hl < 20 || hl > len(b) {
+		return nil, fmt.Errorf("invalid ipv4 header length %d", hl)
+	}
+	if b[9] != protocolTCP {
+		return nil, nil
+	}
+	// More Fragments flag or Fragment Offset are set
+	if binary.BigEndian.Uint16(b[6:8])&0x3fff != 0 {
+		return nil, fmt.Errorf("fragmented ipv4 packets are not supported")
+	}
+	// Frames can be padded beyond the end of IP packet
+	if tl := int(binary.BigEndian.Uint16(b[2:4])); tl >= hl && tl < len(b) {
+		b = b[:tl]
+	}
+	s, err := decodeTCP(b[hl:])
+	if s == nil {
+		return nil, err
+	}
+	s.srcIP, s.dstIP = net.IP(b[12:16]), net.IP(b[16:20])
+
+	return s, nil
+}
+
+func decodeIPv6(b []byte) (*segment, error) {
+	if len(b) < 40 || b[0]>>4 != 6 {
+		return nil, fmt.Errorf("invalid ipv6 packet")
+	}
+	if pl := int(binary.BigEndian.Uint16(b[4:6])); 40+pl < len(b) {
+		b = b[:40+pl]
+	}
+	src, dst := net.IP(b[8:24]), net.IP(b[24:40])
+	next, p := b[6], b[40:]
+	for {
+		switch next {
+		case protocolTCP:
+			s, err := decodeTCP(p)
+			if s == nil {
+				return nil, err
+			}
+			s.srcIP, s.dstIP = src, dst
+			return s, nil
+		case 0, 43, 60:
+			// Hop-by-Hop, Routing and Destination Options headers
+			if len(p) < 8 || len(p) < (int(p[1])+1)*8 {
+				return nil, fmt.Errorf("invalid ipv6 extension header")
+			}
+			next, p = p[0], p[(int(p[1])+1)*8:]
+		case 51:
+			// Authentication header's length is in 32 bits words minus 2
+			if len(p) < 8 || len(p) < (int(p[1])+2)*4 {
+				return nil, fmt.Errorf("invalid ipv6 authentication header")
+			}
+			next, p = p[0], p[(int(p[1])+2)*4:]
+		case 44:
+			return nil, fmt.Errorf("fragmented ipv6 packets are not supported")
+		default:
+			return nil, nil
+		}
+	}
+}
+
+func decodeTCP(b []byte) (*segment, error) {
+	if len(b) < 20 {
+		return nil, fmt.Errorf("invalid tcp header length %d", len(b))
+	}
+	hl := int(b[12]>>4) * 4
+	if hl < 20 || hl > len(b) {
+		return nil, fmt.Errorf("invalid tcp data offset %d", hl)
+	}
+
+	return &segment{
+		srcPort: int(binary.BigEndian.Uint16(b[0:2])),
+		dstPort: int(binary.BigEndian.Uint16(b[2:4])),
+		seq:     binary.BigEndian.Uint32(b[4:8]),
+		flags:   b[13],
+		payload: b[hl:],
+	}, nil
+}
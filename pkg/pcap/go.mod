@@ -0,0 +1,5 @@
+module github.com/sbezverk/gobmp/pkg/pcap
+
+go 1.14
+
+require github.com/golang/glog v0.0.0-20160126235308-23def4e6c14b
@@ -0,0 +1,260 @@
+package pcap
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+const (
+	// pcapngSectionHeader is the type of pcapng Section Header Block, the type reads the same in both byte orders
+	pcapngSectionHeader      = 0x0a0d0d0a
+	pcapngInterface          = 1
+	pcapngObsoletePacket     = 2
+	pcapngSimplePacket       = 3
+	pcapngEnhancedPacket     = 6
+	pcapngByteOrderMagic     = 0x1a2b3c4d
+	pcapngOptionEnd          = 0
+	pcapngOptionTSResolution = 9
+	// maxPacketLength limits the size of a captured packet or a pcapng block to detect corrupted files
+	maxPacketLength = 16 << 20
+)
+
+// Packet defines a packet captured in pcap or pcapng file
+type Packet struct {
+	Time     time.Time
+	LinkType uint32
+	Data     []byte
+}
+
+// pcapngIface defines an interface described by pcapng Interface Description Block
+type pcapngIface struct {
+	linkType uint32
+	// tsUnit is the length of the timestamp's unit in nanoseconds
+	tsUnit float64
+}
+
+// Reader reads packets from pcap or pcapng file, the format and the byte order are detected from the file's header.
+type Reader struct {
+	r     *bufio.Reader
+	order binary.ByteOrder
+	ng    bool
+	// linkType and nano are the link type and the timestamp resolution of pcap file
+	linkType uint32
+	nano     bool
+	// ifaces are the interfaces of the current pcapng section
+	ifaces []pcapngIface
+	// last is the time of the last packet, pcapng Simple Packet Block carries no timestamp
+	last time.Time
+}
+
+// NewReader returns a new reader of pcap or pcapng file
+func NewReader(r io.Reader) (*Reader, error) {
+	pr := &Reader{r: bufio.NewReader(r)}
+	magic, err := pr.r.Peek(4)
+	if err != nil {
+		return nil, fmt.Errorf("fail to read file header with error: %+v", err)
+	}
+	switch {
+	case binary.BigEndian.Uint32(magic) == pcapngSectionHeader:
+		pr.ng = true
+		// Section Header Block is read along with the packets
+		return pr, nil
+	case binary.LittleEndian.Uint32(magic) == 0xa1b2c3d4:
+		pr.order = binary.LittleEndian
+	case binary.BigEndian.Uint32(magic) == 0xa1b2c3d4:
+		pr.order = binary.BigEndian
+	case binary.LittleEndian.Uint32(magic) == 0xa1b23c4d:
+		pr.order, pr.nano = binary.LittleEndian, true
+	case binary.BigEndian.Uint32(magic) == 0xa1b23c4d:
+		pr.order, pr.nano = binary.BigEndian, true
+	default:
+		return nil, fmt.Errorf("not a pcap or pcapng file, invalid magic %x", magic)
+	}
+	h := make([]byte, 24)
+	if _, err := io.ReadFull(pr.r, h); err != nil {
+		return nil, fmt.Errorf("fail to read pcap file header with error: %+v", err)
+	}
+	// Upper bits of the link type carry FCS information
+	pr.linkType = pr.order.Uint32(h[20:24]) & 0xffff
+
+	return pr, nil
+}
+
+// Next returns the next packet, io.EOF is returned at the end of the file
+func (r *Reader) Next() (*Packet, error) {
+	if r.ng {
+		return r.nextBlock()
+	}
+	h := make([]byte, 16)
+	if _, err := io.ReadFull(r.r, h); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, fmt.Errorf("pcap file is truncated")
+		}
+		return nil, err
+	}
+	l := r.order.Uint32(h[8:12])
+	if l > maxPacketLength {
+		return nil, fmt.Errorf("invalid captured packet length %d", l)
+	}
+	p := &Packet{LinkType: r.linkType, Data: make([]byte, l)}
+	if _, err := io.ReadFull(r.r, p.Data); err != nil {
+		return nil, fmt.Errorf("pcap file is truncated")
+	}
+	frac := int64(r.order.Uint32(h[4:8]))
+	if !r.nano {
+		frac *= 1000
+	}
+	p.Time = time.Unix(int64(r.order.Uint32(h[0:4])), frac)
+	r.last = p.Time
+
+	return p, nil
+}
+
+// nextBlock reads pcapng blocks until a packet block is found
+func (r *Reader) nextBlock() (*Packet, error) {
+	for {
+		h := make([]byte, 8)
+		if _, err := io.ReadFull(r.r, h); err != nil {
+			if err == io.ErrUnexpectedEOF {
+				return nil, fmt.Errorf("pcapng file is truncated")
+			}
+			return nil, err
+		}
+		if binary.BigEndian.Uint32(h[:4]) == pcapngSectionHeader {
+			// A new section starts, its byte order is defined by Byte-Order Magic following the block's length
+			bom, err := r.r.Peek(4)
+			if err != nil {
+				return nil, fmt.Errorf("pcapng file is truncated")
+			}
+			switch {
+			case binary.LittleEndian.Uint32(bom) == pcapngByteOrderMagic:
+				r.order = binary.LittleEndian
+			case binary.BigEndian.Uint32(bom) == pcapngByteOrderMagic:
+				r.order = binary.BigEndian
+			default:
+				return nil, fmt.Errorf("invalid pcapng byte-order magic %x", bom)
+			}
+			r.ifaces = r.ifaces[:0]
+		}
+		if r.order == nil {
+			return nil, fmt.Errorf("pcapng file does not start with section header block")
+		}
+		l := r.order.Uint32(h[4:8])
+		if l < 12 || l%4 != 0 || l > maxPacketLength {
+			return nil, fmt.Errorf("invalid pcapng block length %d", l)
+		}
+		b := make([]byte, l-8)
+		if _, err := io.ReadFull(r.r, b); err != nil {
+			return nil, fmt.Errorf("pcapng file is truncated")
+		}
+		// Trailing block length is not part of the body
+		body := b[:len(b)-4]
+		var p *Packet
+		var err error
+		switch r.order.Uint32(h[:4]) {
+		case pcapngInterface:
+			err = r.readInterface(body)
+		case pcapngEnhancedPacket:
+			p, err = r.readPacket(body, r.order.Uint32(body))
+		case pcapngObsoletePacket:
+			// Obsolete Packet Block carries 16 bits interface id followed by 16 bits drops count
+			p, err = r.readPacket(body, uint32(r.order.Uint16(body)))
+		case pcapngSimplePacket:
+			p, err = r.readSimplePacket(body)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if p != nil {
+			return p, nil
+		}
+	}
+}
+
+func (r *Reader) readInterface(b []byte) error {
+	if len(b) < 8 {
+		return fmt.Errorf("invalid pcapng interface description block length %d", len(b))
+	}
+	iface := pcapngIface{linkType: uint32(r.order.Uint16(b[0:2])), tsUnit: 1000}
+	for p := 8; p+4 <= len(b); {
+		code, l := r.order.Uint16(b[p:p+2]), int(r.order.Uint16(b[p+2:p+4]))
+		p += 4
+		if code == pcapngOptionEnd || p+l > len(b) {
+			break
+		}
+		if code == pcapngOptionTSResolution && l == 1 {
+			if v := b[p]; v&0x80 == 0 {
+				iface.tsUnit = math.Pow10(9 - int(v))
+			} else {
+				iface.tsUnit = 1e9 / math.Pow(2, float64(v&0x7f))
+			}
+		}
+		// Option values are padded to 32 bits
+		p += (l + 3) &^ 3
+	}
+	r.ifaces = append(r.ifaces, iface)
+
+	return nil
+}
+
+// readPacket reads Enhanced or Obsolete Packet Block captured on interface id, both blocks carry the timestamp,
+// the captured length, the original length and the packet data at the same offsets.
+func (r *Reader) readPacket(b []byte, id uint32) (*Packet, error) {
+	if len(b) < 20 {
+		return nil, fmt.Errorf("invalid pcapng packet block length %d", len(b))
+	}
+	if int(id) >= len(r.ifaces) {
+		return nil, fmt.Errorf("pcapng packet block refers to unknown interface %d", id)
+	}
+	l := int(r.order.Uint32(b[12:16]))
+	if 20+l > len(b) {
+		return nil, fmt.Errorf("invalid pcapng captured packet length %d", l)
+	}
+	iface := r.ifaces[id]
+	units := uint64(r.order.Uint32(b[4:8]))<<32 | uint64(r.order.Uint32(b[8:12]))
+	p := &Packet{
+		Time:     tsTime(units, iface.tsUnit),
+		LinkType: iface.linkType,
+		Data:     b[20 : 20+l],
+	}
+	r.last = p.Time
+
+	return p, nil
+}
+
+// readSimplePacket reads Simple Packet Block, the block carries no timestamp, the time of the previous
+// packet is used.
+func (r *Reader) readSimplePacket(b []byte) (*Packet, error) {
+	if len(b) < 4 {
+		return nil, fmt.Errorf("invalid pcapng simple packet block length %d", len(b))
+	}
+	if len(r.ifaces) == 0 {
+		return nil, fmt.Errorf("pcapng simple packet block without interface")
+	}
+	l := int(r.order.Uint32(b[0:4]))
+	if l > len(b)-4 {
+		l = len(b) - 4
+	}
+
+	return &Packet{Time: r.last, LinkType: r.ifaces[0].linkType, Data: b[4 : 4+l]}, nil
+}
+
+// tsTime converts pcapng timestamp in units of unit nanoseconds to time
+func tsTime(units uint64, unit float64) time.Time {
+	if unit == 1 {
+		return time.Unix(0, int64(units))
+	}
+	if unit >= 1 && unit == math.Trunc(unit) {
+		u := uint64(unit)
+		perSec := uint64(1e9) / u
+		return time.Unix(int64(units/perSec), int64(units%perSec*u))
+	}
+	ns := float64(units) * unit
+	sec := math.Floor(ns / 1e9)
+
+	return time.Unix(int64(sec), int64(ns-sec*1e9))
+}
@@ -0,0 +1,306 @@
+package pcap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type testPacket struct {
+	t    time.Time
+	data []byte
+}
+
+// tcpFrame returns ethernet frame carrying TCP segment from src to dst
+func tcpFrame(src, dst string, seq uint32, flags byte, payload []byte) []byte {
+	srcHost, srcPort, _ := net.SplitHostPort(src)
+	dstHost, dstPort, _ := net.SplitHostPort(dst)
+	sp, _ := strconv.Atoi(srcPort)
+	dp, _ := strconv.Atoi(dstPort)
+	tcp := make([]byte, 20, 20+len(payload))
+	binary.BigEndian.PutUint16(tcp[0:2], uint16(sp))
+	binary.BigEndian.PutUint16(tcp[2:4], uint16(dp))
+	binary.BigEndian.PutUint32(tcp[4:8], seq)
+	tcp[12] = 5 << 4
+	tcp[13] = flags
+	tcp = append(tcp, payload...)
+	frame := make([]byte, 14)
+	srcIP, dstIP := net.ParseIP(srcHost), net.ParseIP(dstHost)
+	if srcIP.To4() != nil {
+		binary.BigEndian.PutUint16(frame[12:14], etherTypeIPv4)
+		ip := make([]byte, 20)
+		ip[0] = 0x45
+		binary.BigEndian.PutUint16(ip[2:4], uint16(20+len(tcp)))
+		ip[9] = protocolTCP
+		copy(ip[12:16], srcIP.To4())
+		copy(ip[16:20], dstIP.To4())
+		frame = append(append(frame, ip...), tcp...)
+		// Ethernet padding must not become part of the stream
+		return append(frame, 0, 0, 0, 0)
+	}
+	binary.BigEndian.PutUint16(frame[12:14], etherTypeIPv6)
+	ip := make([]byte, 40)
+	ip[0] = 0x60
+	binary.BigEndian.PutUint16(ip[4:6], uint16(len(tcp)))
+	ip[6] = protocolTCP
+	copy(ip[8:24], srcIP)
+	copy(ip[24:40], dstIP)
+
+	return append(append(frame, ip...), tcp...)
+}
+
+func writePcap(packets []testPacket, order binary.ByteOrder, nano bool) []byte {
+	b := make([]byte, 24)
+	if nano {
+		order.PutUint32(b[0:4], 0xa1b23c4d)
+	} else {
+		order.PutUint32(b[0:4], 0xa1b2c3d4)
+	}
+	order.PutUint16(b[4:6], 2)
+	order.PutUint16(b[6:8], 4)
+	order.PutUint32(b[16:20], 65535)
+	order.PutUint32(b[20:24], linkTypeEthernet)
+	for _, p := range packets {
+		h := make([]byte, 16)
+		order.PutUint32(h[0:4], uint32(p.t.Unix()))
+		if nano {
+			order.PutUint32(h[4:8], uint32(p.t.Nanosecond()))
+		} else {
+			order.PutUint32(h[4:8], uint32(p.t.Nanosecond()/1000))
+		}
+		order.PutUint32(h[8:12], uint32(len(p.data)))
+		order.PutUint32(h[12:16], uint32(len(p.data)))
+		b = append(append(b, h...), p.data...)
+	}
+
+	return b
+}
+
+func pcapngBlock(order binary.ByteOrder, t uint32, body []byte) []byte {
+	for len(body)%4 != 0 {
+		body = append(body, 0)
+	}
+	b := make([]byte, 8, len(body)+12)
+	order.PutUint32(b[0:4], t)
+	order.PutUint32(b[4:8], uint32(len(body)+12))
+	b = append(b, body...)
+	l := make([]byte, 4)
+	order.PutUint32(l, uint32(len(body)+12))
+
+	return append(b, l...)
+}
+
+// writePcapng returns pcapng file with an unrelated interface followed by the ethernet interface
+// with nanoseconds timestamps the packets are captured on.
+func writePcapng(packets []testPacket, order binary.ByteOrder) []byte {
+	shb := make([]byte, 16)
+	order.PutUint32(shb[0:4], pcapngByteOrderMagic)
+	order.PutUint16(shb[4:6], 1)
+	binary.BigEndian.PutUint64(shb[8:16], 0xffffffffffffffff)
+	b := pcapngBlock(order, pcapngSectionHeader, shb)
+	idb := make([]byte, 8)
+	order.PutUint16(idb[0:2], linkTypeLinuxSLL)
+	b = append(b, pcapngBlock(order, pcapngInterface, idb)...)
+	idb = make([]byte, 20)
+	order.PutUint16(idb[0:2], linkTypeEthernet)
+	order.PutUint16(idb[8:10], pcapngOptionTSResolution)
+	order.PutUint16(idb[10:12], 1)
+	idb[12] = 9
+	b = append(b, pcapngBlock(order, pcapngInterface, idb)...)
+	for _, p := range packets {
+		epb := make([]byte, 20)
+		order.PutUint32(epb[0:4], 1)
+		ts := uint64(p.t.UnixNano())
+		order.PutUint32(epb[4:8], uint32(ts>>32))
+		order.PutUint32(epb[8:12], uint32(ts))
+		order.PutUint32(epb[12:16], uint32(len(p.data)))
+		order.PutUint32(epb[16:20], uint32(len(p.data)))
+		b = append(b, pcapngBlock(order, pcapngEnhancedPacket, append(epb, p.data...))...)
+	}
+
+	return b
+}
+
+func TestReader(t *testing.T) {
+	base := time.Unix(1600000000, 123456789)
+	packets := []testPacket{
+		{t: base, data: tcpFrame("192.0.2.1:40000", "192.0.2.2:5000", 1, 0, []byte{1, 2, 3})},
+		{t: base.Add(time.Second), data: tcpFrame("[2001:db8::1]:40000", "[2001:db8::2]:5000", 1, 0, []byte{4})},
+	}
+	tests := []struct {
+		name      string
+		input     []byte
+		precision time.Duration
+	}{
+		{
+			name:      "pcap little endian microseconds",
+			input:     writePcap(packets, binary.LittleEndian, false),
+			precision: time.Microsecond,
+		},
+		{
+			name:      "pcap big endian nanoseconds",
+			input:     writePcap(packets, binary.BigEndian, true),
+			precision: time.Nanosecond,
+		},
+		{
+			name:      "pcapng little endian",
+			input:     writePcapng(packets, binary.LittleEndian),
+			precision: time.Nanosecond,
+		},
+		{
+			name:      "pcapng big endian",
+			input:     writePcapng(packets, binary.BigEndian),
+			precision: time.Nanosecond,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewReader(bytes.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("fail to create reader with error: %+v", err)
+			}
+			for _, expect := range packets {
+				p, err := r.Next()
+				if err != nil {
+					t.Fatalf("fail to read packet with error: %+v", err)
+				}
+				if p.LinkType != linkTypeEthernet {
+					t.Fatalf("expected link type %d but got %d", linkTypeEthernet, p.LinkType)
+				}
+				if !p.Time.Equal(expect.t.Truncate(tt.precision)) {
+					t.Fatalf("expected time %s but got %s", expect.t.Truncate(tt.precision), p.Time)
+				}
+				if !bytes.Equal(expect.data, p.Data) {
+					t.Fatalf("expected packet %v but got %v", expect.data, p.Data)
+				}
+			}
+			if _, err := r.Next(); err != io.EOF {
+				t.Fatalf("expected end of file but got error: %+v", err)
+			}
+		})
+	}
+	if _, err := NewReader(bytes.NewReader([]byte("GOBMPREC"))); err == nil {
+		t.Fatalf("expected to fail with invalid magic but succeeded")
+	}
+}
+
+func TestReadStreams(t *testing.T) {
+	const (
+		router  = "192.0.2.1:40000"
+		router6 = "[2001:db8::1]:40001"
+		bmp     = "192.0.2.2:5000"
+		bmp6    = "[2001:db8::2]:5000"
+		other   = "192.0.2.2:179"
+		// Active mode, the collector connects to the router listening on BMP port
+		collector    = "192.0.2.2:40002"
+		activeRouter = "192.0.2.1:5000"
+		syn          = tcpFlagSYN
+		fin          = tcpFlagFIN
+	)
+	type stream struct {
+		src  string
+		data string
+		gaps int
+	}
+	tests := []struct {
+		name    string
+		packets [][]byte
+		expect  []stream
+	}{
+		{
+			name: "in order",
+			packets: [][]byte{
+				tcpFrame(router, bmp, 100, syn, nil),
+				tcpFrame(bmp, router, 500, syn, nil),
+				tcpFrame(router, bmp, 101, 0, []byte("hello ")),
+				tcpFrame(router, bmp, 107, fin, []byte("world")),
+			},
+			expect: []stream{{src: router, data: "hello world"}},
+		},
+		{
+			name: "out of order and retransmitted",
+			packets: [][]byte{
+				tcpFrame(router, bmp, 100, syn, nil),
+				tcpFrame(router, bmp, 107, 0, []byte("world")),
+				tcpFrame(router, bmp, 101, 0, []byte("hello ")),
+				tcpFrame(router, bmp, 101, 0, []byte("hello ")),
+				// Retransmission overlapping with the next segment
+				tcpFrame(router, bmp, 110, 0, []byte("ld, BMP")),
+			},
+			expect: []stream{{src: router, data: "hello world, BMP"}},
+		},
+		{
+			name: "captured in the middle of the connection with missing segment",
+			packets: [][]byte{
+				tcpFrame(router, bmp, 4294967290, 0, []byte("hello ")),
+				tcpFrame(router, bmp, 3, 0, []byte("BMP")),
+			},
+			expect: []stream{{src: router, data: "hello BMP", gaps: 1}},
+		},
+		{
+			name: "reconnected sessions and other traffic",
+			packets: [][]byte{
+				tcpFrame(router, bmp, 100, syn, nil),
+				tcpFrame(router, bmp, 101, fin, []byte("first")),
+				tcpFrame(router, other, 1, 0, []byte("bgp")),
+				tcpFrame(router6, bmp6, 0, 0, []byte("ipv6")),
+				tcpFrame(router, bmp, 1000, syn, nil),
+				tcpFrame(router, bmp, 1001, 0, []byte("second")),
+			},
+			expect: []stream{
+				{src: router, data: "first"},
+				{src: router6, data: "ipv6"},
+				{src: router, data: "second"},
+			},
+		},
+		{
+			name: "active mode session",
+			packets: [][]byte{
+				tcpFrame(collector, activeRouter, 700, syn, nil),
+				tcpFrame(activeRouter, collector, 100, syn, nil),
+				tcpFrame(collector, activeRouter, 701, 0, nil),
+				tcpFrame(activeRouter, collector, 101, 0, []byte("hello ")),
+				tcpFrame(activeRouter, collector, 107, fin, []byte("active")),
+			},
+			expect: []stream{{src: activeRouter, data: "hello active"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packets := make([]testPacket, len(tt.packets))
+			for i, p := range tt.packets {
+				packets[i] = testPacket{t: time.Unix(1600000000+int64(i), 0), data: p}
+			}
+			streams, err := ReadStreams(bytes.NewReader(writePcap(packets, binary.LittleEndian, false)), []int{5000})
+			if err != nil {
+				t.Fatalf("fail to read streams with error: %+v", err)
+			}
+			if len(streams) != len(tt.expect) {
+				t.Fatalf("expected %d streams but got %d: %+v", len(tt.expect), len(streams), streams)
+			}
+			for i, s := range streams {
+				if src, _ := s.Session(); src != tt.expect[i].src {
+					t.Fatalf("expected stream from %s but got %s", tt.expect[i].src, src)
+				}
+				if s.gaps != tt.expect[i].gaps {
+					t.Fatalf("expected %d gaps but got %d", tt.expect[i].gaps, s.gaps)
+				}
+				var data []byte
+				for {
+					_, b, err := s.Next()
+					if err == io.EOF {
+						break
+					}
+					data = append(data, b...)
+				}
+				if string(data) != tt.expect[i].data {
+					t.Fatalf("expected stream %q but got %q", tt.expect[i].data, data)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package pcap
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// chunk defines bytes of a stream carried by a single segment
+type chunk struct {
+	t time.Time
+	b []byte
+}
+
+// Stream is a TCP stream sent by a router to a BMP port reassembled from captured packets, Stream satisfies
+// gobmpsrv.StreamSource interface and can be replayed as a router's session.
+type Stream struct {
+	src    string
+	dst    string
+	chunks []chunk
+	// gaps is the number of holes in the stream left by segments missing from the capture
+	gaps int
+	size int
+}
+
+// Session returns the router's address and an empty label
+func (s *Stream) Session() (string, string) {
+	return s.src, ""
+}
+
+// Next returns the next chunk of the stream and the time its segment was captured
+func (s *Stream) Next() (time.Time, []byte, error) {
+	if len(s.chunks) == 0 {
+		return time.Time{}, nil, io.EOF
+	}
+	c := s.chunks[0]
+	s.chunks = s.chunks[1:]
+
+	return c.t, c.b, nil
+}
+
+// Close releases the chunks left in the stream
+func (s *Stream) Close() error {
+	s.chunks = nil
+	return nil
+}
+
+func (s *Stream) String() string {
+	return fmt.Sprintf("%s -> %s, %d bytes, %d gaps", s.src, s.dst, s.size, s.gaps)
+}
+
+// flow reassembles a stream from the segments of a TCP connection in one direction
+type flow struct {
+	stream *Stream
+	// next is the sequence number of the next byte of the stream, it is unknown until SYN or the first
+	// segment with data is captured
+	next   uint32
+	synced bool
+	// closed is set when FIN or RST is captured, the segments which were missing can still be captured after it
+	closed bool
+	// pending keeps the segments received ahead of next by their sequence numbers
+	pending map[uint32]chunk
+}
+
+func newFlow(s *segment) *flow {
+	return &flow{
+		stream:  &Stream{src: s.src(), dst: s.dst()},
+		pending: make(map[uint32]chunk),
+	}
+}
+
+func (f *flow) deliver(t time.Time, b []byte) {
+	if len(b) == 0 {
+		return
+	}
+	f.stream.chunks = append(f.stream.chunks, chunk{t: t, b: b})
+	f.stream.size += len(b)
+	f.next += uint32(len(b))
+}
+
+// add adds the segment's data to the stream, retransmitted data is dropped and the segments received out of order
+// are kept until the missing data is received.
+func (f *flow) add(t time.Time, seq uint32, b []byte) {
+	if !f.synced {
+		// The capture started after the connection was established
+		glog.V(5).Infof("stream %s -> %s is captured from the middle of the connection", f.stream.src, f.stream.dst)
+		f.next, f.synced = seq, true
+	}
+	if d := int32(seq - f.next); d > 0 {
+		if p, ok := f.pending[seq]; !ok || len(p.b) < len(b) {
+			f.pending[seq] = chunk{t: t, b: b}
+		}
+		return
+	}
+	f.deliver(t, trim(seq, f.next, b))
+	f.drain()
+}
+
+// drain delivers the pending segments which became contiguous with the stream
+func (f *flow) drain() {
+	for found := true; found; {
+		found = false
+		for seq, c := range f.pending {
+			if int32(seq-f.next) > 0 {
+				continue
+			}
+			delete(f.pending, seq)
+			f.deliver(c.t, trim(seq, f.next, c.b))
+			found = true
+		}
+	}
+}
+
+// flush delivers all pending segments skipping over the missing data
+func (f *flow) flush() {
+	for len(f.pending) != 0 {
+		first := true
+		var seq uint32
+		for s := range f.pending {
+			if first || int32(s-seq) < 0 {
+				seq, first = s, false
+			}
+		}
+		glog.Warningf("%d bytes of stream %s -> %s are missing from the capture", seq-f.next, f.stream.src, f.stream.dst)
+		f.stream.gaps++
+		f.next = seq
+		f.drain()
+	}
+}
+
+// trim returns the bytes of the segment starting at seq which follow next
+func trim(seq, next uint32, b []byte) []byte {
+	d := int(next - seq)
+	if d >= len(b) {
+		return nil
+	}
+
+	return b[d:]
+}
+
+// ReadStreams reads a pcap or pcapng file and returns TCP streams sent to or from any of the ports, the streams
+// are ordered by the time of their first data. Routers send BMP messages to the port of the collector in passive
+// mode and from their own port in active mode, the streams carrying no data, the collector's side of BMP sessions,
+// are dropped. Packets which cannot be decoded are skipped.
+func ReadStreams(r io.Reader, ports []int) ([]*Stream, error) {
+	pr, err := NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	bmpPorts := make(map[int]bool, len(ports))
+	for _, p := range ports {
+		bmpPorts[p] = true
+	}
+	flows := make(map[string]*flow)
+	streams := make([]*Stream, 0)
+	finish := func(f *flow) {
+		f.flush()
+		if f.stream.size != 0 {
+			streams = append(streams, f.stream)
+		}
+	}
+	skipped := 0
+	for {
+		p, err := pr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		s, err := decodeSegment(p.LinkType, p.Data)
+		if err != nil {
+			skipped++
+			glog.V(5).Infof("skipping packet captured at %s: %+v", p.Time, err)
+			continue
+		}
+		if s == nil || (!bmpPorts[s.dstPort] && !bmpPorts[s.srcPort]) {
+			continue
+		}
+		key := s.src() + "-" + s.dst()
+		f := flows[key]
+		if s.flags&tcpFlagSYN != 0 {
+			// SYN starts a new connection, the connection which used the same addresses and ports is finished
+			if f != nil && (f.closed || f.stream.size != 0 || len(f.pending) != 0) {
+				finish(f)
+				f = nil
+			}
+			if f == nil {
+				f = newFlow(s)
+				flows[key] = f
+			}
+			// SYN occupies a sequence number
+			s.seq++
+			f.next, f.synced = s.seq, true
+		}
+		if f == nil {
+			f = newFlow(s)
+			flows[key] = f
+		}
+		if len(s.payload) != 0 {
+			f.add(p.Time, s.seq, s.payload)
+		}
+		if s.flags&(tcpFlagFIN|tcpFlagRST) != 0 {
+			f.closed = true
+		}
+	}
+	if skipped != 0 {
+		glog.Warningf("%d packets could not be decoded and were skipped", skipped)
+	}
+	keys := make([]string, 0, len(flows))
+	for key := range flows {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		finish(flows[key])
+	}
+	sort.SliceStable(streams, func(i, j int) bool {
+		return streams[i].chunks[0].t.Before(streams[j].chunks[0].t)
+	})
+
+	return streams, nil
+}
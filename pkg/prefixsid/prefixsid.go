@@ -7,6 +7,9 @@ import (
 	"github.com/sbezverk/gobmp/pkg/tools"
 )
 
+// decoder builds errors of decoding Prefix SID attributes
+const decoder tools.Decoder = "prefixsid"
+
 // LabelIndexTLV defines Label index TLV
 // https://tools.ietf.org/html/rfc8669#section-3.1
 type LabelIndexTLV struct {
@@ -47,48 +50,52 @@ func UnmarshalBGPAttrPrefixSID(b []byte) (*PSid, error) {
 		OriginatorSRGB: nil,
 	}
 	for p := 0; p < len(b); {
+		// Type 1 byte and Length 2 bytes
+		if err := decoder.CheckLength("prefix sid tlv", b[p:], 3); err != nil {
+			return nil, err
+		}
+		t := b[p]
+		l := binary.BigEndian.Uint16(b[p+1 : p+3])
+		p += 3
+		if err := decoder.CheckLength("prefix sid tlv", b[p:], int(l)); err != nil {
+			return nil, err
+		}
+		v := b[p : p+int(l)]
+		p += int(l)
 		// Determin the type, currently only type 1 and 3 are supported
-		switch b[p] {
+		switch t {
 		case 1:
-			p++
+			// Reserved 1 byte, Flags 2 bytes and Label Index 4 bytes
+			if l != 7 {
+				return nil, decoder.Malformed("prefix sid label index tlv", "invalid length %d, expected 7", l)
+			}
 			psid.LabelIndex = &LabelIndexTLV{}
 			psid.LabelIndex.Type = 1
-			psid.LabelIndex.Length = binary.BigEndian.Uint16(b[p : p+2])
-			p += 2
-			// Skip reserved byte
-			p++
-			psid.LabelIndex.Flags = binary.BigEndian.Uint16(b[p : p+2])
-			p += 2
-			psid.LabelIndex.LabelIndex = binary.BigEndian.Uint32(b[p : p+4])
-			p += 4
+			psid.LabelIndex.Length = l
+			psid.LabelIndex.Flags = binary.BigEndian.Uint16(v[1:3])
+			psid.LabelIndex.LabelIndex = binary.BigEndian.Uint32(v[3:7])
 		case 3:
-			p++
+			// Flags 2 bytes followed by SRGBs, each SRGB takes 6 bytes.
+			if l < 2 || (l-2)%6 != 0 {
+				return nil, decoder.Malformed("prefix sid originator srgb tlv", "invalid length %d", l)
+			}
 			psid.OriginatorSRGB = &OriginatorSRGBTLV{}
-			psid.OriginatorSRGB.Type = 1
-			psid.OriginatorSRGB.Length = binary.BigEndian.Uint16(b[p : p+2])
-			p += 2
-			psid.OriginatorSRGB.Flags = binary.BigEndian.Uint16(b[p : p+2])
-			p += 2
-			// Multiple SRGB are possible, loop through, each SRGB takes 6 bytes. Subtrack 2 (length of Flags)
-			// from the total Value portion length
+			psid.OriginatorSRGB.Type = 3
+			psid.OriginatorSRGB.Length = l
+			psid.OriginatorSRGB.Flags = binary.BigEndian.Uint16(v[0:2])
 			psid.OriginatorSRGB.SRGB = make([]SRGB, 0)
-			for i := 0; i < int(psid.OriginatorSRGB.Length-2)/6; i++ {
+			for i := 2; i < len(v); i += 6 {
 				srgb := SRGB{}
 				t := make([]byte, 4)
-				copy(t, b[p:p+3])
+				copy(t[1:], v[i:i+3])
 				srgb.First = binary.BigEndian.Uint32(t)
-				p += 3
 				t = make([]byte, 4)
-				copy(t, b[p:p+3])
+				copy(t[1:], v[i+3:i+6])
 				srgb.Number = binary.BigEndian.Uint32(t)
-				p += 3
 				psid.OriginatorSRGB.SRGB = append(psid.OriginatorSRGB.SRGB, srgb)
 			}
 		default:
-			// Skip unknown type, length 2 bytes and the value
-			p++
-			p += int(binary.BigEndian.Uint16(b[p : p+2]))
-			p += 2
+			// Skip unknown type
 		}
 	}
 	return &psid, nil
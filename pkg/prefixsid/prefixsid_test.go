@@ -10,6 +10,7 @@ func TestUnmarshalBGPAttrPrefixSID(t *testing.T) {
 		name   string
 		input  []byte
 		expect *PSid
+		fail   bool
 	}{
 		{
 			name:  "mp unicast nlri 1",
@@ -23,13 +24,50 @@ func TestUnmarshalBGPAttrPrefixSID(t *testing.T) {
 				OriginatorSRGB: nil,
 			},
 		},
+		{
+			name:  "label index and originator srgb",
+			input: []byte{0x01, 0x00, 0x07, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xa4, 0x03, 0x00, 0x08, 0x00, 0x00, 0x00, 0x3e, 0x80, 0x00, 0x1f, 0x40},
+			expect: &PSid{
+				LabelIndex: &LabelIndexTLV{
+					Type:       1,
+					Length:     7,
+					LabelIndex: 164,
+				},
+				OriginatorSRGB: &OriginatorSRGBTLV{
+					Type:   3,
+					Length: 8,
+					SRGB: []SRGB{
+						{
+							First:  16000,
+							Number: 8000,
+						},
+					},
+				},
+			},
+		},
+		{
+			name:  "truncated label index",
+			input: []byte{0x01, 0x00, 0x07, 0x00, 0x00, 0x00},
+			fail:  true,
+		},
+		{
+			name:  "invalid label index length",
+			input: []byte{0x01, 0x00, 0x02, 0x00, 0x00},
+			fail:  true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			got, err := UnmarshalBGPAttrPrefixSID(tt.input)
-			if err != nil {
+			if err != nil && !tt.fail {
 				t.Fatalf("test failed with error: %+v", err)
 			}
+			if err == nil && tt.fail {
+				t.Fatalf("test supposed to fail but succeeded")
+			}
+			if err != nil {
+				return
+			}
 			if !reflect.DeepEqual(tt.expect, got) {
 				t.Fatalf("test failed as expected prefix sid %+v does not match the actual %+v", tt.expect, got)
 			}
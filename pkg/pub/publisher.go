@@ -4,6 +4,9 @@ package pub
 // msgType is the type of message, defined in pkg/bmp/consts.go
 // MsgHash optionally defines the key to use by the backend when storing message
 // msg is json marshaled message of msgType
+// Close flushes messages buffered by the publisher and releases its resources, no messages
+// can be published after Close.
 type Publisher interface {
 	PublishMessage(msgType int, msgHash []byte, msg []byte) error
+	Close() error
 }
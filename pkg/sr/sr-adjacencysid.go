@@ -27,6 +27,10 @@ func (asid *AdjacencySIDTLV) String() string {
 // UnmarshalAdjacencySIDTLV builds Adjacency SID TLV Object
 func UnmarshalAdjacencySIDTLV(b []byte) (*AdjacencySIDTLV, error) {
 	glog.V(6).Infof("Adjacency SID Raw: %s", tools.MessageHex(b))
+	// Flags, Weight or Algorithm and 2 bytes Reserved
+	if err := decoder.CheckLength("adjacency sid", b, 4); err != nil {
+		return nil, err
+	}
 	asid := AdjacencySIDTLV{}
 	p := 0
 	asid.Flags = b[p]
@@ -28,6 +28,10 @@ func UnmarshalSRCapabilityTLV(b []byte) ([]CapabilityTLV, error) {
 	glog.V(6).Infof("SR Capability TLV Raw: %s", tools.MessageHex(b))
 	caps := make([]CapabilityTLV, 0)
 	for p := 0; p < len(b); {
+		// 3 bytes of Range followed by sub tlv's Type and Length
+		if err := decoder.CheckLength("sr capability tlv", b[p:], 7); err != nil {
+			return nil, err
+		}
 		cap := CapabilityTLV{}
 		r := make([]byte, 4)
 		// Copy 3 bytes of Range into 4 byte slice to convert it into uint32
@@ -39,6 +43,9 @@ func UnmarshalSRCapabilityTLV(b []byte) ([]CapabilityTLV, error) {
 		p += 2
 		l := binary.BigEndian.Uint16(b[p : p+2])
 		p += 2
+		if err := decoder.CheckLength("sr capability tlv", b[p:], int(l)); err != nil {
+			return nil, err
+		}
 		v := make([]byte, l)
 		copy(v, b[p:p+int(l)])
 		p += int(l)
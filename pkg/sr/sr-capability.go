@@ -5,6 +5,9 @@ import (
 	"github.com/sbezverk/gobmp/pkg/tools"
 )
 
+// decoder builds errors of decoding Segment Routing TLVs
+const decoder tools.Decoder = "sr"
+
 // Capability defines SR Capability object
 // https://datatracker.ietf.org/doc/draft-ietf-idr-bgp-ls-segment-routing-ext/?include_text=1 Section 2.1.2
 type Capability struct {
@@ -15,6 +18,10 @@ type Capability struct {
 // UnmarshalSRCapability builds SR Capability object
 func UnmarshalSRCapability(b []byte) (*Capability, error) {
 	glog.V(6).Infof("SR Capability Raw: %s", tools.MessageHex(b))
+	// Flags and Reserved bytes
+	if err := decoder.CheckLength("sr capability", b, 2); err != nil {
+		return nil, err
+	}
 	cap := Capability{}
 	p := 0
 	cap.Flags = b[p]
@@ -26,6 +26,10 @@ func (lb *LocalBlock) String() string {
 // UnmarshalSRLocalBlock builds SR Local Block object
 func UnmarshalSRLocalBlock(b []byte) (*LocalBlock, error) {
 	glog.V(6).Infof("SR Local BLock Raw: %s", tools.MessageHex(b))
+	// Flags and Reserved bytes
+	if err := decoder.CheckLength("sr local block", b, 2); err != nil {
+		return nil, err
+	}
 	lb := LocalBlock{}
 	p := 0
 	lb.Flags = b[p]
@@ -27,6 +27,10 @@ func (psid *PrefixSIDTLV) String() string {
 // UnmarshalPrefixSIDTLV builds Prefix SID TLV Object
 func UnmarshalPrefixSIDTLV(b []byte) (*PrefixSIDTLV, error) {
 	glog.V(6).Infof("Prefix SID TLV Raw: %s", tools.MessageHex(b))
+	// Flags, Weight or Algorithm and 2 bytes Reserved
+	if err := decoder.CheckLength("prefix sid", b, 4); err != nil {
+		return nil, err
+	}
 	psid := PrefixSIDTLV{}
 	p := 0
 	psid.Flags = b[p]
@@ -0,0 +1,35 @@
+package srv6
+
+import "testing"
+
+func FuzzUnmarshalSRv6SIDNLRI(f *testing.F) {
+	f.Add([]byte{0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x1a, 0x02, 0x00, 0x00, 0x04, 0x00, 0x00, 0x13, 0xce, 0x02, 0x01, 0x00, 0x04, 0x00, 0x00, 0x00, 0x00, 0x02, 0x03, 0x00, 0x06, 0x00, 0x00, 0x00, 0x00, 0x00, 0x09, 0x01, 0x07, 0x00, 0x02, 0x00, 0x02, 0x02, 0x06, 0x00, 0x10, 0x01, 0x92, 0x01, 0x68, 0x00, 0x09, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00})
+	f.Fuzz(func(t *testing.T, b []byte) {
+		sr, err := UnmarshalSRv6SIDNLRI(b)
+		if err != nil {
+			return
+		}
+		_ = sr.String()
+		sr.GetAllAttribute()
+		sr.GetSRv6SIDProtocolID()
+		sr.GetSRv6SIDLSID()
+		sr.GetSRv6SIDIGPRouterID()
+		sr.GetSRv6SIDASN()
+		sr.GetSRv6SIDMTID()
+		sr.GetSRv6SID()
+	})
+}
+
+func FuzzUnmarshalSRv6EndXSIDTLV(f *testing.F) {
+	f.Add([]byte{0x00, 0x30, 0x00, 0x00, 0x00, 0x00, 0x01, 0x92, 0x01, 0x68, 0x00, 0x09, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00})
+	f.Fuzz(func(t *testing.T, b []byte) {
+		UnmarshalSRv6EndXSIDTLV(b)
+	})
+}
+
+func FuzzUnmarshalSRv6LocatorTLV(f *testing.F) {
+	f.Add([]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0a})
+	f.Fuzz(func(t *testing.T, b []byte) {
+		UnmarshalSRv6LocatorTLV(b)
+	})
+}
@@ -32,6 +32,10 @@ func (b *BGPPeerNodeSID) String() string {
 // UnmarshalSRv6BGPPeerNodeSIDTLV builds SRv6 BGP Peer Node SID TLV object
 func UnmarshalSRv6BGPPeerNodeSIDTLV(b []byte) (*BGPPeerNodeSID, error) {
 	glog.V(6).Infof("SRv6 BGP Peer Node SID TLV Raw: %s", tools.MessageHex(b))
+	// Flag, Weight, Reserved, Peer AS and Peer BGP Identifier
+	if err := decoder.CheckLength("srv6 bgp peer node sid", b, 12); err != nil {
+		return nil, err
+	}
 	bgp := BGPPeerNodeSID{}
 	p := 0
 	bgp.Flag = b[p]
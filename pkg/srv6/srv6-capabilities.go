@@ -27,6 +27,9 @@ func (cap *CapabilityTLV) String() string {
 // UnmarshalSRv6CapabilityTLV builds SRv6 Capability TLV object
 func UnmarshalSRv6CapabilityTLV(b []byte) (*CapabilityTLV, error) {
 	glog.V(6).Infof("SRv6 Capability TLV Raw: %s", tools.MessageHex(b))
+	if err := decoder.CheckLength("srv6 capability", b, 2); err != nil {
+		return nil, err
+	}
 	cap := CapabilityTLV{}
 	p := 0
 	cap.Flag = binary.BigEndian.Uint16(b[p : p+2])
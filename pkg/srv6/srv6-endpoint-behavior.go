@@ -30,6 +30,9 @@ func (e *EndpointBehavior) String() string {
 // UnmarshalSRv6EndpointBehaviorTLV builds SRv6 Endpoint Behavior TLV object
 func UnmarshalSRv6EndpointBehaviorTLV(b []byte) (*EndpointBehavior, error) {
 	glog.V(6).Infof("SRv6 End.X SID TLV Raw: %s", tools.MessageHex(b))
+	if err := decoder.CheckLength("srv6 endpoint behavior", b, 4); err != nil {
+		return nil, err
+	}
 	e := EndpointBehavior{}
 	p := 0
 	e.EndpointBehavior = binary.BigEndian.Uint16(b[p : p+2])
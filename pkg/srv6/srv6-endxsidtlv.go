@@ -42,6 +42,10 @@ func (x *EndXSIDTLV) String() string {
 // UnmarshalSRv6EndXSIDTLV builds SRv6 End.X SID TLV object
 func UnmarshalSRv6EndXSIDTLV(b []byte) (*EndXSIDTLV, error) {
 	glog.V(6).Infof("SRv6 End.X SID TLV Raw: %s", tools.MessageHex(b))
+	// Endpoint Behavior, Flag, Algorithm, Weight, Reserved and SID
+	if err := decoder.CheckLength("srv6 end.x sid", b, 22); err != nil {
+		return nil, err
+	}
 	endx := EndXSIDTLV{
 		SID: make([]byte, 16),
 	}
@@ -28,11 +28,17 @@ func UnmarshalSRv6SIDInformationTLV(b []byte) ([]SIDInformationTLV, error) {
 	glog.V(6).Infof("SRv6 SID Information TLV Raw: %s", tools.MessageHex(b))
 	tlvs := make([]SIDInformationTLV, 0)
 	for p := 0; p < len(b); {
+		if err := decoder.CheckLength("srv6 sid information tlv", b[p:], 4); err != nil {
+			return nil, err
+		}
 		tlv := SIDInformationTLV{}
 		tlv.Type = binary.BigEndian.Uint16(b[p : p+2])
 		p += 2
 		tlv.Length = binary.BigEndian.Uint16(b[p : p+2])
 		p += 2
+		if err := decoder.CheckLength("srv6 sid information tlv", b[p:], int(tlv.Length)); err != nil {
+			return nil, err
+		}
 		tlv.SID = make([]byte, tlv.Length)
 		copy(tlv.SID, b[p:p+int(tlv.Length)])
 		tlvs = append(tlvs, tlv)
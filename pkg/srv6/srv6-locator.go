@@ -37,6 +37,10 @@ func (loc *LocatorTLV) String() string {
 // UnmarshalSRv6LocatorTLV builds SRv6 Locator TLV object
 func UnmarshalSRv6LocatorTLV(b []byte) (*LocatorTLV, error) {
 	glog.V(6).Infof("SRv6 Locator TLV Raw: %s", tools.MessageHex(b))
+	// Flag, Algorithm, Reserved and Metric
+	if err := decoder.CheckLength("srv6 locator", b, 8); err != nil {
+		return nil, err
+	}
 	loc := LocatorTLV{}
 	p := 0
 	loc.Flag = b[p]
@@ -30,6 +30,9 @@ func (st *SIDStructure) String() string {
 // UnmarshalSRv6SIDStructureTLV builds SRv6 SID Structure TLV object
 func UnmarshalSRv6SIDStructureTLV(b []byte) (*SIDStructure, error) {
 	glog.V(6).Infof("SRv6 SID Structure TLV Raw: %s", tools.MessageHex(b))
+	if err := decoder.CheckLength("srv6 sid structure", b, 4); err != nil {
+		return nil, err
+	}
 	st := SIDStructure{}
 	p := 0
 	st.LBLength = b[p]
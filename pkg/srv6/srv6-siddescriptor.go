@@ -2,7 +2,6 @@ package srv6
 
 import (
 	"encoding/binary"
-	"fmt"
 
 	"github.com/golang/glog"
 	"github.com/sbezverk/gobmp/pkg/base"
@@ -35,25 +34,29 @@ func UnmarshalSRv6SIDDescriptor(b []byte) (*SIDDescriptor, error) {
 	glog.V(6).Infof("SRv6 SID Descriptor Raw: %s", tools.MessageHex(b))
 	srd := SIDDescriptor{}
 	for p := 0; p < len(b); {
+		if err := decoder.CheckLength("srv6 sid descriptor", b[p:], 4); err != nil {
+			return nil, err
+		}
 		t := binary.BigEndian.Uint16(b[p : p+2])
-		var l uint16
+		l := binary.BigEndian.Uint16(b[p+2 : p+4])
+		if err := decoder.CheckLength("srv6 sid descriptor", b[p+4:], int(l)); err != nil {
+			return nil, err
+		}
 		switch t {
 		case 518:
-			l = binary.BigEndian.Uint16(b[p+2 : p+4])
-			inf, err := UnmarshalSRv6SIDInformationTLV(b[p : p+int(l)])
+			inf, err := UnmarshalSRv6SIDInformationTLV(b[p : p+4+int(l)])
 			if err != nil {
 				return nil, err
 			}
 			srd.TLV = inf
 		case 263:
-			l = binary.BigEndian.Uint16(b[p+2 : p+4])
-			mti, err := base.UnmarshalMultiTopologyIdentifierTLV(b[p : p+int(l)])
+			mti, err := base.UnmarshalMultiTopologyIdentifierTLV(b[p+4 : p+4+int(l)])
 			if err != nil {
 				return nil, err
 			}
 			srd.MultiTopologyIdentifier = mti
 		default:
-			return nil, fmt.Errorf("invalid SRv6 SID Descriptor Type: %d", t)
+			return nil, decoder.Malformed("srv6 sid descriptor", "invalid type %d", t)
 		}
 		p += 2      // Type
 		p += 2      // Length
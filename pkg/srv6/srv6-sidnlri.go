@@ -10,6 +10,9 @@ import (
 	"github.com/sbezverk/gobmp/pkg/tools"
 )
 
+// decoder builds errors of decoding SRv6 TLVs
+const decoder tools.Decoder = "srv6"
+
 // SIDNLRI defines SRv6 SID NLRI onject
 // Mp RFC yet
 type SIDNLRI struct {
@@ -92,6 +95,10 @@ func (sr *SIDNLRI) GetSRv6SID() []string {
 // UnmarshalSRv6SIDNLRI builds SRv6SIDNLRI NLRI object
 func UnmarshalSRv6SIDNLRI(b []byte) (*SIDNLRI, error) {
 	glog.V(6).Infof("SRv6 SID NLRI Raw: %s", tools.MessageHex(b))
+	// Protocol ID, Identifier and Node Descriptor's Type and Length
+	if err := decoder.CheckLength("srv6 sid nlri", b, 13); err != nil {
+		return nil, err
+	}
 	sr := SIDNLRI{}
 	p := 0
 	sr.ProtocolID = b[p]
@@ -102,7 +109,10 @@ func UnmarshalSRv6SIDNLRI(b []byte) (*SIDNLRI, error) {
 	p += 8
 	// Get Node Descriptor's length, skip Node Descriptor Type
 	l := binary.BigEndian.Uint16(b[p+2 : p+4])
-	ln, err := base.UnmarshalNodeDescriptor(b[p : p+int(l)])
+	if err := decoder.CheckLength("srv6 sid nlri", b[p+4:], int(l)); err != nil {
+		return nil, err
+	}
+	ln, err := base.UnmarshalNodeDescriptor(b[p : p+4+int(l)])
 	if err != nil {
 		return nil, err
 	}
@@ -25,11 +25,17 @@ func UnmarshalSRv6SubTLV(b []byte) ([]SubTLV, error) {
 	glog.V(6).Infof("SRv6 Sub TLV Raw: %s", tools.MessageHex(b))
 	stlvs := make([]SubTLV, 0)
 	for p := 0; p < len(b); {
+		if err := decoder.CheckLength("srv6 sub tlv", b[p:], 4); err != nil {
+			return nil, err
+		}
 		stlv := SubTLV{}
 		stlv.Type = binary.BigEndian.Uint16(b[p : p+2])
 		p += 2
 		stlv.Length = binary.BigEndian.Uint16(b[p : p+2])
 		p += 2
+		if err := decoder.CheckLength("srv6 sub tlv", b[p:], int(stlv.Length)); err != nil {
+			return nil, err
+		}
 		stlv.Value = make([]byte, stlv.Length)
 		copy(stlv.Value, b[p:p+int(stlv.Length)])
 		p += int(stlv.Length)
@@ -49,7 +49,7 @@ func TestUnmarshalSRv6SIDNLRI(t *testing.T) {
 						},
 					},
 					MultiTopologyIdentifier: &base.MultiTopologyIdentifierTLV{
-						MTI: []base.MultiTopologyIdentifier{263},
+						MTI: []base.MultiTopologyIdentifier{2},
 					},
 				},
 			},
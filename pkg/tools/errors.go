@@ -0,0 +1,59 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrTruncated is returned when a message is shorter than its format or its length fields require
+	ErrTruncated = errors.New("truncated")
+	// ErrMalformed is returned when a message carries an invalid value
+	ErrMalformed = errors.New("malformed")
+)
+
+// DecodeError defines an error returned by decoders when a message cannot be decoded,
+// Err is either ErrTruncated or ErrMalformed and can be checked with errors.Is.
+type DecodeError struct {
+	Object string
+	Err    error
+	Detail string
+	// Package is the name of the package which failed to decode the object
+	Package string
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("%s %s: %s", e.Object, e.Err, e.Detail)
+}
+
+// Unwrap returns the underlying error
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Decoder builds DecodeErrors attributed to the decoding package, every decoding package declares
+// a Decoder constant set to its name.
+type Decoder string
+
+// CheckLength returns ErrTruncated DecodeError when the byte slice is shorter than l
+func (d Decoder) CheckLength(object string, b []byte, l int) error {
+	if len(b) < l {
+		return d.newDecodeError(object, ErrTruncated, fmt.Sprintf("need %d bytes, have %d", l, len(b)))
+	}
+
+	return nil
+}
+
+// Malformed returns ErrMalformed DecodeError with a detail message built from format and args
+func (d Decoder) Malformed(object string, format string, args ...interface{}) error {
+	return d.newDecodeError(object, ErrMalformed, fmt.Sprintf(format, args...))
+}
+
+func (d Decoder) newDecodeError(object string, err error, detail string) error {
+	return &DecodeError{
+		Object:  object,
+		Err:     err,
+		Detail:  detail,
+		Package: string(d),
+	}
+}
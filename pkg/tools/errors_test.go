@@ -0,0 +1,56 @@
+package tools
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDecodeErrors(t *testing.T) {
+	const decoder Decoder = "bmp"
+	tests := []struct {
+		name   string
+		err    error
+		expect error
+		msg    string
+	}{
+		{
+			name: "sufficient length",
+			err:  decoder.CheckLength("bmp common header", []byte{3, 0, 0, 0, 6, 4}, 6),
+		},
+		{
+			name:   "truncated",
+			err:    decoder.CheckLength("bmp common header", []byte{3, 0, 0}, 6),
+			expect: ErrTruncated,
+			msg:    "bmp common header truncated: need 6 bytes, have 3",
+		},
+		{
+			name:   "malformed",
+			err:    decoder.Malformed("bmp common header", "invalid version, expected 3 found %d", 2),
+			expect: ErrMalformed,
+			msg:    "bmp common header malformed: invalid version, expected 3 found 2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.expect == nil {
+				if tt.err != nil {
+					t.Fatalf("expected no error but got: %+v", tt.err)
+				}
+				return
+			}
+			if !errors.Is(tt.err, tt.expect) {
+				t.Fatalf("expected error %+v but got: %+v", tt.expect, tt.err)
+			}
+			var de *DecodeError
+			if !errors.As(tt.err, &de) {
+				t.Fatalf("expected DecodeError but got %T", tt.err)
+			}
+			if de.Package != "bmp" {
+				t.Fatalf("expected package bmp but got %q", de.Package)
+			}
+			if tt.err.Error() != tt.msg {
+				t.Fatalf("expected error message %q but got %q", tt.msg, tt.err.Error())
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ module processor
 go 1.14
 
 replace (
+	github.com/sbezverk/gobmp/pkg/metrics => ../../metrics
 	github.com/sbezverk/gobmp/pkg/topology/dbclient => ../dbclient
 	github.com/sbezverk/gobmp/pkg/topology/kafkamessenger => ../kafkamessenger
 	github.com/sbezverk/gobmp/pkg/topology/messenger => ../messenger
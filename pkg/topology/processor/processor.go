@@ -2,13 +2,21 @@ package processor
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/golang/glog"
 	"github.com/sbezverk/gobmp/pkg/bmp"
 	"github.com/sbezverk/gobmp/pkg/message"
+	"github.com/sbezverk/gobmp/pkg/metrics"
 	"github.com/sbezverk/gobmp/pkg/topology/dbclient"
 )
 
+var (
+	inflightMessages = metrics.NewGaugeVec("gobmp_topology_inflight_messages", "Number of messages received by the processor and not yet stored in the database.", "type")
+	storeDuration    = metrics.NewHistogramVec("gobmp_topology_store_duration_seconds", "Time taken to store a message in the database.", metrics.DefBuckets, "type")
+	storeFailures    = metrics.NewCounterVec("gobmp_topology_store_failures_total", "Number of messages failed to unmarshal or to store in the database.", "type")
+)
+
 // Messenger defines required methonds of a messaging client
 type Messenger interface {
 	// SendMessage is used by the messanger client to send message to Processor for processing
@@ -66,6 +74,7 @@ func (p *processor) GetInterface() Messenger {
 }
 
 func (p *processor) SendMessage(msgType int, msg []byte) {
+	inflightMessages.With(message.TypeName(msgType)).Inc()
 	p.queue <- &queueMsg{
 		msgType: msgType,
 		msgData: msg,
@@ -86,55 +95,61 @@ func (p *processor) msgProcessor() {
 }
 
 func (p *processor) procWorker(m *queueMsg) {
+	defer inflightMessages.With(message.TypeName(m.msgType)).Dec()
 	// var obj interface{}
 	switch m.msgType {
 	case bmp.PeerStateChangeMsg:
 		var o message.PeerStateChange
 		if err := json.Unmarshal(m.msgData, &o); err != nil {
-			glog.Errorf("failed to unmarshal message of type %d with error: %+v", err)
+			glog.Errorf("failed to unmarshal message of type %d with error: %+v", m.msgType, err)
+			storeFailures.With(message.TypeName(m.msgType)).Inc()
 			return
 		}
-		if err := p.db.StoreMessage(m.msgType, &o); err != nil {
+		if err := p.store(m.msgType, &o); err != nil {
 			glog.Errorf("failed to store message of type: %d in the database with error: %+v", m.msgType, err)
 			return
 		}
 	case bmp.UnicastPrefixMsg:
 		var o message.UnicastPrefix
 		if err := json.Unmarshal(m.msgData, &o); err != nil {
-			glog.Errorf("failed to unmarshal message of type %d with error: %+v", err)
+			glog.Errorf("failed to unmarshal message of type %d with error: %+v", m.msgType, err)
+			storeFailures.With(message.TypeName(m.msgType)).Inc()
 			return
 		}
-		if err := p.db.StoreMessage(m.msgType, &o); err != nil {
+		if err := p.store(m.msgType, &o); err != nil {
 			glog.Errorf("failed to store message of type: %d in the database with error: %+v", m.msgType, err)
 			return
 		}
 	case bmp.LSNodeMsg:
 		var o message.LSNode
 		if err := json.Unmarshal(m.msgData, &o); err != nil {
-			glog.Errorf("failed to unmarshal message of type %d with error: %+v", err)
+			glog.Errorf("failed to unmarshal message of type %d with error: %+v", m.msgType, err)
+			storeFailures.With(message.TypeName(m.msgType)).Inc()
 			return
 		}
-		if err := p.db.StoreMessage(m.msgType, &o); err != nil {
+		if err := p.store(m.msgType, &o); err != nil {
 			glog.Errorf("failed to store message of type: %d in the database with error: %+v", m.msgType, err)
 			return
 		}
 	case bmp.LSLinkMsg:
 		var o message.LSLink
 		if err := json.Unmarshal(m.msgData, &o); err != nil {
-			glog.Errorf("failed to unmarshal message of type %d with error: %+v", err)
+			glog.Errorf("failed to unmarshal message of type %d with error: %+v", m.msgType, err)
+			storeFailures.With(message.TypeName(m.msgType)).Inc()
 			return
 		}
-		if err := p.db.StoreMessage(m.msgType, &o); err != nil {
+		if err := p.store(m.msgType, &o); err != nil {
 			glog.Errorf("failed to store message of type: %d in the database with error: %+v", m.msgType, err)
 			return
 		}
 	case bmp.L3VPNMsg:
 		var o message.L3VPNPrefix
 		if err := json.Unmarshal(m.msgData, &o); err != nil {
-			glog.Errorf("failed to unmarshal message of type %d with error: %+v", err)
+			glog.Errorf("failed to unmarshal message of type %d with error: %+v", m.msgType, err)
+			storeFailures.With(message.TypeName(m.msgType)).Inc()
 			return
 		}
-		if err := p.db.StoreMessage(m.msgType, &o); err != nil {
+		if err := p.store(m.msgType, &o); err != nil {
 			glog.Errorf("failed to store message of type: %d in the database with error: %+v", m.msgType, err)
 			return
 		}
@@ -142,3 +157,15 @@ func (p *processor) procWorker(m *queueMsg) {
 
 	glog.Infof("message of type %d was stored in the database", m.msgType)
 }
+
+// store stores the message in the database and records the time taken in gobmp_topology_store_duration_seconds metric
+func (p *processor) store(msgType int, msg interface{}) error {
+	start := time.Now()
+	if err := p.db.StoreMessage(msgType, msg); err != nil {
+		storeFailures.With(message.TypeName(msgType)).Inc()
+		return err
+	}
+	storeDuration.With(message.TypeName(msgType)).Observe(time.Since(start).Seconds())
+
+	return nil
+}
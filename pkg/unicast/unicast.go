@@ -8,8 +8,13 @@ import (
 	"github.com/sbezverk/gobmp/pkg/tools"
 )
 
+// decoder builds errors of decoding unicast and labeled unicast NLRI
+const decoder tools.Decoder = "unicast"
+
 // MPUnicastPrefix defines a single NLRI entry
 type MPUnicastPrefix struct {
+	// PathID is the Path Identifier of the prefix received from ADD-PATH peer (RFC 7911)
+	PathID uint32
 	AFI    uint16
 	SAFI   uint8
 	Count  uint8
@@ -23,16 +28,39 @@ type MPUnicastNLRI struct {
 	NLRI []MPUnicastPrefix
 }
 
-// UnmarshalUnicastNLRI builds MP NLRI object from the slice of bytes
-func UnmarshalUnicastNLRI(b []byte) (*MPUnicastNLRI, error) {
+// unmarshalPathID returns 4 bytes Path Identifier prefixing NLRI at p
+func unmarshalPathID(name string, b []byte, p int) (uint32, error) {
+	// Path Identifier is followed by at least 1 byte of the prefix length
+	if err := decoder.CheckLength(name, b[p:], 5); err != nil {
+		return 0, err
+	}
+
+	return binary.BigEndian.Uint32(b[p : p+4]), nil
+}
+
+// UnmarshalUnicastNLRI builds MP NLRI object from the slice of bytes, when pathID is true, every prefix
+// is prefixed by 4 bytes Path Identifier.
+func UnmarshalUnicastNLRI(b []byte, pathID bool) (*MPUnicastNLRI, error) {
 	glog.V(6).Infof("MP Unicast NLRI Raw: %s", tools.MessageHex(b))
 	mpnlri := MPUnicastNLRI{
 		NLRI: make([]MPUnicastPrefix, 0),
 	}
 	for p := 0; p < len(b); {
 		up := MPUnicastPrefix{}
+		if pathID {
+			id, err := unmarshalPathID("unicast nlri", b, p)
+			if err != nil {
+				return nil, err
+			}
+			up.PathID = id
+			p += 4
+		}
 		// When default prefix is sent, actual NLRI is 1 byte with value of 0x0
-		if b[p] == 0x0 && len(b) != 1 {
+		if !pathID && b[p] == 0x0 && len(b) != 1 {
+			// AFI, SAFI, Count and Length
+			if err := decoder.CheckLength("unicast nlri", b[p:], 5); err != nil {
+				return nil, err
+			}
 			up.AFI = binary.BigEndian.Uint16(b[p : p+2])
 			p += 2
 			up.SAFI = b[p]
@@ -46,6 +74,9 @@ func UnmarshalUnicastNLRI(b []byte) (*MPUnicastNLRI, error) {
 		if up.Length%8 != 0 {
 			l++
 		}
+		if err := decoder.CheckLength("unicast nlri", b[p:], l); err != nil {
+			return nil, err
+		}
 		up.Prefix = make([]byte, l)
 		copy(up.Prefix, b[p:p+l])
 		p += l
@@ -55,8 +86,9 @@ func UnmarshalUnicastNLRI(b []byte) (*MPUnicastNLRI, error) {
 	return &mpnlri, nil
 }
 
-// UnmarshalLUNLRI builds MP NLRI object from the slice of bytes
-func UnmarshalLUNLRI(b []byte) (*MPUnicastNLRI, error) {
+// UnmarshalLUNLRI builds MP NLRI object from the slice of bytes, when pathID is true, every prefix
+// is prefixed by 4 bytes Path Identifier.
+func UnmarshalLUNLRI(b []byte, pathID bool) (*MPUnicastNLRI, error) {
 	glog.V(6).Infof("MP Label Unicast NLRI Raw: %s", tools.MessageHex(b))
 	mpnlri := MPUnicastNLRI{
 		NLRI: make([]MPUnicastPrefix, 0),
@@ -65,7 +97,19 @@ func UnmarshalLUNLRI(b []byte) (*MPUnicastNLRI, error) {
 		up := MPUnicastPrefix{
 			Label: make([]*base.Label, 0),
 		}
-		if b[p] == 0x0 {
+		if pathID {
+			id, err := unmarshalPathID("labeled unicast nlri", b, p)
+			if err != nil {
+				return nil, err
+			}
+			up.PathID = id
+			p += 4
+		}
+		if !pathID && b[p] == 0x0 {
+			// AFI, SAFI, Count and Length
+			if err := decoder.CheckLength("labeled unicast nlri", b[p:], 5); err != nil {
+				return nil, err
+			}
 			up.AFI = binary.BigEndian.Uint16(b[p : p+2])
 			p += 2
 			up.SAFI = b[p]
@@ -76,7 +120,7 @@ func UnmarshalLUNLRI(b []byte) (*MPUnicastNLRI, error) {
 		up.Length = b[p]
 		p++
 		bos := false
-		for !bos && p < len(b) {
+		for !bos && p+3 <= len(b) {
 			label, err := base.MakeLabel(b[p : p+3])
 			if err != nil {
 				return nil, err
@@ -89,6 +133,12 @@ func UnmarshalLUNLRI(b []byte) (*MPUnicastNLRI, error) {
 		if up.Length%8 != 0 {
 			l++
 		}
+		if l < 0 {
+			return nil, decoder.Malformed("labeled unicast nlri", "prefix length %d is shorter than the labels' length", up.Length)
+		}
+		if err := decoder.CheckLength("labeled unicast nlri", b[p:], l); err != nil {
+			return nil, err
+		}
 		up.Prefix = make([]byte, l)
 		copy(up.Prefix, b[p:p+l])
 		p += l
@@ -11,6 +11,7 @@ func TestUnmarshalUnicastNLRI(t *testing.T) {
 	tests := []struct {
 		name   string
 		input  []byte
+		pathID bool
 		expect *MPUnicastNLRI
 	}{
 		{
@@ -86,6 +87,25 @@ func TestUnmarshalUnicastNLRI(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:   "mp unicast nlri with path id",
+			input:  []byte{0x00, 0x00, 0x00, 0x01, 0x20, 0x0a, 0x00, 0x00, 0x02, 0x00, 0x00, 0x01, 0x00, 0x00},
+			pathID: true,
+			expect: &MPUnicastNLRI{
+				NLRI: []MPUnicastPrefix{
+					{
+						PathID: 1,
+						Length: 0x20,
+						Prefix: []byte{0x0a, 0x00, 0x00, 0x02},
+					},
+					{
+						PathID: 256,
+						Length: 0x0,
+						Prefix: []byte{},
+					},
+				},
+			},
+		},
 		{
 			name:  "Default prefix",
 			input: []byte{0x0},
@@ -104,7 +124,7 @@ func TestUnmarshalUnicastNLRI(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := UnmarshalUnicastNLRI(tt.input)
+			got, err := UnmarshalUnicastNLRI(tt.input, tt.pathID)
 			if err != nil {
 				t.Fatalf("test failed with error: %+v", err)
 			}
@@ -119,6 +139,7 @@ func TestUnmarshalLUNLRI(t *testing.T) {
 	tests := []struct {
 		name   string
 		input  []byte
+		pathID bool
 		expect *MPUnicastNLRI
 	}{
 		{
@@ -159,10 +180,31 @@ func TestUnmarshalLUNLRI(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:   "mp labeled unicast nlri with path id",
+			input:  []byte{0x00, 0x00, 0x00, 0x02, 0x38, 0x00, 0x00, 0x31, 0x0a, 0x00, 0x00, 0x00},
+			pathID: true,
+			expect: &MPUnicastNLRI{
+				NLRI: []MPUnicastPrefix{
+					{
+						PathID: 2,
+						Length: 32,
+						Label: []*base.Label{
+							{
+								Value: 3,
+								Exp:   0x0,
+								BoS:   true,
+							},
+						},
+						Prefix: []byte{0x0a, 0x00, 0x00, 0x00},
+					},
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := UnmarshalLUNLRI(tt.input)
+			got, err := UnmarshalLUNLRI(tt.input, tt.pathID)
 			if err != nil {
 				t.Fatalf("test failed with error: %+v", err)
 			}